@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/github"
+	"github.com/Tovli/chatops/internal/adapters/gitlab"
+	"github.com/Tovli/chatops/internal/adapters/jenkins"
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/storage"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/postgres"
+	"github.com/Tovli/chatops/internal/infrastructure/workflows"
+	"go.uber.org/zap"
+)
+
+const checkTimeout = 10 * time.Second
+
+// pinger is a CI provider adapter able to check its connectivity
+type pinger interface {
+	Name() string
+	Ping(ctx context.Context) error
+}
+
+// checker prints the outcome of each check and counts the failures
+type checker struct {
+	out      io.Writer
+	failures int
+}
+
+func (c *checker) ok(format string, args ...interface{}) {
+	fmt.Fprintf(c.out, "ok    %s\n", fmt.Sprintf(format, args...))
+}
+
+func (c *checker) warn(format string, args ...interface{}) {
+	fmt.Fprintf(c.out, "warn  %s\n", fmt.Sprintf(format, args...))
+}
+
+func (c *checker) fail(format string, args ...interface{}) {
+	c.failures++
+	fmt.Fprintf(c.out, "fail  %s\n", fmt.Sprintf(format, args...))
+}
+
+// checkConfigCommand validates the configuration and, unless offline, the connectivity to
+// the database, the CI providers and Slack
+func checkConfigCommand(out io.Writer, logger *zap.Logger, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("check-config", flag.ContinueOnError)
+	offline := fs.Bool("offline", false, "validate the configuration only, without connecting to anything")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return usageError(os.Stderr)
+	}
+
+	c := &checker{out: out}
+	ctx := context.Background()
+
+	// Configuration
+	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
+		c.fail("server port %d is invalid", cfg.Server.Port)
+	} else {
+		c.ok("server listens on %s:%d", cfg.Server.Host, cfg.Server.Port)
+	}
+	switch {
+	case cfg.GRPC.Port == 0:
+		c.ok("gRPC server disabled")
+	case cfg.GRPC.Port < 0 || cfg.GRPC.Port > 65535 || cfg.GRPC.Port == cfg.Server.Port:
+		c.fail("gRPC port %d is invalid or taken by the HTTP server", cfg.GRPC.Port)
+	default:
+		c.ok("gRPC server listens on %s:%d", cfg.GRPC.Host, cfg.GRPC.Port)
+	}
+	if cfg.Slack.BotToken == "" || cfg.Slack.SigningKey == "" {
+		c.fail("slack bot token and signing key are required")
+	}
+	if cfg.API.AdminToken == "" {
+		c.warn("no API admin token, API tokens cannot be bootstrapped")
+	}
+
+	freezes, err := freezeWindows(cfg.Deployments.Freezes)
+	if err == nil {
+		_, err = services.NewDeploymentGuard(services.DeploymentGuardOptions{Freezes: freezes})
+	}
+	if err != nil {
+		c.fail("freeze windows: %v", err)
+	} else {
+		c.ok("%d freeze window(s)", len(freezes))
+	}
+
+	workflowDir := cfg.Workflows.Dir
+	if workflowDir == "" {
+		workflowDir = defaultWorkflowDir
+	}
+	if definitions, err := workflows.LoadDir(workflowDir); err != nil {
+		c.fail("workflows: %v", err)
+	} else {
+		c.ok("%d workflow(s) in %s", len(definitions), workflowDir)
+	}
+
+	var providers []pinger
+	if cfg.GitHub.Token != "" {
+		if adapter, err := github.NewGitHubAdapter(logger, &cfg.GitHub); err != nil {
+			c.fail("github: %v", err)
+		} else {
+			providers = append(providers, adapter)
+		}
+	}
+	if cfg.GitLab.Token != "" {
+		if adapter, err := gitlab.NewGitLabAdapter(logger, &cfg.GitLab); err != nil {
+			c.fail("gitlab: %v", err)
+		} else {
+			providers = append(providers, adapter)
+		}
+	}
+	if cfg.Jenkins.BaseURL != "" {
+		if adapter, err := jenkins.NewJenkinsAdapter(logger, &cfg.Jenkins); err != nil {
+			c.fail("jenkins: %v", err)
+		} else {
+			providers = append(providers, adapter)
+		}
+	}
+	if cfg.GitHub.Token == "" && cfg.GitLab.Token == "" && cfg.Jenkins.BaseURL == "" {
+		c.warn("no CI provider configured")
+	}
+
+	if *offline {
+		return c.result()
+	}
+
+	// Connectivity, reporting pending Postgres migrations rather than applying them
+	switch cfg.Database.Driver {
+	case "", storage.DriverPostgres:
+		dbConfig := cfg.Database
+		dbConfig.AutoMigrate = false
+		if db, err := postgres.NewConnection(dbConfig); err != nil {
+			c.fail("database %s:%d/%s: %v", cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName, err)
+		} else {
+			defer db.Close()
+			c.ok("database %s:%d/%s", cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName)
+			checkSchema(ctx, c, db)
+		}
+	default:
+		if backend, err := storage.Open(cfg.Database); err != nil {
+			c.fail("database %s: %v", cfg.Database.Driver, err)
+		} else {
+			defer backend.Close()
+			c.ok("database %s", cfg.Database.Driver)
+		}
+	}
+
+	for _, provider := range providers {
+		pingCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		if err := provider.Ping(pingCtx); err != nil {
+			c.fail("%s: %v", provider.Name(), err)
+		} else {
+			c.ok("%s", provider.Name())
+		}
+		cancel()
+	}
+
+	if cfg.Slack.BotToken != "" {
+		pingCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		if err := slack.CheckToken(pingCtx, &cfg.Slack); err != nil {
+			c.fail("%v", err)
+		} else {
+			c.ok("slack")
+		}
+		cancel()
+	}
+
+	return c.result()
+}
+
+// checkSchema reports dirty schemas as failures and pending migrations as warnings
+func checkSchema(ctx context.Context, c *checker, db *sql.DB) {
+	migrator, err := postgres.NewMigrator(ctx, db)
+	if err != nil {
+		c.fail("schema: %v", err)
+		return
+	}
+	defer migrator.Close()
+
+	status, err := migrator.Status()
+	switch {
+	case err != nil:
+		c.fail("schema: %v", err)
+	case status.Dirty:
+		c.fail("schema version %d is dirty, the migration failed midway", status.Version)
+	case len(status.Pending) > 0:
+		c.warn("schema version %d, %d pending migration(s); run chatops migrate up or set database.auto_migrate", status.Version, len(status.Pending))
+	default:
+		c.ok("schema version %d", status.Version)
+	}
+}
+
+func (c *checker) result() error {
+	if c.failures > 0 {
+		return fmt.Errorf("%d check(s) failed", c.failures)
+	}
+	return nil
+}
@@ -1,129 +1,93 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
-	"github.com/Tovli/chatops/internal/adapters/github"
-	"github.com/Tovli/chatops/internal/adapters/slack"
-	"github.com/Tovli/chatops/internal/core/ports"
-	"github.com/Tovli/chatops/internal/core/services"
 	"github.com/Tovli/chatops/internal/infrastructure/config"
 	"github.com/Tovli/chatops/internal/infrastructure/env"
-	"github.com/Tovli/chatops/internal/infrastructure/health"
-	"github.com/Tovli/chatops/internal/infrastructure/router"
-	"github.com/Tovli/chatops/internal/infrastructure/storage/postgres"
 	"go.uber.org/zap"
 )
 
+// errUsage is returned for invalid arguments, after the usage has been printed
+var errUsage = errors.New("invalid usage")
+
+const usage = `Usage: chatops [COMMAND]
+
+Commands:
+  serve                        Run the server (the default)
+  migrate up                   Apply pending schema migrations
+  migrate down [N|all]         Revert the last N migrations, 1 by default
+  migrate status               Show the schema version and pending migrations
+  migrate force VERSION        Record VERSION as applied after repairing a failed migration
+  check-config [--offline]     Validate the configuration and connectivity without serving
+  seed [--file FILE]           Store the repositories and roles of a seed file
+  help                         Show this help
+`
+
 func main() {
 	// Initialize logger
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
 
-	// Load environment variables
-	if err := env.LoadEnv(&env.Config{
-		Logger: logger,
-	}); err != nil {
-		logger.Fatal("failed to load environment variables", zap.Error(err))
-	}
-
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		logger.Fatal("failed to load configuration", zap.Error(err))
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
 	}
 
-	// Initialize database
-	db, err := postgres.NewConnection(cfg.Database)
-	if err != nil {
-		logger.Fatal("failed to connect to database", zap.Error(err))
-	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			logger.Error("failed to close database connection", zap.Error(err))
-		}
-	}()
-
-	// Initialize storage
-	storage := postgres.NewPostgresStorage(db)
-
-	// Initialize repository service with optional GitHub integration
-	var githubPort ports.GitHubPort
-	if cfg.GitHub.Token != "" {
-		githubAdapter, err := github.NewGitHubAdapter(logger, &cfg.GitHub)
+	// Admin commands report to the terminal rather than through the logger
+	var err error
+	switch command {
+	case "serve":
+		cfg, err := loadConfig(logger)
 		if err != nil {
-			logger.Fatal("failed to create GitHub adapter", zap.Error(err))
+			logger.Fatal("failed to load configuration", zap.Error(err))
 		}
-		githubPort = githubAdapter
-	}
-
-	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
-		Logger:     logger,
-		GitHubPort: githubPort, // May be nil if GitHub is not configured
-		Storage:    storage,
-	})
-	if err != nil {
-		logger.Fatal("failed to create repository service", zap.Error(err))
+		serve(logger, cfg)
+	case "migrate":
+		err = runAdmin(logger, func(cfg *config.Config) error { return migrateCommand(os.Stdout, cfg, args) })
+	case "check-config":
+		err = runAdmin(logger, func(cfg *config.Config) error { return checkConfigCommand(os.Stdout, logger, cfg, args) })
+	case "seed":
+		err = runAdmin(logger, func(cfg *config.Config) error { return seedCommand(os.Stdout, cfg, args) })
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		err = usageError(os.Stderr)
 	}
 
-	// Initialize command processor
-	cmdProcessor, err := services.NewCommandProcessor(logger, repoService, githubPort)
-	if err != nil {
-		logger.Fatal("failed to create command processor", zap.Error(err))
+	if errors.Is(err, errUsage) {
+		os.Exit(2)
 	}
-
-	// Initialize Slack adapter
-	slackAdapter, err := slack.NewSlackAdapter(logger, &cfg.Slack, cmdProcessor)
 	if err != nil {
-		logger.Fatal("failed to create Slack adapter", zap.Error(err))
-	}
-
-	// Initialize health handler
-	healthHandler := health.NewHandler(logger, db)
-
-	// Initialize router
-	routerConfig := &router.Config{
-		Logger:        logger,
-		SlackAdapter:  slackAdapter,
-		HealthHandler: healthHandler,
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
 	}
-	appRouter := router.NewRouter(routerConfig)
+}
 
-	// Initialize HTTP server
-	srv := &http.Server{
-		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
-		Handler:      appRouter,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+// loadConfig loads the environment files, then the configuration
+func loadConfig(logger *zap.Logger) (*config.Config, error) {
+	if err := env.LoadEnv(&env.Config{
+		Logger: logger,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to load environment variables: %w", err)
 	}
+	return config.Load()
+}
 
-	// Start server
-	go func() {
-		logger.Info("starting server", zap.String("addr", srv.Addr))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("server failed", zap.Error(err))
-		}
-	}()
-
-	// Handle graceful shutdown
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-
-	<-stop
-	logger.Info("shutting down server")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal("server shutdown failed", zap.Error(err))
+// runAdmin loads the configuration and runs an admin command
+func runAdmin(logger *zap.Logger, run func(cfg *config.Config) error) error {
+	cfg, err := loadConfig(logger)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	return run(cfg)
+}
 
-	logger.Info("server stopped")
+func usageError(w io.Writer) error {
+	fmt.Fprint(w, usage)
+	return errUsage
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/storage"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/postgres"
+)
+
+// migrateCommand applies, reverts or reports the embedded schema migrations
+func migrateCommand(out io.Writer, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return usageError(os.Stderr)
+	}
+	action, args := args[0], args[1:]
+
+	// Validate the arguments before connecting
+	steps, version := 1, uint64(0)
+	switch {
+	case action == "up" && len(args) == 0, action == "status" && len(args) == 0:
+	case action == "down" && len(args) == 0:
+	case action == "down" && len(args) == 1 && args[0] == "all":
+		steps = 0
+	case action == "down" && len(args) == 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid number of migrations to revert: %s", args[0])
+		}
+		steps = n
+	case action == "force" && len(args) == 1:
+		v, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid migration version: %s", args[0])
+		}
+		version = v
+	default:
+		return usageError(os.Stderr)
+	}
+
+	if cfg.Database.Driver != "" && cfg.Database.Driver != storage.DriverPostgres {
+		return fmt.Errorf("the %s driver migrates its schema when opened, migrate only applies to postgres", cfg.Database.Driver)
+	}
+
+	// Migrations are run by the command itself, so "down" is not undone on connect
+	ctx := context.Background()
+	dbConfig := cfg.Database
+	dbConfig.AutoMigrate = false
+	db, err := postgres.NewConnection(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := postgres.NewMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch action {
+	case "up":
+		if err := migrator.Up(); err != nil {
+			return err
+		}
+	case "down":
+		if err := migrator.Down(steps); err != nil {
+			return err
+		}
+	case "force":
+		if err := migrator.Force(uint(version)); err != nil {
+			return err
+		}
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	printMigrationStatus(out, status)
+	return nil
+}
+
+func printMigrationStatus(out io.Writer, status *postgres.MigrationStatus) {
+	switch {
+	case status.Dirty:
+		fmt.Fprintf(out, "Schema version %d is dirty: the migration failed midway\n", status.Version)
+	case status.Version == 0:
+		fmt.Fprintln(out, "No migration applied")
+	default:
+		fmt.Fprintf(out, "Schema version %d\n", status.Version)
+	}
+
+	if len(status.Pending) == 0 {
+		fmt.Fprintf(out, "Up to date with migration %d\n", status.Latest)
+		return
+	}
+	fmt.Fprintf(out, "%d pending migration(s):", len(status.Pending))
+	for _, version := range status.Pending {
+		fmt.Fprintf(out, " %d", version)
+	}
+	fmt.Fprintln(out)
+}
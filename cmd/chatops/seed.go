@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/seed"
+	"github.com/Tovli/chatops/internal/infrastructure/storage"
+)
+
+const defaultSeedFile = "./config/seed.yaml"
+
+// seedCommand stores the repositories and roles of a seed file
+func seedCommand(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	path := fs.String("file", defaultSeedFile, "seed file listing repositories and roles")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return usageError(os.Stderr)
+	}
+
+	file, err := seed.Load(*path)
+	if err != nil {
+		return err
+	}
+
+	backend, err := storage.Open(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer backend.Close()
+
+	result, err := seed.Apply(context.Background(), file, backend, backend)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Seeded %d new and %d updated repositories and %d roles from %s\n", result.Added, result.Updated, result.Roles, *path)
+	return nil
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/github"
+	"github.com/Tovli/chatops/internal/adapters/gitlab"
+	grpcadapter "github.com/Tovli/chatops/internal/adapters/grpc"
+	"github.com/Tovli/chatops/internal/adapters/jenkins"
+	"github.com/Tovli/chatops/internal/adapters/rest"
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/health"
+	"github.com/Tovli/chatops/internal/infrastructure/router"
+	"github.com/Tovli/chatops/internal/infrastructure/storage"
+	"github.com/Tovli/chatops/internal/infrastructure/workflows"
+	"github.com/Tovli/chatops/internal/rbac"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultSchedulerLockKey identifies the scheduler leader lock
+	defaultSchedulerLockKey = 4_105_731_001
+	// defaultWorkflowLockKey identifies the workflow engine leader lock
+	defaultWorkflowLockKey = 4_105_731_002
+	// defaultWebhookLockKey identifies the webhook delivery leader lock
+	defaultWebhookLockKey = 4_105_731_003
+
+	defaultWorkflowDir = "./config/workflows"
+)
+
+// serve runs the HTTP and gRPC servers and the background workers until SIGINT or SIGTERM
+func serve(logger *zap.Logger, cfg *config.Config) {
+	// Initialize storage
+	backend, err := storage.Open(cfg.Database)
+	if err != nil {
+		logger.Fatal("failed to connect to database", zap.Error(err))
+	}
+	defer func() {
+		if err := backend.Close(); err != nil {
+			logger.Error("failed to close database connection", zap.Error(err))
+		}
+	}()
+
+	// Initialize RBAC from the stored roles; every chat user may run every command until a
+	// role is stored, while API and gRPC tokens may not unless api.allow_without_roles
+	var access *rbac.Service
+	roles, err := backend.ListRoles(context.Background())
+	if err != nil {
+		logger.Fatal("failed to load roles", zap.Error(err))
+	}
+	if len(roles) > 0 {
+		if access, err = rbac.NewServiceWithRoles(roles); err != nil {
+			logger.Fatal("failed to load roles", zap.Error(err))
+		}
+		logger.Info("loaded roles", zap.Int("count", len(roles)))
+	} else if cfg.API.AllowWithoutRoles {
+		logger.Warn("no roles stored, every user and API token is allowed every command")
+	} else {
+		logger.Warn("no roles stored, every chat user is allowed every command and API tokens none")
+	}
+
+	// Register the configured CI providers; none of them is mandatory
+	providers := services.NewProviderRegistry()
+	if cfg.GitHub.Token != "" {
+		githubAdapter, err := github.NewGitHubAdapter(logger, &cfg.GitHub)
+		if err != nil {
+			logger.Fatal("failed to create GitHub adapter", zap.Error(err))
+		}
+		providers.Register(githubAdapter)
+	}
+	if cfg.GitLab.Token != "" {
+		gitlabAdapter, err := gitlab.NewGitLabAdapter(logger, &cfg.GitLab)
+		if err != nil {
+			logger.Fatal("failed to create GitLab adapter", zap.Error(err))
+		}
+		providers.Register(gitlabAdapter)
+	}
+	if cfg.Jenkins.BaseURL != "" {
+		jenkinsAdapter, err := jenkins.NewJenkinsAdapter(logger, &cfg.Jenkins)
+		if err != nil {
+			logger.Fatal("failed to create Jenkins adapter", zap.Error(err))
+		}
+		providers.Register(jenkinsAdapter)
+	}
+
+	// Initialize repository service
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:    logger,
+		Providers: providers,
+		Storage:   backend,
+	})
+	if err != nil {
+		logger.Fatal("failed to create repository service", zap.Error(err))
+	}
+
+	// Initialize deployment guard, enforcing locks and freeze windows on every trigger
+	freezes, err := freezeWindows(cfg.Deployments.Freezes)
+	if err != nil {
+		logger.Fatal("failed to load freeze windows", zap.Error(err))
+	}
+	guard, err := services.NewDeploymentGuard(services.DeploymentGuardOptions{
+		Locks:      backend,
+		Freezes:    freezes,
+		DefaultTTL: cfg.Deployments.DefaultLockTTL,
+	})
+	if err != nil {
+		logger.Fatal("failed to create deployment guard", zap.Error(err))
+	}
+
+	// Initialize event bus; the outbox makes delivery survive crashes
+	eventBusOpts := services.EventBusOptions{
+		Logger:   logger,
+		Interval: cfg.Events.RelayInterval,
+	}
+	if cfg.Events.Outbox {
+		eventBusOpts.Outbox = backend
+	}
+	eventBus, err := services.NewEventBus(eventBusOpts)
+	if err != nil {
+		logger.Fatal("failed to create event bus", zap.Error(err))
+	}
+
+	// Initialize notification dispatcher, posting events to subscribed channels
+	dispatcher, err := services.NewNotificationDispatcher(services.NotificationDispatcherOptions{
+		Logger:        logger,
+		Subscriptions: backend,
+	})
+	if err != nil {
+		logger.Fatal("failed to create notification dispatcher", zap.Error(err))
+	}
+	eventBus.Subscribe("*", dispatcher)
+
+	// Initialize outgoing webhooks, delivered by a single leader with retries
+	webhookLockKey := cfg.Webhooks.LockKey
+	if webhookLockKey == 0 {
+		webhookLockKey = defaultWebhookLockKey
+	}
+	webhookService, err := services.NewWebhookService(services.WebhookServiceOptions{
+		Logger:      logger,
+		Storage:     backend,
+		Leader:      backend.NewLeaderLock(webhookLockKey),
+		Interval:    cfg.Webhooks.Interval,
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		Backoff:     cfg.Webhooks.Backoff,
+	})
+	if err != nil {
+		logger.Fatal("failed to create webhook service", zap.Error(err))
+	}
+	eventBus.Subscribe("*", webhookService)
+
+	// Initialize audit log, recording every event
+	auditLog, err := services.NewAuditLog(services.AuditLogOptions{
+		Logger:  logger,
+		Storage: backend,
+	})
+	if err != nil {
+		logger.Fatal("failed to create audit log", zap.Error(err))
+	}
+	eventBus.Subscribe("*", auditLog)
+
+	// Initialize API tokens, stored hashed
+	tokenService, err := services.NewTokenService(services.TokenServiceOptions{
+		Logger:  logger,
+		Storage: backend,
+	})
+	if err != nil {
+		logger.Fatal("failed to create token service", zap.Error(err))
+	}
+
+	// Initialize command processor
+	cmdProcessor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:        logger,
+		RepoService:   repoService,
+		Providers:     providers,
+		Runs:          backend,
+		Deployments:   backend,
+		Guard:         guard,
+		Schedules:     backend,
+		Subscriptions: backend,
+		Events:        eventBus,
+		Audit:         backend,
+		RBAC:          access,
+
+		RemoteWithoutRBAC: cfg.API.AllowWithoutRoles,
+	})
+	if err != nil {
+		logger.Fatal("failed to create command processor", zap.Error(err))
+	}
+
+	// Initialize deployment tracker, following deployment runs until shutdown
+	deploymentTracker, err := services.NewDeploymentTracker(services.DeploymentTrackerOptions{
+		Logger:       logger,
+		RepoService:  repoService,
+		Providers:    providers,
+		Deployments:  backend,
+		PollInterval: cfg.Deployments.PollInterval,
+	})
+	if err != nil {
+		logger.Fatal("failed to create deployment tracker", zap.Error(err))
+	}
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go eventBus.Run(workersCtx)
+	go webhookService.Run(workersCtx)
+	go deploymentTracker.Run(workersCtx)
+
+	// Initialize run tracker, publishing an event when a run completes
+	runTracker, err := services.NewRunTracker(services.RunTrackerOptions{
+		Logger:      logger,
+		RepoService: repoService,
+		Providers:   providers,
+		Runs:        backend,
+		Events:      eventBus,
+	})
+	if err != nil {
+		logger.Fatal("failed to create run tracker", zap.Error(err))
+	}
+	go runTracker.Run(workersCtx)
+
+	// Initialize Slack adapter
+	slackAdapter, err := slack.NewSlackAdapter(logger, &cfg.Slack, cmdProcessor)
+	if err != nil {
+		logger.Fatal("failed to create Slack adapter", zap.Error(err))
+	}
+	dispatcher.Register("slack", slackAdapter)
+
+	// Initialize scheduler; replicas elect the one firing schedules through an advisory lock
+	lockKey := cfg.Scheduler.LockKey
+	if lockKey == 0 {
+		lockKey = defaultSchedulerLockKey
+	}
+	scheduler, err := services.NewScheduler(services.SchedulerOptions{
+		Logger:    logger,
+		Processor: cmdProcessor,
+		Schedules: backend,
+		Leader:    backend.NewLeaderLock(lockKey),
+		Notifier:  slackAdapter,
+		Interval:  cfg.Scheduler.Interval,
+	})
+	if err != nil {
+		logger.Fatal("failed to create scheduler", zap.Error(err))
+	}
+	go scheduler.Run(workersCtx)
+
+	// Initialize workflow engine, resuming running workflows on startup
+	workflowDir := cfg.Workflows.Dir
+	if workflowDir == "" {
+		workflowDir = defaultWorkflowDir
+	}
+	definitions, err := workflows.LoadDir(workflowDir)
+	if err != nil {
+		logger.Fatal("failed to load workflows", zap.Error(err))
+	}
+	workflowLockKey := cfg.Workflows.LockKey
+	if workflowLockKey == 0 {
+		workflowLockKey = defaultWorkflowLockKey
+	}
+	workflowEngine, err := services.NewWorkflowEngine(services.WorkflowEngineOptions{
+		Logger:      logger,
+		Processor:   cmdProcessor,
+		Storage:     backend,
+		Definitions: definitions,
+		Notifier:    slackAdapter,
+		Leader:      backend.NewLeaderLock(workflowLockKey),
+		Interval:    cfg.Workflows.Interval,
+	})
+	if err != nil {
+		logger.Fatal("failed to create workflow engine", zap.Error(err))
+	}
+	cmdProcessor.SetWorkflows(workflowEngine)
+	go workflowEngine.Run(workersCtx)
+	logger.Info("loaded workflows", zap.Int("count", len(definitions)), zap.String("dir", workflowDir))
+
+	// Initialize authentication shared by the HTTP and gRPC APIs
+	auth := services.NewAuthenticator(services.AuthenticatorOptions{
+		AdminToken: cfg.API.AdminToken,
+		Tokens:     tokenService,
+	})
+
+	// Initialize REST adapter serving the JSON API
+	restAdapter, err := rest.NewRESTAdapter(rest.RESTAdapterOptions{
+		Logger:    logger,
+		Auth:      auth,
+		Processor: cmdProcessor,
+		Tokens:    tokenService,
+		Webhooks:  webhookService,
+		Audit:     auditLog,
+	})
+	if err != nil {
+		logger.Fatal("failed to create REST adapter", zap.Error(err))
+	}
+
+	// Initialize health handler
+	healthHandler := health.NewHandler(logger, backend)
+
+	// Initialize router
+	routerConfig := &router.Config{
+		Logger:        logger,
+		SlackAdapter:  slackAdapter,
+		RESTAdapter:   restAdapter,
+		HealthHandler: healthHandler,
+	}
+	appRouter := router.NewRouter(routerConfig)
+
+	// Initialize HTTP server
+	srv := &http.Server{
+		Addr:         fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
+		Handler:      appRouter,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	// Initialize gRPC server, sharing authentication with the HTTP API
+	grpcCtx, stopGRPC := context.WithCancel(context.Background())
+	grpcDone := make(chan struct{})
+	if cfg.GRPC.Port != 0 {
+		grpcServer, err := grpcadapter.NewServer(grpcadapter.ServerOptions{
+			Logger:       logger,
+			Auth:         auth,
+			Processor:    cmdProcessor,
+			PollInterval: cfg.GRPC.PollInterval,
+		})
+		if err != nil {
+			logger.Fatal("failed to create gRPC server", zap.Error(err))
+		}
+
+		grpcAddr := fmt.Sprintf("%s:%d", cfg.GRPC.Host, cfg.GRPC.Port)
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			logger.Fatal("failed to listen for gRPC", zap.String("addr", grpcAddr), zap.Error(err))
+		}
+
+		go func() {
+			defer close(grpcDone)
+			logger.Info("starting gRPC server", zap.String("addr", grpcAddr))
+			if err := grpcServer.Serve(grpcCtx, lis); err != nil {
+				logger.Fatal("gRPC server failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(grpcDone)
+	}
+
+	// Start server
+	go func() {
+		logger.Info("starting server", zap.String("addr", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("server failed", zap.Error(err))
+		}
+	}()
+
+	// Handle graceful shutdown
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	<-stop
+	logger.Info("shutting down server")
+	stopWorkers()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Fatal("server shutdown failed", zap.Error(err))
+	}
+
+	stopGRPC()
+	<-grpcDone
+
+	logger.Info("server stopped")
+}
+
+// freezeWindows converts the configured freeze windows, parsing their date ranges
+func freezeWindows(configs []config.FreezeWindowConfig) ([]domain.FreezeWindow, error) {
+	var windows []domain.FreezeWindow
+	for _, c := range configs {
+		window := domain.FreezeWindow{
+			Name:        c.Name,
+			Repository:  c.Repository,
+			Environment: c.Environment,
+			Reason:      c.Reason,
+			Cron:        c.Cron,
+			Duration:    c.Duration,
+			Timezone:    c.Timezone,
+		}
+		if c.Start != "" || c.End != "" {
+			start, err := time.Parse(time.RFC3339, c.Start)
+			if err != nil {
+				return nil, fmt.Errorf("invalid start of freeze window %s: %w", c.Name, err)
+			}
+			end, err := time.Parse(time.RFC3339, c.End)
+			if err != nil {
+				return nil, fmt.Errorf("invalid end of freeze window %s: %w", c.Name, err)
+			}
+			window.Start, window.End = start, end
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
@@ -2,8 +2,15 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Tovli/chatops/internal/core/domain"
 	"github.com/Tovli/chatops/internal/infrastructure/config"
@@ -12,10 +19,27 @@ import (
 	"golang.org/x/oauth2"
 )
 
+const (
+	defaultHost = "github.com"
+	defaultRef  = "main"
+
+	// Dispatched runs are looked up by creation time, allowing for clock skew with GitHub
+	runLookupClockSkew = 10 * time.Second
+	// dispatchMatchWindow bounds when the run of an uncorrelated dispatch must be created
+	dispatchMatchWindow = time.Minute
+	// correlationInput is the workflow_dispatch input carrying the ID ChatOps matches
+	// the run name against
+	correlationInput = "chatops_run_id"
+)
+
+// errInvalidURL rejects URLs without an owner and repository
+var errInvalidURL = domain.Invalid("invalid GitHub URL format")
+
 type GitHubAdapter struct {
 	logger *zap.Logger
 	config *config.GitHubConfig
 	client *github.Client
+	host   string
 }
 
 func NewGitHubAdapter(logger *zap.Logger, config *config.GitHubConfig) (*GitHubAdapter, error) {
@@ -34,28 +58,61 @@ func NewGitHubAdapter(logger *zap.Logger, config *config.GitHubConfig) (*GitHubA
 	)
 	tc := oauth2.NewClient(context.Background(), ts)
 	client := github.NewClient(tc)
+	host := defaultHost
+
+	// GitHub Enterprise Server exposes the API under the configured base URL
+	if config.BaseURL != "" {
+		baseURL, err := url.Parse(config.BaseURL)
+		if err != nil || baseURL.Host == "" {
+			return nil, fmt.Errorf("invalid GitHub base URL: %s", config.BaseURL)
+		}
+		client, err = github.NewEnterpriseClient(config.BaseURL, config.BaseURL, tc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitHub Enterprise client: %w", err)
+		}
+		host = strings.ToLower(baseURL.Host)
+	}
 
 	return &GitHubAdapter{
 		logger: logger,
 		client: client,
 		config: config,
+		host:   host,
 	}, nil
 }
 
+// Name returns the provider identifier
+func (a *GitHubAdapter) Name() string {
+	return "github"
+}
+
+// Host returns the repository host served by this adapter
+func (a *GitHubAdapter) Host() string {
+	return a.host
+}
+
+// Ping checks that the API is reachable and accepts the token
+func (a *GitHubAdapter) Ping(ctx context.Context) error {
+	if _, _, err := a.client.RateLimits(ctx); err != nil {
+		return apiError(err, "failed to reach GitHub")
+	}
+	return nil
+}
+
 func (a *GitHubAdapter) GetRepositoryDetails(ctx context.Context, url string) (*domain.Repository, error) {
 	owner, repo := parseGitHubURL(url)
 	if owner == "" || repo == "" {
-		return nil, fmt.Errorf("invalid GitHub URL format")
+		return nil, errInvalidURL
 	}
 
 	repository, _, err := a.client.Repositories.Get(ctx, owner, repo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repository: %w", err)
+		return nil, apiError(err, "failed to fetch repository %s/%s", owner, repo)
 	}
 
 	pipelines, err := a.getRepositoryWorkflows(ctx, owner, repo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch workflows: %w", err)
+		return nil, apiError(err, "failed to fetch workflows of %s/%s", owner, repo)
 	}
 
 	return &domain.Repository{
@@ -66,6 +123,15 @@ func (a *GitHubAdapter) GetRepositoryDetails(ctx context.Context, url string) (*
 	}, nil
 }
 
+func (a *GitHubAdapter) ListPipelines(ctx context.Context, url string) ([]domain.Pipeline, error) {
+	owner, repo := parseGitHubURL(url)
+	if owner == "" || repo == "" {
+		return nil, errInvalidURL
+	}
+
+	return a.getRepositoryWorkflows(ctx, owner, repo)
+}
+
 func (a *GitHubAdapter) getRepositoryWorkflows(ctx context.Context, owner, repo string) ([]domain.Pipeline, error) {
 	workflows, _, err := a.client.Actions.ListWorkflows(ctx, owner, repo, &github.ListOptions{})
 	if err != nil {
@@ -85,15 +151,49 @@ func (a *GitHubAdapter) getRepositoryWorkflows(ctx context.Context, owner, repo
 
 func (a *GitHubAdapter) TriggerWorkflow(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
 	owner, repo := parseGitHubURL(trigger.Repository)
+	// The dispatch API accepts the workflow file name, not its path in the repository
+	workflowFile := path.Base(trigger.Workflow)
+	dispatchedAt := time.Now().Add(-runLookupClockSkew)
+	ref := trigger.Ref
+	if ref == "" {
+		ref = defaultRef
+	}
+
+	// The dispatch API does not return the run it creates, so the run tracker looks it up
+	// later. Workflows declaring the correlation input get an ID to find it by.
+	pending := url.Values{
+		"workflow": {workflowFile},
+		"ref":      {ref},
+		"since":    {strconv.FormatInt(dispatchedAt.Unix(), 10)},
+	}
+	inputs := make(map[string]interface{}, len(trigger.Parameters)+1)
+	for name, value := range trigger.Parameters {
+		inputs[name] = value
+	}
+	declared, err := a.workflowInputs(ctx, owner, repo, trigger.Workflow, ref)
+	if err != nil {
+		a.logger.Warn("failed to read workflow inputs",
+			zap.String("repository", trigger.Repository),
+			zap.String("workflow", workflowFile),
+			zap.Error(err))
+	}
+	if declaresInput(declared, correlationInput) {
+		id, err := newCorrelationID()
+		if err != nil {
+			return nil, err
+		}
+		inputs[correlationInput] = id
+		pending.Set("id", id)
+	}
 
 	resp, err := a.client.Actions.CreateWorkflowDispatchEventByFileName(
 		ctx,
 		owner,
 		repo,
-		trigger.Workflow,
+		workflowFile,
 		github.CreateWorkflowDispatchEventRequest{
-			Ref:    "main",
-			Inputs: trigger.Parameters,
+			Ref:    ref,
+			Inputs: inputs,
 		},
 	)
 
@@ -114,9 +214,357 @@ func (a *GitHubAdapter) TriggerWorkflow(ctx context.Context, trigger *domain.Wor
 	return &domain.CommandResult{
 		Status:  "success",
 		Message: "Workflow triggered successfully",
+		Details: map[string]interface{}{
+			"pending_run": pending.Encode(),
+		},
 	}, nil
 }
 
+// ResolveRun finds the run created by a workflow dispatch. Correlated dispatches match
+// the run whose name contains their ID. Other dispatches match the only run of the
+// workflow on the same ref created within dispatchMatchWindow, and are refused when
+// concurrent dispatches make that ambiguous.
+func (a *GitHubAdapter) ResolveRun(ctx context.Context, repoURL string, pendingRun string) (*domain.Run, error) {
+	owner, repo := parseGitHubURL(repoURL)
+	if owner == "" || repo == "" {
+		return nil, errInvalidURL
+	}
+	pending, err := url.ParseQuery(pendingRun)
+	if err != nil {
+		return nil, domain.Invalid("invalid pending run %q", pendingRun)
+	}
+	since, err := strconv.ParseInt(pending.Get("since"), 10, 64)
+	if err != nil {
+		return nil, domain.Invalid("invalid pending run %q", pendingRun)
+	}
+	dispatchedAt := time.Unix(since, 0)
+	workflowFile, ref, id := pending.Get("workflow"), pending.Get("ref"), pending.Get("id")
+
+	// Uncorrelated dispatches are matched once every run of their window is listed
+	windowEnd := dispatchedAt.Add(runLookupClockSkew + dispatchMatchWindow)
+	if id == "" && time.Now().Before(windowEnd.Add(runLookupClockSkew)) {
+		return nil, nil
+	}
+
+	runs, err := a.listDispatchedRuns(ctx, owner, repo, workflowFile, dispatchedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*dispatchedRun
+	for _, run := range runs {
+		switch {
+		case id != "":
+			if strings.Contains(run.DisplayTitle, id) {
+				matches = append(matches, run)
+			}
+		case (run.HeadBranch == ref || run.HeadSHA == ref) && run.CreatedAt.Before(windowEnd):
+			matches = append(matches, run)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &domain.Run{
+			ID:     strconv.FormatInt(matches[0].ID, 10),
+			URL:    matches[0].HTMLURL,
+			Status: matches[0].Status,
+		}, nil
+	default:
+		return nil, domain.Conflict("%d runs of %s match the dispatch; declare a %s input and use it in run-name to tell them apart",
+			len(matches), workflowFile, correlationInput)
+	}
+}
+
+// dispatchedRun is a workflow run as listed by the API, including the display title
+// (the rendered run-name) the client library does not expose
+type dispatchedRun struct {
+	ID           int64     `json:"id"`
+	HTMLURL      string    `json:"html_url"`
+	Status       string    `json:"status"`
+	HeadBranch   string    `json:"head_branch"`
+	HeadSHA      string    `json:"head_sha"`
+	DisplayTitle string    `json:"display_title"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// listDispatchedRuns lists the workflow_dispatch runs of a workflow created since the given time
+func (a *GitHubAdapter) listDispatchedRuns(ctx context.Context, owner, repo, workflowFile string, since time.Time) ([]*dispatchedRun, error) {
+	query := url.Values{
+		"event":    {"workflow_dispatch"},
+		"created":  {">=" + since.UTC().Format(time.RFC3339)},
+		"per_page": {"100"},
+	}
+	req, err := a.client.NewRequest(http.MethodGet,
+		fmt.Sprintf("repos/%s/%s/actions/workflows/%s/runs?%s", owner, repo, url.PathEscape(workflowFile), query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs struct {
+		WorkflowRuns []*dispatchedRun `json:"workflow_runs"`
+	}
+	if _, err := a.client.Do(ctx, req, &runs); err != nil {
+		return nil, apiError(err, "failed to list runs of workflow %s", workflowFile)
+	}
+	return runs.WorkflowRuns, nil
+}
+
+// ListRefs returns the branches, then the tags, whose name contains the query
+func (a *GitHubAdapter) ListRefs(ctx context.Context, url, query string, limit int) ([]string, error) {
+	owner, repo := parseGitHubURL(url)
+	if owner == "" || repo == "" {
+		return nil, errInvalidURL
+	}
+
+	var refs []string
+	add := func(name string) {
+		if len(refs) < limit && strings.Contains(strings.ToLower(name), strings.ToLower(query)) {
+			refs = append(refs, name)
+		}
+	}
+	branches, _, err := a.client.Repositories.ListBranches(ctx, owner, repo, &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		return nil, apiError(err, "failed to list branches of %s/%s", owner, repo)
+	}
+	for _, branch := range branches {
+		add(branch.GetName())
+	}
+	tags, _, err := a.client.Repositories.ListTags(ctx, owner, repo, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, apiError(err, "failed to list tags of %s/%s", owner, repo)
+	}
+	for _, tag := range tags {
+		add(tag.GetName())
+	}
+	return refs, nil
+}
+
+func (a *GitHubAdapter) RetryRun(ctx context.Context, url string, runID string, failedOnly bool) (*domain.CommandResult, error) {
+	owner, repo, id, err := parseRunReference(url, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	if failedOnly {
+		_, err = a.client.Actions.RerunFailedJobsByID(ctx, owner, repo, id)
+	} else {
+		_, err = a.client.Actions.RerunWorkflowByID(ctx, owner, repo, id)
+	}
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to re-run workflow: %v", err),
+		}, nil
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Workflow run %s re-run requested", runID),
+		Details: map[string]interface{}{
+			"run_id": runID,
+		},
+	}, nil
+}
+
+func (a *GitHubAdapter) GetRunStatus(ctx context.Context, url string, runID string) (*domain.WorkflowStatus, error) {
+	owner, repo, id, err := parseRunReference(url, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	run, _, err := a.client.Actions.GetWorkflowRunByID(ctx, owner, repo, id)
+	if err != nil {
+		return nil, apiError(err, "failed to fetch workflow run %d", id)
+	}
+
+	status := &domain.WorkflowStatus{
+		ID:        runID,
+		Status:    run.GetStatus(),
+		UpdatedAt: run.GetUpdatedAt().Time,
+	}
+	switch run.GetStatus() {
+	case "completed":
+		status.Status = run.GetConclusion()
+		status.Progress = 100
+		if run.GetConclusion() == "failure" || run.GetConclusion() == "timed_out" {
+			status.Error = fmt.Sprintf("workflow run %d concluded with %s", id, run.GetConclusion())
+		}
+	case "in_progress":
+		status.Progress = 50
+	}
+
+	return status, nil
+}
+
+func (a *GitHubAdapter) CancelRun(ctx context.Context, url string, runID string) error {
+	owner, repo, id, err := parseRunReference(url, runID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.client.Actions.CancelWorkflowRunByID(ctx, owner, repo, id); err != nil {
+		return apiError(err, "failed to cancel workflow run %d", id)
+	}
+	return nil
+}
+
+func (a *GitHubAdapter) GetRunLogs(ctx context.Context, url string, runID string) ([]domain.JobLog, error) {
+	owner, repo, id, err := parseRunReference(url, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, _, err := a.client.Actions.ListWorkflowJobs(ctx, owner, repo, id, &github.ListWorkflowJobsOptions{})
+	if err != nil {
+		return nil, apiError(err, "failed to list jobs of workflow run %d", id)
+	}
+
+	var logs []domain.JobLog
+	for _, job := range jobs.Jobs {
+		content, err := a.downloadJobLog(ctx, owner, repo, job.GetID())
+		if err != nil {
+			return nil, err
+		}
+
+		jobLog := domain.JobLog{
+			JobID:   strconv.FormatInt(job.GetID(), 10),
+			Name:    job.GetName(),
+			Status:  job.GetConclusion(),
+			Content: content,
+		}
+		if jobLog.Status == "" {
+			jobLog.Status = job.GetStatus()
+		}
+		for _, step := range job.Steps {
+			if step.GetConclusion() == "failure" {
+				jobLog.FailedStep = step.GetName()
+				break
+			}
+		}
+		logs = append(logs, jobLog)
+	}
+
+	return logs, nil
+}
+
+// downloadJobLog follows the signed log URL returned by the API and reads the plain-text log
+func (a *GitHubAdapter) downloadJobLog(ctx context.Context, owner, repo string, jobID int64) (string, error) {
+	logURL, _, err := a.client.Actions.GetWorkflowJobLogs(ctx, owner, repo, jobID, true)
+	if err != nil {
+		return "", apiError(err, "failed to fetch logs of job %d", jobID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := a.client.Client().Do(req)
+	if err != nil {
+		return "", domain.Upstream(err, "failed to download logs of job %d", jobID)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", domain.Upstream(nil, "failed to download logs of job %d: HTTP %d", jobID, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read job logs: %w", err)
+	}
+	return string(content), nil
+}
+
+// CreateDeployment creates a GitHub deployment of the ref to the environment. Commit status
+// checks are skipped, since the deploying workflow is the one that runs them.
+func (a *GitHubAdapter) CreateDeployment(ctx context.Context, url string, deployment *domain.Deployment) (string, error) {
+	owner, repo := parseGitHubURL(url)
+	if owner == "" || repo == "" {
+		return "", errInvalidURL
+	}
+
+	created, _, err := a.client.Repositories.CreateDeployment(ctx, owner, repo, &github.DeploymentRequest{
+		Ref:              github.String(deployment.Ref),
+		Environment:      github.String(deployment.Environment),
+		Description:      github.String(fmt.Sprintf("Requested by %s through ChatOps", deployment.DeployedBy)),
+		AutoMerge:        github.Bool(false),
+		RequiredContexts: &[]string{},
+	})
+	if err != nil {
+		return "", apiError(err, "failed to create deployment of %s to %s", deployment.Ref, deployment.Environment)
+	}
+
+	return strconv.FormatInt(created.GetID(), 10), nil
+}
+
+// UpdateDeploymentStatus adds a deployment status linking to the deploying run
+func (a *GitHubAdapter) UpdateDeploymentStatus(ctx context.Context, url string, deployment *domain.Deployment) error {
+	owner, repo := parseGitHubURL(url)
+	if owner == "" || repo == "" {
+		return errInvalidURL
+	}
+
+	id, err := strconv.ParseInt(deployment.ProviderDeploymentID, 10, 64)
+	if err != nil {
+		return domain.Invalid("invalid deployment ID: %s", deployment.ProviderDeploymentID)
+	}
+
+	request := &github.DeploymentStatusRequest{
+		State:       github.String(deployment.Status),
+		Environment: github.String(deployment.Environment),
+	}
+	if deployment.URL != "" {
+		request.LogURL = github.String(deployment.URL)
+	}
+
+	if _, _, err := a.client.Repositories.CreateDeploymentStatus(ctx, owner, repo, id, request); err != nil {
+		return apiError(err, "failed to create deployment status")
+	}
+	return nil
+}
+
+// parseRunReference resolves the owner, repository and numeric run ID of a workflow run
+func parseRunReference(url, runID string) (owner, repo string, id int64, err error) {
+	owner, repo = parseGitHubURL(url)
+	if owner == "" || repo == "" {
+		return "", "", 0, errInvalidURL
+	}
+
+	id, err = strconv.ParseInt(runID, 10, 64)
+	if err != nil {
+		return "", "", 0, domain.Invalid("invalid workflow run ID: %s", runID)
+	}
+	return owner, repo, id, nil
+}
+
+// apiError classifies a failed API call: missing resources and rejected requests keep
+// their meaning, anything else is an upstream failure. Denied access is one too, as it
+// reflects the credentials of ChatOps rather than the permissions of the user.
+func apiError(err error, format string, args ...interface{}) error {
+	var responseErr *github.ErrorResponse
+	if errors.As(err, &responseErr) && responseErr.Response != nil {
+		switch responseErr.Response.StatusCode {
+		case http.StatusNotFound:
+			return domain.NewError(domain.ErrNotFound, err, format+": not found on GitHub", args...)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return domain.NewError(domain.ErrUpstream, err, format+": GitHub denied access", args...)
+		case http.StatusUnprocessableEntity:
+			return domain.NewError(domain.ErrInvalid, err, format+": GitHub rejected the request", args...)
+		}
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return domain.NewError(domain.ErrUpstream, err, format+": GitHub rate limit exceeded", args...)
+	}
+	return domain.Upstream(err, format, args...)
+}
+
 func parseGitHubURL(url string) (owner, repo string) {
 	// Handle both HTTPS and SSH URLs
 	parts := strings.Split(strings.TrimSuffix(url, ".git"), "/")
@@ -0,0 +1,136 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/google/go-github/v45/github"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowsDir holds the workflow files of a repository
+const workflowsDir = ".github/workflows"
+
+// workflowDefinition is the part of a workflow file declaring its triggers
+type workflowDefinition struct {
+	On yaml.Node `yaml:"on"`
+}
+
+// workflowDispatch is the workflow_dispatch trigger of a workflow file. Inputs are kept
+// as a node to preserve their declaration order.
+type workflowDispatch struct {
+	WorkflowDispatch struct {
+		Inputs yaml.Node `yaml:"inputs"`
+	} `yaml:"workflow_dispatch"`
+}
+
+type workflowInput struct {
+	Description string    `yaml:"description"`
+	Required    bool      `yaml:"required"`
+	Default     yaml.Node `yaml:"default"`
+	Type        string    `yaml:"type"`
+	Options     []string  `yaml:"options"`
+}
+
+// GetPipelineInputs returns the workflow_dispatch inputs of a workflow, except the
+// correlation input ChatOps fills in itself
+func (a *GitHubAdapter) GetPipelineInputs(ctx context.Context, url, pipelinePath, ref string) ([]domain.PipelineInput, error) {
+	owner, repo := parseGitHubURL(url)
+	if owner == "" || repo == "" {
+		return nil, errInvalidURL
+	}
+
+	inputs, err := a.workflowInputs(ctx, owner, repo, pipelinePath, ref)
+	if err != nil {
+		return nil, err
+	}
+	declared := make([]domain.PipelineInput, 0, len(inputs))
+	for _, input := range inputs {
+		if input.Name != correlationInput {
+			declared = append(declared, input)
+		}
+	}
+	return declared, nil
+}
+
+// workflowInputs returns the workflow_dispatch inputs declared by a workflow file at
+// the given ref, the default branch when empty
+func (a *GitHubAdapter) workflowInputs(ctx context.Context, owner, repo, workflow, ref string) ([]domain.PipelineInput, error) {
+	if !strings.Contains(workflow, "/") {
+		workflow = path.Join(workflowsDir, workflow)
+	}
+
+	file, _, _, err := a.client.Repositories.GetContents(ctx, owner, repo, workflow, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		return nil, apiError(err, "failed to fetch workflow %s", workflow)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s is not a file", workflow)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+
+	var definition workflowDefinition
+	if err := yaml.Unmarshal([]byte(content), &definition); err != nil {
+		return nil, fmt.Errorf("invalid workflow %s: %w", workflow, err)
+	}
+	// Triggers given as a name or a list declare no inputs
+	if definition.On.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var dispatch workflowDispatch
+	if err := definition.On.Decode(&dispatch); err != nil {
+		return nil, fmt.Errorf("invalid workflow_dispatch trigger in %s: %w", workflow, err)
+	}
+	nodes := dispatch.WorkflowDispatch.Inputs.Content
+	if dispatch.WorkflowDispatch.Inputs.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	inputs := make([]domain.PipelineInput, 0, len(nodes)/2)
+	for i := 0; i+1 < len(nodes); i += 2 {
+		var declared workflowInput
+		if err := nodes[i+1].Decode(&declared); err != nil {
+			return nil, fmt.Errorf("invalid input %s in %s: %w", nodes[i].Value, workflow, err)
+		}
+		input := domain.PipelineInput{
+			Name:        nodes[i].Value,
+			Description: declared.Description,
+			Default:     declared.Default.Value,
+			Required:    declared.Required,
+			Options:     declared.Options,
+		}
+		if declared.Type == "boolean" {
+			input.Options = []string{"true", "false"}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// declaresInput reports whether an input is declared
+func declaresInput(inputs []domain.PipelineInput, name string) bool {
+	for _, input := range inputs {
+		if input.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newCorrelationID returns a random ID to find a dispatched run by
+func newCorrelationID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate correlation ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,498 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultBaseURL = "https://gitlab.com"
+	ciConfigPath   = ".gitlab-ci.yml"
+)
+
+type GitLabAdapter struct {
+	logger  *zap.Logger
+	config  *config.GitLabConfig
+	client  *http.Client
+	baseURL *url.URL
+}
+
+type project struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+	DefaultBranch     string `json:"default_branch"`
+}
+
+type pipeline struct {
+	ID        int       `json:"id"`
+	Status    string    `json:"status"`
+	Ref       string    `json:"ref"`
+	WebURL    string    `json:"web_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// pipelineRequest creates a pipeline through POST /projects/:id/pipeline
+type pipelineRequest struct {
+	Ref       string             `json:"ref"`
+	Variables []pipelineVariable `json:"variables,omitempty"`
+}
+
+type pipelineVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func NewGitLabAdapter(logger *zap.Logger, config *config.GitLabConfig) (*GitLabAdapter, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	if config.Token == "" {
+		return nil, fmt.Errorf("GitLab token is required")
+	}
+
+	rawURL := config.BaseURL
+	if rawURL == "" {
+		rawURL = defaultBaseURL
+	}
+	baseURL, err := url.Parse(strings.TrimSuffix(rawURL, "/"))
+	if err != nil || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid GitLab base URL: %s", rawURL)
+	}
+
+	return &GitLabAdapter{
+		logger:  logger,
+		config:  config,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		baseURL: baseURL,
+	}, nil
+}
+
+// Name returns the provider identifier
+func (a *GitLabAdapter) Name() string {
+	return "gitlab"
+}
+
+// Host returns the repository host served by this adapter
+func (a *GitLabAdapter) Host() string {
+	return strings.ToLower(a.baseURL.Host)
+}
+
+// Ping checks that the API is reachable and accepts the token
+func (a *GitLabAdapter) Ping(ctx context.Context) error {
+	var version struct {
+		Version string `json:"version"`
+	}
+	if err := a.getJSON(ctx, "/version", &version); err != nil {
+		return fmt.Errorf("failed to reach GitLab: %w", err)
+	}
+	return nil
+}
+
+func (a *GitLabAdapter) GetRepositoryDetails(ctx context.Context, repoURL string) (*domain.Repository, error) {
+	path, err := projectPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proj, err := a.getProject(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+
+	pipelines, err := a.getProjectPipelines(ctx, proj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pipelines: %w", err)
+	}
+
+	return &domain.Repository{
+		Name:          proj.Name,
+		URL:           proj.WebURL,
+		DefaultBranch: proj.DefaultBranch,
+		Pipelines:     pipelines,
+	}, nil
+}
+
+func (a *GitLabAdapter) ListPipelines(ctx context.Context, repoURL string) ([]domain.Pipeline, error) {
+	path, err := projectPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proj, err := a.getProject(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %w", err)
+	}
+
+	return a.getProjectPipelines(ctx, proj)
+}
+
+// getProjectPipelines discovers the pipeline declared by the project's .gitlab-ci.yml
+func (a *GitLabAdapter) getProjectPipelines(ctx context.Context, proj *project) ([]domain.Pipeline, error) {
+	endpoint := fmt.Sprintf("/projects/%d/repository/files/%s/raw?ref=%s",
+		proj.ID, url.PathEscape(ciConfigPath), url.QueryEscape(proj.DefaultBranch))
+
+	resp, err := a.do(ctx, http.MethodGet, endpoint, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("GitLab API returned HTTP %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var ciConfig struct {
+		Workflow struct {
+			Name string `yaml:"name"`
+		} `yaml:"workflow"`
+	}
+	if err := yaml.Unmarshal(content, &ciConfig); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ciConfigPath, err)
+	}
+
+	name := ciConfig.Workflow.Name
+	if name == "" {
+		name = "gitlab-ci"
+	}
+
+	return []domain.Pipeline{
+		{
+			Name: name,
+			Path: ciConfigPath,
+		},
+	}, nil
+}
+
+func (a *GitLabAdapter) TriggerWorkflow(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+	path, err := projectPath(trigger.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	proj, err := a.getProject(ctx, path)
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to trigger pipeline: %v", err),
+		}, nil
+	}
+
+	// Pipelines are created with the API token, which works for every project it can
+	// access, rather than with per-project trigger tokens
+	request := pipelineRequest{Ref: proj.DefaultBranch}
+	if trigger.Ref != "" {
+		request.Ref = trigger.Ref
+	}
+	for key, value := range trigger.Parameters {
+		if key == "ref" {
+			request.Ref = fmt.Sprint(value)
+			continue
+		}
+		request.Variables = append(request.Variables, pipelineVariable{Key: key, Value: fmt.Sprint(value)})
+	}
+	sort.Slice(request.Variables, func(i, j int) bool { return request.Variables[i].Key < request.Variables[j].Key })
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pipeline request: %w", err)
+	}
+
+	resp, err := a.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%d/pipeline", proj.ID),
+		bytes.NewReader(body), "application/json")
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to trigger pipeline: %v", err),
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to trigger pipeline: HTTP %d", resp.StatusCode),
+		}, nil
+	}
+
+	var created pipeline
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode pipeline response: %w", err)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Pipeline #%d triggered successfully", created.ID),
+		Details: map[string]interface{}{
+			"run_id":      fmt.Sprint(created.ID),
+			"pipeline_id": created.ID,
+			"ref":         created.Ref,
+			"status":      created.Status,
+			"url":         created.WebURL,
+		},
+	}, nil
+}
+
+func (a *GitLabAdapter) GetRunStatus(ctx context.Context, repoURL string, pipelineID string) (*domain.WorkflowStatus, error) {
+	path, err := projectPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/projects/%s/pipelines/%s", url.PathEscape(path), url.PathEscape(pipelineID))
+	var p pipeline
+	if err := a.getJSON(ctx, endpoint, &p); err != nil {
+		return nil, fmt.Errorf("failed to fetch pipeline: %w", err)
+	}
+
+	status := &domain.WorkflowStatus{
+		ID:        fmt.Sprint(p.ID),
+		Status:    p.Status,
+		UpdatedAt: p.UpdatedAt,
+	}
+	switch p.Status {
+	case "success", "canceled", "skipped":
+		status.Progress = 100
+	case "failed":
+		status.Progress = 100
+		status.Error = fmt.Sprintf("pipeline %d failed", p.ID)
+	case "running":
+		status.Progress = 50
+	}
+
+	return status, nil
+}
+
+// ListRefs returns the branches, then the tags, whose name contains the query
+func (a *GitLabAdapter) ListRefs(ctx context.Context, repoURL, query string, limit int) ([]string, error) {
+	path, err := projectPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	search := url.Values{"search": {query}, "per_page": {fmt.Sprint(limit)}}.Encode()
+	var refs []string
+	for _, kind := range []string{"branches", "tags"} {
+		var named []struct {
+			Name string `json:"name"`
+		}
+		endpoint := fmt.Sprintf("/projects/%s/repository/%s?%s", url.PathEscape(path), kind, search)
+		if err := a.getJSON(ctx, endpoint, &named); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", kind, err)
+		}
+		for _, ref := range named {
+			if len(refs) < limit {
+				refs = append(refs, ref.Name)
+			}
+		}
+	}
+	return refs, nil
+}
+
+func (a *GitLabAdapter) CancelRun(ctx context.Context, repoURL string, pipelineID string) error {
+	path, err := projectPath(repoURL)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("/projects/%s/pipelines/%s/cancel", url.PathEscape(path), url.PathEscape(pipelineID))
+	resp, err := a.do(ctx, http.MethodPost, endpoint, nil, "")
+	if err != nil {
+		return fmt.Errorf("failed to cancel pipeline: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to cancel pipeline: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RetryRun retries the failed jobs of a pipeline. A full re-run triggers a new pipeline
+// on the same ref, since GitLab only supports retrying failed and cancelled jobs in place.
+func (a *GitLabAdapter) RetryRun(ctx context.Context, repoURL string, pipelineID string, failedOnly bool) (*domain.CommandResult, error) {
+	path, err := projectPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	projectID := url.PathEscape(path)
+
+	if !failedOnly {
+		var p pipeline
+		if err := a.getJSON(ctx, fmt.Sprintf("/projects/%s/pipelines/%s", projectID, url.PathEscape(pipelineID)), &p); err != nil {
+			return nil, fmt.Errorf("failed to fetch pipeline: %w", err)
+		}
+		return a.TriggerWorkflow(ctx, &domain.WorkflowTrigger{
+			Repository: repoURL,
+			Workflow:   ciConfigPath,
+			Parameters: map[string]interface{}{"ref": p.Ref},
+		})
+	}
+
+	resp, err := a.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/pipelines/%s/retry", projectID, url.PathEscape(pipelineID)), nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry pipeline: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to retry pipeline: HTTP %d", resp.StatusCode),
+		}, nil
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Failed jobs of pipeline #%s retried", pipelineID),
+		Details: map[string]interface{}{
+			"run_id": pipelineID,
+		},
+	}, nil
+}
+
+func (a *GitLabAdapter) GetRunLogs(ctx context.Context, repoURL string, pipelineID string) ([]domain.JobLog, error) {
+	path, err := projectPath(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	projectID := url.PathEscape(path)
+
+	var jobs []struct {
+		ID     int    `json:"id"`
+		Name   string `json:"name"`
+		Stage  string `json:"stage"`
+		Status string `json:"status"`
+	}
+	endpoint := fmt.Sprintf("/projects/%s/pipelines/%s/jobs", projectID, url.PathEscape(pipelineID))
+	if err := a.getJSON(ctx, endpoint, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to list pipeline jobs: %w", err)
+	}
+
+	logs := make([]domain.JobLog, 0, len(jobs))
+	for _, job := range jobs {
+		resp, err := a.do(ctx, http.MethodGet, fmt.Sprintf("/projects/%s/jobs/%d/trace", projectID, job.ID), nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch job trace: %w", err)
+		}
+		content, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job trace: %w", err)
+		}
+		if resp.StatusCode >= 400 {
+			return nil, fmt.Errorf("failed to fetch job trace: HTTP %d", resp.StatusCode)
+		}
+
+		jobLog := domain.JobLog{
+			JobID:   fmt.Sprint(job.ID),
+			Name:    job.Name,
+			Status:  job.Status,
+			Content: string(content),
+		}
+		// GitLab jobs have no step breakdown; the stage is the closest equivalent
+		if job.Status == "failed" {
+			jobLog.FailedStep = job.Stage
+		}
+		logs = append(logs, jobLog)
+	}
+
+	return logs, nil
+}
+
+func (a *GitLabAdapter) getProject(ctx context.Context, path string) (*project, error) {
+	var proj project
+	if err := a.getJSON(ctx, "/projects/"+url.PathEscape(path), &proj); err != nil {
+		return nil, err
+	}
+	if proj.DefaultBranch == "" {
+		proj.DefaultBranch = "main"
+	}
+	return &proj, nil
+}
+
+func (a *GitLabAdapter) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	resp, err := a.do(ctx, http.MethodGet, endpoint, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("GitLab API returned HTTP %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (a *GitLabAdapter) do(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL.String()+"/api/v4"+endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", a.config.Token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return a.client.Do(req)
+}
+
+// projectPath extracts the namespaced project path from a repository URL
+func projectPath(repoURL string) (string, error) {
+	_, path := parseGitLabURL(repoURL)
+	if path == "" {
+		return "", fmt.Errorf("invalid GitLab URL format")
+	}
+	return path, nil
+}
+
+// parseGitLabURL splits a GitLab repository URL into its host and project path.
+// Both HTTPS and SSH (git@host:group/project.git) URLs are supported.
+func parseGitLabURL(repoURL string) (host, path string) {
+	repoURL = strings.TrimSuffix(strings.TrimSpace(repoURL), ".git")
+
+	if strings.HasPrefix(repoURL, "git@") {
+		parts := strings.SplitN(strings.TrimPrefix(repoURL, "git@"), ":", 2)
+		if len(parts) != 2 {
+			return "", ""
+		}
+		return parts[0], strings.Trim(parts[1], "/")
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return "", ""
+	}
+
+	path = strings.Trim(u.Path, "/")
+	if !strings.Contains(path, "/") {
+		return u.Host, ""
+	}
+	return u.Host, path
+}
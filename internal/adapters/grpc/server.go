@@ -0,0 +1,456 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/pkg/chatopsv1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPollInterval    = 5 * time.Second
+	defaultShutdownTimeout = 10 * time.Second
+	// watchLimit bounds the runs compared on every poll when watching all runs
+	watchLimit = 100
+)
+
+// methodScopes lists the token scope each method requires, as for the HTTP API
+var methodScopes = map[string]string{
+	chatopsv1.ChatOpsService_ProcessCommand_FullMethodName:   domain.ScopeRunCommands,
+	chatopsv1.ChatOpsService_ListRepositories_FullMethodName: domain.ScopeReadRepositories,
+	chatopsv1.ChatOpsService_WatchRuns_FullMethodName:        domain.ScopeReadRuns,
+}
+
+type contextKey int
+
+// tokenKey holds the token of an authenticated call
+const tokenKey contextKey = iota
+
+// Server serves the ChatOps gRPC service. Calls are authenticated with the tokens of the
+// HTTP API, and commands are subject to the RBAC permissions of the token user.
+type Server struct {
+	chatopsv1.UnimplementedChatOpsServiceServer
+
+	logger          *zap.Logger
+	auth            *services.Authenticator
+	processor       *services.CommandProcessor
+	pollInterval    time.Duration
+	shutdownTimeout time.Duration
+
+	stopOnce sync.Once
+	stopping chan struct{}
+}
+
+type ServerOptions struct {
+	Logger          *zap.Logger
+	Auth            *services.Authenticator
+	Processor       *services.CommandProcessor
+	PollInterval    time.Duration // Optional: how often watched runs are polled, defaults to 5 seconds
+	ShutdownTimeout time.Duration // Optional: how long calls in flight may finish on shutdown, defaults to 10 seconds
+}
+
+// NewServer creates a new instance of Server
+func NewServer(opts ServerOptions) (*Server, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.Auth == nil {
+		return nil, fmt.Errorf("authenticator is required")
+	}
+	if opts.Processor == nil {
+		return nil, fmt.Errorf("command processor is required")
+	}
+
+	server := &Server{
+		logger:          opts.Logger,
+		auth:            opts.Auth,
+		processor:       opts.Processor,
+		pollInterval:    opts.PollInterval,
+		shutdownTimeout: opts.ShutdownTimeout,
+		stopping:        make(chan struct{}),
+	}
+	if server.pollInterval <= 0 {
+		server.pollInterval = defaultPollInterval
+	}
+	if server.shutdownTimeout <= 0 {
+		server.shutdownTimeout = defaultShutdownTimeout
+	}
+
+	return server, nil
+}
+
+// Serve serves the service on the listener until the context is cancelled. Run streams
+// then end with codes.Unavailable and other calls in flight get the shutdown timeout to
+// finish before they are cancelled.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(s.authenticateUnary),
+		grpc.StreamInterceptor(s.authenticateStream),
+	)
+	chatopsv1.RegisterChatOpsServiceServer(server, s)
+
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.stopOnce.Do(func() { close(s.stopping) })
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(s.shutdownTimeout):
+		s.logger.Warn("calls still in flight, stopping gRPC server")
+		server.Stop()
+		<-stopped
+	}
+
+	return <-served
+}
+
+func (s *Server) authenticateUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) authenticateStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticate resolves the bearer token of the call metadata and checks the scope of
+// the method
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if !s.auth.Enabled() {
+		return nil, status.Error(codes.PermissionDenied, "the API is disabled")
+	}
+
+	var value string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			value, _ = strings.CutPrefix(values[0], "Bearer ")
+		}
+	}
+
+	token, err := s.auth.Authenticate(ctx, value)
+	if errors.Is(err, services.ErrInvalidToken) {
+		return nil, status.Error(codes.Unauthenticated, "invalid or missing bearer token")
+	}
+	if err != nil {
+		s.logger.Error("failed to authenticate token", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to authenticate")
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok || !token.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "the token lacks the %s scope", scope)
+	}
+
+	return context.WithValue(ctx, tokenKey, token), nil
+}
+
+// authenticatedStream carries the context of an authenticated call to stream handlers
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// ProcessCommand runs a command as the token user
+func (s *Server) ProcessCommand(ctx context.Context, req *chatopsv1.ProcessCommandRequest) (*chatopsv1.ProcessCommandResponse, error) {
+	if req.GetType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "type is required")
+	}
+
+	params := req.GetParameters().AsMap()
+	cmd := &domain.Command{
+		Type:       req.GetType(),
+		Parameters: params,
+		User:       callUser(ctx),
+		Source: domain.CommandSource{
+			Platform:  "grpc",
+			ChannelID: req.GetChannelId(),
+		},
+		Timestamp: time.Now(),
+	}
+
+	result, err := s.processor.ProcessCommand(ctx, cmd)
+	if err != nil {
+		return nil, s.statusError(err, "failed to process command", zap.String("type", cmd.Type))
+	}
+
+	response := &chatopsv1.ProcessCommandResponse{
+		Status:  result.Status,
+		Message: result.Message,
+	}
+	if result.Details != nil {
+		if response.Details, err = toValue(result.Details); err != nil {
+			s.logger.Warn("failed to encode command details", zap.String("type", cmd.Type), zap.Error(err))
+		}
+	}
+	if result.Error != nil {
+		response.Error = result.Error.Error()
+	}
+	return response, nil
+}
+
+// ListRepositories lists the managed repositories
+func (s *Server) ListRepositories(ctx context.Context, req *chatopsv1.ListRepositoriesRequest) (*chatopsv1.ListRepositoriesResponse, error) {
+	repos, err := s.processor.ListRepositories(ctx)
+	if err != nil {
+		s.logger.Error("failed to list repositories", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list repositories")
+	}
+
+	response := &chatopsv1.ListRepositoriesResponse{}
+	for _, repo := range repos {
+		response.Repositories = append(response.Repositories, newRepository(repo))
+	}
+	return response, nil
+}
+
+// WatchRuns polls the recorded runs and streams their status changes. Runs are recorded
+// by every replica and updated by the run tracker, so polling storage sees the changes
+// wherever they happen.
+func (s *Server) WatchRuns(req *chatopsv1.WatchRunsRequest, stream chatopsv1.ChatOpsService_WatchRunsServer) error {
+	if req.GetRunId() != "" {
+		return s.watchRun(req, stream)
+	}
+	return s.watchAll(req, stream)
+}
+
+// watchRun sends the current state of a run and every change until it finished
+func (s *Server) watchRun(req *chatopsv1.WatchRunsRequest, stream chatopsv1.ChatOpsService_WatchRunsServer) error {
+	ctx := stream.Context()
+	run, err := s.getRun(ctx, req.GetRunId())
+	if err != nil {
+		return err
+	}
+	if req.GetRepository() != "" && run.Repository != req.GetRepository() {
+		return status.Errorf(codes.NotFound, "run %s not found in %s", run.ID, req.GetRepository())
+	}
+	if err := stream.Send(newRunUpdate(run, "")); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	// Storage may return the same run on every poll, so the status is kept apart
+	last := run.Status
+	for !run.IsFinished() {
+		if err := s.wait(ctx, ticker); err != nil {
+			return err
+		}
+
+		if run, err = s.getRun(ctx, run.ID); err != nil {
+			return err
+		}
+		if run.Status != last {
+			if err := stream.Send(newRunUpdate(run, last)); err != nil {
+				return err
+			}
+			last = run.Status
+		}
+	}
+
+	return nil
+}
+
+// watchAll sends the status changes of the most recent runs, and the runs recorded after
+// the stream started, until the client cancels
+func (s *Server) watchAll(req *chatopsv1.WatchRunsRequest, stream chatopsv1.ChatOpsService_WatchRunsServer) error {
+	ctx := stream.Context()
+	filter := domain.RunFilter{Repository: req.GetRepository(), Limit: watchLimit}
+
+	statuses := make(map[string]string)
+	runs, err := s.listRuns(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		statuses[run.ID] = run.Status
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.wait(ctx, ticker); err != nil {
+			return err
+		}
+
+		runs, err := s.listRuns(ctx, filter)
+		if err != nil {
+			return err
+		}
+		// Oldest first, so clients see runs in the order they were recorded
+		for i := len(runs) - 1; i >= 0; i-- {
+			run := runs[i]
+			previous, seen := statuses[run.ID]
+			if seen && previous == run.Status {
+				continue
+			}
+			statuses[run.ID] = run.Status
+			if err := stream.Send(newRunUpdate(run, previous)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// wait blocks until the next tick, failing when the call is cancelled or the server stops
+func (s *Server) wait(ctx context.Context, ticker *time.Ticker) error {
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-s.stopping:
+		return status.Error(codes.Unavailable, "the server is shutting down")
+	case <-ticker.C:
+		return nil
+	}
+}
+
+func (s *Server) getRun(ctx context.Context, id string) (*domain.Run, error) {
+	run, err := s.processor.GetRun(ctx, id)
+	if err != nil {
+		return nil, s.statusError(err, "failed to get run", zap.String("run_id", id))
+	}
+	return run, nil
+}
+
+// statusError converts a failed service call to a status. Errors of a known kind keep their
+// message; others are logged and reported as the fallback message.
+func (s *Server) statusError(err error, fallback string, fields ...zap.Field) error {
+	e := domain.AsError(err)
+	if e == nil {
+		s.logger.Error(fallback, append(fields, zap.Error(err))...)
+		return status.Error(codes.Internal, fallback)
+	}
+
+	switch e.Kind {
+	case domain.ErrNotFound:
+		return status.Error(codes.NotFound, e.Message)
+	case domain.ErrAlreadyExists:
+		return status.Error(codes.AlreadyExists, e.Message)
+	case domain.ErrConflict:
+		return status.Error(codes.Aborted, e.Message)
+	case domain.ErrForbidden:
+		return status.Error(codes.PermissionDenied, e.Message)
+	case domain.ErrInvalid:
+		return status.Error(codes.InvalidArgument, e.Message)
+	case domain.ErrUpstream:
+		s.logger.Warn(fallback, append(fields, zap.Error(err))...)
+		return status.Error(codes.Unavailable, e.Message)
+	default:
+		return status.Error(codes.Unknown, e.Message)
+	}
+}
+
+func (s *Server) listRuns(ctx context.Context, filter domain.RunFilter) ([]*domain.Run, error) {
+	runs, err := s.processor.ListRuns(ctx, filter)
+	if err != nil {
+		s.logger.Error("failed to list runs", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list runs")
+	}
+	return runs, nil
+}
+
+// callUser returns the user commands of the call run as
+func callUser(ctx context.Context) domain.User {
+	user := domain.User{ID: services.AdminUser, Platform: "grpc"}
+	if token, ok := ctx.Value(tokenKey).(*domain.APIToken); ok {
+		user.ID = token.UserID
+	}
+	return user
+}
+
+// toValue converts command details, which may be any JSON encodable value, to a protobuf
+// value
+func toValue(details interface{}) (*structpb.Value, error) {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(decoded)
+}
+
+func newRepository(repo *domain.Repository) *chatopsv1.Repository {
+	response := &chatopsv1.Repository{
+		Name:          repo.Name,
+		Url:           repo.URL,
+		DefaultBranch: repo.DefaultBranch,
+		AddedBy:       repo.AddedBy,
+		AddedAt:       timestamppb.New(repo.AddedAt),
+	}
+	for _, pipeline := range repo.Pipelines {
+		response.Pipelines = append(response.Pipelines, &chatopsv1.Pipeline{
+			Name:      pipeline.Name,
+			Path:      pipeline.Path,
+			IsDefault: pipeline.IsDefault,
+		})
+	}
+	for _, env := range repo.Environments {
+		response.Environments = append(response.Environments, &chatopsv1.Environment{
+			Name:     env.Name,
+			Pipeline: env.Pipeline,
+			Ref:      env.Ref,
+		})
+	}
+	return response
+}
+
+func newRunUpdate(run *domain.Run, previousStatus string) *chatopsv1.RunUpdate {
+	return &chatopsv1.RunUpdate{
+		Run: &chatopsv1.Run{
+			Id:          run.ID,
+			Provider:    run.Provider,
+			Repository:  run.Repository,
+			Pipeline:    run.Pipeline,
+			Environment: run.Environment,
+			Type:        run.Type,
+			Status:      run.Status,
+			Url:         run.URL,
+			TriggeredBy: run.TriggeredBy,
+			CreatedAt:   timestamppb.New(run.CreatedAt),
+			UpdatedAt:   timestamppb.New(run.UpdatedAt),
+			Finished:    run.IsFinished(),
+		},
+		PreviousStatus: previousStatus,
+	}
+}
@@ -0,0 +1,478 @@
+package jenkins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"go.uber.org/zap"
+)
+
+type JenkinsAdapter struct {
+	logger  *zap.Logger
+	config  *config.JenkinsConfig
+	client  *http.Client
+	baseURL *url.URL
+}
+
+type job struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Jobs []job  `json:"jobs"`
+}
+
+type queueItem struct {
+	ID         int    `json:"id"`
+	Cancelled  bool   `json:"cancelled"`
+	Why        string `json:"why"`
+	Executable *struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+	} `json:"executable"`
+}
+
+type build struct {
+	Number            int    `json:"number"`
+	URL               string `json:"url"`
+	Result            string `json:"result"`
+	Building          bool   `json:"building"`
+	Timestamp         int64  `json:"timestamp"`
+	Duration          int64  `json:"duration"`
+	EstimatedDuration int64  `json:"estimatedDuration"`
+}
+
+func NewJenkinsAdapter(logger *zap.Logger, config *config.JenkinsConfig) (*JenkinsAdapter, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("Jenkins base URL is required")
+	}
+
+	baseURL, err := url.Parse(strings.TrimSuffix(config.BaseURL, "/"))
+	if err != nil || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid Jenkins base URL: %s", config.BaseURL)
+	}
+
+	// Jenkins ties CSRF crumbs to the HTTP session, so cookies must be kept between requests
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	return &JenkinsAdapter{
+		logger:  logger,
+		config:  config,
+		client:  &http.Client{Timeout: 30 * time.Second, Jar: jar},
+		baseURL: baseURL,
+	}, nil
+}
+
+// Name returns the provider identifier
+func (a *JenkinsAdapter) Name() string {
+	return "jenkins"
+}
+
+// Host returns the host of the Jenkins controller
+func (a *JenkinsAdapter) Host() string {
+	return strings.ToLower(a.baseURL.Host)
+}
+
+// Ping checks that the controller is reachable and accepts the credentials
+func (a *JenkinsAdapter) Ping(ctx context.Context) error {
+	if _, err := a.getJob(ctx, a.baseURL.String()); err != nil {
+		return fmt.Errorf("failed to reach Jenkins: %w", err)
+	}
+	return nil
+}
+
+// GetRepositoryDetails registers a Jenkins folder or job. The jobs of a folder become its
+// pipelines; a standalone job becomes a repository with a single pipeline.
+func (a *JenkinsAdapter) GetRepositoryDetails(ctx context.Context, repoURL string) (*domain.Repository, error) {
+	jobURL, err := a.controllerURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	j, err := a.getJob(ctx, jobURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job: %w", err)
+	}
+
+	return &domain.Repository{
+		Name:      j.Name,
+		URL:       strings.TrimSuffix(j.URL, "/"),
+		Pipelines: a.jobPipelines(j),
+	}, nil
+}
+
+func (a *JenkinsAdapter) ListPipelines(ctx context.Context, repoURL string) ([]domain.Pipeline, error) {
+	jobURL, err := a.controllerURL(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	j, err := a.getJob(ctx, jobURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job: %w", err)
+	}
+	return a.jobPipelines(j), nil
+}
+
+func (a *JenkinsAdapter) jobPipelines(j *job) []domain.Pipeline {
+	if len(j.Jobs) == 0 {
+		return []domain.Pipeline{
+			{Name: j.Name, Path: a.relativePath(j.URL)},
+		}
+	}
+
+	pipelines := make([]domain.Pipeline, 0, len(j.Jobs))
+	for _, child := range j.Jobs {
+		pipelines = append(pipelines, domain.Pipeline{
+			Name: child.Name,
+			Path: a.relativePath(child.URL),
+		})
+	}
+	return pipelines
+}
+
+// TriggerWorkflow queues a build of the pipeline job. Jenkins assigns the build number
+// once an executor picks the queue item up, so the queue item is returned as the pending
+// run for ResolveRun.
+func (a *JenkinsAdapter) TriggerWorkflow(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+	jobPath := strings.Trim(trigger.Workflow, "/")
+	if jobPath == "" {
+		return nil, fmt.Errorf("Jenkins job path is required")
+	}
+
+	endpoint := jobPath + "/build"
+	form := url.Values{}
+	if len(trigger.Parameters) > 0 {
+		endpoint = jobPath + "/buildWithParameters"
+		for key, value := range trigger.Parameters {
+			form.Set(key, fmt.Sprint(value))
+		}
+	}
+
+	resp, err := a.post(ctx, a.resolve(endpoint), form)
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to trigger Jenkins job: %v", err),
+		}, nil
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to trigger Jenkins job: HTTP %d", resp.StatusCode),
+		}, nil
+	}
+
+	queueURL := resp.Header.Get("Location")
+	if queueURL == "" {
+		return nil, fmt.Errorf("Jenkins did not return a queue item location")
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: "Jenkins job queued",
+		Details: map[string]interface{}{
+			"pending_run": a.relativePath(queueURL),
+		},
+	}, nil
+}
+
+// ResolveRun follows a queue item until it leaves the queue. The run ID is the build
+// path relative to the controller.
+func (a *JenkinsAdapter) ResolveRun(ctx context.Context, repoURL string, pendingRun string) (*domain.Run, error) {
+	resp, err := a.get(ctx, a.resolve(pendingRun)+"/api/json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch queue item: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Jenkins forgets queue items a few minutes after they leave the queue
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, domain.Conflict("Jenkins queue item %s expired", pendingRun)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to fetch queue item: HTTP %d", resp.StatusCode)
+	}
+
+	var item queueItem
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+		return nil, fmt.Errorf("failed to decode queue item: %w", err)
+	}
+	if item.Cancelled {
+		return nil, domain.Conflict("Jenkins queue item %s was cancelled", pendingRun)
+	}
+	if item.Executable == nil {
+		return nil, nil
+	}
+
+	return &domain.Run{
+		ID:     a.relativePath(item.Executable.URL),
+		URL:    item.Executable.URL,
+		Status: "running",
+	}, nil
+}
+
+func (a *JenkinsAdapter) GetRunStatus(ctx context.Context, repoURL string, runID string) (*domain.WorkflowStatus, error) {
+	var b build
+	if err := a.getJSON(ctx, a.resolve(runID)+"/api/json", &b); err != nil {
+		return nil, fmt.Errorf("failed to fetch build: %w", err)
+	}
+
+	status := &domain.WorkflowStatus{
+		ID:        runID,
+		UpdatedAt: time.UnixMilli(b.Timestamp + b.Duration),
+	}
+	if b.Building {
+		status.Status = "running"
+		if b.EstimatedDuration > 0 {
+			elapsed := time.Since(time.UnixMilli(b.Timestamp)).Milliseconds()
+			status.Progress = int(min(elapsed*100/b.EstimatedDuration, 99))
+		}
+		return status, nil
+	}
+
+	status.Progress = 100
+	switch b.Result {
+	case "SUCCESS":
+		status.Status = "success"
+	case "ABORTED":
+		status.Status = "cancelled"
+	case "UNSTABLE":
+		status.Status = "unstable"
+		status.Error = fmt.Sprintf("build %d is unstable", b.Number)
+	default:
+		status.Status = "failure"
+		status.Error = fmt.Sprintf("build %d finished with %s", b.Number, b.Result)
+	}
+
+	return status, nil
+}
+
+func (a *JenkinsAdapter) CancelRun(ctx context.Context, repoURL string, runID string) error {
+	resp, err := a.post(ctx, a.resolve(runID)+"/stop", url.Values{})
+	if err != nil {
+		return fmt.Errorf("failed to stop build: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to stop build: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RetryRun rebuilds a build with its original parameters. Jenkins has no notion of
+// re-running only the failed part of a build.
+func (a *JenkinsAdapter) RetryRun(ctx context.Context, repoURL string, runID string, failedOnly bool) (*domain.CommandResult, error) {
+	if failedOnly {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: "Jenkins does not support re-running only failed jobs",
+		}, nil
+	}
+
+	var b struct {
+		Actions []struct {
+			Parameters []struct {
+				Name  string      `json:"name"`
+				Value interface{} `json:"value"`
+			} `json:"parameters"`
+		} `json:"actions"`
+	}
+	if err := a.getJSON(ctx, a.resolve(runID)+"/api/json?tree=actions[parameters[name,value]]", &b); err != nil {
+		return nil, fmt.Errorf("failed to fetch build: %w", err)
+	}
+
+	params := make(map[string]interface{})
+	for _, action := range b.Actions {
+		for _, p := range action.Parameters {
+			params[p.Name] = p.Value
+		}
+	}
+
+	// The job path is the run ID without its trailing build number
+	jobPath := runID[:max(strings.LastIndex(runID, "/"), 0)]
+	return a.TriggerWorkflow(ctx, &domain.WorkflowTrigger{
+		Repository: repoURL,
+		Workflow:   jobPath,
+		Parameters: params,
+	})
+}
+
+func (a *JenkinsAdapter) GetRunLogs(ctx context.Context, repoURL string, runID string) ([]domain.JobLog, error) {
+	status, err := a.GetRunStatus(ctx, repoURL, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.get(ctx, a.resolve(runID)+"/consoleText")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch console output: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to fetch console output: HTTP %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read console output: %w", err)
+	}
+
+	jobLog := domain.JobLog{
+		JobID:   runID,
+		Name:    runID,
+		Status:  status.Status,
+		Content: string(content),
+	}
+	if status.Status == "failure" {
+		jobLog.FailedStep = lastPipelineStage(jobLog.Content)
+	}
+
+	return []domain.JobLog{jobLog}, nil
+}
+
+// lastPipelineStage returns the last declarative pipeline stage opened in the console output
+func lastPipelineStage(console string) string {
+	var stage string
+	scanner := bufio.NewScanner(strings.NewReader(console))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if rest, ok := strings.CutPrefix(line, "[Pipeline] { ("); ok {
+			stage = strings.TrimSuffix(rest, ")")
+		}
+	}
+	return stage
+}
+
+func (a *JenkinsAdapter) getJob(ctx context.Context, jobURL string) (*job, error) {
+	var j job
+	endpoint := strings.TrimSuffix(jobURL, "/") + "/api/json?tree=name,url,jobs[name,url]"
+	if err := a.getJSON(ctx, endpoint, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// post sends a form to Jenkins, attaching a CSRF crumb when the controller issues one
+func (a *JenkinsAdapter) post(ctx context.Context, endpoint string, form url.Values) (*http.Response, error) {
+	field, crumb, err := a.getCrumb(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := a.newRequest(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if field != "" {
+		req.Header.Set(field, crumb)
+	}
+
+	return a.client.Do(req)
+}
+
+// getCrumb fetches a CSRF crumb. An empty field means CSRF protection is disabled.
+func (a *JenkinsAdapter) getCrumb(ctx context.Context) (field, crumb string, err error) {
+	resp, err := a.get(ctx, a.resolve("crumbIssuer/api/json"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch CSRF crumb: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", "", nil
+	}
+	if resp.StatusCode >= 400 {
+		return "", "", fmt.Errorf("failed to fetch CSRF crumb: HTTP %d", resp.StatusCode)
+	}
+
+	var issued struct {
+		Crumb             string `json:"crumb"`
+		CrumbRequestField string `json:"crumbRequestField"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issued); err != nil {
+		return "", "", fmt.Errorf("failed to decode CSRF crumb: %w", err)
+	}
+
+	return issued.CrumbRequestField, issued.Crumb, nil
+}
+
+func (a *JenkinsAdapter) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	resp, err := a.get(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("Jenkins API returned HTTP %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (a *JenkinsAdapter) get(ctx context.Context, endpoint string) (*http.Response, error) {
+	req, err := a.newRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.client.Do(req)
+}
+
+func (a *JenkinsAdapter) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	// Credentials only go to the controller, over the scheme it is configured with
+	if a.config.User != "" && req.URL.Scheme == a.baseURL.Scheme && strings.EqualFold(req.URL.Host, a.baseURL.Host) {
+		req.SetBasicAuth(a.config.User, a.config.APIToken)
+	}
+	return req, nil
+}
+
+// controllerURL rewrites a job URL given by a user onto the configured controller URL,
+// so requests keep its scheme whatever the user typed
+func (a *JenkinsAdapter) controllerURL(jobURL string) (string, error) {
+	u, err := url.Parse(jobURL)
+	if err != nil || !strings.EqualFold(u.Host, a.baseURL.Host) {
+		return "", domain.Invalid("%s is not a job of the Jenkins controller %s", jobURL, a.baseURL.Host)
+	}
+	return a.resolve(a.relativePath(jobURL)), nil
+}
+
+// resolve builds an absolute URL from a path relative to the Jenkins controller
+func (a *JenkinsAdapter) resolve(path string) string {
+	return a.baseURL.String() + "/" + strings.Trim(path, "/")
+}
+
+// relativePath strips the controller URL from an absolute job URL
+func (a *JenkinsAdapter) relativePath(jobURL string) string {
+	u, err := url.Parse(jobURL)
+	if err != nil {
+		return strings.Trim(jobURL, "/")
+	}
+	path := strings.TrimPrefix(u.Path, a.baseURL.Path)
+	return strings.Trim(path, "/")
+}
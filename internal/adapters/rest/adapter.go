@@ -0,0 +1,185 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"go.uber.org/zap"
+)
+
+type contextKey int
+
+// tokenKey holds the token of an authenticated request
+const tokenKey contextKey = iota
+
+// RESTAdapter serves the JSON API
+type RESTAdapter struct {
+	logger    *zap.Logger
+	auth      *services.Authenticator
+	processor *services.CommandProcessor
+	tokens    *services.TokenService
+	webhooks  *services.WebhookService
+	audit     *services.AuditLog
+}
+
+type RESTAdapterOptions struct {
+	Logger    *zap.Logger
+	Auth      *services.Authenticator
+	Processor *services.CommandProcessor
+	Tokens    *services.TokenService   // Optional: enables token management
+	Webhooks  *services.WebhookService // Optional: enables webhook management
+	Audit     *services.AuditLog       // Optional: enables the audit log
+}
+
+// NewRESTAdapter creates a new instance of RESTAdapter
+func NewRESTAdapter(opts RESTAdapterOptions) (*RESTAdapter, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.Auth == nil {
+		return nil, fmt.Errorf("authenticator is required")
+	}
+	if opts.Processor == nil {
+		return nil, fmt.Errorf("command processor is required")
+	}
+
+	return &RESTAdapter{
+		logger:    opts.Logger,
+		auth:      opts.Auth,
+		processor: opts.Processor,
+		tokens:    opts.Tokens,
+		webhooks:  opts.Webhooks,
+		audit:     opts.Audit,
+	}, nil
+}
+
+// Authenticate requires a bearer token, see services.Authenticator. The API is disabled
+// when no token can authenticate.
+func (a *RESTAdapter) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.auth.Enabled() {
+			a.sendError(w, "The API is disabled", http.StatusForbidden)
+			return
+		}
+
+		value, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		token, err := a.auth.Authenticate(r.Context(), value)
+		if errors.Is(err, services.ErrInvalidToken) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			a.sendError(w, "Invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			a.logger.Error("failed to authenticate token", zap.Error(err))
+			a.sendError(w, "Failed to authenticate", http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey, token)))
+	})
+}
+
+// RequireScope rejects requests whose token lacks the scope
+func (a *RESTAdapter) RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := requestToken(r); token == nil || !token.HasScope(scope) {
+			a.sendError(w, fmt.Sprintf("The token lacks the %s scope", scope), http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requestToken returns the token of an authenticated request
+func requestToken(r *http.Request) *domain.APIToken {
+	token, _ := r.Context().Value(tokenKey).(*domain.APIToken)
+	return token
+}
+
+// isAdminToken reports whether a token is the admin token, the only one not stored
+func isAdminToken(token *domain.APIToken) bool {
+	return token.ID == ""
+}
+
+// requestUser returns the user commands of the request run as, subject to the same RBAC
+// checks as chat users
+func requestUser(r *http.Request) domain.User {
+	user := domain.User{ID: services.AdminUser, Platform: "api"}
+	if token := requestToken(r); token != nil {
+		user.ID = token.UserID
+	}
+	return user
+}
+
+// queryLimit parses the limit query parameter
+func queryLimit(r *http.Request, defaultLimit, maxLimit int) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 || n > maxLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+	}
+	return n, nil
+}
+
+func (a *RESTAdapter) sendJSON(w http.ResponseWriter, body interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		a.logger.Error("failed to encode response", zap.Error(err))
+	}
+}
+
+func (a *RESTAdapter) sendError(w http.ResponseWriter, message string, statusCode int) {
+	a.sendJSON(w, map[string]interface{}{
+		"status":  "error",
+		"message": message,
+	}, statusCode)
+}
+
+// sendNotEnabled responds to requests for features that are not configured
+func (a *RESTAdapter) sendNotEnabled(w http.ResponseWriter, feature string) {
+	a.sendError(w, feature+" are not enabled", http.StatusNotFound)
+}
+
+// sendServiceError responds to a failed service call. Errors of a known kind are answered
+// with their message and status; others are logged and answered with the fallback message.
+func (a *RESTAdapter) sendServiceError(w http.ResponseWriter, err error, fallback string, fields ...zap.Field) {
+	if e := domain.AsError(err); e != nil {
+		if e.Kind == domain.ErrUpstream {
+			a.logger.Warn(fallback, append(fields, zap.Error(err))...)
+		}
+		a.sendError(w, e.Sentence(), errorStatus(e.Kind))
+		return
+	}
+
+	a.logger.Error(fallback, append(fields, zap.Error(err))...)
+	a.sendError(w, fallback, http.StatusInternalServerError)
+}
+
+// errorStatus maps an error kind to an HTTP status
+func errorStatus(kind domain.ErrorKind) int {
+	switch kind {
+	case domain.ErrNotFound:
+		return http.StatusNotFound
+	case domain.ErrAlreadyExists, domain.ErrConflict:
+		return http.StatusConflict
+	case domain.ErrForbidden:
+		return http.StatusForbidden
+	case domain.ErrInvalid:
+		return http.StatusBadRequest
+	case domain.ErrUpstream:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type auditEventResponse struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Repository string          `json:"repository,omitempty"`
+	Actor      string          `json:"actor,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// ListAuditEvents handles GET /audit?type=&repository=&actor=&since=&limit=, newest
+// first. type takes an event filter such as "deployment" or "lock.taken", and since an
+// RFC 3339 time.
+func (a *RESTAdapter) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	if a.audit == nil {
+		a.sendNotEnabled(w, "Audit events")
+		return
+	}
+
+	limit, err := queryLimit(r, defaultAuditLimit, maxAuditLimit)
+	if err != nil {
+		a.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		Type:       query.Get("type"),
+		Repository: query.Get("repository"),
+		Actor:      query.Get("actor"),
+		Limit:      limit,
+	}
+	if since := query.Get("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			a.sendError(w, "since must be an RFC 3339 time", http.StatusBadRequest)
+			return
+		}
+	}
+	if filter.Type != "" {
+		if err := domain.ValidateEventFilter(filter.Type); err != nil {
+			a.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := a.audit.ListEvents(r.Context(), filter)
+	if err != nil {
+		a.logger.Error("failed to list audit events", zap.Error(err))
+		a.sendError(w, "Failed to list audit events", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]auditEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, auditEventResponse{
+			ID:         event.ID,
+			Type:       event.Type,
+			Repository: event.Repository,
+			Actor:      event.Actor,
+			Message:    event.Message,
+			Payload:    json.RawMessage(event.Payload),
+			OccurredAt: event.OccurredAt,
+		})
+	}
+	a.sendJSON(w, response, http.StatusOK)
+}
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// commandRequest is a command to run. The user is the one of the token, and the source
+// platform is always "api".
+type commandRequest struct {
+	Type       string                 `json:"type"`
+	Parameters map[string]interface{} `json:"parameters"`
+	ChannelID  string                 `json:"channel_id"` // Optional: channel notifications of the command are posted to
+}
+
+type commandResponse struct {
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+type repositoryResponse struct {
+	ID            string                `json:"id"`
+	Name          string                `json:"name"`
+	URL           string                `json:"url"`
+	DefaultBranch string                `json:"default_branch"`
+	AddedBy       string                `json:"added_by"`
+	AddedAt       time.Time             `json:"added_at"`
+	Pipelines     []pipelineResponse    `json:"pipelines"`
+	Environments  []environmentResponse `json:"environments"`
+}
+
+type pipelineResponse struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	IsDefault bool   `json:"is_default"`
+}
+
+type environmentResponse struct {
+	Name     string `json:"name"`
+	Pipeline string `json:"pipeline"`
+	Ref      string `json:"ref,omitempty"`
+}
+
+// ProcessCommand handles POST /commands. Commands are subject to the RBAC permissions of
+// the token user.
+func (a *RESTAdapter) ProcessCommand(w http.ResponseWriter, r *http.Request) {
+	var req commandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Type == "" {
+		a.sendError(w, "type is required", http.StatusBadRequest)
+		return
+	}
+	if req.Parameters == nil {
+		req.Parameters = map[string]interface{}{}
+	}
+
+	cmd := &domain.Command{
+		Type:       req.Type,
+		Parameters: req.Parameters,
+		User:       requestUser(r),
+		Source: domain.CommandSource{
+			Platform:  "api",
+			ChannelID: req.ChannelID,
+		},
+		Timestamp: time.Now(),
+	}
+
+	result, err := a.processor.ProcessCommand(r.Context(), cmd)
+	if err != nil {
+		a.sendServiceError(w, err, "Failed to process command", zap.String("type", cmd.Type))
+		return
+	}
+
+	response := commandResponse{
+		Status:  result.Status,
+		Message: result.Message,
+		Details: result.Details,
+	}
+	if result.Error != nil {
+		response.Error = result.Error.Error()
+	}
+	a.sendJSON(w, response, http.StatusOK)
+}
+
+// ListRepositories handles GET /repositories
+func (a *RESTAdapter) ListRepositories(w http.ResponseWriter, r *http.Request) {
+	repos, err := a.processor.ListRepositories(r.Context())
+	if err != nil {
+		a.logger.Error("failed to list repositories", zap.Error(err))
+		a.sendError(w, "Failed to list repositories", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]repositoryResponse, 0, len(repos))
+	for _, repo := range repos {
+		response = append(response, newRepositoryResponse(repo))
+	}
+	a.sendJSON(w, response, http.StatusOK)
+}
+
+// ListPipelines handles GET /repositories/{name}/pipelines
+func (a *RESTAdapter) ListPipelines(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	pipelines, err := a.processor.ListPipelines(r.Context(), name)
+	if err != nil {
+		a.sendServiceError(w, err, "Failed to list pipelines of "+name, zap.String("repository", name))
+		return
+	}
+
+	a.sendJSON(w, newPipelineResponses(pipelines), http.StatusOK)
+}
+
+func newRepositoryResponse(repo *domain.Repository) repositoryResponse {
+	response := repositoryResponse{
+		ID:            repo.ID,
+		Name:          repo.Name,
+		URL:           repo.URL,
+		DefaultBranch: repo.DefaultBranch,
+		AddedBy:       repo.AddedBy,
+		AddedAt:       repo.AddedAt,
+		Pipelines:     newPipelineResponses(repo.Pipelines),
+		Environments:  make([]environmentResponse, 0, len(repo.Environments)),
+	}
+	for _, env := range repo.Environments {
+		response.Environments = append(response.Environments, environmentResponse{
+			Name:     env.Name,
+			Pipeline: env.Pipeline,
+			Ref:      env.Ref,
+		})
+	}
+	return response
+}
+
+func newPipelineResponses(pipelines []domain.Pipeline) []pipelineResponse {
+	response := make([]pipelineResponse, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		response = append(response, pipelineResponse{
+			Name:      pipeline.Name,
+			Path:      pipeline.Path,
+			IsDefault: pipeline.IsDefault,
+		})
+	}
+	return response
+}
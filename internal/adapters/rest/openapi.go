@@ -0,0 +1,23 @@
+package rest
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes every route of router.NewRouter. The client package and the
+// router are tested against it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPISpec returns the OpenAPI 3 document of the HTTP API
+func OpenAPISpec() []byte {
+	return openAPISpec
+}
+
+// ServeOpenAPI handles GET /openapi.json. The document is public, it holds no secrets.
+func ServeOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRunLimit = 50
+	maxRunLimit     = 500
+)
+
+type runResponse struct {
+	ID          string    `json:"id"`
+	Provider    string    `json:"provider"`
+	Repository  string    `json:"repository"`
+	Pipeline    string    `json:"pipeline"`
+	Environment string    `json:"environment,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Status      string    `json:"status"`
+	URL         string    `json:"url,omitempty"`
+	TriggeredBy string    `json:"triggered_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListRuns handles GET /runs?repository=&pipeline=&user=&limit=, newest first
+func (a *RESTAdapter) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryLimit(r, defaultRunLimit, maxRunLimit)
+	if err != nil {
+		a.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	runs, err := a.processor.ListRuns(r.Context(), domain.RunFilter{
+		Repository:  query.Get("repository"),
+		Pipeline:    query.Get("pipeline"),
+		TriggeredBy: query.Get("user"),
+		Limit:       limit,
+	})
+	if err != nil {
+		a.logger.Error("failed to list runs", zap.Error(err))
+		a.sendError(w, "Failed to list runs", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]runResponse, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, newRunResponse(run))
+	}
+	a.sendJSON(w, response, http.StatusOK)
+}
+
+// GetRun handles GET /runs/{id}
+func (a *RESTAdapter) GetRun(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	run, err := a.processor.GetRun(r.Context(), id)
+	if err != nil {
+		a.sendServiceError(w, err, "Failed to get run", zap.String("run_id", id))
+		return
+	}
+
+	a.sendJSON(w, newRunResponse(run), http.StatusOK)
+}
+
+func newRunResponse(run *domain.Run) runResponse {
+	return runResponse{
+		ID:          run.ID,
+		Provider:    run.Provider,
+		Repository:  run.Repository,
+		Pipeline:    run.Pipeline,
+		Environment: run.Environment,
+		Type:        run.Type,
+		Status:      run.Status,
+		URL:         run.URL,
+		TriggeredBy: run.TriggeredBy,
+		CreatedAt:   run.CreatedAt,
+		UpdatedAt:   run.UpdatedAt,
+	}
+}
@@ -0,0 +1,168 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// tokenRequest creates an API token. The user defaults to the one of the requesting token.
+type tokenRequest struct {
+	Name      string     `json:"name"`
+	UserID    string     `json:"user_id"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// tokenResponse describes an API token. The token itself is only included on creation.
+type tokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id"`
+	Scopes     []string   `json:"scopes"`
+	Token      string     `json:"token,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ListTokens handles GET /tokens. Only the admin token lists the tokens of other users.
+func (a *RESTAdapter) ListTokens(w http.ResponseWriter, r *http.Request) {
+	if a.tokens == nil {
+		a.sendNotEnabled(w, "API tokens")
+		return
+	}
+
+	tokens, err := a.tokens.ListTokens(r.Context())
+	if err != nil {
+		a.logger.Error("failed to list tokens", zap.Error(err))
+		a.sendError(w, "Failed to list tokens", http.StatusInternalServerError)
+		return
+	}
+
+	requester := requestToken(r)
+	response := make([]tokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		if canManageToken(requester, token) {
+			response = append(response, newTokenResponse(token))
+		}
+	}
+	a.sendJSON(w, response, http.StatusOK)
+}
+
+// CreateToken handles POST /tokens, responding with the token once
+func (a *RESTAdapter) CreateToken(w http.ResponseWriter, r *http.Request) {
+	if a.tokens == nil {
+		a.sendNotEnabled(w, "API tokens")
+		return
+	}
+
+	var req tokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Tokens cannot grant more than the token creating them
+	creator := requestToken(r)
+	if req.UserID != "" && req.UserID != creator.UserID && !isAdminToken(creator) {
+		a.sendError(w, "Only the admin token can create tokens of other users", http.StatusForbidden)
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !creator.HasScope(scope) {
+			a.sendError(w, "Cannot grant the "+scope+" scope the token lacks", http.StatusForbidden)
+			return
+		}
+	}
+
+	token := &domain.APIToken{
+		Name:      req.Name,
+		UserID:    strings.TrimSpace(req.UserID),
+		Scopes:    req.Scopes,
+		CreatedBy: creator.UserID,
+	}
+	if token.UserID == "" {
+		token.UserID = creator.UserID
+	}
+	if req.ExpiresAt != nil {
+		token.ExpiresAt = *req.ExpiresAt
+	}
+
+	value, err := a.tokens.CreateToken(r.Context(), token)
+	if err != nil {
+		a.sendServiceError(w, err, "Failed to create token", zap.String("name", token.Name))
+		return
+	}
+
+	response := newTokenResponse(token)
+	response.Token = value
+	a.sendJSON(w, response, http.StatusCreated)
+}
+
+// RevokeToken handles DELETE /tokens/{id}. Revoked tokens are kept for the record. Only
+// the admin token revokes the tokens of other users.
+func (a *RESTAdapter) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	if a.tokens == nil {
+		a.sendNotEnabled(w, "API tokens")
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	tokens, err := a.tokens.ListTokens(r.Context())
+	if err != nil {
+		a.logger.Error("failed to list tokens", zap.Error(err))
+		a.sendError(w, "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+	index := slices.IndexFunc(tokens, func(token *domain.APIToken) bool { return token.ID == id })
+	if index < 0 {
+		a.sendError(w, "Token "+id+" not found", http.StatusNotFound)
+		return
+	}
+	if !canManageToken(requestToken(r), tokens[index]) {
+		a.sendError(w, "Only the admin token can revoke tokens of other users", http.StatusForbidden)
+		return
+	}
+
+	if err := a.tokens.RevokeToken(r.Context(), id); err != nil {
+		a.sendServiceError(w, err, "Failed to revoke token", zap.String("token_id", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// canManageToken reports whether a requesting token may see or revoke a token: its own
+// user's tokens, or any token for the admin token
+func canManageToken(requester, token *domain.APIToken) bool {
+	return isAdminToken(requester) || token.UserID == requester.UserID
+}
+
+func newTokenResponse(token *domain.APIToken) tokenResponse {
+	response := tokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		UserID:    token.UserID,
+		Scopes:    token.Scopes,
+		CreatedBy: token.CreatedBy,
+		CreatedAt: token.CreatedAt,
+	}
+	if !token.ExpiresAt.IsZero() {
+		response.ExpiresAt = &token.ExpiresAt
+	}
+	if !token.LastUsedAt.IsZero() {
+		response.LastUsedAt = &token.LastUsedAt
+	}
+	if !token.RevokedAt.IsZero() {
+		response.RevokedAt = &token.RevokedAt
+	}
+	return response
+}
@@ -0,0 +1,242 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// webhookRequest creates or replaces a webhook. Omitting the secret on update keeps the
+// current one, and webhooks are active unless stated otherwise.
+type webhookRequest struct {
+	URL     string            `json:"url"`
+	Events  []string          `json:"events"`
+	Secret  *string           `json:"secret"`
+	Headers map[string]string `json:"headers"`
+	Active  *bool             `json:"active"`
+}
+
+// webhookResponse describes a webhook without revealing its secret
+type webhookResponse struct {
+	ID        string            `json:"id"`
+	URL       string            `json:"url"`
+	Events    []string          `json:"events"`
+	HasSecret bool              `json:"has_secret"`
+	Headers   map[string]string `json:"headers"`
+	Active    bool              `json:"active"`
+	CreatedBy string            `json:"created_by"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type deliveryResponse struct {
+	ID            string     `json:"id"`
+	EventID       string     `json:"event_id,omitempty"`
+	EventType     string     `json:"event_type"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// ListWebhooks handles GET /webhooks
+func (a *RESTAdapter) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	if a.webhooks == nil {
+		a.sendNotEnabled(w, "Webhooks")
+		return
+	}
+
+	webhooks, err := a.webhooks.ListWebhooks(r.Context())
+	if err != nil {
+		a.logger.Error("failed to list webhooks", zap.Error(err))
+		a.sendError(w, "Failed to list webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]webhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, newWebhookResponse(webhook))
+	}
+	a.sendJSON(w, response, http.StatusOK)
+}
+
+// CreateWebhook handles POST /webhooks
+func (a *RESTAdapter) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if a.webhooks == nil {
+		a.sendNotEnabled(w, "Webhooks")
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	webhook := &domain.Webhook{CreatedBy: requestUser(r).ID}
+	applyWebhookRequest(webhook, &req)
+	if err := services.ValidateWebhook(webhook); err != nil {
+		a.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.webhooks.CreateWebhook(r.Context(), webhook); err != nil {
+		a.logger.Error("failed to create webhook", zap.Error(err))
+		a.sendError(w, "Failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+	a.sendJSON(w, newWebhookResponse(webhook), http.StatusCreated)
+}
+
+// GetWebhook handles GET /webhooks/{id}
+func (a *RESTAdapter) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := a.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	a.sendJSON(w, newWebhookResponse(webhook), http.StatusOK)
+}
+
+// UpdateWebhook handles PUT /webhooks/{id}
+func (a *RESTAdapter) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		a.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	webhook, ok := a.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	applyWebhookRequest(webhook, &req)
+	if err := services.ValidateWebhook(webhook); err != nil {
+		a.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := a.webhooks.UpdateWebhook(r.Context(), webhook); err != nil {
+		a.sendServiceError(w, err, "Failed to update webhook", zap.String("webhook_id", webhook.ID))
+		return
+	}
+	a.sendJSON(w, newWebhookResponse(webhook), http.StatusOK)
+}
+
+// DeleteWebhook handles DELETE /webhooks/{id}
+func (a *RESTAdapter) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := a.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if err := a.webhooks.DeleteWebhook(r.Context(), webhook.ID); err != nil {
+		a.sendServiceError(w, err, "Failed to delete webhook", zap.String("webhook_id", webhook.ID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /webhooks/{id}/deliveries?limit=N, newest first
+func (a *RESTAdapter) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryLimit(r, defaultDeliveryLimit, maxDeliveryLimit)
+	if err != nil {
+		a.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	webhook, ok := a.findWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := a.webhooks.ListDeliveries(r.Context(), webhook.ID, limit)
+	if err != nil {
+		a.logger.Error("failed to list deliveries", zap.String("webhook_id", webhook.ID), zap.Error(err))
+		a.sendError(w, "Failed to list deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]deliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, newDeliveryResponse(delivery))
+	}
+	a.sendJSON(w, response, http.StatusOK)
+}
+
+// findWebhook loads the webhook of the request path, responding with an error when it
+// cannot be found
+func (a *RESTAdapter) findWebhook(w http.ResponseWriter, r *http.Request) (*domain.Webhook, bool) {
+	if a.webhooks == nil {
+		a.sendNotEnabled(w, "Webhooks")
+		return nil, false
+	}
+
+	id := mux.Vars(r)["id"]
+	webhook, err := a.webhooks.GetWebhook(r.Context(), id)
+	if err != nil {
+		a.sendServiceError(w, err, "Failed to get webhook", zap.String("webhook_id", id))
+		return nil, false
+	}
+	return webhook, true
+}
+
+func applyWebhookRequest(webhook *domain.Webhook, req *webhookRequest) {
+	webhook.URL = req.URL
+	webhook.Events = req.Events
+	webhook.Headers = req.Headers
+	if req.Secret != nil {
+		webhook.Secret = *req.Secret
+	}
+	webhook.Active = req.Active == nil || *req.Active
+}
+
+func newWebhookResponse(webhook *domain.Webhook) webhookResponse {
+	headers := webhook.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	return webhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		HasSecret: webhook.Secret != "",
+		Headers:   headers,
+		Active:    webhook.Active,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+func newDeliveryResponse(delivery *domain.WebhookDelivery) deliveryResponse {
+	response := deliveryResponse{
+		ID:           delivery.ID,
+		EventID:      delivery.EventID,
+		EventType:    delivery.EventType,
+		Status:       delivery.Status,
+		Attempts:     delivery.Attempts,
+		ResponseCode: delivery.ResponseCode,
+		Error:        delivery.Error,
+		CreatedAt:    delivery.CreatedAt,
+	}
+	if delivery.Status == domain.DeliveryStatusPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	if !delivery.DeliveredAt.IsZero() {
+		response.DeliveredAt = &delivery.DeliveredAt
+	}
+	return response
+}
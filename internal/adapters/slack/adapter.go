@@ -27,6 +27,7 @@ type SlackAdapter struct {
 	config    *config.SlackConfig
 	processor *services.CommandProcessor
 	client    *slack.Client
+	events    *eventCache
 }
 
 // NewSlackAdapter creates a new instance of SlackAdapter
@@ -44,16 +45,34 @@ func NewSlackAdapter(logger *zap.Logger, config *config.SlackConfig, processor *
 		return nil, fmt.Errorf("slack bot token is required")
 	}
 
-	client := slack.New(config.BotToken)
-
 	return &SlackAdapter{
 		logger:    logger,
 		config:    config,
 		processor: processor,
-		client:    client,
+		client:    newClient(config),
+		events:    newEventCache(eventRetention),
 	}, nil
 }
 
+// CheckToken checks that Slack is reachable and accepts the bot token of the configuration
+func CheckToken(ctx context.Context, config *config.SlackConfig) error {
+	if config.BotToken == "" {
+		return fmt.Errorf("slack bot token is required")
+	}
+	if _, err := newClient(config).AuthTestContext(ctx); err != nil {
+		return fmt.Errorf("failed to authenticate with Slack: %w", err)
+	}
+	return nil
+}
+
+func newClient(config *config.SlackConfig) *slack.Client {
+	var options []slack.Option
+	if config.APIURL != "" {
+		options = append(options, slack.OptionAPIURL(config.APIURL))
+	}
+	return slack.New(config.BotToken, options...)
+}
+
 func (a *SlackAdapter) HandleSlashCommand(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -77,6 +96,20 @@ func (a *SlackAdapter) HandleSlashCommand(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// "run" opens a modal to enter the command instead of running it
+	if fields := strings.Fields(cmd.Text); len(fields) > 0 && fields[0] == "run" {
+		if err := a.openRunModal(r.Context(), cmd, fields[1:]); err != nil {
+			a.logger.Error("failed to open run modal", zap.Error(err))
+			a.sendErrorResponse(w, fmt.Sprintf("Failed to open the run dialog: %v", err), http.StatusBadRequest)
+			return
+		}
+		response := map[string]interface{}{"status": "success", "message": "Opened the run dialog"}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			a.logger.Error("failed to encode response", zap.Error(err))
+		}
+		return
+	}
+
 	domainCmd, err := a.parseCommand(cmd)
 	if err != nil {
 		a.sendErrorResponse(w, err.Error(), http.StatusBadRequest)
@@ -85,16 +118,57 @@ func (a *SlackAdapter) HandleSlashCommand(w http.ResponseWriter, r *http.Request
 
 	result, err := a.processor.ProcessCommand(r.Context(), domainCmd)
 	if err != nil {
-		a.sendErrorResponse(w, fmt.Sprintf("Failed to process command: %v", err), http.StatusInternalServerError)
+		statusCode := http.StatusOK
+		if domain.AsError(err) == nil {
+			a.logger.Error("failed to process command", zap.String("command", domainCmd.Type), zap.Error(err))
+			statusCode = http.StatusInternalServerError
+		}
+		a.sendErrorResponse(w, errorText(err), statusCode)
 		return
 	}
 
+	// Log summaries are posted to the channel, with the full log attached in the thread
+	if summary, ok := result.Details.(*domain.LogSummary); ok {
+		if err := a.postLogSummary(r.Context(), cmd.ChannelID, "", result.Message, summary); err != nil {
+			a.logger.Error("failed to post log summary", zap.String("run_id", summary.RunID), zap.Error(err))
+			result.Message = fmt.Sprintf("%s\n```\n%s\n```", result.Message, summary.Excerpt)
+		} else {
+			result.Message = fmt.Sprintf("Posted logs of run %s to the channel", summary.RunID)
+		}
+	}
+
 	response := a.buildSlackResponse(result)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		a.logger.Error("failed to encode response", zap.Error(err))
 	}
 }
 
+// errorText renders a failed command as a chat message. Errors of a known kind are shown
+// with their message, others without details, which only belong in the logs.
+func errorText(err error) string {
+	e := domain.AsError(err)
+	if e == nil {
+		return "Something went wrong while processing the command, please try again later"
+	}
+
+	switch e.Kind {
+	case domain.ErrNotFound:
+		return "Not found: " + e.Message
+	case domain.ErrAlreadyExists:
+		return "Already exists: " + e.Message
+	case domain.ErrConflict:
+		return "Changed meanwhile, please try again: " + e.Message
+	case domain.ErrForbidden:
+		return e.Sentence()
+	case domain.ErrInvalid:
+		return "Invalid command: " + e.Message
+	case domain.ErrUpstream:
+		return "The CI provider failed, please try again later: " + e.Message
+	default:
+		return e.Sentence()
+	}
+}
+
 func (a *SlackAdapter) sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
 	w.WriteHeader(statusCode)
 	response := map[string]interface{}{
@@ -107,50 +181,322 @@ func (a *SlackAdapter) sendErrorResponse(w http.ResponseWriter, message string,
 }
 
 func (a *SlackAdapter) parseCommand(cmd slack.SlashCommand) (*domain.Command, error) {
-	parts := strings.Fields(cmd.Text)
+	parts, force := splitForce(strings.Fields(cmd.Text))
 	if len(parts) < 2 {
 		return nil, fmt.Errorf("invalid command format: expected at least 2 parts, got %d", len(parts))
 	}
 
+	domainCmd, err := parseAction(cmd, parts)
+	if err != nil {
+		return nil, err
+	}
+	if force {
+		// Scheduled commands carry the flag to every run rather than to the scheduling
+		if params, ok := domainCmd.Parameters["command_parameters"].(map[string]interface{}); ok {
+			params["force"] = true
+		} else {
+			domainCmd.Parameters["force"] = true
+		}
+	}
+	return domainCmd, nil
+}
+
+// parseAction converts the action and arguments of a slash command into a domain command
+func parseAction(cmd slack.SlashCommand, parts []string) (*domain.Command, error) {
+
 	action := parts[0]
 	switch action {
 	case "manage":
-		return &domain.Command{
-			Type: domain.CommandTypeManageRepo,
-			Parameters: map[string]interface{}{
-				"repository_url": parts[1],
-			},
-			User: domain.User{
-				ID:       cmd.UserID,
-				Platform: "slack",
-			},
-			Source: domain.CommandSource{
-				Platform:  "slack",
-				ChannelID: cmd.ChannelID,
-			},
-			Timestamp: time.Now(),
-		}, nil
+		return newSlackCommand(cmd, domain.CommandTypeManageRepo, map[string]interface{}{
+			"repository_url": parts[1],
+		}), nil
 	case "verify":
-		return &domain.Command{
-			Type: domain.CommandTypeVerifyRepo,
-			Parameters: map[string]interface{}{
+		params := map[string]interface{}{
+			"repository_name": parts[1],
+		}
+		if len(parts) > 2 {
+			params["pipeline_name"] = strings.Join(parts[2:], " ")
+		}
+		return newSlackCommand(cmd, domain.CommandTypeVerifyRepo, params), nil
+	case "deploy":
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid command format: expected deploy <repository> <environment> [ref]")
+		}
+		params := map[string]interface{}{
+			"repository_name": parts[1],
+			"environment":     parts[2],
+		}
+		if len(parts) > 3 {
+			params["ref"] = parts[3]
+		}
+		return newSlackCommand(cmd, domain.CommandTypeDeploy, params), nil
+	case "envs":
+		if len(parts) == 2 {
+			return newSlackCommand(cmd, domain.CommandTypeListEnvs, map[string]interface{}{
 				"repository_name": parts[1],
-			},
-			User: domain.User{
-				ID:       cmd.UserID,
-				Platform: "slack",
-			},
-			Source: domain.CommandSource{
-				Platform:  "slack",
-				ChannelID: cmd.ChannelID,
-			},
-			Timestamp: time.Now(),
-		}, nil
+			}), nil
+		}
+		args, options := splitOptions(parts[2:])
+		if len(args) < 3 || args[0] != "set" {
+			return nil, fmt.Errorf("invalid command format: expected envs <repository> [set <environment> <pipeline> [ref=<ref>]]")
+		}
+		params := map[string]interface{}{
+			"repository_name": parts[1],
+			"environment":     args[1],
+			"pipeline_name":   strings.Join(args[2:], " "),
+		}
+		if ref, ok := options["ref"]; ok {
+			params["ref"] = ref
+		}
+		return newSlackCommand(cmd, domain.CommandTypeSetEnv, params), nil
+	case "lock", "unlock":
+		args, options := splitOptions(parts[1:])
+		if len(args) < 2 {
+			return nil, fmt.Errorf("invalid command format: expected %s <repository> <environment>", parts[0])
+		}
+		params := map[string]interface{}{
+			"repository_name": args[0],
+			"environment":     args[1],
+		}
+		if parts[0] == "unlock" {
+			return newSlackCommand(cmd, domain.CommandTypeUnlock, params), nil
+		}
+		if ttl, ok := options["ttl"]; ok {
+			d, err := time.ParseDuration(ttl)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid ttl option: %s", ttl)
+			}
+			params["ttl"] = d
+		}
+		if len(args) > 2 {
+			params["reason"] = strings.Join(args[2:], " ")
+		}
+		return newSlackCommand(cmd, domain.CommandTypeLock, params), nil
+	case "schedule":
+		return parseSchedule(cmd, parts[1:])
+	case "workflow":
+		return parseWorkflow(cmd, parts[1:])
+	case "subscribe":
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid command format: expected subscribe <repository|*> <event>[,<event>...]")
+		}
+		var events []string
+		for _, arg := range parts[2:] {
+			for _, event := range strings.Split(arg, ",") {
+				if event != "" {
+					events = append(events, event)
+				}
+			}
+		}
+		return newSlackCommand(cmd, domain.CommandTypeSubscribe, map[string]interface{}{
+			"repository_name": parts[1],
+			"events":          events,
+		}), nil
+	case "unsubscribe":
+		return newSlackCommand(cmd, domain.CommandTypeUnsubscribe, map[string]interface{}{
+			"subscription_id": strings.TrimPrefix(parts[1], "#"),
+		}), nil
+	case "notifications":
+		return parseNotifications(cmd, parts[1:])
+	case "approve", "reject":
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid command format: expected %s <workflow-id> <step>", action)
+		}
+		commandType := domain.CommandTypeApproveStep
+		if action == "reject" {
+			commandType = domain.CommandTypeRejectStep
+		}
+		return newSlackCommand(cmd, commandType, map[string]interface{}{
+			"workflow_id": strings.TrimPrefix(parts[1], "#"),
+			"step_id":     parts[2],
+		}), nil
+	case "cancel", "rerun", "rerun-failed", "logs":
+		args, options := splitOptions(parts[1:])
+		if len(args) == 0 {
+			return nil, fmt.Errorf("invalid command format: expected a run ID or last <repository> [pipeline]")
+		}
+		params, err := parseRunReference(args)
+		if err != nil {
+			return nil, err
+		}
+		if lines, ok := options["lines"]; ok {
+			n, err := strconv.Atoi(lines)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid lines option: %s", lines)
+			}
+			params["lines"] = n
+		}
+		commandType := map[string]string{
+			"cancel":       domain.CommandTypeCancelRun,
+			"rerun":        domain.CommandTypeRerunRun,
+			"rerun-failed": domain.CommandTypeRerunFailed,
+			"logs":         domain.CommandTypeRunLogs,
+		}[action]
+		return newSlackCommand(cmd, commandType, params), nil
 	default:
 		return nil, fmt.Errorf("unknown action: %s", action)
 	}
 }
 
+// parseSchedule parses "add <cron> [tz=<zone>] <command>", "list" and "remove <id>".
+// The cron expression takes five fields, or one for descriptors such as @daily.
+func parseSchedule(cmd slack.SlashCommand, args []string) (*domain.Command, error) {
+	switch args[0] {
+	case "list":
+		return newSlackCommand(cmd, domain.CommandTypeListSchedules, map[string]interface{}{}), nil
+	case "remove":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("invalid command format: expected schedule remove <id>")
+		}
+		return newSlackCommand(cmd, domain.CommandTypeRemoveSchedule, map[string]interface{}{
+			"schedule_id": strings.TrimPrefix(args[1], "#"),
+		}), nil
+	case "add":
+		cronFields := 5
+		if len(args) > 1 && strings.HasPrefix(args[1], "@") {
+			cronFields = 1
+		}
+		if len(args) < 1+cronFields+2 {
+			return nil, fmt.Errorf("invalid command format: expected schedule add <cron> [tz=<zone>] <command>")
+		}
+
+		params := map[string]interface{}{
+			"cron": strings.Join(args[1:1+cronFields], " "),
+		}
+		rest := args[1+cronFields:]
+		if timezone, ok := strings.CutPrefix(rest[0], "tz="); ok {
+			params["timezone"] = timezone
+			rest = rest[1:]
+		}
+		if len(rest) < 2 {
+			return nil, fmt.Errorf("invalid command format: expected schedule add <cron> [tz=<zone>] <command>")
+		}
+
+		scheduled, err := parseAction(cmd, rest)
+		if err != nil {
+			return nil, err
+		}
+		params["command_type"] = scheduled.Type
+		params["command_parameters"] = scheduled.Parameters
+		params["command_text"] = strings.Join(rest, " ")
+		return newSlackCommand(cmd, domain.CommandTypeAddSchedule, params), nil
+	default:
+		return nil, fmt.Errorf("unknown schedule action: %s", args[0])
+	}
+}
+
+// parseWorkflow parses "list", "run <name> [input=value ...]" and "status <id>"
+func parseWorkflow(cmd slack.SlashCommand, args []string) (*domain.Command, error) {
+	switch args[0] {
+	case "list":
+		return newSlackCommand(cmd, domain.CommandTypeListWorkflows, map[string]interface{}{}), nil
+	case "status":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("invalid command format: expected workflow status <id>")
+		}
+		return newSlackCommand(cmd, domain.CommandTypeWorkflowStatus, map[string]interface{}{
+			"workflow_id": strings.TrimPrefix(args[1], "#"),
+		}), nil
+	case "run":
+		positional, options := splitOptions(args[1:])
+		if len(positional) != 1 {
+			return nil, fmt.Errorf("invalid command format: expected workflow run <name> [input=value ...]")
+		}
+		inputs := make(map[string]interface{}, len(options))
+		for name, value := range options {
+			inputs[name] = value
+		}
+		return newSlackCommand(cmd, domain.CommandTypeStartWorkflow, map[string]interface{}{
+			"workflow_name": positional[0],
+			"inputs":        inputs,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown workflow action: %s", args[0])
+	}
+}
+
+// parseNotifications parses "list", "mute [duration]" and "unmute"
+func parseNotifications(cmd slack.SlashCommand, args []string) (*domain.Command, error) {
+	switch args[0] {
+	case "list":
+		return newSlackCommand(cmd, domain.CommandTypeListSubscriptions, map[string]interface{}{}), nil
+	case "mute":
+		params := map[string]interface{}{}
+		if len(args) > 1 {
+			d, err := time.ParseDuration(args[1])
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid mute duration: %s", args[1])
+			}
+			params["duration"] = d
+		}
+		return newSlackCommand(cmd, domain.CommandTypeMuteChannel, params), nil
+	case "unmute":
+		return newSlackCommand(cmd, domain.CommandTypeUnmuteChannel, map[string]interface{}{}), nil
+	default:
+		return nil, fmt.Errorf("unknown notifications action: %s", args[0])
+	}
+}
+
+// parseRunReference parses "<run-id>" or the "last <repo> [pipeline]" shorthand
+func parseRunReference(args []string) (map[string]interface{}, error) {
+	if args[0] != "last" {
+		return map[string]interface{}{"run_id": args[0]}, nil
+	}
+	if len(args) < 2 {
+		return nil, fmt.Errorf("invalid command format: expected last <repository> [pipeline]")
+	}
+
+	params := map[string]interface{}{"repository_name": args[1]}
+	if len(args) > 2 {
+		params["pipeline_name"] = strings.Join(args[2:], " ")
+	}
+	return params, nil
+}
+
+// splitForce removes the --force flag, which asks to override deployment locks and freezes
+func splitForce(parts []string) ([]string, bool) {
+	var remaining []string
+	force := false
+	for _, part := range parts {
+		if part == "--force" {
+			force = true
+			continue
+		}
+		remaining = append(remaining, part)
+	}
+	return remaining, force
+}
+
+// splitOptions separates key=value options from positional arguments
+func splitOptions(args []string) ([]string, map[string]string) {
+	var positional []string
+	options := make(map[string]string)
+	for _, arg := range args {
+		if key, value, ok := strings.Cut(arg, "="); ok {
+			options[key] = value
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	return positional, options
+}
+
+func newSlackCommand(cmd slack.SlashCommand, commandType string, params map[string]interface{}) *domain.Command {
+	return &domain.Command{
+		Type:       commandType,
+		Parameters: params,
+		User: domain.User{
+			ID:       cmd.UserID,
+			Platform: "slack",
+		},
+		Source: domain.CommandSource{
+			Platform:  "slack",
+			ChannelID: cmd.ChannelID,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
 func (a *SlackAdapter) buildSlackResponse(result *domain.CommandResult) map[string]interface{} {
 	response := map[string]interface{}{
 		"status":  result.Status,
@@ -164,6 +510,54 @@ func (a *SlackAdapter) buildSlackResponse(result *domain.CommandResult) map[stri
 	return response
 }
 
+// postLogSummary posts a log excerpt to the channel, or into an existing thread, and
+// uploads the full log into the same thread
+func (a *SlackAdapter) postLogSummary(ctx context.Context, channelID, threadTS, message string, summary *domain.LogSummary) error {
+	text := fmt.Sprintf("%s\n```\n%s\n```", message, summary.Excerpt)
+	if summary.URL != "" {
+		text = fmt.Sprintf("%s\n<%s|View run>", text, summary.URL)
+	}
+
+	options := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if threadTS != "" {
+		options = append(options, slack.MsgOptionTS(threadTS))
+	}
+	_, postedTS, err := a.client.PostMessageContext(ctx, channelID, options...)
+	if err != nil {
+		return fmt.Errorf("failed to post log excerpt: %w", err)
+	}
+	if threadTS == "" {
+		threadTS = postedTS
+	}
+
+	if summary.FullLog == "" {
+		return nil
+	}
+
+	filename := strings.NewReplacer("/", "-", " ", "-").Replace(fmt.Sprintf("run-%s-%s.log", summary.RunID, summary.Job))
+	_, err = a.client.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Channel:         channelID,
+		ThreadTimestamp: threadTS,
+		Filename:        filename,
+		Title:           fmt.Sprintf("Full log of %s", summary.Job),
+		Content:         summary.FullLog,
+		FileSize:        len(summary.FullLog),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload full log: %w", err)
+	}
+
+	return nil
+}
+
+// Notify posts a message to a channel
+func (a *SlackAdapter) Notify(ctx context.Context, channelID, message string) error {
+	if _, _, err := a.client.PostMessageContext(ctx, channelID, slack.MsgOptionText(message, false)); err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	return nil
+}
+
 // verifySlackSignature verifies the request signature from Slack
 func (a *SlackAdapter) verifySlackSignature(r *http.Request, body []byte) error {
 	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
@@ -212,14 +606,7 @@ func (a *SlackAdapter) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse payload
-	var payload struct {
-		Type      string                 `json:"type"`
-		Event     map[string]interface{} `json:"event"`
-		TeamID    string                 `json:"team_id"`
-		APIAppID  string                 `json:"api_app_id"`
-		Challenge string                 `json:"challenge"`
-	}
-
+	var payload webhookPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
 		a.sendErrorResponse(w, "Failed to parse webhook payload", http.StatusBadRequest)
 		return
@@ -234,76 +621,39 @@ func (a *SlackAdapter) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Process webhook event
-	result, err := a.processWebhookEvent(&payload)
+	// Mentions, direct messages and Home tab openings are acknowledged at once and
+	// processed in the background
+	if payload.Type == "event_callback" && (a.handleMessageEvent(r, &payload) || a.handleHomeOpened(&payload)) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	// Other events are acknowledged too, so Slack does not redeliver what is never handled
+	if eventType := callbackEventType(&payload); payload.Type == "event_callback" && eventType != "workflow_step_execute" {
+		a.logger.Debug("ignored unhandled event",
+			zap.String("event_id", payload.EventID),
+			zap.String("event_type", eventType))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Workflow Builder steps are validated now, acknowledged and run in the background
+	cmd, executeID, err := a.workflowStepCommand(&payload)
 	if err != nil {
+		// Errors of a known kind were reported already and are not worth a redelivery
+		if domain.AsError(err) != nil {
+			a.sendErrorResponse(w, errorText(err), http.StatusOK)
+			return
+		}
 		a.sendErrorResponse(w, fmt.Sprintf("Failed to process webhook: %v", err), http.StatusInternalServerError)
 		return
 	}
+	a.startWorkflowStep(r, &payload, cmd, executeID)
 
-	// Send success response
 	response := map[string]interface{}{
 		"status":  "success",
-		"message": "Webhook processed successfully",
-	}
-	if result != nil {
-		response["details"] = result
+		"message": "Workflow step accepted",
 	}
-
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		a.logger.Error("failed to encode webhook response", zap.Error(err))
 	}
 }
-
-func (a *SlackAdapter) processWebhookEvent(payload *struct {
-	Type      string                 `json:"type"`
-	Event     map[string]interface{} `json:"event"`
-	TeamID    string                 `json:"team_id"`
-	APIAppID  string                 `json:"api_app_id"`
-	Challenge string                 `json:"challenge"`
-}) (*domain.CommandResult, error) {
-	if payload.Type != "workflow_step_execute" {
-		return nil, fmt.Errorf("unsupported event type: %s", payload.Type)
-	}
-
-	workflowStep, ok := payload.Event["workflow_step"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid workflow step format")
-	}
-
-	inputs, ok := workflowStep["inputs"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid workflow step inputs")
-	}
-
-	repository, ok := inputs["repository"].(string)
-	if !ok {
-		return nil, fmt.Errorf("repository input is required")
-	}
-
-	action, ok := inputs["action"].(string)
-	if !ok {
-		return nil, fmt.Errorf("action input is required")
-	}
-
-	// Create and process command
-	cmd := &domain.Command{
-		Type: domain.CommandTypeVerifyRepo,
-		Parameters: map[string]interface{}{
-			"repository_name": repository,
-			"action":          action,
-		},
-		User: domain.User{
-			ID:       "workflow",
-			Platform: "slack",
-		},
-		Source: domain.CommandSource{
-			Platform:   "slack",
-			WorkflowID: workflowStep["workflow_id"].(string),
-			StepID:     workflowStep["step_id"].(string),
-		},
-		Timestamp: time.Now(),
-	}
-
-	return a.processor.ProcessCommand(context.Background(), cmd)
-}
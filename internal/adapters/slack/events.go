@@ -0,0 +1,182 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventRetention is how long event IDs are remembered to drop retried deliveries.
+	// Slack retries up to three times within about an hour.
+	eventRetention = time.Hour
+	// eventTimeout bounds the background processing of a single event
+	eventTimeout = 2 * time.Minute
+)
+
+// mentionPattern matches user mentions such as <@U0BOT> or <@U0BOT|chatops>
+var mentionPattern = regexp.MustCompile(`<@[A-Z0-9]+(\|[^>]*)?>`)
+
+// messageEvent is an app_mention or message event
+type messageEvent struct {
+	Type        string `json:"type"`
+	ChannelType string `json:"channel_type"`
+	Subtype     string `json:"subtype"`
+	BotID       string `json:"bot_id"`
+	User        string `json:"user"`
+	Channel     string `json:"channel"`
+	Text        string `json:"text"`
+	TS          string `json:"ts"`
+	ThreadTS    string `json:"thread_ts"`
+}
+
+// eventCache remembers recently seen event IDs. It is kept per replica, so a retry
+// reaching another replica is processed again.
+type eventCache struct {
+	mu        sync.Mutex
+	retention time.Duration
+	seen      map[string]time.Time
+}
+
+func newEventCache(retention time.Duration) *eventCache {
+	return &eventCache{retention: retention, seen: make(map[string]time.Time)}
+}
+
+// firstSeen records the event ID and reports whether it had not been seen before
+func (c *eventCache) firstSeen(id string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for seenID, at := range c.seen {
+		if now.Sub(at) > c.retention {
+			delete(c.seen, seenID)
+		}
+	}
+	if _, ok := c.seen[id]; ok {
+		return false
+	}
+	c.seen[id] = now
+	return true
+}
+
+// callbackEventType returns the type of the event of an event_callback, empty when the
+// event cannot be parsed
+func callbackEventType(payload *webhookPayload) string {
+	var event struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(payload.Event, &event); err != nil {
+		return ""
+	}
+	return event.Type
+}
+
+// handleMessageEvent starts processing app_mention and direct message events in the
+// background and reports whether the event was one of them. Retried deliveries of an
+// event already seen are dropped.
+func (a *SlackAdapter) handleMessageEvent(r *http.Request, payload *webhookPayload) bool {
+	var event messageEvent
+	if err := json.Unmarshal(payload.Event, &event); err != nil {
+		return false
+	}
+
+	switch {
+	case event.Type == "app_mention":
+	case event.Type == "message" && event.ChannelType == "im":
+		// Edits, joins and the bot's own replies arrive as message events too
+		if event.Subtype != "" || event.BotID != "" {
+			return true
+		}
+	default:
+		return false
+	}
+
+	logger := a.logger.With(
+		zap.String("event_id", payload.EventID),
+		zap.String("event_type", event.Type),
+		zap.String("channel_id", event.Channel))
+
+	if payload.EventID != "" && !a.events.firstSeen(payload.EventID, time.Now()) {
+		logger.Info("dropped duplicate event", zap.String("retry_num", r.Header.Get("X-Slack-Retry-Num")),
+			zap.String("retry_reason", r.Header.Get("X-Slack-Retry-Reason")))
+		return true
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+		defer cancel()
+		a.processMessageEvent(ctx, logger, &event)
+	}()
+	return true
+}
+
+// processMessageEvent parses the message with the slash command grammar, processes the
+// command and replies in the thread of the message
+func (a *SlackAdapter) processMessageEvent(ctx context.Context, logger *zap.Logger, event *messageEvent) {
+	threadTS := event.ThreadTS
+	if threadTS == "" {
+		threadTS = event.TS
+	}
+
+	text := strings.TrimSpace(mentionPattern.ReplaceAllString(event.Text, ""))
+	text = strings.TrimSpace(strings.TrimPrefix(text, "/chatops"))
+
+	cmd, err := a.parseCommand(slack.SlashCommand{
+		Command:   "/chatops",
+		Text:      text,
+		UserID:    event.User,
+		ChannelID: event.Channel,
+	})
+	if err != nil {
+		a.reply(ctx, logger, event.Channel, threadTS, fmt.Sprintf("Sorry, I couldn't understand that: %v", err))
+		return
+	}
+
+	result, err := a.processor.ProcessCommand(ctx, cmd)
+	if err != nil {
+		logger.Error("failed to process event command", zap.String("command", cmd.Type), zap.Error(err))
+		a.reply(ctx, logger, event.Channel, threadTS, errorText(err))
+		return
+	}
+
+	if summary, ok := result.Details.(*domain.LogSummary); ok {
+		if err := a.postLogSummary(ctx, event.Channel, threadTS, result.Message, summary); err != nil {
+			logger.Error("failed to post log summary", zap.String("run_id", summary.RunID), zap.Error(err))
+			a.reply(ctx, logger, event.Channel, threadTS, fmt.Sprintf("%s\n```\n%s\n```", result.Message, summary.Excerpt))
+		}
+		return
+	}
+
+	a.reply(ctx, logger, event.Channel, threadTS, resultText(result))
+}
+
+func (a *SlackAdapter) reply(ctx context.Context, logger *zap.Logger, channelID, threadTS, text string) {
+	_, _, err := a.client.PostMessageContext(ctx, channelID, slack.MsgOptionText(text, false), slack.MsgOptionTS(threadTS))
+	if err != nil {
+		logger.Error("failed to reply to event", zap.Error(err))
+	}
+}
+
+// resultText renders a command result as a chat message, listing the pipelines to
+// choose from when the command needs a pipeline
+func resultText(result *domain.CommandResult) string {
+	pipelines, ok := result.Details.([]domain.Pipeline)
+	if result.Status != "select_pipeline" || !ok {
+		return result.Message
+	}
+
+	lines := []string{result.Message + ":"}
+	for _, pipeline := range pipelines {
+		lines = append(lines, fmt.Sprintf("• %s", pipeline.Name))
+	}
+	return strings.Join(lines, "\n")
+}
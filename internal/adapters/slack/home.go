@@ -0,0 +1,158 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// maxSectionLength keeps section texts under the 3000 characters Slack accepts
+const maxSectionLength = 2900
+
+// homeOpenedEvent is an app_home_opened event
+type homeOpenedEvent struct {
+	Type string `json:"type"`
+	User string `json:"user"`
+	Tab  string `json:"tab"`
+}
+
+// handleHomeOpened publishes the Home tab of the user in the background and reports
+// whether the event was an app_home_opened event
+func (a *SlackAdapter) handleHomeOpened(payload *webhookPayload) bool {
+	var event homeOpenedEvent
+	if err := json.Unmarshal(payload.Event, &event); err != nil || event.Type != "app_home_opened" {
+		return false
+	}
+	// The Messages tab of the app opens the same event
+	if event.Tab != "home" || event.User == "" {
+		return true
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+		defer cancel()
+		if err := a.publishHome(ctx, event.User); err != nil {
+			a.logger.Error("failed to publish home tab", zap.String("user_id", event.User), zap.Error(err))
+		}
+	}()
+	return true
+}
+
+// publishHome renders the dashboard of the user into their Home tab
+func (a *SlackAdapter) publishHome(ctx context.Context, userID string) error {
+	dashboard, err := a.processor.Dashboard(ctx, domain.User{ID: userID, Platform: "slack"})
+	if err != nil {
+		return err
+	}
+	if _, err := a.client.PublishViewContext(ctx, userID, homeView(dashboard), ""); err != nil {
+		return fmt.Errorf("failed to publish view: %w", err)
+	}
+	return nil
+}
+
+// homeView lays out the dashboard as one section per part
+func homeView(dashboard *domain.Dashboard) slack.HomeTabViewRequest {
+	var runs []string
+	for _, run := range dashboard.RecentRuns {
+		target := run.Pipeline
+		if run.Environment != "" {
+			target = fmt.Sprintf("deploy to %s", run.Environment)
+		}
+		line := fmt.Sprintf("• *%s* %s: `%s` %s", run.Repository, target, run.Status, slackDate(run.CreatedAt))
+		if run.URL != "" {
+			line = fmt.Sprintf("%s <%s|run %s>", line, run.URL, run.ID)
+		}
+		runs = append(runs, line)
+	}
+
+	var commands []string
+	for _, command := range dashboard.RecentCommands {
+		line := fmt.Sprintf("• `%s`", command.Type)
+		if command.Repository != "" {
+			line = fmt.Sprintf("%s *%s*", line, command.Repository)
+		}
+		commands = append(commands, fmt.Sprintf("%s: `%s` %s", line, command.Status, slackDate(command.RanAt)))
+	}
+
+	var approvals []string
+	for _, approval := range dashboard.PendingApprovals {
+		approvals = append(approvals, fmt.Sprintf("• %s (#%s) step *%s*, waiting since %s\n`/chatops approve %s %s` or `/chatops reject %s %s`",
+			approval.WorkflowName, approval.WorkflowID, approval.StepID, slackDate(approval.RequestedAt),
+			approval.WorkflowID, approval.StepID, approval.WorkflowID, approval.StepID))
+	}
+
+	var repositories []string
+	for _, repo := range dashboard.Repositories {
+		var actions []string
+		if dashboard.CanTrigger && len(repo.Pipelines) > 0 {
+			names := make([]string, 0, len(repo.Pipelines))
+			for _, pipeline := range repo.Pipelines {
+				names = append(names, pipeline.Name)
+			}
+			actions = append(actions, "pipelines "+strings.Join(names, ", "))
+		}
+		if dashboard.CanDeploy && len(repo.Environments) > 0 {
+			names := make([]string, 0, len(repo.Environments))
+			for _, env := range repo.Environments {
+				names = append(names, env.Name)
+			}
+			actions = append(actions, "deploys to "+strings.Join(names, ", "))
+		}
+		line := fmt.Sprintf("• *%s*", repo.Name)
+		if len(actions) > 0 {
+			line = fmt.Sprintf("%s: %s", line, strings.Join(actions, "; "))
+		}
+		repositories = append(repositories, line)
+	}
+
+	var locks []string
+	for _, lock := range dashboard.Locks {
+		line := fmt.Sprintf("• *%s/%s* locked by <@%s> until %s", lock.Repository, lock.Environment, lock.Owner, slackDate(lock.ExpiresAt))
+		if lock.Reason != "" {
+			line = fmt.Sprintf("%s: %s", line, lock.Reason)
+		}
+		locks = append(locks, line)
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "ChatOps", false, false)),
+		homeSection("Your recent runs", runs, "You have not triggered any runs yet."),
+		homeSection("Your recent commands", commands, "You have not run any commands yet."),
+		homeSection("Waiting for your approval", approvals, "Nothing is waiting for your approval."),
+		homeSection("Repositories you can act on", repositories, "You cannot run pipelines or deploy any repository."),
+		homeSection("Deployment locks", locks, "No environment is locked."),
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("Updated %s. Use `/chatops run` to start a pipeline or workflow.", slackDate(time.Now())), false, false)),
+	}
+	return slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+}
+
+// homeSection renders a titled list, trimmed to the length Slack accepts
+func homeSection(title string, lines []string, empty string) *slack.SectionBlock {
+	text := fmt.Sprintf("*%s*\n", title)
+	if len(lines) == 0 {
+		text += "_" + empty + "_"
+	}
+	for i, line := range lines {
+		if len(text)+len(line) > maxSectionLength {
+			text += fmt.Sprintf("_and %d more_", len(lines)-i)
+			break
+		}
+		text += line + "\n"
+	}
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, strings.TrimSpace(text), false, false), nil, nil)
+}
+
+// slackDate formats a time in the time zone of the reader
+func slackDate(at time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", at.Unix(), at.UTC().Format("2006-01-02 15:04 UTC"))
+}
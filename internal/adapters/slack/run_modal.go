@@ -0,0 +1,377 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Block IDs of the run modal. Workflow inputs use the input name after runBlockInput.
+const (
+	runModalCallbackID = "chatops_run"
+	runBlockRepository = "repository"
+	runBlockPipeline   = "pipeline"
+	runBlockRef        = "ref"
+	runBlockInput      = "input:"
+
+	// runActionID is the action ID of the element within each input block
+	runActionID = "value"
+
+	// maxSuggestions is the number of options Slack accepts for an external select
+	maxSuggestions = 100
+)
+
+// runModalMetadata is kept in the private metadata of the run modal
+type runModalMetadata struct {
+	ChannelID string `json:"channel_id"`
+	Workflow  string `json:"workflow,omitempty"`
+}
+
+// openRunModal opens the modal of "/chatops run", which runs a pipeline, or of
+// "/chatops run <workflow>", which starts a workflow with one field per declared input
+func (a *SlackAdapter) openRunModal(ctx context.Context, cmd slack.SlashCommand, args []string) error {
+	metadata := runModalMetadata{ChannelID: cmd.ChannelID}
+	title := "Run pipeline"
+	var blocks []slack.Block
+
+	if len(args) == 0 {
+		blocks = runPipelineBlocks(nil)
+	} else {
+		definition, err := a.findWorkflowDefinition(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		metadata.Workflow = definition.Name
+		title = "Run workflow"
+		blocks = workflowInputBlocks(definition)
+	}
+
+	modal, err := runModal(title, blocks, metadata)
+	if err != nil {
+		return err
+	}
+	if _, err := a.client.OpenViewContext(ctx, cmd.TriggerID, modal); err != nil {
+		return fmt.Errorf("failed to open view: %w", err)
+	}
+	return nil
+}
+
+// updateRunModal renders one field per input of the chosen pipeline once the repository
+// or pipeline of the run modal changes
+func (a *SlackAdapter) updateRunModal(ctx context.Context, callback *slack.InteractionCallback) error {
+	var metadata runModalMetadata
+	if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &metadata); err != nil {
+		return fmt.Errorf("invalid run modal metadata: %w", err)
+	}
+
+	var inputs []domain.PipelineInput
+	values := runModalValues(&callback.View)
+	if repository := values[runBlockRepository]; repository != "" {
+		var err error
+		inputs, err = a.processor.GetPipelineInputs(ctx, repository, values[runBlockPipeline])
+		if err != nil {
+			// The pipeline still runs with its default inputs
+			a.logger.Warn("failed to get pipeline inputs", zap.String("repository", repository), zap.Error(err))
+		}
+	}
+
+	modal, err := runModal("Run pipeline", runPipelineBlocks(inputs), metadata)
+	if err != nil {
+		return err
+	}
+	if _, err := a.client.UpdateViewContext(ctx, modal, "", callback.View.Hash, callback.View.ID); err != nil {
+		return fmt.Errorf("failed to update view: %w", err)
+	}
+	return nil
+}
+
+// runModal builds the run modal around its blocks
+func runModal(title string, blocks []slack.Block, metadata runModalMetadata) (slack.ModalViewRequest, error) {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return slack.ModalViewRequest{}, fmt.Errorf("failed to encode modal metadata: %w", err)
+	}
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, title, false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Run", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+		CallbackID:      runModalCallbackID,
+		PrivateMetadata: string(encoded),
+	}, nil
+}
+
+// runPipelineBlocks renders the repository, pipeline and ref selects followed by one
+// field per pipeline input. Choosing a repository or pipeline dispatches a block action,
+// which loads the inputs of the chosen pipeline.
+func runPipelineBlocks(inputs []domain.PipelineInput) []slack.Block {
+	minQueryLength := 0
+	externalSelect := func(placeholder string) *slack.SelectBlockElement {
+		element := slack.NewOptionsSelectBlockElement(slack.OptTypeExternal,
+			slack.NewTextBlockObject(slack.PlainTextType, placeholder, false, false), runActionID)
+		element.MinQueryLength = &minQueryLength
+		return element
+	}
+
+	repositoryBlock := slack.NewInputBlock(runBlockRepository,
+		slack.NewTextBlockObject(slack.PlainTextType, "Repository", false, false), nil,
+		externalSelect("Choose a repository"))
+	repositoryBlock.DispatchAction = true
+	pipelineBlock := slack.NewInputBlock(runBlockPipeline,
+		slack.NewTextBlockObject(slack.PlainTextType, "Pipeline", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Pipelines of the chosen repository; the default when empty", false, false),
+		externalSelect("Default pipeline"))
+	pipelineBlock.Optional = true
+	pipelineBlock.DispatchAction = true
+	refBlock := slack.NewInputBlock(runBlockRef,
+		slack.NewTextBlockObject(slack.PlainTextType, "Ref", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Branch, tag or commit; the default branch when empty", false, false),
+		externalSelect("Default branch"))
+	refBlock.Optional = true
+
+	blocks := []slack.Block{repositoryBlock, pipelineBlock, refBlock}
+	for _, input := range inputs {
+		blocks = append(blocks, pipelineInputBlock(input))
+	}
+	return blocks
+}
+
+// pipelineInputBlock renders a pipeline input as a select of its options, or as a text
+// field, prefilled with its default
+func pipelineInputBlock(input domain.PipelineInput) *slack.InputBlock {
+	var element slack.BlockElement
+	if len(input.Options) > 0 {
+		options := make([]*slack.OptionBlockObject, 0, len(input.Options))
+		var initial *slack.OptionBlockObject
+		for _, value := range input.Options {
+			option := slack.NewOptionBlockObject(value, slack.NewTextBlockObject(slack.PlainTextType, value, false, false), nil)
+			if value == input.Default {
+				initial = option
+			}
+			options = append(options, option)
+		}
+		selectElement := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, nil, runActionID, options...)
+		selectElement.InitialOption = initial
+		element = selectElement
+	} else {
+		textElement := slack.NewPlainTextInputBlockElement(nil, runActionID)
+		textElement.InitialValue = input.Default
+		element = textElement
+	}
+
+	var hint *slack.TextBlockObject
+	if input.Description != "" {
+		hint = slack.NewTextBlockObject(slack.PlainTextType, input.Description, false, false)
+	}
+	block := slack.NewInputBlock(runBlockInput+input.Name, slack.NewTextBlockObject(slack.PlainTextType, input.Name, false, false), hint, element)
+	block.Optional = !input.Required || input.Default != ""
+	return block
+}
+
+// findWorkflowDefinition looks up a workflow that can be started
+func (a *SlackAdapter) findWorkflowDefinition(ctx context.Context, name string) (*domain.WorkflowDefinition, error) {
+	definitions := a.processor.ListWorkflowDefinitions(ctx)
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("no workflows are configured")
+	}
+	for i := range definitions {
+		if definitions[i].Name == name {
+			return &definitions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown workflow: %s", name)
+}
+
+// workflowInputBlocks renders one text field per declared input, prefilled with its
+// default. Inputs without a default are required.
+func workflowInputBlocks(definition *domain.WorkflowDefinition) []slack.Block {
+	var blocks []slack.Block
+	if definition.Description != "" {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*%s*\n%s", definition.Name, definition.Description), false, false), nil, nil))
+	}
+
+	names := make([]string, 0, len(definition.Inputs))
+	for name := range definition.Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		element := slack.NewPlainTextInputBlockElement(nil, runActionID)
+		element.InitialValue = definition.Inputs[name]
+		block := slack.NewInputBlock(runBlockInput+name, slack.NewTextBlockObject(slack.PlainTextType, name, false, false), nil, element)
+		block.Optional = definition.Inputs[name] != ""
+		blocks = append(blocks, block)
+	}
+
+	if len(names) == 0 {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("Workflow *%s* takes no inputs.", definition.Name), false, false), nil, nil))
+	}
+	return blocks
+}
+
+// suggestRunOptions answers the external selects of the run modal: repositories, or
+// the pipelines and refs of the repository chosen so far
+func (a *SlackAdapter) suggestRunOptions(ctx context.Context, callback *slack.InteractionCallback) (*slack.OptionsResponse, error) {
+	query := strings.ToLower(strings.TrimSpace(callback.Value))
+	var names []string
+	switch callback.BlockID {
+	case runBlockRepository:
+		repositories, err := a.processor.ListRepositories(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		for _, repo := range repositories {
+			names = append(names, repo.Name)
+		}
+	case runBlockPipeline:
+		repository := runModalValues(&callback.View)[runBlockRepository]
+		if repository == "" {
+			return &slack.OptionsResponse{Options: []*slack.OptionBlockObject{}}, nil
+		}
+		pipelines, err := a.processor.ListPipelines(ctx, repository)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pipelines of %s: %w", repository, err)
+		}
+		for _, pipeline := range pipelines {
+			names = append(names, pipeline.Name)
+		}
+	case runBlockRef:
+		repository := runModalValues(&callback.View)[runBlockRepository]
+		if repository == "" {
+			return &slack.OptionsResponse{Options: []*slack.OptionBlockObject{}}, nil
+		}
+		refs, err := a.processor.ListRefs(ctx, repository, query, maxSuggestions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list refs of %s: %w", repository, err)
+		}
+		// The typed text is offered as is, so commits and unlisted refs can be run too
+		if typed := strings.TrimSpace(callback.Value); typed != "" && !slices.Contains(refs, typed) {
+			names = append(names, typed)
+		}
+		names = append(names, refs...)
+	default:
+		return nil, fmt.Errorf("unknown block: %s", callback.BlockID)
+	}
+
+	options := []*slack.OptionBlockObject{}
+	for _, name := range names {
+		if len(options) == maxSuggestions {
+			break
+		}
+		if query != "" && callback.BlockID != runBlockRef && !strings.Contains(strings.ToLower(name), query) {
+			continue
+		}
+		options = append(options, slack.NewOptionBlockObject(name, slack.NewTextBlockObject(slack.PlainTextType, name, false, false), nil))
+	}
+	return &slack.OptionsResponse{Options: options}, nil
+}
+
+// submitRunModal converts a submitted run modal into a command and processes it in the
+// background, since runs may take longer than Slack waits for the submission response.
+// It returns validation errors keyed by block ID.
+func (a *SlackAdapter) submitRunModal(callback *slack.InteractionCallback) map[string]string {
+	var metadata runModalMetadata
+	if err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &metadata); err != nil {
+		a.logger.Error("invalid run modal metadata", zap.Error(err))
+	}
+
+	cmd, errors := runModalCommand(&callback.View, metadata)
+	if len(errors) > 0 {
+		return errors
+	}
+	cmd.User = domain.User{ID: callback.User.ID, Platform: "slack"}
+	cmd.Source = domain.CommandSource{Platform: "slack", ChannelID: metadata.ChannelID}
+
+	logger := a.logger.With(zap.String("command", cmd.Type), zap.String("user_id", cmd.User.ID))
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+		defer cancel()
+
+		var text string
+		result, err := a.processor.ProcessCommand(ctx, cmd)
+		if err != nil {
+			logger.Error("failed to process modal command", zap.Error(err))
+			text = errorText(err)
+		} else {
+			text = resultText(result)
+		}
+
+		// Without a channel, such as from a shortcut, the result goes to a direct message
+		if metadata.ChannelID == "" {
+			_, _, err = a.client.PostMessageContext(ctx, cmd.User.ID, slack.MsgOptionText(text, false))
+		} else {
+			_, err = a.client.PostEphemeralContext(ctx, metadata.ChannelID, cmd.User.ID, slack.MsgOptionText(text, false))
+		}
+		if err != nil {
+			logger.Error("failed to post modal command result", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// runModalCommand builds the verify or start_workflow command of a submitted run modal
+func runModalCommand(view *slack.View, metadata runModalMetadata) (*domain.Command, map[string]string) {
+	values := runModalValues(view)
+	cmd := &domain.Command{
+		Parameters: map[string]interface{}{},
+		Timestamp:  time.Now(),
+	}
+
+	inputs := map[string]interface{}{}
+	for blockID, value := range values {
+		if name, ok := strings.CutPrefix(blockID, runBlockInput); ok && value != "" {
+			inputs[name] = value
+		}
+	}
+
+	if metadata.Workflow != "" {
+		cmd.Type = domain.CommandTypeStartWorkflow
+		cmd.Parameters["workflow_name"] = metadata.Workflow
+		cmd.Parameters["inputs"] = inputs
+		return cmd, nil
+	}
+
+	if values[runBlockRepository] == "" {
+		return nil, map[string]string{runBlockRepository: "Choose a repository"}
+	}
+	cmd.Type = domain.CommandTypeVerifyRepo
+	cmd.Parameters["repository_name"] = values[runBlockRepository]
+	if values[runBlockPipeline] != "" {
+		cmd.Parameters["pipeline_name"] = values[runBlockPipeline]
+	}
+	if values[runBlockRef] != "" {
+		cmd.Parameters["ref"] = values[runBlockRef]
+	}
+	if len(inputs) > 0 {
+		cmd.Parameters["inputs"] = inputs
+	}
+	return cmd, nil
+}
+
+// runModalValues reads the text or selected option of every input block of the modal
+func runModalValues(view *slack.View) map[string]string {
+	values := map[string]string{}
+	if view.State == nil {
+		return values
+	}
+	for blockID, actions := range view.State.Values {
+		action := actions[runActionID]
+		values[blockID] = strings.TrimSpace(action.Value)
+		if action.SelectedOption.Value != "" {
+			values[blockID] = action.SelectedOption.Value
+		}
+	}
+	return values
+}
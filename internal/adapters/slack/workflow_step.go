@@ -0,0 +1,426 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// Inputs of the ChatOps Workflow Builder step, which double as block IDs of its
+// configuration modal
+const (
+	stepInputAction       = "action"
+	stepInputRepository   = "repository"
+	stepInputTarget       = "target"
+	stepInputRef          = "ref"
+	stepInputConfiguredBy = "configured_by"
+
+	// stepInputActionID is the action ID of the element within each input block
+	stepInputActionID = "value"
+
+	// workflowStepUser runs steps configured before their configuring user was recorded
+	workflowStepUser = "workflow"
+)
+
+// stepActions are the commands a Workflow Builder step can run
+var stepActions = []struct{ value, label string }{
+	{"verify", "Run pipeline"},
+	{"deploy", "Deploy"},
+	{"workflow", "Start ChatOps workflow"},
+}
+
+// stepOutputs are the step results later Workflow Builder steps can use
+var stepOutputs = []slack.WorkflowStepOutput{
+	{Name: "status", Type: "text", Label: "ChatOps status"},
+	{Name: "message", Type: "text", Label: "ChatOps message"},
+	{Name: "run_id", Type: "text", Label: "Run ID"},
+	{Name: "run_url", Type: "text", Label: "Run URL"},
+	{Name: "workflow_id", Type: "text", Label: "ChatOps workflow ID"},
+}
+
+// webhookPayload is an Events API request. Workflow step executions arrive as
+// event_callback events, or with the event type at the top level from older callers.
+type webhookPayload struct {
+	Type      string          `json:"type"`
+	EventID   string          `json:"event_id"`
+	Event     json.RawMessage `json:"event"`
+	TeamID    string          `json:"team_id"`
+	APIAppID  string          `json:"api_app_id"`
+	Challenge string          `json:"challenge"`
+}
+
+type workflowStepExecuteEvent struct {
+	Type         string `json:"type"`
+	WorkflowStep struct {
+		WorkflowStepExecuteID string                     `json:"workflow_step_execute_id"`
+		WorkflowID            string                     `json:"workflow_id"`
+		StepID                string                     `json:"step_id"`
+		Inputs                map[string]json.RawMessage `json:"inputs"`
+	} `json:"workflow_step"`
+}
+
+// HandleInteraction processes interactivity payloads: the configuration modal of a
+// Workflow Builder step and the run modal of "/chatops run"
+func (a *SlackAdapter) HandleInteraction(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		a.sendErrorResponse(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body)) // Replace the body for further reading
+
+	if err := a.verifySlackSignature(r, body); err != nil {
+		a.sendErrorResponse(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		a.sendErrorResponse(w, "Invalid interaction format", http.StatusBadRequest)
+		return
+	}
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(r.PostFormValue("payload")), &callback); err != nil {
+		a.sendErrorResponse(w, "Failed to parse interaction payload", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case callback.Type == slack.InteractionTypeWorkflowStepEdit:
+		if err := a.openStepConfiguration(r.Context(), &callback); err != nil {
+			a.logger.Error("failed to open workflow step configuration", zap.Error(err))
+			a.sendErrorResponse(w, "Failed to open step configuration", http.StatusInternalServerError)
+			return
+		}
+	case callback.Type == slack.InteractionTypeViewSubmission && callback.View.Type == slack.VTWorkflowStep:
+		if errors := a.saveStepConfiguration(r.Context(), &callback); len(errors) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(slack.NewErrorsViewSubmissionResponse(errors)); err != nil {
+				a.logger.Error("failed to encode view submission errors", zap.Error(err))
+			}
+			return
+		}
+	case callback.Type == slack.InteractionTypeBlockSuggestion:
+		options, err := a.suggestRunOptions(r.Context(), &callback)
+		if err != nil {
+			a.logger.Error("failed to suggest options", zap.String("block_id", callback.BlockID), zap.Error(err))
+			options = &slack.OptionsResponse{Options: []*slack.OptionBlockObject{}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(options); err != nil {
+			a.logger.Error("failed to encode options", zap.Error(err))
+		}
+		return
+	case callback.Type == slack.InteractionTypeBlockActions && callback.View.CallbackID == runModalCallbackID:
+		if err := a.updateRunModal(r.Context(), &callback); err != nil {
+			a.logger.Error("failed to update run modal", zap.Error(err))
+			a.sendErrorResponse(w, "Failed to update run modal", http.StatusInternalServerError)
+			return
+		}
+	case callback.Type == slack.InteractionTypeViewSubmission && callback.View.CallbackID == runModalCallbackID:
+		if errors := a.submitRunModal(&callback); len(errors) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(slack.NewErrorsViewSubmissionResponse(errors)); err != nil {
+				a.logger.Error("failed to encode view submission errors", zap.Error(err))
+			}
+			return
+		}
+	default:
+		a.sendErrorResponse(w, fmt.Sprintf("unsupported interaction type: %s", callback.Type), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// openStepConfiguration opens the configuration modal, prefilled with the saved inputs
+func (a *SlackAdapter) openStepConfiguration(ctx context.Context, callback *slack.InteractionCallback) error {
+	inputs := slack.WorkflowStepInputs{}
+	if callback.WorkflowStep.Inputs != nil {
+		inputs = *callback.WorkflowStep.Inputs
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(stepActions))
+	var initial *slack.OptionBlockObject
+	for _, action := range stepActions {
+		option := slack.NewOptionBlockObject(action.value, slack.NewTextBlockObject(slack.PlainTextType, action.label, false, false), nil)
+		if inputs[stepInputAction].Value == action.value {
+			initial = option
+		}
+		options = append(options, option)
+	}
+	actionSelect := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, nil, stepInputActionID, options...)
+	actionSelect.InitialOption = initial
+
+	textInput := func(name, label, hint string, optional bool) *slack.InputBlock {
+		element := slack.NewPlainTextInputBlockElement(nil, stepInputActionID)
+		element.InitialValue = inputs[name].Value
+		block := slack.NewInputBlock(name,
+			slack.NewTextBlockObject(slack.PlainTextType, label, false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, hint, false, false),
+			element)
+		block.Optional = optional
+		return block
+	}
+
+	blocks := slack.Blocks{BlockSet: []slack.Block{
+		slack.NewInputBlock(stepInputAction, slack.NewTextBlockObject(slack.PlainTextType, "Action", false, false), nil, actionSelect),
+		textInput(stepInputRepository, "Repository", "Repository name, not needed to start a workflow", true),
+		textInput(stepInputTarget, "Target", "Pipeline to run, environment to deploy or workflow to start", true),
+		textInput(stepInputRef, "Ref", "Branch, tag or commit; the default when empty", true),
+	}}
+
+	modal := slack.NewConfigurationModalRequest(blocks, "", "")
+	if _, err := a.client.OpenViewContext(ctx, callback.TriggerID, modal.ModalViewRequest); err != nil {
+		return fmt.Errorf("failed to open view: %w", err)
+	}
+	return nil
+}
+
+// saveStepConfiguration validates the submitted modal and saves it as the step inputs.
+// It returns validation errors keyed by block ID.
+func (a *SlackAdapter) saveStepConfiguration(ctx context.Context, callback *slack.InteractionCallback) map[string]string {
+	values := map[string]string{}
+	if callback.View.State != nil {
+		for blockID, actions := range callback.View.State.Values {
+			action := actions[stepInputActionID]
+			values[blockID] = strings.TrimSpace(action.Value)
+			if action.SelectedOption.Value != "" {
+				values[blockID] = action.SelectedOption.Value
+			}
+		}
+	}
+
+	errors := map[string]string{}
+	switch values[stepInputAction] {
+	case "verify":
+		if values[stepInputRepository] == "" {
+			errors[stepInputRepository] = "A repository is required to run a pipeline"
+		}
+	case "deploy":
+		if values[stepInputRepository] == "" {
+			errors[stepInputRepository] = "A repository is required to deploy"
+		}
+		if values[stepInputTarget] == "" {
+			errors[stepInputTarget] = "An environment is required to deploy"
+		}
+	case "workflow":
+		if values[stepInputTarget] == "" {
+			errors[stepInputTarget] = "A workflow name is required"
+		}
+	default:
+		errors[stepInputAction] = "Choose an action"
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+
+	// Steps run with the permissions of the user who configured them
+	inputs := slack.WorkflowStepInputs{
+		stepInputAction:       {Value: values[stepInputAction]},
+		stepInputConfiguredBy: {Value: callback.User.ID},
+	}
+	for _, name := range []string{stepInputRepository, stepInputTarget, stepInputRef} {
+		if values[name] != "" {
+			inputs[name] = slack.WorkflowStepInputElement{Value: values[name]}
+		}
+	}
+	outputs := stepOutputs
+
+	if err := a.client.SaveWorkflowStepConfigurationContext(ctx, callback.WorkflowStep.WorkflowStepEditID, &inputs, &outputs); err != nil {
+		a.logger.Error("failed to save workflow step configuration", zap.Error(err))
+		return map[string]string{stepInputAction: "Failed to save the step, please try again"}
+	}
+	return nil
+}
+
+// workflowStepCommand parses a workflow_step_execute event into the command of the step,
+// reporting invalid steps back to Slack. It returns the workflow_step_execute_id too,
+// empty when the event did not come from a Workflow Builder execution.
+func (a *SlackAdapter) workflowStepCommand(payload *webhookPayload) (*domain.Command, string, error) {
+	var event workflowStepExecuteEvent
+	if len(payload.Event) > 0 {
+		if err := json.Unmarshal(payload.Event, &event); err != nil {
+			return nil, "", fmt.Errorf("invalid event format")
+		}
+	}
+
+	eventType := payload.Type
+	if eventType == "event_callback" {
+		eventType = event.Type
+	}
+	if eventType != "workflow_step_execute" {
+		return nil, "", fmt.Errorf("unsupported event type: %s", eventType)
+	}
+
+	step := event.WorkflowStep
+	executeID := step.WorkflowStepExecuteID
+	if step.WorkflowID == "" || step.StepID == "" {
+		return nil, executeID, a.failStep(executeID, domain.Invalid("workflow_id and step_id are required"))
+	}
+
+	inputs := make(map[string]string, len(step.Inputs))
+	for name, raw := range step.Inputs {
+		inputs[name] = stepInputValue(raw)
+	}
+
+	cmd, err := stepCommand(inputs)
+	if err != nil {
+		return nil, executeID, a.failStep(executeID, err)
+	}
+	cmd.Source = domain.CommandSource{
+		Platform:   "slack",
+		WorkflowID: step.WorkflowID,
+		StepID:     step.StepID,
+	}
+	return cmd, executeID, nil
+}
+
+// startWorkflowStep runs a step in the background, as dispatching its pipeline may take
+// longer than Slack waits for the acknowledgement. Steps trigger pipelines, so retried
+// deliveries of an event already seen are dropped rather than run again.
+func (a *SlackAdapter) startWorkflowStep(r *http.Request, payload *webhookPayload, cmd *domain.Command, executeID string) {
+	logger := a.logger.With(
+		zap.String("event_id", payload.EventID),
+		zap.String("workflow_id", cmd.Source.WorkflowID),
+		zap.String("step_id", cmd.Source.StepID))
+
+	if payload.EventID != "" && !a.events.firstSeen(payload.EventID, time.Now()) {
+		logger.Info("dropped duplicate event", zap.String("retry_num", r.Header.Get("X-Slack-Retry-Num")),
+			zap.String("retry_reason", r.Header.Get("X-Slack-Retry-Reason")))
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+		defer cancel()
+		if err := a.runWorkflowStep(ctx, logger, cmd, executeID); err != nil {
+			logger.Error("failed to run workflow step", zap.Error(err))
+		}
+	}()
+}
+
+// runWorkflowStep processes the command of a step and reports its outcome back to Slack,
+// so later steps can use the outputs
+func (a *SlackAdapter) runWorkflowStep(ctx context.Context, logger *zap.Logger, cmd *domain.Command, executeID string) error {
+	result, err := a.processor.ProcessCommand(ctx, cmd)
+	if err != nil {
+		return a.failStep(executeID, err)
+	}
+	if result.Status != "success" {
+		if executeID != "" {
+			if err := a.client.WorkflowStepFailed(executeID, result.Message); err != nil {
+				logger.Error("failed to report workflow step failure", zap.Error(err))
+			}
+		}
+		return nil
+	}
+
+	if executeID != "" {
+		outputs := stepResultOutputs(result)
+		if err := a.client.WorkflowStepCompleted(executeID, slack.WorkflowStepCompletedRequestOptionOutput(outputs)); err != nil {
+			return fmt.Errorf("failed to complete workflow step: %w", err)
+		}
+	}
+	return nil
+}
+
+// failStep reports a step failure to Slack when the event came from a Workflow Builder
+// execution, and returns the cause
+func (a *SlackAdapter) failStep(executeID string, cause error) error {
+	if executeID != "" {
+		if err := a.client.WorkflowStepFailed(executeID, errorText(cause)); err != nil {
+			a.logger.Error("failed to report workflow step failure", zap.Error(err))
+		}
+	}
+	return cause
+}
+
+// stepCommand builds the command a step runs from its inputs. Steps saved without an
+// action predate the action input and verify the repository.
+func stepCommand(inputs map[string]string) (*domain.Command, error) {
+	user := inputs[stepInputConfiguredBy]
+	if user == "" {
+		user = workflowStepUser
+	}
+	cmd := &domain.Command{
+		Parameters: map[string]interface{}{},
+		User:       domain.User{ID: user, Platform: "slack"},
+		Timestamp:  time.Now(),
+	}
+
+	repository, target, ref := inputs[stepInputRepository], inputs[stepInputTarget], inputs[stepInputRef]
+	switch inputs[stepInputAction] {
+	case "", "verify":
+		if repository == "" {
+			return nil, domain.Invalid("repository input is required")
+		}
+		cmd.Type = domain.CommandTypeVerifyRepo
+		cmd.Parameters["repository_name"] = repository
+		if target != "" {
+			cmd.Parameters["pipeline_name"] = target
+		}
+	case "deploy":
+		if repository == "" || target == "" {
+			return nil, domain.Invalid("repository and target inputs are required to deploy")
+		}
+		cmd.Type = domain.CommandTypeDeploy
+		cmd.Parameters["repository_name"] = repository
+		cmd.Parameters["environment"] = target
+	case "workflow":
+		if target == "" {
+			return nil, domain.Invalid("target input is required to start a workflow")
+		}
+		cmd.Type = domain.CommandTypeStartWorkflow
+		cmd.Parameters["workflow_name"] = target
+		return cmd, nil
+	default:
+		return nil, domain.Invalid("unknown action input: %s", inputs[stepInputAction])
+	}
+
+	if ref != "" {
+		cmd.Parameters["ref"] = ref
+	}
+	return cmd, nil
+}
+
+// stepInputValue reads an input sent as {"value": ...} by Workflow Builder, or as a plain string
+func stepInputValue(raw json.RawMessage) string {
+	var element slack.WorkflowStepInputElement
+	if json.Unmarshal(raw, &element) == nil && element.Value != "" {
+		return element.Value
+	}
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		return value
+	}
+	return ""
+}
+
+// stepResultOutputs maps a command result to the declared step outputs
+func stepResultOutputs(result *domain.CommandResult) map[string]string {
+	outputs := map[string]string{
+		"status":  result.Status,
+		"message": result.Message,
+	}
+	switch details := result.Details.(type) {
+	case map[string]interface{}:
+		if runID, ok := details["run_id"].(string); ok {
+			outputs["run_id"] = runID
+		}
+		if url, ok := details["url"].(string); ok {
+			outputs["run_url"] = url
+		}
+	case *domain.Workflow:
+		outputs["workflow_id"] = details.ID
+	}
+	return outputs
+}
@@ -0,0 +1,294 @@
+// Package cli implements chatops-cli, a terminal client running the RBAC guarded
+// operations of the ChatOps HTTP API
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/pkg/client"
+)
+
+const defaultPollInterval = 5 * time.Second
+
+// ErrUsage is returned for invalid arguments, after the usage has been printed
+var ErrUsage = errors.New("invalid usage")
+
+// command is a subcommand of the CLI
+type command struct {
+	usage   string
+	summary string
+	run     func(ctx context.Context, c *CLI, args []string) error
+}
+
+// commands is filled in init, since the commands print the usage listing them
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"run": {
+			usage:   "run [--ref REF] [--follow] REPOSITORY [PIPELINE]",
+			summary: "Run a pipeline, the default one unless named",
+			run:     runPipeline,
+		},
+		"repos": {
+			usage:   "repos",
+			summary: "List repositories",
+			run:     listRepositories,
+		},
+		"pipelines": {
+			usage:   "pipelines REPOSITORY",
+			summary: "List the pipelines of a repository",
+			run:     listPipelines,
+		},
+		"status": {
+			usage:   "status [--repository NAME] [--pipeline NAME] [--user ID] [--limit N] [--follow] [RUN_ID]",
+			summary: "Show a run, following it until it finishes, or list recent runs",
+			run:     showStatus,
+		},
+		"approve": {
+			usage:   "approve [--reject] WORKFLOW_ID STEP_ID",
+			summary: "Approve or reject a workflow approval step",
+			run:     approveStep,
+		},
+		"audit": {
+			usage:   "audit [--type TYPE] [--repository NAME] [--actor ID] [--since DURATION|TIME] [--limit N]",
+			summary: "List recorded events, newest first",
+			run:     listAuditEvents,
+		},
+	}
+}
+
+// CLI runs chatops-cli commands
+type CLI struct {
+	stdout       io.Writer
+	stderr       io.Writer
+	getenv       func(string) string
+	httpClient   *http.Client
+	pollInterval time.Duration
+
+	global   globalFlags
+	settings Settings
+	client   *client.Client
+	printer  *printer
+}
+
+type Options struct {
+	Stdout       io.Writer
+	Stderr       io.Writer
+	Getenv       func(string) string // Optional: defaults to os.Getenv
+	HTTPClient   *http.Client        // Optional: defaults to the client package default
+	PollInterval time.Duration       // Optional: how often followed runs are polled, defaults to 5 seconds
+}
+
+// New creates a new instance of CLI
+func New(opts Options) (*CLI, error) {
+	if opts.Stdout == nil || opts.Stderr == nil {
+		return nil, fmt.Errorf("stdout and stderr are required")
+	}
+
+	cli := &CLI{
+		stdout:       opts.Stdout,
+		stderr:       opts.Stderr,
+		getenv:       opts.Getenv,
+		httpClient:   opts.HTTPClient,
+		pollInterval: opts.PollInterval,
+	}
+	if cli.getenv == nil {
+		cli.getenv = os.Getenv
+	}
+	if cli.pollInterval <= 0 {
+		cli.pollInterval = defaultPollInterval
+	}
+
+	return cli, nil
+}
+
+// Run runs the command named by the arguments, which exclude the program name
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	fs := c.flagSet("", &c.global)
+	if err := fs.Parse(args); err != nil {
+		if err = parseError(err); errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		c.usage()
+		if fs.NArg() == 0 {
+			return ErrUsage
+		}
+		return nil
+	}
+
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(c.stderr, "unknown command %q\n\n", name)
+		c.usage()
+		return ErrUsage
+	}
+
+	err := cmd.run(ctx, c, fs.Args()[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+// globalFlags are accepted before and after the command name
+type globalFlags struct {
+	config string
+	server string
+	token  string
+	output string
+}
+
+// merge returns the flags with the ones set in other taking precedence
+func (f globalFlags) merge(other globalFlags) globalFlags {
+	if other.config != "" {
+		f.config = other.config
+	}
+	if other.server != "" {
+		f.server = other.server
+	}
+	if other.token != "" {
+		f.token = other.token
+	}
+	if other.output != "" {
+		f.output = other.output
+	}
+	return f
+}
+
+// flagSet creates the flag set of a command, or of the CLI when the name is empty, with
+// the global flags bound to flags. Commands bind their own copy, merged over the flags
+// given before the command name.
+func (c *CLI) flagSet(name string, flags *globalFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = c.usage
+	if name != "" {
+		fs.Usage = func() {
+			fmt.Fprintf(c.stderr, "Usage: chatops-cli %s\n\nFlags:\n", commands[name].usage)
+			fs.PrintDefaults()
+		}
+	}
+	fs.StringVar(&flags.config, "config", "", "config file, defaults to $CHATOPS_CONFIG or ~/.chatops.yaml")
+	fs.StringVar(&flags.server, "server", "", "server URL, overrides the config file and $CHATOPS_SERVER")
+	fs.StringVar(&flags.token, "token", "", "API token, overrides the config file and $CHATOPS_TOKEN")
+	fs.StringVar(&flags.output, "output", "", "output format: table, json or yaml")
+	fs.StringVar(&flags.output, "o", "", "shorthand for --output")
+	return fs
+}
+
+// parse parses the flags of a command, which may appear before and after its arguments,
+// and connects to the server. It returns the positional arguments.
+func (c *CLI) parse(fs *flag.FlagSet, flags *globalFlags, args []string, minArgs, maxArgs int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, parseError(err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) < minArgs || len(positional) > maxArgs {
+		fmt.Fprintf(c.stderr, "%s: expected %s\n\n", fs.Name(), describeArgs(minArgs, maxArgs))
+		fs.Usage()
+		return nil, ErrUsage
+	}
+
+	if err := c.connect(c.global.merge(*flags)); err != nil {
+		return nil, err
+	}
+	return positional, nil
+}
+
+// connect loads the settings and creates the API client and output printer
+func (c *CLI) connect(flags globalFlags) error {
+	settings, err := LoadSettings(flags.config, c.getenv, c.stderr)
+	if err != nil {
+		return err
+	}
+	if flags.server != "" {
+		settings.Server = flags.server
+	}
+	if flags.token != "" {
+		settings.Token = flags.token
+	}
+	if flags.output != "" {
+		settings.Output = flags.output
+	}
+	if settings.Server == "" {
+		return fmt.Errorf("no server configured; set server in %s or $CHATOPS_SERVER", settings.Path)
+	}
+
+	printer, err := newPrinter(c.stdout, settings.Output)
+	if err != nil {
+		return err
+	}
+	api, err := client.New(client.Options{
+		BaseURL:    settings.Server,
+		Token:      settings.Token,
+		HTTPClient: c.httpClient,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.settings = *settings
+	c.client = api
+	c.printer = printer
+	return nil
+}
+
+// parseError converts flag parsing errors, which the flag set has already printed along
+// with the usage. Help requests are returned as is, to end the command successfully.
+func parseError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return ErrUsage
+}
+
+func (c *CLI) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Usage: chatops-cli [--config FILE] [--server URL] [--token TOKEN] [-o table|json|yaml] COMMAND\n\nCommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-10s %s\n", name, commands[name].summary)
+	}
+	b.WriteString("\nUsage of each command:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  chatops-cli %s\n", commands[name].usage)
+	}
+	fmt.Fprint(c.stderr, b.String())
+}
+
+func describeArgs(minArgs, maxArgs int) string {
+	switch {
+	case maxArgs == 0:
+		return "no arguments"
+	case minArgs == maxArgs:
+		return fmt.Sprintf("%d argument(s)", minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", minArgs, maxArgs)
+	}
+}
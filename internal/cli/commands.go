@@ -0,0 +1,270 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/pkg/client"
+)
+
+// Command types run through the API, as handled by the command processor
+const (
+	commandTypeVerifyRepo  = "verify_repository"
+	commandTypeApproveStep = "approve_step"
+	commandTypeRejectStep  = "reject_step"
+)
+
+// runPipeline triggers a pipeline of a repository
+func runPipeline(ctx context.Context, c *CLI, args []string) error {
+	var flags globalFlags
+	fs := c.flagSet("run", &flags)
+	ref := fs.String("ref", "", "branch, tag or commit to run, defaults to the provider default branch")
+	follow := fs.Bool("follow", false, "follow the triggered run until it finishes")
+	positional, err := c.parse(fs, &flags, args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{"repository_name": positional[0]}
+	if len(positional) > 1 {
+		params["pipeline_name"] = positional[1]
+	}
+	if *ref != "" {
+		params["ref"] = *ref
+	}
+
+	result, err := c.client.RunCommand(ctx, &client.Command{Type: commandTypeVerifyRepo, Parameters: params})
+	if err != nil {
+		return err
+	}
+	if result.Status == "select_pipeline" {
+		var pipelines []client.Pipeline
+		_ = json.Unmarshal(result.Details, &pipelines)
+		names := make([]string, 0, len(pipelines))
+		for _, pipeline := range pipelines {
+			names = append(names, pipeline.Name)
+		}
+		return fmt.Errorf("%s has no default pipeline, name one of: %s", positional[0], strings.Join(names, ", "))
+	}
+	if err := c.printResult(result); err != nil || !*follow {
+		return err
+	}
+
+	var details struct {
+		RunID string `json:"run_id"`
+	}
+	if err := json.Unmarshal(result.Details, &details); err != nil || details.RunID == "" {
+		return fmt.Errorf("the provider reported no run to follow")
+	}
+	return c.followRun(ctx, details.RunID)
+}
+
+func listRepositories(ctx context.Context, c *CLI, args []string) error {
+	var flags globalFlags
+	fs := c.flagSet("repos", &flags)
+	if _, err := c.parse(fs, &flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	repos, err := c.client.ListRepositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"NAME", "URL", "DEFAULT BRANCH", "PIPELINES"}}
+	for _, repo := range repos {
+		var pipelines []string
+		for _, pipeline := range repo.Pipelines {
+			name := pipeline.Name
+			if pipeline.IsDefault {
+				name += "*"
+			}
+			pipelines = append(pipelines, name)
+		}
+		t.rows = append(t.rows, []string{repo.Name, repo.URL, repo.DefaultBranch, strings.Join(pipelines, ", ")})
+	}
+	return c.printer.print(repos, t)
+}
+
+func listPipelines(ctx context.Context, c *CLI, args []string) error {
+	var flags globalFlags
+	fs := c.flagSet("pipelines", &flags)
+	positional, err := c.parse(fs, &flags, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	pipelines, err := c.client.ListPipelines(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"NAME", "PATH", "DEFAULT"}}
+	for _, pipeline := range pipelines {
+		t.rows = append(t.rows, []string{pipeline.Name, pipeline.Path, yesNo(pipeline.IsDefault)})
+	}
+	return c.printer.print(pipelines, t)
+}
+
+// showStatus shows a run, or lists the recent runs without a run ID
+func showStatus(ctx context.Context, c *CLI, args []string) error {
+	var flags globalFlags
+	var filter client.RunFilter
+	fs := c.flagSet("status", &flags)
+	fs.StringVar(&filter.Repository, "repository", "", "list the runs of a repository")
+	fs.StringVar(&filter.Pipeline, "pipeline", "", "list the runs of a pipeline")
+	fs.StringVar(&filter.User, "user", "", "list the runs triggered by a user")
+	fs.IntVar(&filter.Limit, "limit", 0, "maximum number of runs to list")
+	follow := fs.Bool("follow", false, "follow the run until it finishes")
+	positional, err := c.parse(fs, &flags, args, 0, 1)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 {
+		if *follow {
+			return fmt.Errorf("--follow needs a run ID")
+		}
+		runs, err := c.client.ListRuns(ctx, filter)
+		if err != nil {
+			return err
+		}
+		return c.printer.print(runs, runTable(runs...))
+	}
+
+	if *follow {
+		return c.followRun(ctx, positional[0])
+	}
+	run, err := c.client.GetRun(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return c.printer.print(run, runTable(*run))
+}
+
+// approveStep approves or rejects a workflow approval step
+func approveStep(ctx context.Context, c *CLI, args []string) error {
+	var flags globalFlags
+	fs := c.flagSet("approve", &flags)
+	reject := fs.Bool("reject", false, "reject the step rather than approving it")
+	positional, err := c.parse(fs, &flags, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	commandType := commandTypeApproveStep
+	if *reject {
+		commandType = commandTypeRejectStep
+	}
+	result, err := c.client.RunCommand(ctx, &client.Command{
+		Type:       commandType,
+		Parameters: map[string]interface{}{"workflow_id": positional[0], "step_id": positional[1]},
+	})
+	if err != nil {
+		return err
+	}
+	return c.printResult(result)
+}
+
+func listAuditEvents(ctx context.Context, c *CLI, args []string) error {
+	var flags globalFlags
+	var filter client.AuditFilter
+	fs := c.flagSet("audit", &flags)
+	fs.StringVar(&filter.Type, "type", "", "event filter, such as deployment or lock.taken")
+	fs.StringVar(&filter.Repository, "repository", "", "list the events of a repository")
+	fs.StringVar(&filter.Actor, "actor", "", "list the events of a user")
+	since := fs.String("since", "", "list events since a duration ago, such as 24h, or an RFC 3339 time")
+	fs.IntVar(&filter.Limit, "limit", 0, "maximum number of events to list")
+	if _, err := c.parse(fs, &flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *since != "" {
+		if d, err := time.ParseDuration(*since); err == nil {
+			filter.Since = time.Now().Add(-d)
+		} else if filter.Since, err = time.Parse(time.RFC3339, *since); err != nil {
+			return fmt.Errorf("invalid --since %q, expected a duration or an RFC 3339 time", *since)
+		}
+	}
+
+	events, err := c.client.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"TIME", "TYPE", "REPOSITORY", "ACTOR", "MESSAGE"}}
+	for _, event := range events {
+		t.rows = append(t.rows, []string{formatTime(event.OccurredAt), event.Type, event.Repository, event.Actor, event.Message})
+	}
+	return c.printer.print(events, t)
+}
+
+// printResult prints the result of a command. Results with the error status fail the
+// command, so scripts can rely on the exit status.
+func (c *CLI) printResult(result *client.CommandResult) error {
+	if result.Status == "error" {
+		if c.printer.format != FormatTable {
+			if err := c.printer.print(result, table{}); err != nil {
+				return err
+			}
+		}
+		if result.Error != "" {
+			return fmt.Errorf("%s: %s", result.Message, result.Error)
+		}
+		return fmt.Errorf("%s", result.Message)
+	}
+	return c.printer.message(result, result.Message)
+}
+
+// followRun prints the run and each change of its status until it finishes. Runs ending
+// other than successfully fail the command.
+func (c *CLI) followRun(ctx context.Context, id string) error {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	var last string
+	for {
+		run, err := c.client.GetRun(ctx, id)
+		if err != nil {
+			return err
+		}
+		if run.Status != last {
+			line := fmt.Sprintf("%s  run %s of %s/%s is %s", formatTime(run.UpdatedAt), run.ID, run.Repository, run.Pipeline, run.Status)
+			if err := c.printer.stream(run, line); err != nil {
+				return err
+			}
+			last = run.Status
+		}
+
+		if run.IsFinished() {
+			if run.Status != client.RunStatusSuccess {
+				return fmt.Errorf("run %s finished with status %s", run.ID, run.Status)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func runTable(runs ...client.Run) table {
+	t := table{headers: []string{"ID", "REPOSITORY", "PIPELINE", "ENVIRONMENT", "STATUS", "TRIGGERED BY", "CREATED"}}
+	for _, run := range runs {
+		t.rows = append(t.rows, []string{run.ID, run.Repository, run.Pipeline, run.Environment, run.Status, run.TriggeredBy, formatTime(run.CreatedAt)})
+	}
+	return t
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
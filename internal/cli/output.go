@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// table is the table output of a value
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// printer writes values in the selected output format
+type printer struct {
+	out    io.Writer
+	format string
+}
+
+func newPrinter(out io.Writer, format string) (*printer, error) {
+	switch format {
+	case "":
+		format = FormatTable
+	case FormatTable, FormatJSON, FormatYAML:
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected table, json or yaml", format)
+	}
+	return &printer{out: out, format: format}, nil
+}
+
+// print writes the value as JSON or YAML, or the table in table format
+func (p *printer) print(value interface{}, t table) error {
+	switch p.format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.out, "%s\n", data)
+		return err
+	case FormatYAML:
+		data, err := toYAML(value)
+		if err != nil {
+			return err
+		}
+		_, err = p.out.Write(data)
+		return err
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// message writes the value as JSON or YAML, or the message in table format
+func (p *printer) message(value interface{}, message string) error {
+	if p.format == FormatTable {
+		_, err := fmt.Fprintln(p.out, message)
+		return err
+	}
+	return p.print(value, table{})
+}
+
+// stream writes one of a sequence of values: a JSON line, a YAML document or the line in
+// table format
+func (p *printer) stream(value interface{}, line string) error {
+	switch p.format {
+	case FormatJSON:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.out, "%s\n", data)
+		return err
+	case FormatYAML:
+		data, err := toYAML(value)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(p.out, "---\n%s", data)
+		return err
+	}
+	_, err := fmt.Fprintln(p.out, line)
+	return err
+}
+
+// toYAML encodes the value through its JSON encoding, so the keys follow the API and
+// keep their order
+func toYAML(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+// blockStyle drops the flow and quoting styles JSON documents decode with
+func blockStyle(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 && node.Tag == "!!str" {
+			node.Style = 0
+		}
+	} else {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
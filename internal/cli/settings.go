@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSettingsFile is the dotfile read from the home directory
+const DefaultSettingsFile = ".chatops.yaml"
+
+// Settings are read from the dotfile, then overridden by the CHATOPS_SERVER,
+// CHATOPS_TOKEN and CHATOPS_OUTPUT environment variables
+type Settings struct {
+	Server string `yaml:"server"`
+	Token  string `yaml:"token"`
+	Output string `yaml:"output"` // Optional: table, json or yaml, defaults to table
+	Path   string `yaml:"-"`      // File the settings were read from
+}
+
+// LoadSettings reads the settings from the file at path, $CHATOPS_CONFIG or
+// ~/.chatops.yaml, in that order. Only an explicitly named file must exist. A warning is
+// written when the file holding a token is readable by other users.
+func LoadSettings(path string, getenv func(string) string, warnings io.Writer) (*Settings, error) {
+	explicit := true
+	if path == "" {
+		path = getenv("CHATOPS_CONFIG")
+	}
+	if path == "" {
+		explicit = false
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the home directory: %w", err)
+		}
+		path = filepath.Join(home, DefaultSettingsFile)
+	}
+
+	settings := &Settings{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+	case err != nil:
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	default:
+		if err := yaml.Unmarshal(data, settings); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if settings.Token != "" && runtime.GOOS != "windows" {
+			if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o077 != 0 {
+				fmt.Fprintf(warnings, "warning: %s holds a token but is readable by other users; run chmod 600 %s\n", path, path)
+			}
+		}
+	}
+	settings.Path = path
+
+	if server := getenv("CHATOPS_SERVER"); server != "" {
+		settings.Server = server
+	}
+	if token := getenv("CHATOPS_TOKEN"); token != "" {
+		settings.Token = token
+	}
+	if output := getenv("CHATOPS_OUTPUT"); output != "" {
+		settings.Output = output
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// API token scopes
+const (
+	ScopeRunCommands      = "commands:run"
+	ScopeReadRepositories = "repositories:read"
+	ScopeReadRuns         = "runs:read"
+	ScopeReadAudit        = "audit:read"
+	ScopeManageWebhooks   = "webhooks:manage"
+	ScopeManageTokens     = "tokens:manage"
+)
+
+// AllScopes lists every API token scope
+var AllScopes = []string{
+	ScopeRunCommands,
+	ScopeReadRepositories,
+	ScopeReadRuns,
+	ScopeReadAudit,
+	ScopeManageWebhooks,
+	ScopeManageTokens,
+}
+
+// APIToken authenticates API requests as a user. Commands run through the token are
+// subject to the RBAC permissions of that user, and the scopes limit the endpoints.
+type APIToken struct {
+	ID         string
+	Name       string
+	UserID     string
+	Scopes     []string
+	Hash       string // SHA-256 of the token, which itself is never stored
+	CreatedBy  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time // Zero when the token never expires
+	LastUsedAt time.Time // Zero until first used
+	RevokedAt  time.Time // Zero unless revoked
+}
+
+// HasScope reports whether the token grants a scope
+func (t *APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// IsValid reports whether the token may be used at the given time
+func (t *APIToken) IsValid(at time.Time) bool {
+	if !t.RevokedAt.IsZero() {
+		return false
+	}
+	return t.ExpiresAt.IsZero() || at.Before(t.ExpiresAt)
+}
+
+// ValidateScopes checks that every scope is known
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return Invalid("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(AllScopes, scope) {
+			return Invalid("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
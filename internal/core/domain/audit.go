@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// AuditEvent is a recorded event of the event bus
+type AuditEvent struct {
+	ID         string
+	Type       string
+	Repository string
+	Actor      string
+	Message    string
+	Payload    []byte // JSON encoded event payload, empty when the event had none
+	OccurredAt time.Time
+}
+
+// AuditFilter narrows an audit event listing. Empty fields match every event.
+type AuditFilter struct {
+	Type       string // Event filter, see MatchesEventFilter
+	Repository string
+	Actor      string
+	Since      time.Time
+	Limit      int
+}
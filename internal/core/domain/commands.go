@@ -1,8 +1,31 @@
 package domain
 
 const (
-	CommandTypeManageRepo = "manage_repository"
-	CommandTypeVerifyRepo = "verify_repository"
+	CommandTypeManageRepo     = "manage_repository"
+	CommandTypeVerifyRepo     = "verify_repository"
+	CommandTypeCancelRun      = "cancel_run"
+	CommandTypeRerunRun       = "rerun_run"
+	CommandTypeRerunFailed    = "rerun_failed_jobs"
+	CommandTypeRunLogs        = "run_logs"
+	CommandTypeDeploy         = "deploy"
+	CommandTypeListEnvs       = "list_environments"
+	CommandTypeSetEnv         = "set_environment"
+	CommandTypeLock           = "lock_environment"
+	CommandTypeUnlock         = "unlock_environment"
+	CommandTypeAddSchedule    = "add_schedule"
+	CommandTypeListSchedules  = "list_schedules"
+	CommandTypeRemoveSchedule = "remove_schedule"
+	CommandTypeStartWorkflow  = "start_workflow"
+	CommandTypeWorkflowStatus = "workflow_status"
+	CommandTypeListWorkflows  = "list_workflows"
+	CommandTypeApproveStep    = "approve_step"
+	CommandTypeRejectStep     = "reject_step"
+
+	CommandTypeSubscribe         = "subscribe"
+	CommandTypeUnsubscribe       = "unsubscribe"
+	CommandTypeListSubscriptions = "list_subscriptions"
+	CommandTypeMuteChannel       = "mute_channel"
+	CommandTypeUnmuteChannel     = "unmute_channel"
 )
 
 type RepositoryCommand struct {
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Dashboard is a user's overview of ChatOps state
+type Dashboard struct {
+	User             User
+	RecentRuns       []*Run          // Runs the user triggered, newest first
+	RecentCommands   []RecentCommand // Commands the user ran, newest first
+	Repositories     []*Repository
+	CanTrigger       bool // The user may run pipelines of the repositories
+	CanDeploy        bool // The user may deploy the repositories
+	PendingApprovals []PendingApproval
+	Locks            []*DeploymentLock
+}
+
+// RecentCommand is a command the user ran, read back from its command.completed audit event
+type RecentCommand struct {
+	Type       string
+	Repository string
+	Status     string // Result status, "failed" when the command returned an error
+	RanAt      time.Time
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Environment maps a deployment environment of a repository to the pipeline deploying it
+type Environment struct {
+	Name     string
+	Pipeline string                 // Name or path of the pipeline that deploys the environment
+	Ref      string                 // Optional: default ref, the repository default branch otherwise
+	Inputs   map[string]interface{} // Optional: extra inputs passed to the pipeline
+}
+
+// Deployment represents a deployment of a ref to an environment triggered through ChatOps
+type Deployment struct {
+	ID                   string
+	ProviderDeploymentID string // Deployment ID on the provider, empty when unsupported
+	Repository           string // Repository name
+	Environment          string
+	Ref                  string
+	RunID                string // Run of the deploying pipeline, a pending run ID until the provider created it
+	Status               string // "queued", "in_progress", "success", "failure" or "error"
+	URL                  string // URL of the deploying run
+	DeployedBy           string // User ID who requested the deployment
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+// Deployment statuses, named after the GitHub deployment states
+const (
+	DeploymentStatusQueued     = "queued"
+	DeploymentStatusInProgress = "in_progress"
+	DeploymentStatusSuccess    = "success"
+	DeploymentStatusFailure    = "failure"
+	DeploymentStatusError      = "error"
+)
+
+// IsFinal reports whether the deployment reached a final status
+func (d *Deployment) IsFinal() bool {
+	switch d.Status {
+	case DeploymentStatusSuccess, DeploymentStatusFailure, DeploymentStatusError:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorKind classifies an error so adapters can render it, as a chat message or an HTTP
+// status. Kinds are errors themselves, so errors.Is(err, ErrNotFound) matches every Error
+// of that kind.
+type ErrorKind string
+
+func (k ErrorKind) Error() string {
+	return string(k)
+}
+
+const (
+	ErrNotFound      ErrorKind = "not found"
+	ErrAlreadyExists ErrorKind = "already exists"
+	ErrConflict      ErrorKind = "conflict" // The record changed since it was read
+	ErrForbidden     ErrorKind = "forbidden"
+	ErrInvalid       ErrorKind = "invalid"
+	ErrUpstream      ErrorKind = "upstream failure" // A CI provider or another remote service failed
+)
+
+// Error is an error of a known kind. Its message is meant for users, while Error()
+// appends the cause for logs.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Err     error // Optional: the cause
+}
+
+// NewError creates an error of a kind with a formatted message
+func NewError(kind ErrorKind, cause error, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: cause}
+}
+
+func NotFound(format string, args ...interface{}) *Error {
+	return NewError(ErrNotFound, nil, format, args...)
+}
+
+func AlreadyExists(format string, args ...interface{}) *Error {
+	return NewError(ErrAlreadyExists, nil, format, args...)
+}
+
+func Conflict(format string, args ...interface{}) *Error {
+	return NewError(ErrConflict, nil, format, args...)
+}
+
+func Forbidden(format string, args ...interface{}) *Error {
+	return NewError(ErrForbidden, nil, format, args...)
+}
+
+func Invalid(format string, args ...interface{}) *Error {
+	return NewError(ErrInvalid, nil, format, args...)
+}
+
+func Upstream(cause error, format string, args ...interface{}) *Error {
+	return NewError(ErrUpstream, cause, format, args...)
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Sentence returns the message with its first letter upper-cased, for display
+func (e *Error) Sentence() string {
+	if e.Message == "" {
+		return string(e.Kind)
+	}
+	return strings.ToUpper(e.Message[:1]) + e.Message[1:]
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the kind of the error
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(ErrorKind)
+	return ok && kind == e.Kind
+}
+
+// AsError returns the first Error in the chain of err, or nil for errors of no known kind
+func AsError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
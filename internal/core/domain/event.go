@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Event kinds. Event types are the kind followed by a qualifier, such as
+// "workflow_run.failure" or "deployment.production".
+const (
+	EventKindRun        = "workflow_run" // Qualified by the conclusion: success, failure or cancelled
+	EventKindDeployment = "deployment"   // Qualified by the environment
+	EventKindRepository = "repository"
+	EventKindLock       = "lock"
+	EventKindApproval   = "approval"
+	EventKindCommand    = "command"
+)
+
+// Event types without a variable qualifier
+const (
+	EventRepositoryAdded   = EventKindRepository + ".added"
+	EventLockTaken         = EventKindLock + ".taken"
+	EventApprovalRequested = EventKindApproval + ".requested"
+	EventCommandReceived   = EventKindCommand + ".received"
+	EventCommandCompleted  = EventKindCommand + ".completed"
+	EventRunTriggered      = EventKindRun + ".triggered"
+)
+
+// Event is something that happened in ChatOps. Events are published on the event bus,
+// and chat channels may subscribe to those carrying a message.
+type Event struct {
+	ID         string // Assigned when the event is stored in the outbox
+	Type       string
+	Repository string // Empty for events not tied to a repository
+	Actor      string // ID of the user who caused the event, empty for system events
+	Message    string // Human readable description posted to subscribed channels, empty for events not meant for chat
+	Payload    interface{}
+	OccurredAt time.Time
+}
+
+// CommandReceived is the payload of command.received events
+type CommandReceived struct {
+	Command *Command
+}
+
+// CommandCompleted is the payload of command.completed events
+type CommandCompleted struct {
+	Command  *Command
+	Status   string // Result status, "failed" when the command returned an error
+	Message  string
+	Error    string
+	Duration time.Duration
+}
+
+// WorkflowTriggered is the payload of workflow_run.triggered events, published when a
+// pipeline run is started
+type WorkflowTriggered struct {
+	Run *Run
+}
+
+// WorkflowRunUpdated is the payload of run status changes, published as
+// workflow_run.<status> or, for deployment runs, deployment.<environment>
+type WorkflowRunUpdated struct {
+	Run            *Run
+	PreviousStatus string
+}
+
+// RepositoryAdded is the payload of repository.added events
+type RepositoryAdded struct {
+	Repository *Repository
+}
+
+// NewEventPayload returns a pointer to the payload type of an event type, for decoding
+// stored events. Lock events carry a DeploymentLock and approval events a PendingApproval.
+func NewEventPayload(eventType string) interface{} {
+	switch eventType {
+	case EventCommandReceived:
+		return &CommandReceived{}
+	case EventCommandCompleted:
+		return &CommandCompleted{}
+	case EventRunTriggered:
+		return &WorkflowTriggered{}
+	case EventRepositoryAdded:
+		return &RepositoryAdded{}
+	case EventLockTaken:
+		return &DeploymentLock{}
+	case EventApprovalRequested:
+		return &PendingApproval{}
+	}
+	switch kind, _, _ := strings.Cut(eventType, "."); kind {
+	case EventKindRun, EventKindDeployment:
+		return &WorkflowRunUpdated{}
+	default:
+		return nil
+	}
+}
+
+// Kind returns the part of the event type before the qualifier
+func (e *Event) Kind() string {
+	kind, _, _ := strings.Cut(e.Type, ".")
+	return kind
+}
+
+// MatchesEventFilter reports whether an event type matches a filter. A filter is "*",
+// an event kind matching every qualifier, or a full event type.
+func MatchesEventFilter(filter, eventType string) bool {
+	if filter == "*" || filter == eventType {
+		return true
+	}
+	return strings.HasPrefix(eventType, filter+".")
+}
+
+// eventKinds lists the kinds filters may name
+var eventKinds = []string{EventKindRun, EventKindDeployment, EventKindRepository, EventKindLock, EventKindApproval, EventKindCommand}
+
+// ValidateEventFilter checks that a filter names a known event kind
+func ValidateEventFilter(filter string) error {
+	if filter == "*" {
+		return nil
+	}
+	kind, qualifier, qualified := strings.Cut(filter, ".")
+	if !slices.Contains(eventKinds, kind) {
+		return fmt.Errorf("unknown event %q, expected one of %s", filter, strings.Join(eventKinds, ", "))
+	}
+	if qualified && qualifier == "" {
+		return fmt.Errorf("invalid event %q", filter)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// DeploymentLock prevents deployments of a repository environment by anyone but its owner
+type DeploymentLock struct {
+	Repository  string
+	Environment string
+	Owner       string // User ID holding the lock
+	Reason      string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// IsExpired reports whether the lock expired at the given time
+func (l *DeploymentLock) IsExpired(at time.Time) bool {
+	return !at.Before(l.ExpiresAt)
+}
+
+// FreezeWindow blocks pipeline triggers during a change freeze. A window is either
+// recurring, starting on a cron schedule and lasting Duration, or a fixed date range.
+type FreezeWindow struct {
+	Name        string
+	Repository  string // Optional: every repository when empty
+	Environment string // Optional: every trigger, including non-deployment runs, when empty
+	Reason      string
+
+	Cron     string        // Recurring windows: standard 5-field cron expression of the window start
+	Duration time.Duration // Recurring windows: how long the freeze lasts
+	Timezone string        // Recurring windows: IANA timezone of the cron expression, UTC when empty
+
+	Start time.Time // Date range windows
+	End   time.Time // Date range windows
+}
+
+// Applies reports whether the window covers triggers of the repository environment
+func (w *FreezeWindow) Applies(repository, environment string) bool {
+	if w.Repository != "" && w.Repository != repository {
+		return false
+	}
+	return w.Environment == "" || w.Environment == environment
+}
@@ -0,0 +1,22 @@
+package domain
+
+const (
+	// PermissionAll grants every permission
+	PermissionAll = "*"
+	// PermissionTriggerPipeline allows triggering, cancelling and re-running pipelines
+	PermissionTriggerPipeline = "pipeline:trigger"
+	// PermissionDeploy allows deploying to environments and controlling deployment runs
+	PermissionDeploy = "deployment:create"
+	// PermissionManageEnvironments allows mapping environments to pipelines
+	PermissionManageEnvironments = "environment:manage"
+	// PermissionOverrideGuard allows deploying through locks and freeze windows and releasing locks of others
+	PermissionOverrideGuard = "deployment:override"
+	// PermissionManageSchedules allows adding and removing scheduled commands
+	PermissionManageSchedules = "schedule:manage"
+	// PermissionRunWorkflow allows starting workflows
+	PermissionRunWorkflow = "workflow:run"
+	// PermissionApproveWorkflow allows approving and rejecting workflow approval steps
+	PermissionApproveWorkflow = "workflow:approve"
+	// PermissionManageSubscriptions allows subscribing, unsubscribing and muting channels
+	PermissionManageSubscriptions = "subscription:manage"
+)
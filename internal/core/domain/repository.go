@@ -10,6 +10,19 @@ type Repository struct {
 	AddedBy       string // User ID who added the repo
 	AddedAt       time.Time
 	Pipelines     []Pipeline
+	Environments  []Environment
+	Version       int // Incremented by every update; updates of a stale version conflict
+	UpdatedAt     time.Time
+}
+
+// Environment returns the environment with the given name, or nil when it is not mapped
+func (r *Repository) Environment(name string) *Environment {
+	for i := range r.Environments {
+		if r.Environments[i].Name == name {
+			return &r.Environments[i]
+		}
+	}
+	return nil
 }
 
 type Pipeline struct {
@@ -17,3 +30,12 @@ type Pipeline struct {
 	Path      string // Path to the workflow file
 	IsDefault bool
 }
+
+// PipelineInput is an input declared by a pipeline, such as a workflow_dispatch input
+type PipelineInput struct {
+	Name        string
+	Description string
+	Default     string
+	Required    bool
+	Options     []string // Choices of a choice or boolean input; free text when empty
+}
@@ -0,0 +1,8 @@
+package domain
+
+// Role grants permissions to the users assigned to it
+type Role struct {
+	Name        string
+	Permissions []string
+	Users       []string // IDs of the users assigned to the role
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Run statuses. Provider statuses are normalized to these by the run tracker.
+const (
+	RunStatusQueued     = "queued"
+	RunStatusInProgress = "in_progress"
+	RunStatusCancelling = "cancelling"
+	RunStatusSuccess    = "success"
+	RunStatusFailure    = "failure"
+	RunStatusCancelled  = "cancelled"
+	// RunStatusUntracked is the final status of a triggered run the provider never
+	// identified, e.g. when concurrent dispatches could not be told apart
+	RunStatusUntracked = "untracked"
+)
+
+// pendingRunPrefix marks the IDs of runs the provider accepted but did not create yet.
+// The rest of the ID is the reference the provider resolves the run from.
+const pendingRunPrefix = "pending:"
+
+// PendingRunID returns the ID recorded for a run known by its provider reference only
+func PendingRunID(reference string) string {
+	return pendingRunPrefix + reference
+}
+
+// IsPendingRunID reports whether the ID belongs to a run the provider did not create yet
+func IsPendingRunID(id string) bool {
+	return strings.HasPrefix(id, pendingRunPrefix)
+}
+
+// PendingRunReference returns the provider reference of a pending run ID
+func PendingRunReference(id string) string {
+	return strings.TrimPrefix(id, pendingRunPrefix)
+}
+
+// Run represents a pipeline run triggered through ChatOps
+type Run struct {
+	ID          string // Provider-specific run identifier
+	Provider    string // "github", "gitlab", "jenkins"
+	Repository  string // Repository name
+	Pipeline    string // Pipeline name
+	Environment string // Deployment environment, empty for other runs
+	Type        string // Trigger type, e.g. "verification"
+	Status      string
+	URL         string
+	TriggeredBy string // User ID who triggered the run
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// IsFinished reports whether the run reached a final status
+func (r *Run) IsFinished() bool {
+	switch r.Status {
+	case RunStatusSuccess, RunStatusFailure, RunStatusCancelled, RunStatusUntracked:
+		return true
+	default:
+		return false
+	}
+}
+
+// RunFilter narrows a run listing. Empty fields match every run.
+type RunFilter struct {
+	Repository  string
+	Pipeline    string
+	TriggeredBy string
+	Limit       int
+}
+
+// LogSummary is a trimmed view of a failed run's logs for posting into chat
+type LogSummary struct {
+	RunID      string
+	Repository string
+	Pipeline   string
+	URL        string
+	Job        string
+	FailedStep string
+	Excerpt    string // Last lines around the first error of the failing job
+	FullLog    string // Complete log of the failing job
+}
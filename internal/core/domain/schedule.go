@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Schedule fires a command on a cron schedule on behalf of the user who created it
+type Schedule struct {
+	ID          string
+	Cron        string // Standard 5-field cron expression or descriptor such as @daily
+	Timezone    string // IANA timezone of the cron expression, UTC when empty
+	CommandType string
+	Parameters  map[string]interface{}
+	CommandText string // Command as typed by the user, for display
+	User        User   // Identity the command runs as
+	ChannelID   string // Channel notified of each run
+	CreatedAt   time.Time
+	LastRunAt   time.Time
+	NextRunAt   time.Time
+}
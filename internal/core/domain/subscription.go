@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// AllRepositories subscribes a channel to the events of every repository, including
+// events not tied to a repository
+const AllRepositories = "*"
+
+// Subscription posts the matching events of a repository to a chat channel
+type Subscription struct {
+	ID         string
+	Platform   string
+	ChannelID  string
+	Repository string   // Repository name or AllRepositories
+	Events     []string // Event filters, see MatchesEventFilter
+	CreatedBy  string
+	CreatedAt  time.Time
+}
+
+// Matches reports whether the event should be posted to the subscribed channel
+func (s *Subscription) Matches(event *Event) bool {
+	if s.Repository != AllRepositories && s.Repository != event.Repository {
+		return false
+	}
+	for _, filter := range s.Events {
+		if MatchesEventFilter(filter, event.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChannelMute silences the subscriptions of a channel
+type ChannelMute struct {
+	Platform  string
+	ChannelID string
+	Until     time.Time // Zero until the channel is unmuted
+	MutedBy   string
+}
+
+// IsActive reports whether the channel is still muted at the given time
+func (m *ChannelMute) IsActive(at time.Time) bool {
+	return m.Until.IsZero() || at.Before(m.Until)
+}
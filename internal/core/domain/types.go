@@ -30,6 +30,7 @@ type WorkflowTrigger struct {
 	Type       string
 	Repository string
 	Workflow   string
+	Ref        string // Optional: branch, tag or SHA to run on; providers fall back to the default branch
 	Parameters map[string]interface{}
 }
 
@@ -40,14 +41,20 @@ type CommandResult struct {
 	Error   error
 }
 
+// Workflow is an execution of a workflow definition
 type Workflow struct {
 	ID         string
-	Name       string
+	Name       string // Name of the workflow definition
 	Repository string
 	Path       string
-	Parameters map[string]interface{}
+	Parameters map[string]interface{} // Workflow inputs
 	Status     string
+	Steps      map[string]*StepState // Execution state keyed by step ID
+	StartedBy  User
+	ChannelID  string // Channel the workflow was started from
+	Version    int    // Incremented by every update; updates of a stale version conflict
 	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
 type WorkflowStatus struct {
@@ -57,3 +64,12 @@ type WorkflowStatus struct {
 	Error     string
 	UpdatedAt time.Time
 }
+
+// JobLog holds the log output of a single job within a pipeline run
+type JobLog struct {
+	JobID      string
+	Name       string
+	Status     string
+	FailedStep string // Name of the first failed step, if known
+	Content    string
+}
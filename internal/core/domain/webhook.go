@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending = "pending" // Waiting for its first attempt or a retry
+	DeliveryStatusSuccess = "success"
+	DeliveryStatusFailed  = "failed" // Every attempt failed
+)
+
+// Webhook posts the matching events of the event bus to an external URL
+type Webhook struct {
+	ID        string
+	URL       string
+	Events    []string          // Event filters, see MatchesEventFilter
+	Secret    string            // Optional: signs the body with HMAC-SHA256
+	Headers   map[string]string // Optional: added to every request
+	Active    bool
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Matches reports whether the event should be posted to the webhook
+func (w *Webhook) Matches(event *Event) bool {
+	if !w.Active {
+		return false
+	}
+	for _, filter := range w.Events {
+		if MatchesEventFilter(filter, event.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records the delivery of an event to a webhook and its attempts
+type WebhookDelivery struct {
+	ID            string
+	WebhookID     string
+	EventID       string // Outbox ID of the event, empty without an outbox
+	EventType     string
+	Body          string
+	Status        string
+	Attempts      int
+	ResponseCode  int    // Status code of the last attempt, zero when it got no response
+	Error         string // Error of the last failed attempt
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DeliveredAt   time.Time // Zero until delivered
+}
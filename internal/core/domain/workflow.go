@@ -0,0 +1,96 @@
+package domain
+
+import "time"
+
+// Workflow step types
+const (
+	StepTypeTriggerPipeline = "trigger_pipeline"
+	StepTypeWaitForStatus   = "wait_for_status"
+	StepTypeApproval        = "approval"
+	StepTypePostMessage     = "post_message"
+	StepTypeWebhook         = "webhook"
+)
+
+// Workflow and step statuses
+const (
+	WorkflowStatusRunning   = "running"
+	WorkflowStatusSucceeded = "succeeded"
+	WorkflowStatusFailed    = "failed"
+
+	StepStatusPending = "pending"
+	StepStatusRunning = "running"
+	StepStatusWaiting = "waiting"
+	StepStatusSuccess = "success"
+	StepStatusFailure = "failure"
+	StepStatusSkipped = "skipped"
+)
+
+// WorkflowDefinition declares a multi-step workflow. Steps run in sequence unless any
+// step declares Needs, in which case they form a DAG.
+type WorkflowDefinition struct {
+	Name        string
+	Description string
+	Path        string            // File the definition was loaded from
+	Inputs      map[string]string // Input names with their default values
+	Steps       []WorkflowStep
+}
+
+// WorkflowStep is a single step of a workflow definition. String fields other than ID,
+// Type and Needs are Go templates rendered with the workflow inputs and step results.
+type WorkflowStep struct {
+	ID    string
+	Type  string
+	Needs []string // Steps that must finish first
+	If    string   // Optional: template rendering "true" when the step should run
+
+	// trigger_pipeline
+	Repository  string
+	Pipeline    string
+	Environment string // Deploys the environment instead of running Pipeline
+	Ref         string
+
+	// wait_for_status: the trigger_pipeline step whose run is awaited
+	Run string
+
+	// approval
+	Approvers []string // Optional: user IDs allowed to approve, anyone permitted when empty
+
+	// post_message and approval
+	Channel string // Optional: the channel the workflow was started from when empty
+	Message string
+
+	// webhook
+	URL     string
+	Method  string
+	Headers map[string]string
+	Body    string
+
+	// wait_for_status and approval
+	Timeout time.Duration
+}
+
+// PendingApproval is an approval step waiting for a decision
+type PendingApproval struct {
+	WorkflowID   string
+	WorkflowName string
+	StepID       string
+	RequestedAt  time.Time
+}
+
+// StepState is the persisted execution state of a workflow step
+type StepState struct {
+	Status     string
+	Outputs    map[string]interface{}
+	Error      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// IsFinished reports whether the step reached a final status
+func (s *StepState) IsFinished() bool {
+	switch s.Status {
+	case StepStatusSuccess, StepStatusFailure, StepStatusSkipped:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// TokenStorage persists API tokens by the hash of their value
+type TokenStorage interface {
+	AddToken(ctx context.Context, token *domain.APIToken) error
+	// GetTokenByHash returns the token with the given hash, revoked and expired ones included
+	GetTokenByHash(ctx context.Context, hash string) (*domain.APIToken, error)
+	ListTokens(ctx context.Context) ([]*domain.APIToken, error)
+	RevokeToken(ctx context.Context, id string, at time.Time) error
+	TouchToken(ctx context.Context, id string, at time.Time) error
+}
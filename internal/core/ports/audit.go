@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// AuditStorage persists the audit log
+type AuditStorage interface {
+	AddAuditEvent(ctx context.Context, event *domain.AuditEvent) error
+	// ListAuditEvents returns the events matching the filter, newest first
+	ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error)
+}
@@ -0,0 +1,55 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// CIProviderPort defines the provider-neutral interface for CI systems
+// such as GitHub Actions or GitLab CI
+type CIProviderPort interface {
+	// Name returns the provider identifier, e.g. "github" or "gitlab"
+	Name() string
+	// Host returns the repository host served by this provider, e.g. "github.com"
+	Host() string
+	// GetRepositoryDetails fetches repository details and its pipelines
+	GetRepositoryDetails(ctx context.Context, url string) (*domain.Repository, error)
+	// ListPipelines lists the pipelines declared by the repository
+	ListPipelines(ctx context.Context, url string) ([]domain.Pipeline, error)
+	// TriggerWorkflow triggers a pipeline run. When the provider can identify the run,
+	// its ID is returned as Details["run_id"] and its URL as Details["url"]. When the
+	// run is created later, Details["pending_run"] holds the reference to resolve it with.
+	TriggerWorkflow(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error)
+	// GetRunStatus reports the status of a pipeline run
+	GetRunStatus(ctx context.Context, url string, runID string) (*domain.WorkflowStatus, error)
+	// CancelRun cancels a pipeline run
+	CancelRun(ctx context.Context, url string, runID string) error
+	// RetryRun re-runs a pipeline run, or only its failed jobs when failedOnly is set
+	RetryRun(ctx context.Context, url string, runID string, failedOnly bool) (*domain.CommandResult, error)
+	// GetRunLogs fetches the job logs of a pipeline run
+	GetRunLogs(ctx context.Context, url string, runID string) ([]domain.JobLog, error)
+}
+
+// RunResolver is implemented by CI providers that create runs after accepting a trigger,
+// such as GitHub workflow dispatches or Jenkins queue items
+type RunResolver interface {
+	// ResolveRun returns the ID, URL and status of the run created for the pending_run
+	// reference of a trigger, or nil while the provider has not created it. It fails with
+	// a Conflict error when the run cannot be identified at all.
+	ResolveRun(ctx context.Context, url string, pendingRun string) (*domain.Run, error)
+}
+
+// PipelineInputPort is implemented by CI providers whose pipelines declare their inputs,
+// such as GitHub workflow_dispatch inputs
+type PipelineInputPort interface {
+	// GetPipelineInputs returns the inputs a pipeline declares at a ref, the default branch
+	// when empty, in declaration order
+	GetPipelineInputs(ctx context.Context, url, pipelinePath, ref string) ([]domain.PipelineInput, error)
+}
+
+// RefPort is implemented by CI providers that list the branches and tags of a repository
+type RefPort interface {
+	// ListRefs returns at most limit branch and tag names containing the query
+	ListRefs(ctx context.Context, url, query string, limit int) ([]string, error)
+}
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// DeploymentPort is implemented by CI providers that track deployments natively,
+// such as the GitHub Deployments API
+type DeploymentPort interface {
+	// CreateDeployment records a deployment on the provider and returns its ID
+	CreateDeployment(ctx context.Context, url string, deployment *domain.Deployment) (string, error)
+	// UpdateDeploymentStatus reports the status of a deployment on the provider
+	UpdateDeploymentStatus(ctx context.Context, url string, deployment *domain.Deployment) error
+}
+
+// DeploymentStorage persists the deployments triggered through ChatOps
+type DeploymentStorage interface {
+	AddDeployment(ctx context.Context, deployment *domain.Deployment) error
+	UpdateDeploymentStatus(ctx context.Context, id, status string) error
+	// SetDeploymentRun records the run that a pending run ID of a deployment resolved to
+	SetDeploymentRun(ctx context.Context, id, runID, url string) error
+	// ListCurrentDeployments returns the latest deployment of each environment of a repository
+	ListCurrentDeployments(ctx context.Context, repository string) ([]*domain.Deployment, error)
+	// ListPendingDeployments returns the deployments that did not reach a final status
+	ListPendingDeployments(ctx context.Context) ([]*domain.Deployment, error)
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// EventPublisher delivers ChatOps events to whoever is interested in them. Publishing
+// never fails the operation that caused the event.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *domain.Event)
+}
+
+// EventHandler reacts to the events it subscribed to. When the event bus has an outbox,
+// events a handler failed on are delivered again.
+type EventHandler interface {
+	HandleEvent(ctx context.Context, event *domain.Event) error
+}
+
+// EventOutbox persists published events until they were delivered to every handler
+type EventOutbox interface {
+	// AppendEvent stores an event and sets its ID. The event is claimed by the caller for
+	// the lease duration.
+	AppendEvent(ctx context.Context, event *domain.Event, lease time.Duration) error
+	// ClaimEvents claims up to limit undelivered events whose previous claim expired,
+	// oldest first
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*domain.Event, error)
+	MarkEventDelivered(ctx context.Context, id string) error
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// LockStorage persists deployment locks
+type LockStorage interface {
+	// AcquireLock stores the lock unless another owner holds an unexpired lock of the same
+	// repository environment. The lock in effect afterwards is returned.
+	AcquireLock(ctx context.Context, lock *domain.DeploymentLock) (*domain.DeploymentLock, error)
+	// GetLock returns the unexpired lock of a repository environment, or nil when unlocked
+	GetLock(ctx context.Context, repository, environment string) (*domain.DeploymentLock, error)
+	ReleaseLock(ctx context.Context, repository, environment string) error
+	// ListLocks returns every unexpired lock
+	ListLocks(ctx context.Context) ([]*domain.DeploymentLock, error)
+}
@@ -12,4 +12,5 @@ type RepositoryService interface {
 	ListRepositories(ctx context.Context) ([]*domain.Repository, error)
 	GetRepositoryPipelines(ctx context.Context, name string) ([]domain.Pipeline, error)
 	SetDefaultPipeline(ctx context.Context, repoName, pipelineName string) error
+	SetEnvironment(ctx context.Context, repoName string, env domain.Environment) error
 }
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// RoleStorage persists RBAC roles and the users assigned to them
+type RoleStorage interface {
+	// SaveRole creates a role or replaces its permissions, and assigns it to its users.
+	// Users assigned before keep the role.
+	SaveRole(ctx context.Context, role *domain.Role) error
+	ListRoles(ctx context.Context) ([]*domain.Role, error)
+}
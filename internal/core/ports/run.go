@@ -0,0 +1,30 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// RunStorage persists the pipeline runs triggered through ChatOps
+type RunStorage interface {
+	AddRun(ctx context.Context, run *domain.Run) error
+	GetRun(ctx context.Context, runID string) (*domain.Run, error)
+	// GetLatestRun returns the most recent run of a repository, optionally narrowed to one pipeline
+	GetLatestRun(ctx context.Context, repository, pipeline string) (*domain.Run, error)
+	UpdateRunStatus(ctx context.Context, runID, status string) error
+	// ListActiveRuns returns the runs created since the given time that have not finished
+	ListActiveRuns(ctx context.Context, since time.Time) ([]*domain.Run, error)
+	// TransitionRunStatus changes the status of a run, provided it still equals from.
+	// It returns false when another replica changed it first.
+	TransitionRunStatus(ctx context.Context, runID, from, to string) (bool, error)
+	// ResolvePendingRun replaces the ID of a pending run with the ID and URL of the run the
+	// provider created, provided it is still pending. It returns false when another replica
+	// resolved it first.
+	ResolvePendingRun(ctx context.Context, pendingID, runID, url string) (bool, error)
+	// ListUserRuns returns the most recent runs triggered by a user, newest first
+	ListUserRuns(ctx context.Context, userID string, limit int) ([]*domain.Run, error)
+	// ListRuns returns the most recent runs matching the filter, newest first
+	ListRuns(ctx context.Context, filter domain.RunFilter) ([]*domain.Run, error)
+}
@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// ScheduleStorage persists scheduled commands
+type ScheduleStorage interface {
+	AddSchedule(ctx context.Context, schedule *domain.Schedule) error
+	GetSchedule(ctx context.Context, id string) (*domain.Schedule, error)
+	ListSchedules(ctx context.Context) ([]*domain.Schedule, error)
+	DeleteSchedule(ctx context.Context, id string) error
+	// ListDueSchedules returns the schedules whose next run is at or before the given time
+	ListDueSchedules(ctx context.Context, at time.Time) ([]*domain.Schedule, error)
+	// ClaimScheduleRun moves the next run of a schedule forward, provided it still equals
+	// expectedNext. It returns false when the run was already claimed.
+	ClaimScheduleRun(ctx context.Context, id string, expectedNext, ranAt, next time.Time) (bool, error)
+}
+
+// LeaderLock elects a single leader among replicas
+type LeaderLock interface {
+	// TryAcquire returns whether this replica holds the lock, acquiring it when free
+	TryAcquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// Notifier posts messages to a chat channel
+type Notifier interface {
+	Notify(ctx context.Context, channelID, message string) error
+}
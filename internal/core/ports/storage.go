@@ -6,9 +6,36 @@ import (
 	"github.com/Tovli/chatops/internal/core/domain"
 )
 
+// RepositoryStorage stores repositories. Stored repositories start at version 1 and every
+// update increments it, setting Version and UpdatedAt of the saved repository.
 type RepositoryStorage interface {
 	AddRepository(ctx context.Context, repo *domain.Repository) error
 	GetRepository(ctx context.Context, name string) (*domain.Repository, error)
 	ListRepositories(ctx context.Context) ([]*domain.Repository, error)
+	// UpdateRepository saves a repository read at repo.Version, failing with
+	// domain.ErrConflict when it was updated since
 	UpdateRepository(ctx context.Context, repo *domain.Repository) error
+	// UpsertRepository adds a repository, or refreshes the default branch and pipelines of
+	// the stored one of the same name and URL when it is still at repo.Version, failing with
+	// domain.ErrConflict otherwise. A stored repository of the same name at another URL
+	// fails with domain.ErrAlreadyExists. The other fields of repo are set from the store.
+	UpsertRepository(ctx context.Context, repo *domain.Repository) error
+}
+
+// Storage holds every store of ChatOps. Each storage backend implements it. On every
+// backend lookups of missing records fail with an error matching both domain.ErrNotFound
+// and sql.ErrNoRows, and duplicate records with domain.ErrAlreadyExists.
+type Storage interface {
+	RepositoryStorage
+	RunStorage
+	DeploymentStorage
+	LockStorage
+	ScheduleStorage
+	SubscriptionStorage
+	WorkflowStorage
+	EventOutbox
+	WebhookStorage
+	AuditStorage
+	TokenStorage
+	RoleStorage
 }
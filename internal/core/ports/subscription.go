@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// SubscriptionStorage persists channel subscriptions and mutes
+type SubscriptionStorage interface {
+	AddSubscription(ctx context.Context, subscription *domain.Subscription) error
+	GetSubscription(ctx context.Context, id string) (*domain.Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	// ListSubscriptions returns the subscriptions of a repository, including those to
+	// every repository
+	ListSubscriptions(ctx context.Context, repository string) ([]*domain.Subscription, error)
+	ListChannelSubscriptions(ctx context.Context, platform, channelID string) ([]*domain.Subscription, error)
+	// MuteChannel mutes a channel, replacing any previous mute
+	MuteChannel(ctx context.Context, mute *domain.ChannelMute) error
+	UnmuteChannel(ctx context.Context, platform, channelID string) error
+	// GetChannelMute returns the mute of a channel, or nil when it was never muted
+	GetChannelMute(ctx context.Context, platform, channelID string) (*domain.ChannelMute, error)
+}
@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// WebhookStorage persists outgoing webhooks and their delivery log
+type WebhookStorage interface {
+	AddWebhook(ctx context.Context, webhook *domain.Webhook) error
+	GetWebhook(ctx context.Context, id string) (*domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error
+	// DeleteWebhook removes a webhook along with its deliveries
+	DeleteWebhook(ctx context.Context, id string) error
+
+	AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// ListDueDeliveries returns up to limit pending deliveries whose next attempt is at or
+	// before the given time, oldest first
+	ListDueDeliveries(ctx context.Context, at time.Time, limit int) ([]*domain.WebhookDelivery, error)
+	// ListDeliveries returns the latest deliveries of a webhook, newest first
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]*domain.WebhookDelivery, error)
+}
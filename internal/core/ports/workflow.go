@@ -8,6 +8,25 @@ import (
 
 // WorkflowPort defines the interface for workflow operations
 type WorkflowPort interface {
+	// ExecuteWorkflow starts an execution of the definition named by workflow.Name with
+	// workflow.Parameters as inputs, assigning workflow.ID
 	ExecuteWorkflow(ctx context.Context, workflow *domain.Workflow) error
 	GetWorkflowStatus(ctx context.Context, workflowID string) (*domain.WorkflowStatus, error)
+	// GetWorkflow returns a workflow execution with the state of its steps
+	GetWorkflow(ctx context.Context, workflowID string) (*domain.Workflow, error)
+	// ListDefinitions returns the workflows that can be started
+	ListDefinitions(ctx context.Context) []domain.WorkflowDefinition
+	// ResolveApproval approves or rejects a waiting approval step
+	ResolveApproval(ctx context.Context, workflowID, stepID string, user domain.User, approved bool) error
+	// ListPendingApprovals returns the waiting approval steps the user may resolve
+	ListPendingApprovals(ctx context.Context, user domain.User) ([]domain.PendingApproval, error)
+}
+
+// WorkflowStorage persists workflow executions
+type WorkflowStorage interface {
+	AddWorkflow(ctx context.Context, workflow *domain.Workflow) error
+	GetWorkflow(ctx context.Context, id string) (*domain.Workflow, error)
+	UpdateWorkflow(ctx context.Context, workflow *domain.Workflow) error
+	// ListActiveWorkflows returns the executions that are still running
+	ListActiveWorkflows(ctx context.Context) ([]*domain.Workflow, error)
 }
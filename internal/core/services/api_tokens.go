@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+// APITokenPrefix starts every API token, so leaked tokens are easy to scan for
+const APITokenPrefix = "chatops_"
+
+// ErrInvalidToken is returned for unknown, revoked and expired API tokens
+var ErrInvalidToken = errors.New("invalid API token")
+
+// TokenService issues and authenticates API tokens. Only the SHA-256 hash of a token is
+// stored, the token itself is returned once on creation.
+type TokenService struct {
+	logger  *zap.Logger
+	storage ports.TokenStorage
+	now     func() time.Time
+}
+
+type TokenServiceOptions struct {
+	Logger  *zap.Logger
+	Storage ports.TokenStorage
+	Clock   func() time.Time // Optional: defaults to time.Now
+}
+
+// NewTokenService creates a new instance of TokenService
+func NewTokenService(opts TokenServiceOptions) (*TokenService, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.Storage == nil {
+		return nil, fmt.Errorf("token storage is required")
+	}
+
+	service := &TokenService{
+		logger:  opts.Logger,
+		storage: opts.Storage,
+		now:     opts.Clock,
+	}
+	if service.now == nil {
+		service.now = time.Now
+	}
+
+	return service, nil
+}
+
+// CreateToken validates and stores a new token, returning its value
+func (s *TokenService) CreateToken(ctx context.Context, token *domain.APIToken) (string, error) {
+	if strings.TrimSpace(token.Name) == "" {
+		return "", domain.Invalid("name is required")
+	}
+	if strings.TrimSpace(token.UserID) == "" {
+		return "", domain.Invalid("user is required")
+	}
+	if err := domain.ValidateScopes(token.Scopes); err != nil {
+		return "", err
+	}
+	token.CreatedAt = s.now()
+	if !token.ExpiresAt.IsZero() && !token.ExpiresAt.After(token.CreatedAt) {
+		return "", domain.Invalid("expiry must be in the future")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	value := APITokenPrefix + hex.EncodeToString(secret)
+	token.Hash = HashToken(value)
+
+	if err := s.storage.AddToken(ctx, token); err != nil {
+		return "", fmt.Errorf("failed to add token: %w", err)
+	}
+	return value, nil
+}
+
+// Authenticate returns the valid token with the given value
+func (s *TokenService) Authenticate(ctx context.Context, value string) (*domain.APIToken, error) {
+	if !strings.HasPrefix(value, APITokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := s.storage.GetTokenByHash(ctx, HashToken(value))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	now := s.now()
+	if !token.IsValid(now) {
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.storage.TouchToken(ctx, token.ID, now); err != nil {
+		s.logger.Warn("failed to record token use", zap.String("token_id", token.ID), zap.Error(err))
+	}
+	token.LastUsedAt = now
+	return token, nil
+}
+
+func (s *TokenService) ListTokens(ctx context.Context) ([]*domain.APIToken, error) {
+	tokens, err := s.storage.ListTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *TokenService) RevokeToken(ctx context.Context, id string) error {
+	if err := s.storage.RevokeToken(ctx, id, s.now()); err != nil {
+		return fmt.Errorf("failed to revoke token %s: %w", id, err)
+	}
+	return nil
+}
+
+// HashToken returns the hex SHA-256 of a token value, as stored
+func HashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
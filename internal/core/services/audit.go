@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+// AuditLog records the events of the event bus. command.received is not recorded since
+// command.completed carries the same command along with its outcome.
+type AuditLog struct {
+	logger  *zap.Logger
+	storage ports.AuditStorage
+}
+
+type AuditLogOptions struct {
+	Logger  *zap.Logger
+	Storage ports.AuditStorage
+}
+
+// NewAuditLog creates a new instance of AuditLog
+func NewAuditLog(opts AuditLogOptions) (*AuditLog, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.Storage == nil {
+		return nil, fmt.Errorf("audit storage is required")
+	}
+
+	return &AuditLog{
+		logger:  opts.Logger,
+		storage: opts.Storage,
+	}, nil
+}
+
+// HandleEvent records an event
+func (l *AuditLog) HandleEvent(ctx context.Context, event *domain.Event) error {
+	if event.Type == domain.EventCommandReceived {
+		return nil
+	}
+
+	entry := &domain.AuditEvent{
+		Type:       event.Type,
+		Repository: event.Repository,
+		Actor:      event.Actor,
+		Message:    event.Message,
+		OccurredAt: event.OccurredAt,
+	}
+	if event.Payload != nil {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
+		entry.Payload = payload
+	}
+
+	if err := l.storage.AddAuditEvent(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// ListEvents returns the recorded events matching the filter, newest first
+func (l *AuditLog) ListEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	if filter.Type != "" {
+		if err := domain.ValidateEventFilter(filter.Type); err != nil {
+			return nil, err
+		}
+	}
+
+	events, err := l.storage.ListAuditEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// AdminUser is the user the admin token acts as
+const AdminUser = "api"
+
+// Authenticator resolves the bearer tokens of the HTTP and gRPC APIs, either the admin
+// token, which acts as AdminUser with every scope, or an API token
+type Authenticator struct {
+	adminToken string
+	tokens     *TokenService
+}
+
+type AuthenticatorOptions struct {
+	AdminToken string        // Optional: only API tokens authenticate when empty
+	Tokens     *TokenService // Optional: only the admin token authenticates when nil
+}
+
+// NewAuthenticator creates a new instance of Authenticator
+func NewAuthenticator(opts AuthenticatorOptions) *Authenticator {
+	return &Authenticator{
+		adminToken: opts.AdminToken,
+		tokens:     opts.Tokens,
+	}
+}
+
+// Enabled reports whether any token can authenticate
+func (a *Authenticator) Enabled() bool {
+	return a.adminToken != "" || a.tokens != nil
+}
+
+// Authenticate returns the token with the given value, failing with ErrInvalidToken for
+// unknown, revoked and expired tokens. The admin token has no ID.
+func (a *Authenticator) Authenticate(ctx context.Context, value string) (*domain.APIToken, error) {
+	if value == "" {
+		return nil, ErrInvalidToken
+	}
+	if a.adminToken != "" && subtle.ConstantTimeCompare([]byte(value), []byte(a.adminToken)) == 1 {
+		return &domain.APIToken{Name: "admin", UserID: AdminUser, Scopes: domain.AllScopes}, nil
+	}
+	if a.tokens == nil {
+		return nil, ErrInvalidToken
+	}
+	return a.tokens.Authenticate(ctx, value)
+}
@@ -3,6 +3,10 @@ package services
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Tovli/chatops/internal/core/domain"
 	"github.com/Tovli/chatops/internal/core/ports"
@@ -11,49 +15,255 @@ import (
 )
 
 type CommandProcessor struct {
-	logger      *zap.Logger
-	rbac        *rbac.Service
-	workflow    ports.WorkflowPort
-	audit       *ports.AuditService
-	repoService ports.RepositoryService
-	githubPort  ports.GitHubPort
+	logger        *zap.Logger
+	rbac          *rbac.Service
+	remoteOpen    bool
+	workflows     ports.WorkflowPort
+	repoService   ports.RepositoryService
+	providers     *ProviderRegistry
+	runs          ports.RunStorage
+	deployments   ports.DeploymentStorage
+	guard         *DeploymentGuard
+	schedules     ports.ScheduleStorage
+	subscriptions ports.SubscriptionStorage
+	events        ports.EventPublisher
+	audit         ports.AuditStorage
+}
+
+type CommandProcessorOptions struct {
+	Logger        *zap.Logger
+	RepoService   ports.RepositoryService
+	Providers     *ProviderRegistry         // Optional: may be empty when no CI provider is configured
+	Runs          ports.RunStorage          // Optional: enables run tracking, cancel and re-run
+	Deployments   ports.DeploymentStorage   // Optional: enables deployment history for envs
+	Guard         *DeploymentGuard          // Optional: enables deployment locks and freeze windows
+	Schedules     ports.ScheduleStorage     // Optional: enables scheduled commands
+	Subscriptions ports.SubscriptionStorage // Optional: enables channel subscriptions
+	Events        ports.EventPublisher      // Optional: receives the domain events of commands, runs, repositories and locks
+	Audit         ports.AuditStorage        // Optional: enables the recent commands of dashboards
+	RBAC          *rbac.Service             // Optional: every command is allowed when nil
+	// Optional: lets API and gRPC users run every command when RBAC is nil; they are
+	// denied any command otherwise
+	RemoteWithoutRBAC bool
 }
 
 // NewCommandProcessor creates a new instance of CommandProcessor
-func NewCommandProcessor(logger *zap.Logger, repoService ports.RepositoryService, githubPort ports.GitHubPort) (*CommandProcessor, error) {
-	if logger == nil {
+func NewCommandProcessor(opts CommandProcessorOptions) (*CommandProcessor, error) {
+	if opts.Logger == nil {
 		return nil, fmt.Errorf("logger is required")
 	}
-	if repoService == nil {
+	if opts.RepoService == nil {
 		return nil, fmt.Errorf("repository service is required")
 	}
-	if githubPort == nil {
-		return nil, fmt.Errorf("github port is required")
+
+	providers := opts.Providers
+	if providers == nil {
+		providers = NewProviderRegistry()
 	}
 
 	return &CommandProcessor{
-		logger:      logger,
-		repoService: repoService,
-		githubPort:  githubPort,
-		// Note: rbac, workflow, and audit services are optional and can be initialized later if needed
+		logger:        opts.Logger,
+		repoService:   opts.RepoService,
+		providers:     providers,
+		runs:          opts.Runs,
+		deployments:   opts.Deployments,
+		guard:         opts.Guard,
+		schedules:     opts.Schedules,
+		subscriptions: opts.Subscriptions,
+		events:        opts.Events,
+		audit:         opts.Audit,
+		rbac:          opts.RBAC,
+		remoteOpen:    opts.RemoteWithoutRBAC,
+		// Note: the workflow engine is optional and set later, see SetWorkflows
 	}, nil
 }
 
+// SetWorkflows enables the workflow commands. The engine is set after construction since
+// it runs its steps through the processor.
+func (cp *CommandProcessor) SetWorkflows(workflows ports.WorkflowPort) {
+	cp.workflows = workflows
+}
+
+// ListRepositories lists the managed repositories, for platforms offering them as choices
+func (cp *CommandProcessor) ListRepositories(ctx context.Context) ([]*domain.Repository, error) {
+	return cp.repoService.ListRepositories(ctx)
+}
+
+// ListPipelines lists the pipelines of a managed repository
+func (cp *CommandProcessor) ListPipelines(ctx context.Context, repoName string) ([]domain.Pipeline, error) {
+	return cp.repoService.GetRepositoryPipelines(ctx, repoName)
+}
+
+// GetPipelineInputs returns the inputs declared by a pipeline of a managed repository, or
+// by its default pipeline when pipelineName is empty. Pipelines of providers that do not
+// declare inputs have none.
+func (cp *CommandProcessor) GetPipelineInputs(ctx context.Context, repoName, pipelineName string) ([]domain.PipelineInput, error) {
+	repo, err := cp.repoService.GetRepository(ctx, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	var pipeline *domain.Pipeline
+	if pipelineName != "" {
+		pipeline = findPipeline(repo, pipelineName)
+	} else {
+		for i := range repo.Pipelines {
+			if repo.Pipelines[i].IsDefault {
+				pipeline = &repo.Pipelines[i]
+				break
+			}
+		}
+	}
+	if pipeline == nil {
+		return nil, nil
+	}
+
+	provider, err := cp.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+	port, ok := provider.(ports.PipelineInputPort)
+	if !ok {
+		return nil, nil
+	}
+	return port.GetPipelineInputs(ctx, repo.URL, pipeline.Path, "")
+}
+
+// ListRefs lists at most limit branches and tags of a managed repository containing the
+// query, none when its provider cannot list them
+func (cp *CommandProcessor) ListRefs(ctx context.Context, repoName, query string, limit int) ([]string, error) {
+	repo, err := cp.repoService.GetRepository(ctx, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+	provider, err := cp.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+	port, ok := provider.(ports.RefPort)
+	if !ok {
+		return nil, nil
+	}
+	return port.ListRefs(ctx, repo.URL, query, limit)
+}
+
+// ListRuns lists the recorded runs matching the filter, none when run tracking is not
+// configured
+func (cp *CommandProcessor) ListRuns(ctx context.Context, filter domain.RunFilter) ([]*domain.Run, error) {
+	if cp.runs == nil {
+		return nil, nil
+	}
+	return cp.runs.ListRuns(ctx, filter)
+}
+
+// GetRun returns a recorded run. It fails with domain.ErrNotFound when the run is unknown
+// or run tracking is not configured.
+func (cp *CommandProcessor) GetRun(ctx context.Context, runID string) (*domain.Run, error) {
+	if cp.runs == nil {
+		return nil, domain.NotFound("run %s not found", runID)
+	}
+	return cp.runs.GetRun(ctx, runID)
+}
+
+// ListWorkflowDefinitions lists the workflows that can be started, none when workflows
+// are not configured
+func (cp *CommandProcessor) ListWorkflowDefinitions(ctx context.Context) []domain.WorkflowDefinition {
+	if cp.workflows == nil {
+		return nil
+	}
+	return cp.workflows.ListDefinitions(ctx)
+}
+
+// ProcessCommand runs a command, publishing command.received before and command.completed
+// after it
 func (cp *CommandProcessor) ProcessCommand(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	repository, _ := cmd.Parameters["repository_name"].(string)
+	started := time.Now()
+	cp.publish(ctx, &domain.Event{
+		Type:       domain.EventCommandReceived,
+		Repository: repository,
+		Actor:      cmd.User.ID,
+		Payload:    &domain.CommandReceived{Command: cmd},
+	})
+
+	result, err := cp.processCommand(ctx, cmd)
+
+	completed := &domain.CommandCompleted{Command: cmd, Duration: time.Since(started)}
+	switch {
+	case err != nil:
+		completed.Status = "failed"
+		completed.Error = err.Error()
+	case result != nil:
+		completed.Status = result.Status
+		completed.Message = result.Message
+	}
+	cp.publish(ctx, &domain.Event{
+		Type:       domain.EventCommandCompleted,
+		Repository: repository,
+		Actor:      cmd.User.ID,
+		Payload:    completed,
+	})
+
+	return result, err
+}
+
+func (cp *CommandProcessor) processCommand(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	// Chat users may run every command while no role is configured, but tokens reach the
+	// server remotely and only run commands once roles restrict them
+	if cp.rbac == nil && !cp.remoteOpen && isRemoteUser(cmd.User) {
+		return nil, domain.Forbidden("commands over the API are denied until roles are configured")
+	}
+
 	switch cmd.Type {
 	case domain.CommandTypeManageRepo:
 		return cp.handleManageRepository(ctx, cmd)
 	case domain.CommandTypeVerifyRepo:
 		return cp.handleVerifyRepository(ctx, cmd)
+	case domain.CommandTypeCancelRun, domain.CommandTypeRerunRun, domain.CommandTypeRerunFailed:
+		return cp.handleRunControl(ctx, cmd)
+	case domain.CommandTypeRunLogs:
+		return cp.handleRunLogs(ctx, cmd)
+	case domain.CommandTypeDeploy:
+		return cp.handleDeploy(ctx, cmd)
+	case domain.CommandTypeListEnvs:
+		return cp.handleListEnvironments(ctx, cmd)
+	case domain.CommandTypeSetEnv:
+		return cp.handleSetEnvironment(ctx, cmd)
+	case domain.CommandTypeLock:
+		return cp.handleLock(ctx, cmd)
+	case domain.CommandTypeUnlock:
+		return cp.handleUnlock(ctx, cmd)
+	case domain.CommandTypeAddSchedule:
+		return cp.handleAddSchedule(ctx, cmd)
+	case domain.CommandTypeListSchedules:
+		return cp.handleListSchedules(ctx, cmd)
+	case domain.CommandTypeRemoveSchedule:
+		return cp.handleRemoveSchedule(ctx, cmd)
+	case domain.CommandTypeStartWorkflow:
+		return cp.handleStartWorkflow(ctx, cmd)
+	case domain.CommandTypeWorkflowStatus:
+		return cp.handleWorkflowStatus(ctx, cmd)
+	case domain.CommandTypeListWorkflows:
+		return cp.handleListWorkflows(ctx, cmd)
+	case domain.CommandTypeApproveStep, domain.CommandTypeRejectStep:
+		return cp.handleResolveApproval(ctx, cmd)
+	case domain.CommandTypeSubscribe:
+		return cp.handleSubscribe(ctx, cmd)
+	case domain.CommandTypeUnsubscribe:
+		return cp.handleUnsubscribe(ctx, cmd)
+	case domain.CommandTypeListSubscriptions:
+		return cp.handleListSubscriptions(ctx, cmd)
+	case domain.CommandTypeMuteChannel, domain.CommandTypeUnmuteChannel:
+		return cp.handleMuteChannel(ctx, cmd)
 	default:
-		return nil, fmt.Errorf("unknown command type: %s", cmd.Type)
+		return nil, domain.Invalid("unknown command type: %s", cmd.Type)
 	}
 }
 
 func (cp *CommandProcessor) handleManageRepository(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
 	repoCmd, ok := cmd.Parameters["repository_url"].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid repository URL")
+		return nil, domain.Invalid("invalid repository URL")
 	}
 
 	repo := &domain.Repository{
@@ -65,6 +275,20 @@ func (cp *CommandProcessor) handleManageRepository(ctx context.Context, cmd *dom
 	if err := cp.repoService.AddRepository(ctx, repo); err != nil {
 		return nil, fmt.Errorf("failed to add repository: %w", err)
 	}
+	// Adding a managed repository again only refreshes it, which is no news to subscribers
+	if repo.Version > 1 {
+		return &domain.CommandResult{
+			Status:  "success",
+			Message: fmt.Sprintf("Repository %s was already managed and has been refreshed", repo.Name),
+		}, nil
+	}
+	cp.publish(ctx, &domain.Event{
+		Type:       domain.EventRepositoryAdded,
+		Repository: repo.Name,
+		Actor:      cmd.User.ID,
+		Message:    fmt.Sprintf("Repository %s was added by %s", repo.Name, cmd.User.ID),
+		Payload:    &domain.RepositoryAdded{Repository: repo},
+	})
 
 	return &domain.CommandResult{
 		Status:  "success",
@@ -75,7 +299,7 @@ func (cp *CommandProcessor) handleManageRepository(ctx context.Context, cmd *dom
 func (cp *CommandProcessor) handleVerifyRepository(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
 	repoName, ok := cmd.Parameters["repository_name"].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid repository name")
+		return nil, domain.Invalid("invalid repository name")
 	}
 
 	repo, err := cp.repoService.GetRepository(ctx, repoName)
@@ -90,6 +314,22 @@ func (cp *CommandProcessor) handleVerifyRepository(ctx context.Context, cmd *dom
 		}, nil
 	}
 
+	// Runs on the provider default branch unless a ref is given
+	ref, _ := cmd.Parameters["ref"].(string)
+	inputs, _ := cmd.Parameters["inputs"].(map[string]interface{})
+
+	// An explicitly named pipeline takes precedence over the default one
+	if pipelineName, _ := cmd.Parameters["pipeline_name"].(string); pipelineName != "" {
+		pipeline := findPipeline(repo, pipelineName)
+		if pipeline == nil {
+			return &domain.CommandResult{
+				Status:  "error",
+				Message: fmt.Sprintf("Pipeline %s not found in %s", pipelineName, repo.Name),
+			}, nil
+		}
+		return cp.triggerPipeline(ctx, cmd, repo, pipeline, pipelineRun{runType: "verification", ref: ref, params: inputs})
+	}
+
 	var defaultPipeline *domain.Pipeline
 	for _, p := range repo.Pipelines {
 		if p.IsDefault {
@@ -108,9 +348,377 @@ func (cp *CommandProcessor) handleVerifyRepository(ctx context.Context, cmd *dom
 	}
 
 	// Trigger default pipeline
-	return cp.githubPort.TriggerWorkflow(ctx, &domain.WorkflowTrigger{
+	return cp.triggerPipeline(ctx, cmd, repo, defaultPipeline, pipelineRun{runType: "verification", ref: ref, params: inputs})
+}
+
+// pipelineRun describes how a pipeline is triggered
+type pipelineRun struct {
+	runType     string
+	ref         string // Optional: the provider default branch when empty
+	environment string // Optional: set for deployments
+	params      map[string]interface{}
+	guarded     bool // Set when the caller already checked locks and freeze windows
+}
+
+// triggerPipeline authorizes the user, checks locks and freeze windows, triggers the pipeline
+// on the provider owning the repository and records the resulting run when the provider
+// reports its ID
+func (cp *CommandProcessor) triggerPipeline(ctx context.Context, cmd *domain.Command, repo *domain.Repository, pipeline *domain.Pipeline, run pipelineRun) (*domain.CommandResult, error) {
+	if !cp.isAllowed(ctx, cmd.User, triggerPermission(run.runType)) {
+		return nil, forbidden(fmt.Sprintf("run %s on %s", pipeline.Name, repo.Name))
+	}
+
+	if !run.guarded {
+		if blocked, err := cp.checkGuard(ctx, cmd, repo.Name, run.environment); blocked != nil || err != nil {
+			return blocked, err
+		}
+	}
+
+	provider, err := cp.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot run pipelines for %s: %v", repo.Name, err),
+		}, nil
+	}
+
+	// Trigger the pipeline on the provider that owns the repository
+	result, err := provider.TriggerWorkflow(ctx, &domain.WorkflowTrigger{
+		Repository: repo.URL,
+		Workflow:   pipeline.Path,
+		Type:       run.runType,
+		Ref:        run.ref,
+		Parameters: run.params,
+	})
+	if err != nil || result.Status != "success" {
+		return result, err
+	}
+
+	triggered := &domain.Run{
+		Provider:    provider.Name(),
+		Repository:  repo.Name,
+		Pipeline:    pipeline.Name,
+		Environment: run.environment,
+		Type:        run.runType,
+		Status:      domain.RunStatusQueued,
+		TriggeredBy: cmd.User.ID,
+		CreatedAt:   time.Now(),
+	}
+	cp.recordRun(ctx, triggered, result)
+	cp.publish(ctx, &domain.Event{
+		Type:       domain.EventRunTriggered,
 		Repository: repo.Name,
-		Workflow:   defaultPipeline.Path,
-		Type:       "verification",
+		Actor:      cmd.User.ID,
+		Payload:    &domain.WorkflowTriggered{Run: triggered},
 	})
+
+	return result, nil
+}
+
+// recordRun sets the run ID and URL reported by the provider and stores the run when
+// there is an ID. Runs the provider creates later are stored under a pending ID, which
+// the run tracker resolves. Failures are logged only, since the pipeline has already
+// been triggered.
+func (cp *CommandProcessor) recordRun(ctx context.Context, run *domain.Run, result *domain.CommandResult) {
+	details, _ := result.Details.(map[string]interface{})
+	runID, _ := details["run_id"].(string)
+	if pending, _ := details["pending_run"].(string); runID == "" && pending != "" {
+		runID = domain.PendingRunID(pending)
+	}
+	run.ID = runID
+	run.URL, _ = details["url"].(string)
+	if cp.runs == nil || runID == "" {
+		return
+	}
+
+	if err := cp.runs.AddRun(ctx, run); err != nil {
+		cp.logger.Error("failed to record run",
+			zap.String("run_id", runID),
+			zap.String("repository", run.Repository),
+			zap.Error(err))
+	}
+}
+
+// handleRunControl cancels or re-runs a previously triggered run
+func (cp *CommandProcessor) handleRunControl(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.runs == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: "Run history is not configured",
+		}, nil
+	}
+
+	run, err := cp.resolveRun(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	// Controlling a run requires the same permission as triggering it
+	if !cp.isAllowed(ctx, cmd.User, triggerPermission(run.Type)) {
+		return nil, forbidden(fmt.Sprintf("control run %s", run.ID))
+	}
+	if domain.IsPendingRunID(run.ID) {
+		return runNotStarted(run), nil
+	}
+
+	repo, err := cp.repoService.GetRepository(ctx, run.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	provider, err := cp.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot control runs for %s: %v", repo.Name, err),
+		}, nil
+	}
+
+	switch cmd.Type {
+	case domain.CommandTypeCancelRun:
+		if err := provider.CancelRun(ctx, repo.URL, run.ID); err != nil {
+			return &domain.CommandResult{
+				Status:  "error",
+				Message: fmt.Sprintf("Failed to cancel run %s: %v", run.ID, err),
+			}, nil
+		}
+		if err := cp.runs.UpdateRunStatus(ctx, run.ID, domain.RunStatusCancelling); err != nil {
+			cp.logger.Error("failed to update run status", zap.String("run_id", run.ID), zap.Error(err))
+		}
+		return &domain.CommandResult{
+			Status:  "success",
+			Message: fmt.Sprintf("Cancellation of run %s of %s on %s requested", run.ID, run.Pipeline, run.Repository),
+		}, nil
+	default:
+		if blocked, err := cp.checkGuard(ctx, cmd, run.Repository, run.Environment); blocked != nil || err != nil {
+			return blocked, err
+		}
+
+		result, err := provider.RetryRun(ctx, repo.URL, run.ID, cmd.Type == domain.CommandTypeRerunFailed)
+		if err != nil || result.Status != "success" {
+			return result, err
+		}
+
+		// Providers that start a new run for a re-run report a different or pending run ID
+		rerun := *run
+		rerun.Status = domain.RunStatusQueued
+		rerun.TriggeredBy = cmd.User.ID
+		rerun.CreatedAt = time.Now()
+		details, _ := result.Details.(map[string]interface{})
+		newRunID, _ := details["run_id"].(string)
+		pending, _ := details["pending_run"].(string)
+		if (newRunID != "" && newRunID != run.ID) || pending != "" {
+			cp.recordRun(ctx, &rerun, result)
+		} else if err := cp.runs.UpdateRunStatus(ctx, run.ID, domain.RunStatusQueued); err != nil {
+			cp.logger.Error("failed to update run status", zap.String("run_id", run.ID), zap.Error(err))
+		}
+		cp.publish(ctx, &domain.Event{
+			Type:       domain.EventRunTriggered,
+			Repository: rerun.Repository,
+			Actor:      cmd.User.ID,
+			Payload:    &domain.WorkflowTriggered{Run: &rerun},
+		})
+		return result, nil
+	}
+}
+
+// handleRunLogs fetches the logs of a run and summarizes the failing job
+func (cp *CommandProcessor) handleRunLogs(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.runs == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: "Run history is not configured",
+		}, nil
+	}
+
+	run, err := cp.resolveRun(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	// Logs may hold deployment secrets, so they need the permission to run the pipeline
+	if !cp.isAllowed(ctx, cmd.User, triggerPermission(run.Type)) {
+		return nil, forbidden(fmt.Sprintf("read logs of run %s", run.ID))
+	}
+	lines, err := intParameter(cmd.Parameters, "lines")
+	if err != nil {
+		return nil, err
+	}
+	if domain.IsPendingRunID(run.ID) {
+		return runNotStarted(run), nil
+	}
+
+	repo, err := cp.repoService.GetRepository(ctx, run.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	provider, err := cp.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot fetch logs for %s: %v", repo.Name, err),
+		}, nil
+	}
+
+	logs, err := provider.GetRunLogs(ctx, repo.URL, run.ID)
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to fetch logs of run %s: %v", run.ID, err),
+		}, nil
+	}
+
+	job := failingJob(logs)
+	if job == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("No job logs found for run %s", run.ID),
+		}, nil
+	}
+
+	summary := &domain.LogSummary{
+		RunID:      run.ID,
+		Repository: run.Repository,
+		Pipeline:   run.Pipeline,
+		URL:        run.URL,
+		Job:        job.Name,
+		FailedStep: job.FailedStep,
+		Excerpt:    logExcerpt(job.Content, lines),
+		FullLog:    job.Content,
+	}
+
+	message := fmt.Sprintf("Logs of job %s in run %s of %s on %s", job.Name, run.ID, run.Pipeline, run.Repository)
+	if job.FailedStep != "" {
+		message = fmt.Sprintf("Step %q failed in job %s of run %s (%s on %s)", job.FailedStep, job.Name, run.ID, run.Pipeline, run.Repository)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: message,
+		Details: summary,
+	}, nil
+}
+
+// resolveRun finds the run referenced by the command, either by its ID or as the
+// latest run of a repository and optional pipeline
+func (cp *CommandProcessor) resolveRun(ctx context.Context, cmd *domain.Command) (*domain.Run, error) {
+	if runID, ok := cmd.Parameters["run_id"].(string); ok && runID != "" {
+		run, err := cp.runs.GetRun(ctx, runID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get run %s: %w", runID, err)
+		}
+		return run, nil
+	}
+
+	repoName, ok := cmd.Parameters["repository_name"].(string)
+	if !ok || repoName == "" {
+		return nil, domain.Invalid("a run ID or repository name is required")
+	}
+	pipelineName, _ := cmd.Parameters["pipeline_name"].(string)
+
+	run, err := cp.runs.GetLatestRun(ctx, repoName, pipelineName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest run of %s: %w", repoName, err)
+	}
+	return run, nil
+}
+
+// runNotStarted answers commands about a run its provider has not created yet
+func runNotStarted(run *domain.Run) *domain.CommandResult {
+	return &domain.CommandResult{
+		Status:  "error",
+		Message: fmt.Sprintf("The run of %s on %s has not started yet, please try again shortly", run.Pipeline, run.Repository),
+	}
+}
+
+// checkGuard returns an error result when a deployment lock or freeze window blocks the
+// trigger. Users allowed to override the guard may bypass it with the force parameter.
+func (cp *CommandProcessor) checkGuard(ctx context.Context, cmd *domain.Command, repository, environment string) (*domain.CommandResult, error) {
+	if cp.guard == nil {
+		return nil, nil
+	}
+
+	reason, err := cp.guard.Check(ctx, repository, environment, cmd.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return nil, nil
+	}
+
+	if force, _ := cmd.Parameters["force"].(bool); force {
+		if !cp.isAllowed(ctx, cmd.User, domain.PermissionOverrideGuard) {
+			return nil, forbidden(fmt.Sprintf("override the guard (%s)", reason))
+		}
+		cp.logger.Warn("deployment guard overridden",
+			zap.String("user_id", cmd.User.ID),
+			zap.String("repository", repository),
+			zap.String("environment", environment),
+			zap.String("reason", reason))
+		return nil, nil
+	}
+
+	return &domain.CommandResult{
+		Status:  "error",
+		Message: fmt.Sprintf("Blocked: %s. Admins may override with --force", reason),
+	}, nil
+}
+
+// isAllowed checks a permission through RBAC; every user is allowed when RBAC is not configured
+func (cp *CommandProcessor) isAllowed(ctx context.Context, user domain.User, permission string) bool {
+	if cp.rbac == nil {
+		return true
+	}
+	return cp.rbac.IsAllowed(ctx, user, permission)
+}
+
+// isRemoteUser reports whether a user acts through an API token rather than chat
+func isRemoteUser(user domain.User) bool {
+	return user.Platform == "api" || user.Platform == "grpc"
+}
+
+// intParameter reads an optional integer parameter. Chat adapters pass ints, while JSON
+// bodies of the REST API decode numbers as float64 and may quote them.
+func intParameter(params map[string]interface{}, name string) (int, error) {
+	switch value := params[name].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return value, nil
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, domain.Invalid("%s must be a whole number", name)
+		}
+		return int(value), nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, domain.Invalid("%s must be a number", name)
+		}
+		return n, nil
+	default:
+		return 0, domain.Invalid("%s must be a number", name)
+	}
+}
+
+// triggerPermission returns the permission required to trigger and control runs of a type
+func triggerPermission(runType string) string {
+	if runType == runTypeDeployment {
+		return domain.PermissionDeploy
+	}
+	return domain.PermissionTriggerPipeline
+}
+
+// publish hands an event to the event publisher, when one is configured
+func (cp *CommandProcessor) publish(ctx context.Context, event *domain.Event) {
+	if cp.events == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	cp.events.Publish(ctx, event)
+}
+
+func forbidden(action string) error {
+	return domain.Forbidden("you are not allowed to %s", action)
 }
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+// dashboardRunLimit is the number of recent runs shown on a dashboard
+const dashboardRunLimit = 10
+
+// dashboardCommandLimit is the number of recent commands shown on a dashboard
+const dashboardCommandLimit = 10
+
+// Dashboard collects the recent runs and commands of the user, the repositories they can act on, the
+// approvals waiting for them and the active deployment locks. Parts whose storage is not
+// configured are left empty.
+func (cp *CommandProcessor) Dashboard(ctx context.Context, user domain.User) (*domain.Dashboard, error) {
+	dashboard := &domain.Dashboard{
+		User:       user,
+		CanTrigger: cp.isAllowed(ctx, user, domain.PermissionTriggerPipeline),
+		CanDeploy:  cp.isAllowed(ctx, user, domain.PermissionDeploy),
+	}
+
+	if cp.runs != nil {
+		runs, err := cp.runs.ListUserRuns(ctx, user.ID, dashboardRunLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list runs: %w", err)
+		}
+		dashboard.RecentRuns = runs
+	}
+
+	if cp.audit != nil {
+		commands, err := cp.recentCommands(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.RecentCommands = commands
+	}
+
+	if dashboard.CanTrigger || dashboard.CanDeploy {
+		repositories, err := cp.repoService.ListRepositories(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		dashboard.Repositories = repositories
+	}
+
+	if cp.workflows != nil && cp.isAllowed(ctx, user, domain.PermissionApproveWorkflow) {
+		approvals, err := cp.workflows.ListPendingApprovals(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pending approvals: %w", err)
+		}
+		dashboard.PendingApprovals = approvals
+	}
+
+	if cp.guard != nil {
+		locks, err := cp.guard.ListLocks(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list locks: %w", err)
+		}
+		dashboard.Locks = locks
+	}
+
+	return dashboard, nil
+}
+
+// recentCommands reads the latest commands of the user back from the audit log
+func (cp *CommandProcessor) recentCommands(ctx context.Context, user domain.User) ([]domain.RecentCommand, error) {
+	events, err := cp.audit.ListAuditEvents(ctx, domain.AuditFilter{
+		Type:  domain.EventCommandCompleted,
+		Actor: user.ID,
+		Limit: dashboardCommandLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commands: %w", err)
+	}
+
+	commands := make([]domain.RecentCommand, 0, len(events))
+	for _, event := range events {
+		var completed domain.CommandCompleted
+		if err := json.Unmarshal(event.Payload, &completed); err != nil || completed.Command == nil {
+			cp.logger.Warn("skipping undecodable command event", zap.String("event_id", event.ID), zap.Error(err))
+			continue
+		}
+		commands = append(commands, domain.RecentCommand{
+			Type:       completed.Command.Type,
+			Repository: event.Repository,
+			Status:     completed.Status,
+			RanAt:      event.OccurredAt,
+		})
+	}
+	return commands, nil
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+// runTypeDeployment is the run type of pipelines triggered by deploy commands
+const runTypeDeployment = "deployment"
+
+// handleDeploy deploys a ref of a repository to an environment by creating a provider
+// deployment, when supported, and dispatching the pipeline mapped to the environment
+func (cp *CommandProcessor) handleDeploy(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	repoName, _ := cmd.Parameters["repository_name"].(string)
+	envName, _ := cmd.Parameters["environment"].(string)
+	if repoName == "" || envName == "" {
+		return nil, domain.Invalid("repository name and environment are required")
+	}
+
+	repo, err := cp.repoService.GetRepository(ctx, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	env := repo.Environment(envName)
+	if env == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Environment %s is not configured for %s. Available environments: %s", envName, repo.Name, environmentNames(repo)),
+		}, nil
+	}
+
+	pipeline := findPipeline(repo, env.Pipeline)
+	if pipeline == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Pipeline %s of environment %s no longer exists in %s", env.Pipeline, env.Name, repo.Name),
+		}, nil
+	}
+
+	ref, _ := cmd.Parameters["ref"].(string)
+	if ref == "" {
+		ref = env.Ref
+	}
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionDeploy) {
+		return nil, forbidden(fmt.Sprintf("deploy %s to %s", repo.Name, env.Name))
+	}
+
+	// Checked before the provider deployment is created, rather than on dispatch
+	if blocked, err := cp.checkGuard(ctx, cmd, repo.Name, env.Name); blocked != nil || err != nil {
+		return blocked, err
+	}
+
+	provider, err := cp.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot deploy %s: %v", repo.Name, err),
+		}, nil
+	}
+
+	deployment := &domain.Deployment{
+		Repository:  repo.Name,
+		Environment: env.Name,
+		Ref:         ref,
+		Status:      domain.DeploymentStatusQueued,
+		DeployedBy:  cmd.User.ID,
+		CreatedAt:   time.Now(),
+	}
+
+	// Providers with native deployments track the deployment alongside the run
+	tracker, tracked := provider.(ports.DeploymentPort)
+	if tracked {
+		id, err := tracker.CreateDeployment(ctx, repo.URL, deployment)
+		if err != nil {
+			return &domain.CommandResult{
+				Status:  "error",
+				Message: fmt.Sprintf("Failed to create deployment of %s to %s: %v", repo.Name, env.Name, err),
+			}, nil
+		}
+		deployment.ProviderDeploymentID = id
+	}
+
+	result, err := cp.triggerPipeline(ctx, cmd, repo, pipeline, pipelineRun{
+		runType:     runTypeDeployment,
+		ref:         ref,
+		environment: env.Name,
+		params:      env.Inputs,
+		guarded:     true,
+	})
+	if err != nil || result.Status != "success" {
+		if tracked {
+			deployment.Status = domain.DeploymentStatusError
+			cp.reportDeploymentStatus(ctx, tracker, repo.URL, deployment)
+		}
+		return result, err
+	}
+
+	details, _ := result.Details.(map[string]interface{})
+	deployment.RunID, _ = details["run_id"].(string)
+	deployment.URL, _ = details["url"].(string)
+	if pending, _ := details["pending_run"].(string); deployment.RunID == "" && pending != "" {
+		// The deployment tracker records the run once the provider has created it
+		deployment.RunID = domain.PendingRunID(pending)
+	}
+	if deployment.RunID == "" {
+		// Without a run the deployment can never be followed
+		deployment.Status = domain.DeploymentStatusError
+	}
+	if tracked {
+		cp.reportDeploymentStatus(ctx, tracker, repo.URL, deployment)
+	}
+	cp.recordDeployment(ctx, deployment)
+
+	result.Message = fmt.Sprintf("Deploying %s@%s to %s", repo.Name, ref, env.Name)
+	switch {
+	case deployment.RunID == "":
+		result.Message = fmt.Sprintf("%s; %s did not report its run, so the deployment is not tracked", result.Message, provider.Name())
+	case !domain.IsPendingRunID(deployment.RunID):
+		result.Message = fmt.Sprintf("%s (run %s)", result.Message, deployment.RunID)
+	}
+	return result, nil
+}
+
+// handleListEnvironments shows the environments of a repository and what is deployed to each
+func (cp *CommandProcessor) handleListEnvironments(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	repoName, ok := cmd.Parameters["repository_name"].(string)
+	if !ok || repoName == "" {
+		return nil, domain.Invalid("invalid repository name")
+	}
+
+	repo, err := cp.repoService.GetRepository(ctx, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	if len(repo.Environments) == 0 {
+		return &domain.CommandResult{
+			Status:  "success",
+			Message: fmt.Sprintf("No environments are configured for %s", repo.Name),
+		}, nil
+	}
+
+	current := make(map[string]*domain.Deployment)
+	var deployments []*domain.Deployment
+	if cp.deployments != nil {
+		deployments, err = cp.deployments.ListCurrentDeployments(ctx, repo.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %w", err)
+		}
+		for _, d := range deployments {
+			current[d.Environment] = d
+		}
+	}
+
+	lines := []string{fmt.Sprintf("Environments of %s:", repo.Name)}
+	for _, env := range repo.Environments {
+		d, ok := current[env.Name]
+		if !ok {
+			lines = append(lines, fmt.Sprintf("• %s (%s): never deployed", env.Name, env.Pipeline))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("• %s (%s): %s, %s by %s at %s",
+			env.Name, env.Pipeline, d.Ref, d.Status, d.DeployedBy, d.CreatedAt.UTC().Format(time.RFC3339)))
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: strings.Join(lines, "\n"),
+		Details: deployments,
+	}, nil
+}
+
+// handleSetEnvironment maps an environment of a repository to the pipeline deploying it
+func (cp *CommandProcessor) handleSetEnvironment(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	repoName, _ := cmd.Parameters["repository_name"].(string)
+	envName, _ := cmd.Parameters["environment"].(string)
+	pipelineName, _ := cmd.Parameters["pipeline_name"].(string)
+	if repoName == "" || envName == "" || pipelineName == "" {
+		return nil, domain.Invalid("repository name, environment and pipeline are required")
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionManageEnvironments) {
+		return nil, forbidden(fmt.Sprintf("configure environments of %s", repoName))
+	}
+
+	env := domain.Environment{Name: envName, Pipeline: pipelineName}
+	env.Ref, _ = cmd.Parameters["ref"].(string)
+
+	if err := cp.repoService.SetEnvironment(ctx, repoName, env); err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Failed to configure environment %s: %v", envName, err),
+		}, nil
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Environment %s of %s is now deployed by %s", envName, repoName, pipelineName),
+	}, nil
+}
+
+// handleLock locks a repository environment so only the lock owner may deploy it
+func (cp *CommandProcessor) handleLock(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	repoName, envName, err := lockTarget(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if cp.guard == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: "Deployment locks are not configured",
+		}, nil
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionDeploy) {
+		return nil, forbidden(fmt.Sprintf("lock %s/%s", repoName, envName))
+	}
+
+	repo, err := cp.repoService.GetRepository(ctx, repoName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+	if repo.Environment(envName) == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Environment %s is not configured for %s. Available environments: %s", envName, repo.Name, environmentNames(repo)),
+		}, nil
+	}
+
+	ttl, _ := cmd.Parameters["ttl"].(time.Duration)
+	reason, _ := cmd.Parameters["reason"].(string)
+	lock, acquired, err := cp.guard.Lock(ctx, repo.Name, envName, cmd.User.ID, reason, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot lock: %s", describeLock(lock)),
+			Details: lock,
+		}, nil
+	}
+
+	message := fmt.Sprintf("%s/%s was locked by %s until %s", lock.Repository, lock.Environment, lock.Owner, lock.ExpiresAt.UTC().Format(time.RFC3339))
+	if lock.Reason != "" {
+		message = fmt.Sprintf("%s: %s", message, lock.Reason)
+	}
+	cp.publish(ctx, &domain.Event{
+		Type:       domain.EventLockTaken,
+		Repository: lock.Repository,
+		Actor:      lock.Owner,
+		Message:    message,
+		Payload:    lock,
+	})
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Locked %s/%s until %s", lock.Repository, lock.Environment, lock.ExpiresAt.UTC().Format(time.RFC3339)),
+		Details: lock,
+	}, nil
+}
+
+// handleUnlock releases the lock of a repository environment. Locks of other users may
+// only be released by users allowed to override the guard.
+func (cp *CommandProcessor) handleUnlock(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	repoName, envName, err := lockTarget(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if cp.guard == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: "Deployment locks are not configured",
+		}, nil
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionDeploy) {
+		return nil, forbidden(fmt.Sprintf("unlock %s/%s", repoName, envName))
+	}
+
+	force := cp.isAllowed(ctx, cmd.User, domain.PermissionOverrideGuard)
+	lock, err := cp.guard.Unlock(ctx, repoName, envName, cmd.User.ID, force)
+	if err != nil {
+		if lock != nil {
+			return nil, forbidden(fmt.Sprintf("unlock %s/%s, held by %s", repoName, envName, lock.Owner))
+		}
+		return nil, err
+	}
+	if lock == nil {
+		return &domain.CommandResult{
+			Status:  "success",
+			Message: fmt.Sprintf("%s/%s is not locked", repoName, envName),
+		}, nil
+	}
+
+	if lock.Owner != cmd.User.ID {
+		cp.logger.Warn("deployment lock released by another user",
+			zap.String("user_id", cmd.User.ID),
+			zap.String("owner", lock.Owner),
+			zap.String("repository", repoName),
+			zap.String("environment", envName))
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Unlocked %s/%s", repoName, envName),
+	}, nil
+}
+
+func lockTarget(cmd *domain.Command) (repoName, envName string, err error) {
+	repoName, _ = cmd.Parameters["repository_name"].(string)
+	envName, _ = cmd.Parameters["environment"].(string)
+	if repoName == "" || envName == "" {
+		return "", "", domain.Invalid("repository name and environment are required")
+	}
+	return repoName, envName, nil
+}
+
+// recordDeployment stores a deployment. Failures are logged only, since the pipeline
+// has already been triggered.
+func (cp *CommandProcessor) recordDeployment(ctx context.Context, deployment *domain.Deployment) {
+	if cp.deployments == nil {
+		return
+	}
+
+	if err := cp.deployments.AddDeployment(ctx, deployment); err != nil {
+		cp.logger.Error("failed to record deployment",
+			zap.String("repository", deployment.Repository),
+			zap.String("environment", deployment.Environment),
+			zap.Error(err))
+	}
+}
+
+func (cp *CommandProcessor) reportDeploymentStatus(ctx context.Context, tracker ports.DeploymentPort, url string, deployment *domain.Deployment) {
+	if err := tracker.UpdateDeploymentStatus(ctx, url, deployment); err != nil {
+		cp.logger.Error("failed to update deployment status",
+			zap.String("repository", deployment.Repository),
+			zap.String("environment", deployment.Environment),
+			zap.String("status", deployment.Status),
+			zap.Error(err))
+	}
+}
+
+// deploymentStatus maps a provider run status to a deployment status
+func deploymentStatus(runStatus string) string {
+	switch strings.ToLower(runStatus) {
+	case "success":
+		return domain.DeploymentStatusSuccess
+	case "failure", "failed", "timed_out", "unstable":
+		return domain.DeploymentStatusFailure
+	case "cancelled", "canceled", "skipped", "stale":
+		return domain.DeploymentStatusError
+	case "in_progress", "running":
+		return domain.DeploymentStatusInProgress
+	default:
+		return domain.DeploymentStatusQueued
+	}
+}
+
+func environmentNames(repo *domain.Repository) string {
+	if len(repo.Environments) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(repo.Environments))
+	for _, env := range repo.Environments {
+		names = append(names, env.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"github.com/robfig/cron/v3"
+)
+
+const defaultLockTTL = time.Hour
+
+// DeploymentGuard decides whether pipelines may be triggered, based on deployment locks
+// and change freeze windows
+type DeploymentGuard struct {
+	locks      ports.LockStorage
+	freezes    []freezeWindow
+	defaultTTL time.Duration
+	now        func() time.Time
+}
+
+type DeploymentGuardOptions struct {
+	Locks      ports.LockStorage     // Optional: lock commands are unavailable when nil
+	Freezes    []domain.FreezeWindow // Optional
+	DefaultTTL time.Duration         // Optional: lock TTL when none is given, defaults to one hour
+	Clock      func() time.Time      // Optional: defaults to time.Now
+}
+
+// freezeWindow is a freeze window with its cron schedule parsed
+type freezeWindow struct {
+	domain.FreezeWindow
+	schedule cron.Schedule
+	location *time.Location
+}
+
+// NewDeploymentGuard creates a new instance of DeploymentGuard, validating the freeze windows
+func NewDeploymentGuard(opts DeploymentGuardOptions) (*DeploymentGuard, error) {
+	guard := &DeploymentGuard{
+		locks:      opts.Locks,
+		defaultTTL: opts.DefaultTTL,
+		now:        opts.Clock,
+	}
+	if guard.defaultTTL <= 0 {
+		guard.defaultTTL = defaultLockTTL
+	}
+	if guard.now == nil {
+		guard.now = time.Now
+	}
+
+	for _, window := range opts.Freezes {
+		parsed, err := parseFreezeWindow(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid freeze window %s: %w", window.Name, err)
+		}
+		guard.freezes = append(guard.freezes, parsed)
+	}
+
+	return guard, nil
+}
+
+func parseFreezeWindow(window domain.FreezeWindow) (freezeWindow, error) {
+	parsed := freezeWindow{FreezeWindow: window, location: time.UTC}
+
+	if window.Cron == "" {
+		if window.Start.IsZero() || window.End.IsZero() {
+			return parsed, fmt.Errorf("either a cron expression or a start and end date is required")
+		}
+		if !window.End.After(window.Start) {
+			return parsed, fmt.Errorf("end must be after start")
+		}
+		return parsed, nil
+	}
+
+	if window.Duration <= 0 {
+		return parsed, fmt.Errorf("duration is required for cron windows")
+	}
+	schedule, err := cron.ParseStandard(window.Cron)
+	if err != nil {
+		return parsed, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	parsed.schedule = schedule
+
+	if window.Timezone != "" {
+		location, err := time.LoadLocation(window.Timezone)
+		if err != nil {
+			return parsed, fmt.Errorf("invalid timezone: %w", err)
+		}
+		parsed.location = location
+	}
+
+	return parsed, nil
+}
+
+// active reports whether the window is in effect at the given time. A recurring window
+// is active when it started less than its duration ago.
+func (w *freezeWindow) active(at time.Time) bool {
+	if w.schedule == nil {
+		return !at.Before(w.Start) && at.Before(w.End)
+	}
+
+	at = at.In(w.location)
+	start := w.schedule.Next(at.Add(-w.Duration))
+	return !start.After(at)
+}
+
+// ActiveFreeze returns the freeze window covering the repository environment now, if any
+func (g *DeploymentGuard) ActiveFreeze(repository, environment string) *domain.FreezeWindow {
+	now := g.now()
+	for i := range g.freezes {
+		if g.freezes[i].Applies(repository, environment) && g.freezes[i].active(now) {
+			return &g.freezes[i].FreezeWindow
+		}
+	}
+	return nil
+}
+
+// Check returns why the user may not trigger pipelines of the repository environment,
+// or an empty string when nothing blocks them. Locks only apply to deployments, while
+// freeze windows without an environment apply to every trigger.
+func (g *DeploymentGuard) Check(ctx context.Context, repository, environment, userID string) (string, error) {
+	if freeze := g.ActiveFreeze(repository, environment); freeze != nil {
+		reason := fmt.Sprintf("%s is frozen by %s", repository, freeze.Name)
+		if freeze.Reason != "" {
+			reason = fmt.Sprintf("%s: %s", reason, freeze.Reason)
+		}
+		return reason, nil
+	}
+
+	if g.locks == nil || environment == "" {
+		return "", nil
+	}
+
+	lock, err := g.locks.GetLock(ctx, repository, environment)
+	if err != nil {
+		return "", fmt.Errorf("failed to get lock: %w", err)
+	}
+	if lock == nil || lock.Owner == userID || lock.IsExpired(g.now()) {
+		return "", nil
+	}
+	return describeLock(lock), nil
+}
+
+// Lock locks a repository environment for the user. When another user holds the lock,
+// their lock is returned and acquired is false.
+func (g *DeploymentGuard) Lock(ctx context.Context, repository, environment, userID, reason string, ttl time.Duration) (lock *domain.DeploymentLock, acquired bool, err error) {
+	if g.locks == nil {
+		return nil, false, fmt.Errorf("deployment locks are not configured")
+	}
+	if ttl <= 0 {
+		ttl = g.defaultTTL
+	}
+
+	now := g.now()
+	lock, err = g.locks.AcquireLock(ctx, &domain.DeploymentLock{
+		Repository:  repository,
+		Environment: environment,
+		Owner:       userID,
+		Reason:      reason,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	return lock, lock.Owner == userID, nil
+}
+
+// Unlock releases the lock of a repository environment. Locks of other users are only
+// released when force is set.
+func (g *DeploymentGuard) Unlock(ctx context.Context, repository, environment, userID string, force bool) (*domain.DeploymentLock, error) {
+	if g.locks == nil {
+		return nil, fmt.Errorf("deployment locks are not configured")
+	}
+
+	lock, err := g.locks.GetLock(ctx, repository, environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lock: %w", err)
+	}
+	if lock == nil {
+		return nil, nil
+	}
+	if lock.Owner != userID && !force {
+		return lock, fmt.Errorf("%s", describeLock(lock))
+	}
+
+	if err := g.locks.ReleaseLock(ctx, repository, environment); err != nil {
+		return nil, fmt.Errorf("failed to release lock: %w", err)
+	}
+	return lock, nil
+}
+
+// ListLocks returns the locks that have not expired, none when locks are not configured
+func (g *DeploymentGuard) ListLocks(ctx context.Context) ([]*domain.DeploymentLock, error) {
+	if g.locks == nil {
+		return nil, nil
+	}
+	return g.locks.ListLocks(ctx)
+}
+
+func describeLock(lock *domain.DeploymentLock) string {
+	description := fmt.Sprintf("%s/%s is locked by %s until %s",
+		lock.Repository, lock.Environment, lock.Owner, lock.ExpiresAt.UTC().Format(time.RFC3339))
+	if lock.Reason != "" {
+		description = fmt.Sprintf("%s: %s", description, lock.Reason)
+	}
+	return description
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+const defaultDeploymentPollInterval = 30 * time.Second
+
+// DeploymentTracker follows the runs of pending deployments and propagates their
+// status to the deployment history and to providers with native deployments
+type DeploymentTracker struct {
+	logger       *zap.Logger
+	repoService  ports.RepositoryService
+	providers    *ProviderRegistry
+	deployments  ports.DeploymentStorage
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+type DeploymentTrackerOptions struct {
+	Logger       *zap.Logger
+	RepoService  ports.RepositoryService
+	Providers    *ProviderRegistry
+	Deployments  ports.DeploymentStorage
+	PollInterval time.Duration    // Optional: defaults to 30 seconds
+	Clock        func() time.Time // Optional: defaults to time.Now
+}
+
+// NewDeploymentTracker creates a new instance of DeploymentTracker
+func NewDeploymentTracker(opts DeploymentTrackerOptions) (*DeploymentTracker, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.RepoService == nil {
+		return nil, fmt.Errorf("repository service is required")
+	}
+	if opts.Providers == nil {
+		return nil, fmt.Errorf("provider registry is required")
+	}
+	if opts.Deployments == nil {
+		return nil, fmt.Errorf("deployment storage is required")
+	}
+
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultDeploymentPollInterval
+	}
+
+	now := opts.Clock
+	if now == nil {
+		now = time.Now
+	}
+
+	return &DeploymentTracker{
+		logger:       opts.Logger,
+		repoService:  opts.RepoService,
+		providers:    opts.Providers,
+		deployments:  opts.Deployments,
+		pollInterval: pollInterval,
+		now:          now,
+	}, nil
+}
+
+// Run syncs pending deployments on every poll interval until the context is cancelled
+func (t *DeploymentTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Sync(ctx); err != nil {
+				t.logger.Error("failed to sync deployments", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Sync checks the run of every pending deployment once and records status changes
+func (t *DeploymentTracker) Sync(ctx context.Context) error {
+	pending, err := t.deployments.ListPendingDeployments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list pending deployments: %w", err)
+	}
+
+	for _, deployment := range pending {
+		if err := t.sync(ctx, deployment); err != nil {
+			t.logger.Warn("failed to sync deployment",
+				zap.String("deployment_id", deployment.ID),
+				zap.String("repository", deployment.Repository),
+				zap.String("environment", deployment.Environment),
+				zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+func (t *DeploymentTracker) sync(ctx context.Context, deployment *domain.Deployment) error {
+	repo, err := t.repoService.GetRepository(ctx, deployment.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	provider, err := t.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return err
+	}
+
+	// Deployments whose pipeline reported no run can never be followed
+	if deployment.RunID == "" {
+		return t.update(ctx, provider, repo.URL, deployment, domain.DeploymentStatusError)
+	}
+	if domain.IsPendingRunID(deployment.RunID) {
+		if resolved, err := t.resolve(ctx, provider, repo.URL, deployment); err != nil || !resolved {
+			return err
+		}
+	}
+
+	runStatus, err := provider.GetRunStatus(ctx, repo.URL, deployment.RunID)
+	if err != nil {
+		return err
+	}
+
+	status := deploymentStatus(runStatus.Status)
+	if status == deployment.Status {
+		return nil
+	}
+	return t.update(ctx, provider, repo.URL, deployment, status)
+}
+
+// resolve records the run created for the pending run ID of a deployment. Deployments
+// whose run cannot be identified, or still does not exist after the pending run window,
+// are marked as failed with an error.
+func (t *DeploymentTracker) resolve(ctx context.Context, provider ports.CIProviderPort, url string, deployment *domain.Deployment) (bool, error) {
+	created, err := resolvePendingRun(ctx, provider, url, deployment.RunID)
+	if errors.Is(err, domain.ErrConflict) || (err == nil && created == nil && t.now().Sub(deployment.CreatedAt) > pendingRunWindow) {
+		t.logger.Warn("giving up on tracking deployment",
+			zap.String("deployment_id", deployment.ID),
+			zap.String("repository", deployment.Repository),
+			zap.String("environment", deployment.Environment),
+			zap.Error(err))
+		return false, t.update(ctx, provider, url, deployment, domain.DeploymentStatusError)
+	}
+	if err != nil || created == nil {
+		return false, err
+	}
+
+	if err := t.deployments.SetDeploymentRun(ctx, deployment.ID, created.ID, created.URL); err != nil {
+		return false, fmt.Errorf("failed to record deployment run %s: %w", created.ID, err)
+	}
+	deployment.RunID, deployment.URL = created.ID, created.URL
+	return true, nil
+}
+
+// update records a status change of the deployment and reports it to providers with
+// native deployments
+func (t *DeploymentTracker) update(ctx context.Context, provider ports.CIProviderPort, url string, deployment *domain.Deployment, status string) error {
+	deployment.Status = status
+
+	if err := t.deployments.UpdateDeploymentStatus(ctx, deployment.ID, status); err != nil {
+		return fmt.Errorf("failed to update deployment status: %w", err)
+	}
+
+	if tracker, ok := provider.(ports.DeploymentPort); ok && deployment.ProviderDeploymentID != "" {
+		if err := tracker.UpdateDeploymentStatus(ctx, url, deployment); err != nil {
+			return fmt.Errorf("failed to report deployment status: %w", err)
+		}
+	}
+
+	return nil
+}
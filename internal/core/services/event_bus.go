@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRelayInterval = 5 * time.Second
+	// outboxLease is how long a claimed event is left to its claimer before it is relayed again
+	outboxLease     = time.Minute
+	outboxBatchSize = 100
+)
+
+// EventBus publishes domain events to the handlers subscribed to them. Without an outbox
+// events are delivered in-process once; with one they are stored first and relayed until
+// every handler processed them, so they survive crashes and are delivered at least once.
+type EventBus struct {
+	logger   *zap.Logger
+	outbox   ports.EventOutbox
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	handlers []eventSubscription
+}
+
+type eventSubscription struct {
+	filter  string
+	handler ports.EventHandler
+}
+
+type EventBusOptions struct {
+	Logger   *zap.Logger
+	Outbox   ports.EventOutbox // Optional: enables at-least-once delivery
+	Interval time.Duration     // Optional: how often the outbox is relayed, defaults to 5 seconds
+	Clock    func() time.Time  // Optional: defaults to time.Now
+}
+
+// NewEventBus creates a new instance of EventBus
+func NewEventBus(opts EventBusOptions) (*EventBus, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultRelayInterval
+	}
+	now := opts.Clock
+	if now == nil {
+		now = time.Now
+	}
+
+	return &EventBus{
+		logger:   opts.Logger,
+		outbox:   opts.Outbox,
+		interval: interval,
+		now:      now,
+	}, nil
+}
+
+// Subscribe delivers the events matching a filter to a handler. Filters follow
+// domain.MatchesEventFilter, so "*" subscribes to every event.
+func (b *EventBus) Subscribe(filter string, handler ports.EventHandler) {
+	if filter == "" {
+		filter = "*"
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, eventSubscription{filter: filter, handler: handler})
+}
+
+// Publish delivers an event to its handlers. With an outbox the event is stored first and
+// left for the relay when a handler fails.
+func (b *EventBus) Publish(ctx context.Context, event *domain.Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = b.now()
+	}
+	logger := b.logger.With(zap.String("event", event.Type))
+
+	if b.outbox != nil {
+		if err := b.outbox.AppendEvent(ctx, event, outboxLease); err != nil {
+			// Deliver anyway; the event is only lost if this process crashes
+			logger.Error("failed to store event in outbox", zap.Error(err))
+		}
+	}
+
+	if err := b.deliver(ctx, event); err != nil {
+		logger.Warn("failed to handle event", zap.Error(err))
+		return
+	}
+	b.markDelivered(ctx, event)
+}
+
+// Run relays the outbox on every interval until the context is cancelled. It returns
+// immediately when the bus has no outbox.
+func (b *EventBus) Run(ctx context.Context) {
+	if b.outbox == nil {
+		return
+	}
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.Relay(ctx); err != nil {
+				b.logger.Error("failed to relay events", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Relay delivers the stored events that were not delivered to every handler, such as
+// those of a crashed process or of a failing handler
+func (b *EventBus) Relay(ctx context.Context) error {
+	if b.outbox == nil {
+		return nil
+	}
+
+	events, err := b.outbox.ClaimEvents(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		return fmt.Errorf("failed to claim events: %w", err)
+	}
+
+	for _, event := range events {
+		if err := b.deliver(ctx, event); err != nil {
+			b.logger.Warn("failed to relay event",
+				zap.String("event_id", event.ID),
+				zap.String("event", event.Type),
+				zap.Error(err))
+			continue
+		}
+		b.markDelivered(ctx, event)
+	}
+
+	return nil
+}
+
+// deliver hands the event to every matching handler, even when one of them fails
+func (b *EventBus) deliver(ctx context.Context, event *domain.Event) error {
+	b.mu.RLock()
+	handlers := make([]eventSubscription, len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, subscription := range handlers {
+		if !domain.MatchesEventFilter(subscription.filter, event.Type) {
+			continue
+		}
+		if err := subscription.handler.HandleEvent(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *EventBus) markDelivered(ctx context.Context, event *domain.Event) {
+	if b.outbox == nil || event.ID == "" {
+		return
+	}
+	if err := b.outbox.MarkEventDelivered(ctx, event.ID); err != nil {
+		b.logger.Error("failed to mark event delivered", zap.String("event_id", event.ID), zap.Error(err))
+	}
+}
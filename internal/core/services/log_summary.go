@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const (
+	defaultLogExcerptLines = 30
+	maxLogExcerptLines     = 200
+	// Lines kept after the error line, so the excerpt shows how the step ended.
+	// Never more than half the excerpt, so the error line itself is always included.
+	logExcerptTrailingLines = 5
+)
+
+// errorMarkers are substrings that identify error lines in CI logs
+var errorMarkers = []string{
+	"##[error]",
+	"error:",
+	"ERROR",
+	"FAIL",
+	"fatal:",
+	"panic:",
+	"Exception",
+	"exit code",
+}
+
+// failingJob returns the first failed job, falling back to the last job when none failed
+func failingJob(logs []domain.JobLog) *domain.JobLog {
+	for i := range logs {
+		switch strings.ToLower(logs[i].Status) {
+		case "failure", "failed", "timed_out":
+			return &logs[i]
+		}
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+	return &logs[len(logs)-1]
+}
+
+// logExcerpt returns up to n lines ending just after the last error line of the log.
+// Without any recognizable error, the last n lines are returned.
+func logExcerpt(content string, n int) string {
+	if n <= 0 {
+		n = defaultLogExcerptLines
+	}
+	n = min(n, maxLogExcerptLines)
+
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+
+	trailing := min(logExcerptTrailingLines, (n-1)/2)
+	end := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if isErrorLine(lines[i]) {
+			end = min(i+1+trailing, len(lines))
+			break
+		}
+	}
+
+	start := max(end-n, 0)
+	return strings.Join(lines[start:end], "\n")
+}
+
+func isErrorLine(line string) bool {
+	for _, marker := range errorMarkers {
+		if strings.Contains(line, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+// NotificationDispatcher posts events to the chat channels subscribed to them, through
+// the notifier of the platform each channel belongs to. It handles the events of the
+// event bus.
+type NotificationDispatcher struct {
+	logger        *zap.Logger
+	subscriptions ports.SubscriptionStorage
+	now           func() time.Time
+
+	mu        sync.RWMutex
+	notifiers map[string]ports.Notifier
+}
+
+type NotificationDispatcherOptions struct {
+	Logger        *zap.Logger
+	Subscriptions ports.SubscriptionStorage
+	Clock         func() time.Time // Optional: defaults to time.Now
+}
+
+// NewNotificationDispatcher creates a new instance of NotificationDispatcher
+func NewNotificationDispatcher(opts NotificationDispatcherOptions) (*NotificationDispatcher, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.Subscriptions == nil {
+		return nil, fmt.Errorf("subscription storage is required")
+	}
+
+	now := opts.Clock
+	if now == nil {
+		now = time.Now
+	}
+
+	return &NotificationDispatcher{
+		logger:        opts.Logger,
+		subscriptions: opts.Subscriptions,
+		now:           now,
+		notifiers:     make(map[string]ports.Notifier),
+	}, nil
+}
+
+// Register sets the notifier posting to the channels of a platform. Messenger adapters
+// register after construction since they depend on the command processor.
+func (d *NotificationDispatcher) Register(platform string, notifier ports.Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.notifiers[platform] = notifier
+}
+
+// HandleEvent posts the event once to every unmuted channel with a matching subscription.
+// Events without a message are not meant for chat and are skipped. Failures to post are
+// logged rather than returned, so a redelivery never posts twice to the other channels.
+func (d *NotificationDispatcher) HandleEvent(ctx context.Context, event *domain.Event) error {
+	if event.Message == "" {
+		return nil
+	}
+	logger := d.logger.With(zap.String("event", event.Type), zap.String("repository", event.Repository))
+
+	subscriptions, err := d.subscriptions.ListSubscriptions(ctx, event.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	notified := make(map[string]bool)
+	for _, subscription := range subscriptions {
+		channel := subscription.Platform + "/" + subscription.ChannelID
+		if notified[channel] || !subscription.Matches(event) {
+			continue
+		}
+		notified[channel] = true
+
+		channelLogger := logger.With(zap.String("platform", subscription.Platform), zap.String("channel_id", subscription.ChannelID))
+		mute, err := d.subscriptions.GetChannelMute(ctx, subscription.Platform, subscription.ChannelID)
+		if err != nil {
+			channelLogger.Error("failed to get channel mute", zap.Error(err))
+			continue
+		}
+		if mute != nil && mute.IsActive(d.now()) {
+			continue
+		}
+
+		d.mu.RLock()
+		notifier, ok := d.notifiers[subscription.Platform]
+		d.mu.RUnlock()
+		if !ok {
+			channelLogger.Warn("no notifier registered for platform")
+			continue
+		}
+		if err := notifier.Notify(ctx, subscription.ChannelID, event.Message); err != nil {
+			channelLogger.Error("failed to notify channel", zap.Error(err))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+)
+
+// ProviderRegistry resolves CI providers by repository host
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]ports.CIProviderPort
+}
+
+// NewProviderRegistry creates a registry containing the given providers
+func NewProviderRegistry(providers ...ports.CIProviderPort) *ProviderRegistry {
+	r := &ProviderRegistry{
+		providers: make(map[string]ports.CIProviderPort),
+	}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds a provider, replacing any provider already registered for the same host
+func (r *ProviderRegistry) Register(provider ports.CIProviderPort) {
+	if provider == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[strings.ToLower(provider.Host())] = provider
+}
+
+// ProviderFor returns the provider serving the host of the given repository URL
+func (r *ProviderRegistry) ProviderFor(repoURL string) (ports.CIProviderPort, error) {
+	host := RepositoryHost(repoURL)
+	if host == "" {
+		return nil, domain.Invalid("invalid repository URL: %s", repoURL)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.providers[host]
+	if !ok {
+		return nil, domain.Invalid("no CI provider configured for host %s", host)
+	}
+	return provider, nil
+}
+
+// Providers returns all registered providers
+func (r *ProviderRegistry) Providers() []ports.CIProviderPort {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	providers := make([]ports.CIProviderPort, 0, len(r.providers))
+	for _, p := range r.providers {
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+// RepositoryHost extracts the lower-cased host from an HTTPS or SSH repository URL
+func RepositoryHost(repoURL string) string {
+	repoURL = strings.TrimSpace(repoURL)
+
+	if strings.HasPrefix(repoURL, "git@") {
+		host, _, found := strings.Cut(strings.TrimPrefix(repoURL, "git@"), ":")
+		if !found {
+			return ""
+		}
+		return strings.ToLower(host)
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
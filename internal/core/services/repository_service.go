@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -10,16 +11,19 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxUpdateAttempts bounds the attempts of an update racing with other writers
+const maxUpdateAttempts = 5
+
 type repositoryService struct {
-	logger     *zap.Logger
-	githubPort ports.GitHubPort // Optional: only needed for GitHub repositories
-	storage    ports.RepositoryStorage
+	logger    *zap.Logger
+	providers *ProviderRegistry
+	storage   ports.RepositoryStorage
 }
 
 type RepositoryServiceOptions struct {
-	Logger     *zap.Logger
-	GitHubPort ports.GitHubPort // Optional
-	Storage    ports.RepositoryStorage
+	Logger    *zap.Logger
+	Providers *ProviderRegistry // Optional
+	Storage   ports.RepositoryStorage
 }
 
 func NewRepositoryService(opts RepositoryServiceOptions) (ports.RepositoryService, error) {
@@ -30,45 +34,89 @@ func NewRepositoryService(opts RepositoryServiceOptions) (ports.RepositoryServic
 		return nil, fmt.Errorf("storage is required")
 	}
 
+	providers := opts.Providers
+	if providers == nil {
+		providers = NewProviderRegistry()
+	}
+
 	return &repositoryService{
-		logger:     opts.Logger,
-		githubPort: opts.GitHubPort, // May be nil if GitHub integration is not needed
-		storage:    opts.Storage,
+		logger:    opts.Logger,
+		providers: providers,
+		storage:   opts.Storage,
 	}, nil
 }
 
 func (s *repositoryService) AddRepository(ctx context.Context, repo *domain.Repository) error {
-	// If it's a GitHub repository and we have GitHub integration
-	if isGitHubURL(repo.URL) {
-		if s.githubPort == nil {
-			return fmt.Errorf("GitHub integration is not configured")
-		}
+	// Resolve the CI provider from the repository host
+	provider, err := s.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return err
+	}
 
-		// Fetch repository details from GitHub
-		details, err := s.githubPort.GetRepositoryDetails(ctx, repo.URL)
-		if err != nil {
-			return fmt.Errorf("failed to fetch GitHub repository details: %w", err)
+	// Fetch repository details from the provider
+	details, err := provider.GetRepositoryDetails(ctx, repo.URL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s repository details: %w", provider.Name(), err)
+	}
+
+	// Update repository with provider details
+	repo.Name = details.Name
+	repo.DefaultBranch = details.DefaultBranch
+
+	// Adding a repository again refreshes it, keeping the default pipeline chosen since.
+	// The refresh only saves over the version read, so concurrent changes are not lost.
+	// Names drop the owner, so a repository of the same name at another URL is a different
+	// one and never overwrites the stored one.
+	requested := repo.URL
+	return s.retryConflicts(repo.Name, func() error {
+		repo.URL = requested
+		repo.Pipelines = append([]domain.Pipeline(nil), details.Pipelines...)
+		repo.Version = 0
+
+		existing, err := s.storage.GetRepository(ctx, repo.Name)
+		switch {
+		case err == nil:
+			if !sameRepositoryURL(existing.URL, repo.URL) {
+				return domain.AlreadyExists("a repository named %s is already managed from %s", repo.Name, existing.URL)
+			}
+			repo.URL = existing.URL
+			keepDefaultPipeline(repo, existing)
+			repo.Version = existing.Version
+		case !errors.Is(err, domain.ErrNotFound):
+			return err
 		}
 
-		// Update repository with GitHub details
-		repo.Name = details.Name
-		repo.DefaultBranch = details.DefaultBranch
-		repo.Pipelines = details.Pipelines
+		return s.storage.UpsertRepository(ctx, repo)
+	})
+}
+
+// sameRepositoryURL reports whether two URLs point to the same repository, ignoring case,
+// a trailing slash and a .git suffix
+func sameRepositoryURL(a, b string) bool {
+	normalize := func(url string) string {
+		return strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(url), "/"), ".git")
 	}
+	return normalize(a) == normalize(b)
+}
 
-	// Store repository information
-	return s.storage.AddRepository(ctx, repo)
+// keepDefaultPipeline makes the default pipeline of the stored repository the default of
+// the refreshed one, when it still exists
+func keepDefaultPipeline(repo, stored *domain.Repository) {
+	for _, pipeline := range stored.Pipelines {
+		if !pipeline.IsDefault || findPipeline(repo, pipeline.Path) == nil {
+			continue
+		}
+		for i := range repo.Pipelines {
+			repo.Pipelines[i].IsDefault = repo.Pipelines[i].Path == pipeline.Path
+		}
+		return
+	}
 }
 
 func (s *repositoryService) GetRepository(ctx context.Context, name string) (*domain.Repository, error) {
 	return s.storage.GetRepository(ctx, name)
 }
 
-// isGitHubURL checks if the given URL is a GitHub repository URL
-func isGitHubURL(url string) bool {
-	return strings.Contains(url, "github.com")
-}
-
 func (s *repositoryService) GetRepositoryPipelines(ctx context.Context, name string) ([]domain.Pipeline, error) {
 	repo, err := s.GetRepository(ctx, name)
 	if err != nil {
@@ -78,30 +126,78 @@ func (s *repositoryService) GetRepositoryPipelines(ctx context.Context, name str
 }
 
 func (s *repositoryService) SetDefaultPipeline(ctx context.Context, repoName, pipelineName string) error {
-	repo, err := s.GetRepository(ctx, repoName)
-	if err != nil {
-		return err
-	}
+	return s.updateRepository(ctx, repoName, func(repo *domain.Repository) error {
+		found := false
+		for i := range repo.Pipelines {
+			if repo.Pipelines[i].Name == pipelineName {
+				repo.Pipelines[i].IsDefault = true
+				found = true
+			} else {
+				repo.Pipelines[i].IsDefault = false
+			}
+		}
 
-	found := false
-	for i := range repo.Pipelines {
-		if repo.Pipelines[i].Name == pipelineName {
-			repo.Pipelines[i].IsDefault = true
-			found = true
+		if !found {
+			return domain.NotFound("pipeline %s not found in repository %s", pipelineName, repoName)
+		}
+		return nil
+	})
+}
+
+// SetEnvironment maps an environment to one of the repository pipelines, replacing any
+// existing mapping of the same environment
+func (s *repositoryService) SetEnvironment(ctx context.Context, repoName string, env domain.Environment) error {
+	return s.updateRepository(ctx, repoName, func(repo *domain.Repository) error {
+		if findPipeline(repo, env.Pipeline) == nil {
+			return domain.NotFound("pipeline %s not found in repository %s", env.Pipeline, repoName)
+		}
+
+		if existing := repo.Environment(env.Name); existing != nil {
+			*existing = env
 		} else {
-			repo.Pipelines[i].IsDefault = false
+			repo.Environments = append(repo.Environments, env)
 		}
-	}
+		return nil
+	})
+}
 
-	if !found {
-		return fmt.Errorf("pipeline %s not found in repository %s", pipelineName, repoName)
+// updateRepository applies change to the stored repository and saves it. When another
+// writer updated the repository in between, the change is applied again to the new version.
+func (s *repositoryService) updateRepository(ctx context.Context, name string, change func(repo *domain.Repository) error) error {
+	return s.retryConflicts(name, func() error {
+		repo, err := s.storage.GetRepository(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := change(repo); err != nil {
+			return err
+		}
+		return s.storage.UpdateRepository(ctx, repo)
+	})
+}
+
+// retryConflicts runs save until it does not fail with domain.ErrConflict, a few times at most
+func (s *repositoryService) retryConflicts(name string, save func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxUpdateAttempts; attempt++ {
+		if err = save(); !errors.Is(err, domain.ErrConflict) {
+			return err
+		}
+		s.logger.Debug("repository updated concurrently, retrying", zap.String("repository", name), zap.Int("attempt", attempt))
 	}
+	return err
+}
 
-	return s.storage.UpdateRepository(ctx, repo)
+// findPipeline looks a pipeline up by name or workflow path
+func findPipeline(repo *domain.Repository, nameOrPath string) *domain.Pipeline {
+	for i := range repo.Pipelines {
+		if repo.Pipelines[i].Name == nameOrPath || repo.Pipelines[i].Path == nameOrPath {
+			return &repo.Pipelines[i]
+		}
+	}
+	return nil
 }
 
 func (s *repositoryService) ListRepositories(ctx context.Context) ([]*domain.Repository, error) {
 	return s.storage.ListRepositories(ctx)
 }
-
-// ... implement other interface methods
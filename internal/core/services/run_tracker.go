@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRunPollInterval = 30 * time.Second
+	// runTrackingWindow bounds how long a run that never reports a final status is polled
+	runTrackingWindow = 24 * time.Hour
+	// pendingRunWindow bounds how long the provider may take to create a triggered run
+	pendingRunWindow = time.Hour
+)
+
+// RunTracker follows the runs triggered through ChatOps until they finish, recording
+// their status and publishing an event on every change
+type RunTracker struct {
+	logger       *zap.Logger
+	repoService  ports.RepositoryService
+	providers    *ProviderRegistry
+	runs         ports.RunStorage
+	events       ports.EventPublisher
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+type RunTrackerOptions struct {
+	Logger       *zap.Logger
+	RepoService  ports.RepositoryService
+	Providers    *ProviderRegistry
+	Runs         ports.RunStorage
+	Events       ports.EventPublisher // Optional: receives run status changes
+	PollInterval time.Duration        // Optional: defaults to 30 seconds
+	Clock        func() time.Time     // Optional: defaults to time.Now
+}
+
+// NewRunTracker creates a new instance of RunTracker
+func NewRunTracker(opts RunTrackerOptions) (*RunTracker, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.RepoService == nil {
+		return nil, fmt.Errorf("repository service is required")
+	}
+	if opts.Providers == nil {
+		return nil, fmt.Errorf("provider registry is required")
+	}
+	if opts.Runs == nil {
+		return nil, fmt.Errorf("run storage is required")
+	}
+
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultRunPollInterval
+	}
+	now := opts.Clock
+	if now == nil {
+		now = time.Now
+	}
+
+	return &RunTracker{
+		logger:       opts.Logger,
+		repoService:  opts.RepoService,
+		providers:    opts.Providers,
+		runs:         opts.Runs,
+		events:       opts.Events,
+		pollInterval: pollInterval,
+		now:          now,
+	}, nil
+}
+
+// Run syncs active runs on every poll interval until the context is cancelled
+func (t *RunTracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Sync(ctx); err != nil {
+				t.logger.Error("failed to sync runs", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Sync checks every active run once and records status changes
+func (t *RunTracker) Sync(ctx context.Context) error {
+	active, err := t.runs.ListActiveRuns(ctx, t.now().Add(-runTrackingWindow))
+	if err != nil {
+		return fmt.Errorf("failed to list active runs: %w", err)
+	}
+
+	for _, run := range active {
+		if err := t.sync(ctx, run); err != nil {
+			t.logger.Warn("failed to sync run",
+				zap.String("run_id", run.ID),
+				zap.String("repository", run.Repository),
+				zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+func (t *RunTracker) sync(ctx context.Context, run *domain.Run) error {
+	repo, err := t.repoService.GetRepository(ctx, run.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	provider, err := t.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return err
+	}
+
+	if domain.IsPendingRunID(run.ID) {
+		if resolved, err := t.resolve(ctx, provider, repo.URL, run); !resolved || err != nil {
+			return err
+		}
+	}
+
+	runStatus, err := provider.GetRunStatus(ctx, repo.URL, run.ID)
+	if err != nil {
+		return err
+	}
+
+	status := normalizeRunStatus(runStatus.Status)
+	finished := (&domain.Run{Status: status}).IsFinished()
+	// Cancelling runs keep their status until the provider reports them finished
+	if status == run.Status || (run.Status == domain.RunStatusCancelling && !finished) {
+		return nil
+	}
+
+	return t.transition(ctx, run, status)
+}
+
+// resolve records the run the provider created for a pending run and reports whether
+// it did. Runs the provider cannot identify, or did not create within pendingRunWindow,
+// are given up as untracked.
+func (t *RunTracker) resolve(ctx context.Context, provider ports.CIProviderPort, url string, run *domain.Run) (bool, error) {
+	created, err := resolvePendingRun(ctx, provider, url, run.ID)
+	if errors.Is(err, domain.ErrConflict) || (err == nil && created == nil && t.now().Sub(run.CreatedAt) > pendingRunWindow) {
+		t.logger.Warn("giving up on tracking run",
+			zap.String("repository", run.Repository),
+			zap.String("pipeline", run.Pipeline),
+			zap.Error(err))
+		return false, t.transition(ctx, run, domain.RunStatusUntracked)
+	}
+	if err != nil || created == nil {
+		return false, err
+	}
+
+	resolved, err := t.runs.ResolvePendingRun(ctx, run.ID, created.ID, created.URL)
+	if err != nil {
+		return false, fmt.Errorf("failed to record run %s: %w", created.ID, err)
+	}
+	run.ID, run.URL = created.ID, created.URL
+	return resolved, nil
+}
+
+// transition records a status change of the run. Only the replica that records the
+// change publishes it.
+func (t *RunTracker) transition(ctx context.Context, run *domain.Run, status string) error {
+	previousStatus := run.Status
+	changed, err := t.runs.TransitionRunStatus(ctx, run.ID, previousStatus, status)
+	if err != nil {
+		return fmt.Errorf("failed to update run status: %w", err)
+	}
+	run.Status = status
+	if !changed || t.events == nil {
+		return nil
+	}
+
+	t.events.Publish(ctx, runUpdatedEvent(run, previousStatus, t.now()))
+	return nil
+}
+
+// resolvePendingRun asks the provider for the run it created for a pending run ID. It
+// returns nil while the run does not exist yet.
+func resolvePendingRun(ctx context.Context, provider ports.CIProviderPort, url, pendingID string) (*domain.Run, error) {
+	resolver, ok := provider.(ports.RunResolver)
+	if !ok {
+		return nil, domain.Conflict("%s cannot identify the runs it creates later", provider.Name())
+	}
+	return resolver.ResolveRun(ctx, url, domain.PendingRunReference(pendingID))
+}
+
+// runUpdatedEvent describes a run status change. Deployment runs are reported as
+// deployment events of their environment. Only finished runs carry a chat message.
+func runUpdatedEvent(run *domain.Run, previousStatus string, at time.Time) *domain.Event {
+	event := &domain.Event{
+		Type:       domain.EventKindRun + "." + run.Status,
+		Repository: run.Repository,
+		Payload:    &domain.WorkflowRunUpdated{Run: run, PreviousStatus: previousStatus},
+		OccurredAt: at,
+	}
+	if run.Environment != "" {
+		event.Type = domain.EventKindDeployment + "." + run.Environment
+	}
+	if !run.IsFinished() {
+		return event
+	}
+
+	outcome := map[string]string{
+		domain.RunStatusSuccess:   "succeeded",
+		domain.RunStatusFailure:   "failed",
+		domain.RunStatusCancelled: "was cancelled",
+		domain.RunStatusUntracked: "could not be tracked",
+	}[run.Status]
+	switch {
+	case run.Environment != "" && domain.IsPendingRunID(run.ID):
+		event.Message = fmt.Sprintf("Deployment of %s to %s %s", run.Repository, run.Environment, outcome)
+	case run.Environment != "":
+		event.Message = fmt.Sprintf("Deployment of %s to %s %s (run %s)", run.Repository, run.Environment, outcome, run.ID)
+	case domain.IsPendingRunID(run.ID):
+		event.Message = fmt.Sprintf("A run of %s on %s %s", run.Pipeline, run.Repository, outcome)
+	default:
+		event.Message = fmt.Sprintf("Run %s of %s on %s %s", run.ID, run.Pipeline, run.Repository, outcome)
+	}
+	if run.TriggeredBy != "" {
+		event.Message = fmt.Sprintf("%s, triggered by %s", event.Message, run.TriggeredBy)
+	}
+	if run.URL != "" {
+		event.Message = fmt.Sprintf("%s\n%s", event.Message, run.URL)
+	}
+	return event
+}
+
+// normalizeRunStatus maps a provider run status to a run status
+func normalizeRunStatus(providerStatus string) string {
+	switch strings.ToLower(providerStatus) {
+	case "success":
+		return domain.RunStatusSuccess
+	case "failure", "failed", "timed_out", "unstable":
+		return domain.RunStatusFailure
+	case "cancelled", "canceled", "skipped", "stale":
+		return domain.RunStatusCancelled
+	case "in_progress", "running":
+		return domain.RunStatusInProgress
+	default:
+		return domain.RunStatusQueued
+	}
+}
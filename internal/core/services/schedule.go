@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// handleAddSchedule schedules a command to run on a cron schedule as the requesting user
+func (cp *CommandProcessor) handleAddSchedule(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.schedules == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: "Schedules are not configured",
+		}, nil
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionManageSchedules) {
+		return nil, forbidden("manage schedules")
+	}
+
+	expression, _ := cmd.Parameters["cron"].(string)
+	timezone, _ := cmd.Parameters["timezone"].(string)
+	commandType, _ := cmd.Parameters["command_type"].(string)
+	commandText, _ := cmd.Parameters["command_text"].(string)
+	parameters, _ := cmd.Parameters["command_parameters"].(map[string]interface{})
+	if expression == "" || commandType == "" {
+		return nil, domain.Invalid("cron expression and command are required")
+	}
+
+	permission, ok := schedulableCommands[commandType]
+	if !ok {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("`%s` cannot be scheduled; only verify and deploy commands can", commandText),
+		}, nil
+	}
+	// Schedules run as their creator, who must be allowed to run the command now
+	if !cp.isAllowed(ctx, cmd.User, permission) {
+		return nil, forbidden(fmt.Sprintf("schedule `%s`", commandText))
+	}
+
+	now := time.Now()
+	next, err := nextScheduleRun(expression, timezone, now)
+	if err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Invalid schedule: %v", err),
+		}, nil
+	}
+
+	schedule := &domain.Schedule{
+		Cron:        expression,
+		Timezone:    timezone,
+		CommandType: commandType,
+		Parameters:  parameters,
+		CommandText: commandText,
+		User:        cmd.User,
+		ChannelID:   cmd.Source.ChannelID,
+		CreatedAt:   now,
+		NextRunAt:   next,
+	}
+	if err := cp.schedules.AddSchedule(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to add schedule: %w", err)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Scheduled `%s` (%s) as #%s, next run at %s", commandText, describeCron(schedule), schedule.ID, next.Format(time.RFC3339)),
+		Details: schedule,
+	}, nil
+}
+
+// handleListSchedules lists every schedule with its next run
+func (cp *CommandProcessor) handleListSchedules(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.schedules == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: "Schedules are not configured",
+		}, nil
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionManageSchedules) {
+		return nil, forbidden("manage schedules")
+	}
+
+	schedules, err := cp.schedules.ListSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+
+	if len(schedules) == 0 {
+		return &domain.CommandResult{
+			Status:  "success",
+			Message: "No commands are scheduled",
+		}, nil
+	}
+
+	lines := []string{"Scheduled commands:"}
+	for _, s := range schedules {
+		lines = append(lines, fmt.Sprintf("• #%s `%s` (%s) by %s, next run at %s",
+			s.ID, s.CommandText, describeCron(s), s.User.ID, s.NextRunAt.Format(time.RFC3339)))
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: strings.Join(lines, "\n"),
+		Details: schedules,
+	}, nil
+}
+
+// handleRemoveSchedule removes a schedule
+func (cp *CommandProcessor) handleRemoveSchedule(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.schedules == nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: "Schedules are not configured",
+		}, nil
+	}
+
+	id, ok := cmd.Parameters["schedule_id"].(string)
+	if !ok || id == "" {
+		return nil, domain.Invalid("invalid schedule ID")
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionManageSchedules) {
+		return nil, forbidden("manage schedules")
+	}
+
+	schedule, err := cp.schedules.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule %s: %w", id, err)
+	}
+
+	if err := cp.schedules.DeleteSchedule(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to remove schedule %s: %w", id, err)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Removed schedule #%s `%s`", schedule.ID, schedule.CommandText),
+	}, nil
+}
+
+func describeCron(schedule *domain.Schedule) string {
+	if schedule.Timezone == "" {
+		return schedule.Cron + " UTC"
+	}
+	return schedule.Cron + " " + schedule.Timezone
+}
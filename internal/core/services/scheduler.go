@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const defaultSchedulerInterval = 15 * time.Second
+
+// schedulableCommands maps the command types that may be scheduled to the permission
+// needed to run them
+var schedulableCommands = map[string]string{
+	domain.CommandTypeVerifyRepo: domain.PermissionTriggerPipeline,
+	domain.CommandTypeDeploy:     domain.PermissionDeploy,
+}
+
+// Scheduler fires due schedules through the command processor. With a leader lock, only
+// the replica holding it fires schedules.
+type Scheduler struct {
+	logger    *zap.Logger
+	processor *CommandProcessor
+	schedules ports.ScheduleStorage
+	leader    ports.LeaderLock
+	notifier  ports.Notifier
+	interval  time.Duration
+	now       func() time.Time
+}
+
+type SchedulerOptions struct {
+	Logger    *zap.Logger
+	Processor *CommandProcessor
+	Schedules ports.ScheduleStorage
+	Leader    ports.LeaderLock // Optional: every replica fires schedules when nil
+	Notifier  ports.Notifier   // Optional: run results are only logged when nil
+	Interval  time.Duration    // Optional: how often due schedules are checked, defaults to 15 seconds
+	Clock     func() time.Time // Optional: defaults to time.Now
+}
+
+// NewScheduler creates a new instance of Scheduler
+func NewScheduler(opts SchedulerOptions) (*Scheduler, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.Processor == nil {
+		return nil, fmt.Errorf("command processor is required")
+	}
+	if opts.Schedules == nil {
+		return nil, fmt.Errorf("schedule storage is required")
+	}
+
+	scheduler := &Scheduler{
+		logger:    opts.Logger,
+		processor: opts.Processor,
+		schedules: opts.Schedules,
+		leader:    opts.Leader,
+		notifier:  opts.Notifier,
+		interval:  opts.Interval,
+		now:       opts.Clock,
+	}
+	if scheduler.interval <= 0 {
+		scheduler.interval = defaultSchedulerInterval
+	}
+	if scheduler.now == nil {
+		scheduler.now = time.Now
+	}
+
+	return scheduler, nil
+}
+
+// Run fires due schedules on every interval until the context is cancelled, then
+// releases the leader lock
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if s.leader != nil {
+				if err := s.leader.Release(context.Background()); err != nil {
+					s.logger.Error("failed to release scheduler leader lock", zap.Error(err))
+				}
+			}
+			return
+		case <-ticker.C:
+			if err := s.Tick(ctx); err != nil {
+				s.logger.Error("failed to run schedules", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Tick fires every due schedule once, provided this replica is the leader
+func (s *Scheduler) Tick(ctx context.Context) error {
+	if s.leader != nil {
+		leader, err := s.leader.TryAcquire(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acquire leader lock: %w", err)
+		}
+		if !leader {
+			return nil
+		}
+	}
+
+	now := s.now()
+	due, err := s.schedules.ListDueSchedules(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to list due schedules: %w", err)
+	}
+
+	for _, schedule := range due {
+		s.fire(ctx, schedule, now)
+	}
+
+	return nil
+}
+
+// fire claims the run of a schedule before processing its command, so a run is never
+// fired twice even if processing fails
+func (s *Scheduler) fire(ctx context.Context, schedule *domain.Schedule, now time.Time) {
+	logger := s.logger.With(zap.String("schedule_id", schedule.ID), zap.String("command", schedule.CommandText))
+
+	next, err := nextScheduleRun(schedule.Cron, schedule.Timezone, now)
+	if err != nil {
+		logger.Error("invalid schedule", zap.Error(err))
+		return
+	}
+
+	claimed, err := s.schedules.ClaimScheduleRun(ctx, schedule.ID, schedule.NextRunAt, now, next)
+	if err != nil {
+		logger.Error("failed to claim schedule run", zap.Error(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	parameters := make(map[string]interface{}, len(schedule.Parameters))
+	for key, value := range schedule.Parameters {
+		parameters[key] = value
+	}
+
+	result, err := s.processor.ProcessCommand(ctx, &domain.Command{
+		ID:         fmt.Sprintf("schedule-%s-%d", schedule.ID, now.Unix()),
+		Type:       schedule.CommandType,
+		Parameters: parameters,
+		User:       schedule.User,
+		Source: domain.CommandSource{
+			Platform:  "scheduler",
+			ChannelID: schedule.ChannelID,
+		},
+		Timestamp: now,
+	})
+
+	message := fmt.Sprintf("Scheduled `%s` ", schedule.CommandText)
+	switch {
+	case err != nil:
+		logger.Error("scheduled command failed", zap.Error(err))
+		message += fmt.Sprintf("failed: %v", err)
+	case result.Status != "success":
+		logger.Warn("scheduled command was not successful", zap.String("status", result.Status), zap.String("message", result.Message))
+		message += fmt.Sprintf("did not run: %s", result.Message)
+	default:
+		logger.Info("scheduled command fired", zap.String("message", result.Message))
+		message += fmt.Sprintf("ran: %s", result.Message)
+	}
+
+	if s.notifier != nil && schedule.ChannelID != "" {
+		if err := s.notifier.Notify(ctx, schedule.ChannelID, message); err != nil {
+			logger.Error("failed to notify schedule run", zap.Error(err))
+		}
+	}
+}
+
+// nextScheduleRun returns the first run of a cron expression in a timezone after the given time
+func nextScheduleRun(expression, timezone string, after time.Time) (time.Time, error) {
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+
+	location := time.UTC
+	if timezone != "" {
+		location, err = time.LoadLocation(timezone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+		}
+	}
+
+	return schedule.Next(after.In(location)), nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// handleSubscribe subscribes the channel of the command to events of a repository
+func (cp *CommandProcessor) handleSubscribe(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.subscriptions == nil {
+		return subscriptionsNotConfigured(), nil
+	}
+
+	repoName, _ := cmd.Parameters["repository_name"].(string)
+	events, _ := cmd.Parameters["events"].([]string)
+	if repoName == "" || len(events) == 0 {
+		return nil, domain.Invalid("repository name and events are required")
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionManageSubscriptions) {
+		return nil, forbidden("manage subscriptions")
+	}
+
+	for _, filter := range events {
+		if err := domain.ValidateEventFilter(filter); err != nil {
+			return &domain.CommandResult{
+				Status:  "error",
+				Message: fmt.Sprintf("Cannot subscribe: %v", err),
+			}, nil
+		}
+	}
+	if repoName != domain.AllRepositories {
+		repo, err := cp.repoService.GetRepository(ctx, repoName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repository: %w", err)
+		}
+		repoName = repo.Name
+	}
+
+	subscription := &domain.Subscription{
+		Platform:   cmd.Source.Platform,
+		ChannelID:  cmd.Source.ChannelID,
+		Repository: repoName,
+		Events:     events,
+		CreatedBy:  cmd.User.ID,
+		CreatedAt:  time.Now(),
+	}
+	if err := cp.subscriptions.AddSubscription(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to add subscription: %w", err)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Subscribed this channel to %s as #%s", describeSubscription(subscription), subscription.ID),
+		Details: subscription,
+	}, nil
+}
+
+// handleUnsubscribe removes a subscription of the channel of the command
+func (cp *CommandProcessor) handleUnsubscribe(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.subscriptions == nil {
+		return subscriptionsNotConfigured(), nil
+	}
+
+	id, ok := cmd.Parameters["subscription_id"].(string)
+	if !ok || id == "" {
+		return nil, domain.Invalid("invalid subscription ID")
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionManageSubscriptions) {
+		return nil, forbidden("manage subscriptions")
+	}
+
+	subscription, err := cp.subscriptions.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription %s: %w", id, err)
+	}
+	// Channels manage their own subscriptions only
+	if subscription.Platform != cmd.Source.Platform || subscription.ChannelID != cmd.Source.ChannelID {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Subscription #%s belongs to another channel", id),
+		}, nil
+	}
+
+	if err := cp.subscriptions.DeleteSubscription(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to remove subscription %s: %w", id, err)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Unsubscribed this channel from %s", describeSubscription(subscription)),
+	}, nil
+}
+
+// handleListSubscriptions lists the subscriptions of the channel of the command and
+// whether it is muted
+func (cp *CommandProcessor) handleListSubscriptions(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.subscriptions == nil {
+		return subscriptionsNotConfigured(), nil
+	}
+
+	subscriptions, err := cp.subscriptions.ListChannelSubscriptions(ctx, cmd.Source.Platform, cmd.Source.ChannelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	mute, err := cp.subscriptions.GetChannelMute(ctx, cmd.Source.Platform, cmd.Source.ChannelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel mute: %w", err)
+	}
+
+	var lines []string
+	if len(subscriptions) == 0 {
+		lines = append(lines, "This channel has no subscriptions")
+	} else {
+		lines = append(lines, "Subscriptions of this channel:")
+		for _, s := range subscriptions {
+			lines = append(lines, fmt.Sprintf("• #%s %s, by %s", s.ID, describeSubscription(s), s.CreatedBy))
+		}
+	}
+	if mute != nil && mute.IsActive(time.Now()) {
+		lines = append(lines, describeMute(mute))
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: strings.Join(lines, "\n"),
+		Details: subscriptions,
+	}, nil
+}
+
+// handleMuteChannel silences the subscriptions of the channel of the command, for a
+// duration or until unmuted, or unmutes it
+func (cp *CommandProcessor) handleMuteChannel(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.subscriptions == nil {
+		return subscriptionsNotConfigured(), nil
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionManageSubscriptions) {
+		return nil, forbidden("manage subscriptions")
+	}
+
+	if cmd.Type == domain.CommandTypeUnmuteChannel {
+		if err := cp.subscriptions.UnmuteChannel(ctx, cmd.Source.Platform, cmd.Source.ChannelID); err != nil {
+			return nil, fmt.Errorf("failed to unmute channel: %w", err)
+		}
+		return &domain.CommandResult{
+			Status:  "success",
+			Message: "Notifications of this channel are unmuted",
+		}, nil
+	}
+
+	mute := &domain.ChannelMute{
+		Platform:  cmd.Source.Platform,
+		ChannelID: cmd.Source.ChannelID,
+		MutedBy:   cmd.User.ID,
+	}
+	if duration, ok := cmd.Parameters["duration"].(time.Duration); ok && duration > 0 {
+		mute.Until = time.Now().Add(duration)
+	}
+	if err := cp.subscriptions.MuteChannel(ctx, mute); err != nil {
+		return nil, fmt.Errorf("failed to mute channel: %w", err)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: describeMute(mute),
+		Details: mute,
+	}, nil
+}
+
+func describeSubscription(subscription *domain.Subscription) string {
+	repository := subscription.Repository
+	if repository == domain.AllRepositories {
+		repository = "all repositories"
+	}
+	return fmt.Sprintf("%s of %s", strings.Join(subscription.Events, ", "), repository)
+}
+
+func describeMute(mute *domain.ChannelMute) string {
+	if mute.Until.IsZero() {
+		return fmt.Sprintf("Notifications of this channel are muted by %s until unmuted", mute.MutedBy)
+	}
+	return fmt.Sprintf("Notifications of this channel are muted by %s until %s", mute.MutedBy, mute.Until.UTC().Format(time.RFC3339))
+}
+
+func subscriptionsNotConfigured() *domain.CommandResult {
+	return &domain.CommandResult{
+		Status:  "error",
+		Message: "Subscriptions are not configured",
+	}
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultWebhookInterval    = 5 * time.Second
+	defaultWebhookMaxAttempts = 5
+	defaultWebhookBackoff     = 10 * time.Second
+	maxWebhookBackoff         = time.Hour
+	webhookBatchSize          = 50
+	// maxDeliveryError bounds the response excerpt recorded for a failed attempt
+	maxDeliveryError = 512
+)
+
+// Headers set on every webhook request
+const (
+	WebhookEventHeader     = "X-ChatOps-Event"
+	WebhookDeliveryHeader  = "X-ChatOps-Delivery"
+	WebhookSignatureHeader = "X-ChatOps-Signature-256" // "sha256=" and the hex HMAC of the body
+)
+
+// WebhookService manages outgoing webhooks and delivers the events of the event bus to
+// them. Deliveries are recorded first and attempted on every interval, with exponential
+// backoff between retries. With a leader lock, only the replica holding it delivers.
+type WebhookService struct {
+	logger      *zap.Logger
+	storage     ports.WebhookStorage
+	leader      ports.LeaderLock
+	client      *http.Client
+	interval    time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	now         func() time.Time
+}
+
+type WebhookServiceOptions struct {
+	Logger      *zap.Logger
+	Storage     ports.WebhookStorage
+	Leader      ports.LeaderLock // Optional: every replica delivers when nil
+	HTTPClient  *http.Client     // Optional: defaults to a client with a 10 second timeout
+	Interval    time.Duration    // Optional: how often due deliveries are attempted, defaults to 5 seconds
+	MaxAttempts int              // Optional: attempts before a delivery fails, defaults to 5
+	Backoff     time.Duration    // Optional: delay before the first retry, doubled on every retry; defaults to 10 seconds
+	Clock       func() time.Time // Optional: defaults to time.Now
+}
+
+// NewWebhookService creates a new instance of WebhookService
+func NewWebhookService(opts WebhookServiceOptions) (*WebhookService, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.Storage == nil {
+		return nil, fmt.Errorf("webhook storage is required")
+	}
+
+	service := &WebhookService{
+		logger:      opts.Logger,
+		storage:     opts.Storage,
+		leader:      opts.Leader,
+		client:      opts.HTTPClient,
+		interval:    opts.Interval,
+		maxAttempts: opts.MaxAttempts,
+		backoff:     opts.Backoff,
+		now:         opts.Clock,
+	}
+	if service.client == nil {
+		service.client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	if service.interval <= 0 {
+		service.interval = defaultWebhookInterval
+	}
+	if service.maxAttempts <= 0 {
+		service.maxAttempts = defaultWebhookMaxAttempts
+	}
+	if service.backoff <= 0 {
+		service.backoff = defaultWebhookBackoff
+	}
+	if service.now == nil {
+		service.now = time.Now
+	}
+
+	return service, nil
+}
+
+// ValidateWebhook checks the URL, event filters and headers of a webhook
+func ValidateWebhook(webhook *domain.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if len(webhook.Events) == 0 {
+		return fmt.Errorf("at least one event is required")
+	}
+	for _, filter := range webhook.Events {
+		if err := domain.ValidateEventFilter(filter); err != nil {
+			return err
+		}
+	}
+	for name := range webhook.Headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+	return nil
+}
+
+// CreateWebhook validates and stores a new webhook
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	if err := ValidateWebhook(webhook); err != nil {
+		return err
+	}
+
+	webhook.CreatedAt = s.now()
+	webhook.UpdatedAt = webhook.CreatedAt
+	if err := s.storage.AddWebhook(ctx, webhook); err != nil {
+		return fmt.Errorf("failed to add webhook: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhook validates and stores the changes to a webhook
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	if err := ValidateWebhook(webhook); err != nil {
+		return err
+	}
+
+	webhook.UpdatedAt = s.now()
+	if err := s.storage.UpdateWebhook(ctx, webhook); err != nil {
+		return fmt.Errorf("failed to update webhook %s: %w", webhook.ID, err)
+	}
+	return nil
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	webhook, err := s.storage.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook %s: %w", id, err)
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	webhooks, err := s.storage.ListWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id string) error {
+	if err := s.storage.DeleteWebhook(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook %s: %w", id, err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first
+func (s *WebhookService) ListDeliveries(ctx context.Context, id string, limit int) ([]*domain.WebhookDelivery, error) {
+	deliveries, err := s.storage.ListDeliveries(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries of webhook %s: %w", id, err)
+	}
+	return deliveries, nil
+}
+
+// webhookBody is the JSON body posted to webhooks
+type webhookBody struct {
+	ID         string      `json:"id,omitempty"`
+	Event      string      `json:"event"`
+	Repository string      `json:"repository,omitempty"`
+	Actor      string      `json:"actor,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data,omitempty"`
+}
+
+// HandleEvent records a pending delivery for every active webhook matching the event
+func (s *WebhookService) HandleEvent(ctx context.Context, event *domain.Event) error {
+	webhooks, err := s.storage.ListWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	var body []byte
+	for _, webhook := range webhooks {
+		if !webhook.Matches(event) {
+			continue
+		}
+		if body == nil {
+			body, err = json.Marshal(webhookBody{
+				ID:         event.ID,
+				Event:      event.Type,
+				Repository: event.Repository,
+				Actor:      event.Actor,
+				Message:    event.Message,
+				OccurredAt: event.OccurredAt,
+				Data:       event.Payload,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to encode event: %w", err)
+			}
+		}
+
+		now := s.now()
+		delivery := &domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Body:          string(body),
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := s.storage.AddDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to record delivery to webhook %s: %w", webhook.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Run attempts due deliveries on every interval until the context is cancelled, then
+// releases the leader lock
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if s.leader != nil {
+				if err := s.leader.Release(context.Background()); err != nil {
+					s.logger.Error("failed to release webhook leader lock", zap.Error(err))
+				}
+			}
+			return
+		case <-ticker.C:
+			if err := s.Tick(ctx); err != nil {
+				s.logger.Error("failed to deliver webhooks", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Tick attempts every due delivery once, provided this replica is the leader
+func (s *WebhookService) Tick(ctx context.Context) error {
+	if s.leader != nil {
+		leader, err := s.leader.TryAcquire(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acquire leader lock: %w", err)
+		}
+		if !leader {
+			return nil
+		}
+	}
+
+	due, err := s.storage.ListDueDeliveries(ctx, s.now(), webhookBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list due deliveries: %w", err)
+	}
+
+	webhooks := make(map[string]*domain.Webhook)
+	for _, delivery := range due {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			if webhook, err = s.storage.GetWebhook(ctx, delivery.WebhookID); err != nil {
+				s.logger.Error("failed to get webhook", zap.String("webhook_id", delivery.WebhookID), zap.Error(err))
+				continue
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+		s.attempt(ctx, webhook, delivery)
+	}
+
+	return nil
+}
+
+// attempt posts a delivery once and records the outcome, scheduling a retry on failure
+// until the attempts run out
+func (s *WebhookService) attempt(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) {
+	logger := s.logger.With(zap.String("webhook_id", webhook.ID), zap.String("delivery_id", delivery.ID))
+
+	delivery.Attempts++
+	err := s.post(ctx, webhook, delivery)
+	now := s.now()
+	switch {
+	case err == nil:
+		delivery.Status = domain.DeliveryStatusSuccess
+		delivery.Error = ""
+		delivery.DeliveredAt = now
+	case !webhook.Active || delivery.Attempts >= s.maxAttempts:
+		delivery.Status = domain.DeliveryStatusFailed
+		delivery.Error = err.Error()
+		logger.Warn("webhook delivery failed", zap.Int("attempts", delivery.Attempts), zap.Error(err))
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = now.Add(s.retryDelay(delivery.Attempts))
+	}
+
+	if err := s.storage.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Error("failed to record delivery attempt", zap.Error(err))
+	}
+}
+
+// retryDelay doubles the backoff after every failed attempt
+func (s *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := s.backoff
+	for i := 1; i < attempts && delay < maxWebhookBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxWebhookBackoff)
+}
+
+func (s *WebhookService) post(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) error {
+	delivery.ResponseCode = 0
+	if !webhook.Active {
+		return fmt.Errorf("webhook is inactive")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Body))
+	if err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ChatOps-Webhook")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	if webhook.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookBody(webhook.Secret, []byte(delivery.Body)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	delivery.ResponseCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxDeliveryError))
+		if len(bytes.TrimSpace(excerpt)) == 0 {
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(excerpt))
+	}
+	return nil
+}
+
+// SignWebhookBody returns the hex HMAC-SHA256 of a webhook body, which receivers compare
+// against the signature header
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// handleStartWorkflow starts a workflow as the requesting user
+func (cp *CommandProcessor) handleStartWorkflow(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.workflows == nil {
+		return workflowsNotConfigured(), nil
+	}
+
+	name, ok := cmd.Parameters["workflow_name"].(string)
+	if !ok || name == "" {
+		return nil, domain.Invalid("invalid workflow name")
+	}
+	inputs, _ := cmd.Parameters["inputs"].(map[string]interface{})
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionRunWorkflow) {
+		return nil, forbidden(fmt.Sprintf("run workflow %s", name))
+	}
+
+	workflow := &domain.Workflow{
+		Name:       name,
+		Parameters: inputs,
+		StartedBy:  cmd.User,
+		ChannelID:  cmd.Source.ChannelID,
+	}
+	if err := cp.workflows.ExecuteWorkflow(ctx, workflow); err != nil {
+		// Unknown workflows and invalid inputs are reported before anything is stored
+		if workflow.ID == "" {
+			return &domain.CommandResult{
+				Status:  "error",
+				Message: fmt.Sprintf("Cannot start workflow %s: %v", name, err),
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to run workflow %s: %w", name, err)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Started workflow %s as #%s\n%s", name, workflow.ID, cp.describeWorkflow(ctx, workflow)),
+		Details: workflow,
+	}, nil
+}
+
+// handleWorkflowStatus shows the state of every step of a workflow execution
+func (cp *CommandProcessor) handleWorkflowStatus(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.workflows == nil {
+		return workflowsNotConfigured(), nil
+	}
+
+	id, ok := cmd.Parameters["workflow_id"].(string)
+	if !ok || id == "" {
+		return nil, domain.Invalid("invalid workflow ID")
+	}
+
+	workflow, err := cp.workflows.GetWorkflow(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow %s: %w", id, err)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("Workflow %s (#%s) is %s\n%s", workflow.Name, workflow.ID, workflow.Status, cp.describeWorkflow(ctx, workflow)),
+		Details: workflow,
+	}, nil
+}
+
+// handleListWorkflows lists the workflows that can be started
+func (cp *CommandProcessor) handleListWorkflows(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.workflows == nil {
+		return workflowsNotConfigured(), nil
+	}
+
+	definitions := cp.workflows.ListDefinitions(ctx)
+	if len(definitions) == 0 {
+		return &domain.CommandResult{
+			Status:  "success",
+			Message: "No workflows are defined",
+		}, nil
+	}
+
+	lines := []string{"Workflows:"}
+	for _, definition := range definitions {
+		line := fmt.Sprintf("• %s (%d steps)", definition.Name, len(definition.Steps))
+		if definition.Description != "" {
+			line = fmt.Sprintf("%s: %s", line, definition.Description)
+		}
+		if len(definition.Inputs) > 0 {
+			names := make([]string, 0, len(definition.Inputs))
+			for name := range definition.Inputs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			line = fmt.Sprintf("%s, inputs: %s", line, strings.Join(names, ", "))
+		}
+		lines = append(lines, line)
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: strings.Join(lines, "\n"),
+		Details: definitions,
+	}, nil
+}
+
+// handleResolveApproval approves or rejects a waiting approval step
+func (cp *CommandProcessor) handleResolveApproval(ctx context.Context, cmd *domain.Command) (*domain.CommandResult, error) {
+	if cp.workflows == nil {
+		return workflowsNotConfigured(), nil
+	}
+
+	id, _ := cmd.Parameters["workflow_id"].(string)
+	stepID, _ := cmd.Parameters["step_id"].(string)
+	if id == "" || stepID == "" {
+		return nil, domain.Invalid("workflow ID and step are required")
+	}
+
+	approved := cmd.Type == domain.CommandTypeApproveStep
+	action, outcome := "approve", "Approved"
+	if !approved {
+		action, outcome = "reject", "Rejected"
+	}
+
+	if !cp.isAllowed(ctx, cmd.User, domain.PermissionApproveWorkflow) {
+		return nil, forbidden(fmt.Sprintf("%s workflow steps", action))
+	}
+
+	if err := cp.workflows.ResolveApproval(ctx, id, stepID, cmd.User, approved); err != nil {
+		return &domain.CommandResult{
+			Status:  "error",
+			Message: fmt.Sprintf("Cannot %s step %s of workflow #%s: %v", action, stepID, id, err),
+		}, nil
+	}
+
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: fmt.Sprintf("%s step %s of workflow #%s", outcome, stepID, id),
+	}, nil
+}
+
+// describeWorkflow lists the steps of an execution in definition order
+func (cp *CommandProcessor) describeWorkflow(ctx context.Context, workflow *domain.Workflow) string {
+	var ids []string
+	for _, definition := range cp.workflows.ListDefinitions(ctx) {
+		if definition.Name == workflow.Name {
+			for _, step := range definition.Steps {
+				ids = append(ids, step.ID)
+			}
+		}
+	}
+	if ids == nil {
+		for id := range workflow.Steps {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+	}
+
+	lines := make([]string, 0, len(ids))
+	for _, id := range ids {
+		state, ok := workflow.Steps[id]
+		if !ok {
+			continue
+		}
+		line := fmt.Sprintf("• %s: %s", id, state.Status)
+		if state.Error != "" {
+			line = fmt.Sprintf("%s (%s)", line, state.Error)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func workflowsNotConfigured() *domain.CommandResult {
+	return &domain.CommandResult{
+		Status:  "error",
+		Message: "Workflows are not configured",
+	}
+}
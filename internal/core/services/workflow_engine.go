@@ -0,0 +1,562 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultWorkflowInterval = 15 * time.Second
+	defaultWebhookTimeout   = 10 * time.Second
+
+	// stepRunTimeout bounds how long a step may run. Steps still running after it were
+	// interrupted by a replica stopping.
+	stepRunTimeout = 5 * time.Minute
+	// maxWorkflowUpdateAttempts bounds the retries of updates that conflict with another replica
+	maxWorkflowUpdateAttempts = 5
+)
+
+// WorkflowEngine executes multi-step workflows. Execution state is persisted after every
+// step, so running workflows resume where they stopped when the engine restarts. Steps
+// still running after the step run timeout were interrupted and fail rather than run
+// twice. Executions are updated with a version compare-and-swap, so replicas advancing
+// the same execution never overwrite each other's progress.
+type WorkflowEngine struct {
+	logger      *zap.Logger
+	processor   *CommandProcessor
+	storage     ports.WorkflowStorage
+	notifier    ports.Notifier
+	leader      ports.LeaderLock
+	client      *http.Client
+	interval    time.Duration
+	now         func() time.Time
+	definitions map[string]domain.WorkflowDefinition
+
+	// mu serializes the advancement of executions within this replica
+	mu sync.Mutex
+}
+
+type WorkflowEngineOptions struct {
+	Logger      *zap.Logger
+	Processor   *CommandProcessor
+	Storage     ports.WorkflowStorage
+	Definitions []domain.WorkflowDefinition
+	Notifier    ports.Notifier   // Optional: post_message steps fail and approvals are not announced when nil
+	Leader      ports.LeaderLock // Optional: every replica polls running workflows when nil
+	HTTPClient  *http.Client     // Optional: used by webhook steps, defaults to a client with a 10 second timeout
+	Interval    time.Duration    // Optional: how often waiting steps are polled, defaults to 15 seconds
+	Clock       func() time.Time // Optional: defaults to time.Now
+}
+
+// NewWorkflowEngine creates a new instance of WorkflowEngine, validating the workflow definitions
+func NewWorkflowEngine(opts WorkflowEngineOptions) (*WorkflowEngine, error) {
+	if opts.Logger == nil {
+		return nil, fmt.Errorf("logger is required")
+	}
+	if opts.Processor == nil {
+		return nil, fmt.Errorf("command processor is required")
+	}
+	if opts.Storage == nil {
+		return nil, fmt.Errorf("workflow storage is required")
+	}
+
+	engine := &WorkflowEngine{
+		logger:      opts.Logger,
+		processor:   opts.Processor,
+		storage:     opts.Storage,
+		notifier:    opts.Notifier,
+		leader:      opts.Leader,
+		client:      opts.HTTPClient,
+		interval:    opts.Interval,
+		now:         opts.Clock,
+		definitions: make(map[string]domain.WorkflowDefinition, len(opts.Definitions)),
+	}
+	if engine.client == nil {
+		engine.client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	if engine.interval <= 0 {
+		engine.interval = defaultWorkflowInterval
+	}
+	if engine.now == nil {
+		engine.now = time.Now
+	}
+
+	for _, definition := range opts.Definitions {
+		if _, exists := engine.definitions[definition.Name]; exists {
+			return nil, fmt.Errorf("workflow %s is defined more than once", definition.Name)
+		}
+		normalized, err := normalizeWorkflow(definition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid workflow %s: %w", definition.Name, err)
+		}
+		engine.definitions[definition.Name] = normalized
+	}
+
+	return engine, nil
+}
+
+// ExecuteWorkflow starts an execution of the definition named by workflow.Name and runs
+// its steps until they finish or wait
+func (e *WorkflowEngine) ExecuteWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	definition, ok := e.definitions[workflow.Name]
+	if !ok {
+		return fmt.Errorf("workflow %s does not exist", workflow.Name)
+	}
+
+	inputs, err := workflowInputs(definition, workflow.Parameters)
+	if err != nil {
+		return err
+	}
+
+	now := e.now()
+	workflow.Path = definition.Path
+	workflow.Parameters = inputs
+	workflow.Status = domain.WorkflowStatusRunning
+	workflow.Steps = make(map[string]*domain.StepState, len(definition.Steps))
+	for _, step := range definition.Steps {
+		workflow.Steps[step.ID] = &domain.StepState{Status: domain.StepStatusPending}
+	}
+	workflow.CreatedAt = now
+	workflow.UpdatedAt = now
+
+	if err := e.storage.AddWorkflow(ctx, workflow); err != nil {
+		return fmt.Errorf("failed to add workflow: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.advance(ctx, workflow)
+}
+
+// GetWorkflowStatus summarizes the progress of a workflow execution
+func (e *WorkflowEngine) GetWorkflowStatus(ctx context.Context, workflowID string) (*domain.WorkflowStatus, error) {
+	workflow, err := e.storage.GetWorkflow(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	finished := 0
+	var failures []string
+	for id, state := range workflow.Steps {
+		if state.IsFinished() {
+			finished++
+		}
+		if state.Status == domain.StepStatusFailure {
+			failures = append(failures, fmt.Sprintf("%s: %s", id, state.Error))
+		}
+	}
+	sort.Strings(failures)
+
+	status := &domain.WorkflowStatus{
+		ID:        workflow.ID,
+		Status:    workflow.Status,
+		Error:     strings.Join(failures, "; "),
+		UpdatedAt: workflow.UpdatedAt,
+	}
+	if len(workflow.Steps) > 0 {
+		status.Progress = finished * 100 / len(workflow.Steps)
+	}
+	return status, nil
+}
+
+// GetWorkflow returns a workflow execution with the state of its steps
+func (e *WorkflowEngine) GetWorkflow(ctx context.Context, workflowID string) (*domain.Workflow, error) {
+	return e.storage.GetWorkflow(ctx, workflowID)
+}
+
+// ListDefinitions returns the workflow definitions sorted by name
+func (e *WorkflowEngine) ListDefinitions(ctx context.Context) []domain.WorkflowDefinition {
+	definitions := make([]domain.WorkflowDefinition, 0, len(e.definitions))
+	for _, definition := range e.definitions {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions
+}
+
+// ResolveApproval approves or rejects a waiting approval step and continues the workflow
+func (e *WorkflowEngine) ResolveApproval(ctx context.Context, workflowID, stepID string, user domain.User, approved bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		workflow, err := e.resolveApproval(ctx, workflowID, stepID, user, approved)
+		if errors.Is(err, domain.ErrConflict) && attempt < maxWorkflowUpdateAttempts {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return e.advance(ctx, workflow)
+	}
+}
+
+// resolveApproval records the decision on the latest version of the workflow
+func (e *WorkflowEngine) resolveApproval(ctx context.Context, workflowID, stepID string, user domain.User, approved bool) (*domain.Workflow, error) {
+	workflow, err := e.storage.GetWorkflow(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, ok := e.definitions[workflow.Name]
+	if !ok {
+		return nil, fmt.Errorf("workflow %s no longer exists", workflow.Name)
+	}
+	step := definitionStep(definition, stepID)
+	state := workflow.Steps[stepID]
+	if step == nil || step.Type != domain.StepTypeApproval || state == nil {
+		return nil, fmt.Errorf("step %s of workflow #%s is not an approval", stepID, workflow.ID)
+	}
+	if state.Status != domain.StepStatusWaiting {
+		return nil, fmt.Errorf("step %s of workflow #%s is not waiting for approval", stepID, workflow.ID)
+	}
+	if len(step.Approvers) > 0 && !slices.Contains(step.Approvers, user.ID) {
+		return nil, fmt.Errorf("only %s may approve step %s", strings.Join(step.Approvers, ", "), stepID)
+	}
+
+	state.Outputs = map[string]interface{}{"approved": approved, "approved_by": user.ID}
+	if approved {
+		e.finishStep(state, domain.StepStatusSuccess, "")
+	} else {
+		e.finishStep(state, domain.StepStatusFailure, fmt.Sprintf("rejected by %s", user.ID))
+	}
+	if err := e.save(ctx, workflow); err != nil {
+		return nil, err
+	}
+	return workflow, nil
+}
+
+// ListPendingApprovals returns the waiting approval steps the user is an approver of.
+// Steps without approvers may be resolved by anyone.
+func (e *WorkflowEngine) ListPendingApprovals(ctx context.Context, user domain.User) ([]domain.PendingApproval, error) {
+	workflows, err := e.storage.ListActiveWorkflows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active workflows: %w", err)
+	}
+
+	var approvals []domain.PendingApproval
+	for _, workflow := range workflows {
+		definition, ok := e.definitions[workflow.Name]
+		if !ok {
+			continue
+		}
+		for _, step := range definition.Steps {
+			state := workflow.Steps[step.ID]
+			if step.Type != domain.StepTypeApproval || state == nil || state.Status != domain.StepStatusWaiting {
+				continue
+			}
+			if len(step.Approvers) > 0 && !slices.Contains(step.Approvers, user.ID) {
+				continue
+			}
+			approvals = append(approvals, domain.PendingApproval{
+				WorkflowID:   workflow.ID,
+				WorkflowName: workflow.Name,
+				StepID:       step.ID,
+				RequestedAt:  state.StartedAt,
+			})
+		}
+	}
+	return approvals, nil
+}
+
+// Run resumes running workflows, then polls their waiting steps on every interval until
+// the context is cancelled
+func (e *WorkflowEngine) Run(ctx context.Context) {
+	if err := e.Tick(ctx); err != nil {
+		e.logger.Error("failed to resume workflows", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if e.leader != nil {
+				if err := e.leader.Release(context.Background()); err != nil {
+					e.logger.Error("failed to release workflow leader lock", zap.Error(err))
+				}
+			}
+			return
+		case <-ticker.C:
+			if err := e.Tick(ctx); err != nil {
+				e.logger.Error("failed to advance workflows", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Tick advances every running workflow once, provided this replica is the leader
+func (e *WorkflowEngine) Tick(ctx context.Context) error {
+	if e.leader != nil {
+		leader, err := e.leader.TryAcquire(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acquire leader lock: %w", err)
+		}
+		if !leader {
+			return nil
+		}
+	}
+
+	active, err := e.storage.ListActiveWorkflows(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list active workflows: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, listed := range active {
+		// Reloaded under the lock, since another call may have advanced it after the listing
+		workflow, err := e.storage.GetWorkflow(ctx, listed.ID)
+		if err == nil {
+			err = e.advance(ctx, workflow)
+		}
+		if err != nil {
+			e.logger.Error("failed to advance workflow",
+				zap.String("workflow_id", listed.ID),
+				zap.String("workflow", listed.Name),
+				zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// advance advances the workflow, re-reading it and trying again when another replica
+// updated it concurrently. Callers must hold mu.
+func (e *WorkflowEngine) advance(ctx context.Context, workflow *domain.Workflow) error {
+	for attempt := 1; ; attempt++ {
+		err := e.advanceOnce(ctx, workflow)
+		if !errors.Is(err, domain.ErrConflict) || attempt == maxWorkflowUpdateAttempts {
+			return err
+		}
+		if err := e.reload(ctx, workflow); err != nil {
+			return err
+		}
+	}
+}
+
+// advanceOnce starts every step whose dependencies finished and polls waiting steps
+// until no step makes progress, persisting each change
+func (e *WorkflowEngine) advanceOnce(ctx context.Context, workflow *domain.Workflow) error {
+	if workflow.Status != domain.WorkflowStatusRunning {
+		return nil
+	}
+
+	definition, ok := e.definitions[workflow.Name]
+	if !ok {
+		workflow.Status = domain.WorkflowStatusFailed
+		e.notify(ctx, workflow.ChannelID, fmt.Sprintf("Workflow %s (#%s) failed: its definition no longer exists", workflow.Name, workflow.ID))
+		return e.save(ctx, workflow)
+	}
+
+	for progressed := true; progressed; {
+		progressed = false
+		for i := range definition.Steps {
+			step := &definition.Steps[i]
+			state, ok := workflow.Steps[step.ID]
+			if !ok {
+				// Steps added to the definition after the workflow started
+				state = &domain.StepState{Status: domain.StepStatusPending}
+				workflow.Steps[step.ID] = state
+			}
+
+			var changed bool
+			var err error
+			switch state.Status {
+			case domain.StepStatusRunning:
+				// Another call, possibly on another replica, may be running the step. Only
+				// steps left running by a replica that stopped outlive the step run timeout.
+				if e.now().Sub(state.StartedAt) > stepRunTimeout {
+					from := *state
+					e.finishStep(state, domain.StepStatusFailure, "interrupted before completion")
+					changed, err = true, e.saveStep(ctx, workflow, step.ID, from)
+				}
+			case domain.StepStatusPending:
+				changed, err = e.startStep(ctx, workflow, step, state)
+			case domain.StepStatusWaiting:
+				from := *state
+				if changed = e.pollStep(ctx, workflow, step, state); changed {
+					err = e.saveStep(ctx, workflow, step.ID, from)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			progressed = progressed || changed
+		}
+	}
+
+	return e.complete(ctx, workflow)
+}
+
+// startStep runs a pending step once its dependencies finished. A step without a
+// condition is skipped unless every dependency succeeded, while a step with a condition
+// runs whenever the condition holds.
+func (e *WorkflowEngine) startStep(ctx context.Context, workflow *domain.Workflow, step *domain.WorkflowStep, state *domain.StepState) (bool, error) {
+	succeeded := true
+	for _, need := range step.Needs {
+		dependency := workflow.Steps[need]
+		if dependency == nil || !dependency.IsFinished() {
+			return false, nil
+		}
+		succeeded = succeeded && dependency.Status == domain.StepStatusSuccess
+	}
+
+	data := templateData(workflow)
+	pending := *state
+	if step.If == "" && !succeeded {
+		e.finishStep(state, domain.StepStatusSkipped, "")
+		return true, e.saveStep(ctx, workflow, step.ID, pending)
+	}
+	if step.If != "" {
+		run, err := evaluateCondition(step.If, data)
+		if err != nil {
+			e.finishStep(state, domain.StepStatusFailure, err.Error())
+			return true, e.saveStep(ctx, workflow, step.ID, pending)
+		}
+		if !run {
+			e.finishStep(state, domain.StepStatusSkipped, "")
+			return true, e.saveStep(ctx, workflow, step.ID, pending)
+		}
+	}
+
+	// Persisted before running, so a step is never run twice after a restart. The update
+	// conflicts when another replica started the step first.
+	state.Status = domain.StepStatusRunning
+	state.StartedAt = e.now()
+	if err := e.save(ctx, workflow); err != nil {
+		return false, err
+	}
+	claimed := *state
+
+	runCtx, cancel := context.WithTimeout(ctx, stepRunTimeout)
+	defer cancel()
+	e.runStep(runCtx, workflow, step, state, data)
+	return true, e.saveStep(ctx, workflow, step.ID, claimed)
+}
+
+// complete finishes the workflow once every step finished and announces the outcome
+func (e *WorkflowEngine) complete(ctx context.Context, workflow *domain.Workflow) error {
+	failed := false
+	for _, state := range workflow.Steps {
+		if !state.IsFinished() {
+			return nil
+		}
+		failed = failed || state.Status == domain.StepStatusFailure
+	}
+
+	workflow.Status = domain.WorkflowStatusSucceeded
+	if failed {
+		workflow.Status = domain.WorkflowStatusFailed
+	}
+	if err := e.save(ctx, workflow); err != nil {
+		return err
+	}
+
+	e.logger.Info("workflow finished",
+		zap.String("workflow_id", workflow.ID),
+		zap.String("workflow", workflow.Name),
+		zap.String("status", workflow.Status))
+	e.notify(ctx, workflow.ChannelID, fmt.Sprintf("Workflow %s (#%s) %s", workflow.Name, workflow.ID, workflow.Status))
+	return nil
+}
+
+func (e *WorkflowEngine) finishStep(state *domain.StepState, status, errorMessage string) {
+	state.Status = status
+	state.Error = errorMessage
+	state.FinishedAt = e.now()
+}
+
+func (e *WorkflowEngine) save(ctx context.Context, workflow *domain.Workflow) error {
+	workflow.UpdatedAt = e.now()
+	if err := e.storage.UpdateWorkflow(ctx, workflow); err != nil {
+		return fmt.Errorf("failed to update workflow %s: %w", workflow.ID, err)
+	}
+	return nil
+}
+
+// saveStep persists a change of a step. When another replica updated the workflow
+// meanwhile, the change is applied to the latest version, provided the step there is
+// still in the state the change started from.
+func (e *WorkflowEngine) saveStep(ctx context.Context, workflow *domain.Workflow, stepID string, from domain.StepState) error {
+	state := workflow.Steps[stepID]
+	for attempt := 1; ; attempt++ {
+		err := e.save(ctx, workflow)
+		if !errors.Is(err, domain.ErrConflict) || attempt == maxWorkflowUpdateAttempts {
+			return err
+		}
+
+		if err := e.reload(ctx, workflow); err != nil {
+			return err
+		}
+		current := workflow.Steps[stepID]
+		if current == nil || current.Status != from.Status || !current.StartedAt.Equal(from.StartedAt) {
+			return domain.Conflict("step %s of workflow %s was changed concurrently", stepID, workflow.ID)
+		}
+		workflow.Steps[stepID] = state
+	}
+}
+
+// reload replaces the workflow with its latest stored version
+func (e *WorkflowEngine) reload(ctx context.Context, workflow *domain.Workflow) error {
+	latest, err := e.storage.GetWorkflow(ctx, workflow.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload workflow %s: %w", workflow.ID, err)
+	}
+	*workflow = *latest
+	return nil
+}
+
+// notify posts a message when a notifier is configured. Failures are logged only.
+func (e *WorkflowEngine) notify(ctx context.Context, channelID, message string) {
+	if e.notifier == nil || channelID == "" {
+		return
+	}
+	if err := e.notifier.Notify(ctx, channelID, message); err != nil {
+		e.logger.Error("failed to post workflow message", zap.String("channel_id", channelID), zap.Error(err))
+	}
+}
+
+// workflowInputs merges the given parameters over the input defaults. Inputs without a
+// default are required, and undeclared parameters are rejected.
+func workflowInputs(definition domain.WorkflowDefinition, params map[string]interface{}) (map[string]interface{}, error) {
+	inputs := make(map[string]interface{}, len(definition.Inputs))
+	for name, value := range definition.Inputs {
+		if value != "" {
+			inputs[name] = value
+		}
+	}
+	for name, value := range params {
+		if _, ok := definition.Inputs[name]; !ok {
+			return nil, fmt.Errorf("workflow %s has no input %s", definition.Name, name)
+		}
+		inputs[name] = value
+	}
+	for name := range definition.Inputs {
+		if _, ok := inputs[name]; !ok {
+			return nil, fmt.Errorf("input %s of workflow %s is required", name, definition.Name)
+		}
+	}
+	return inputs, nil
+}
+
+func definitionStep(definition domain.WorkflowDefinition, id string) *domain.WorkflowStep {
+	for i := range definition.Steps {
+		if definition.Steps[i].ID == id {
+			return &definition.Steps[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,498 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"go.uber.org/zap"
+)
+
+// maxWebhookResponse bounds the webhook response body kept in step outputs
+const maxWebhookResponse = 64 * 1024
+
+// normalizeWorkflow validates a definition. Steps of a workflow where no step declares
+// needs run in sequence, so each of them is made to need the step before it.
+func normalizeWorkflow(definition domain.WorkflowDefinition) (domain.WorkflowDefinition, error) {
+	if definition.Name == "" {
+		return definition, fmt.Errorf("name is required")
+	}
+	if len(definition.Steps) == 0 {
+		return definition, fmt.Errorf("at least one step is required")
+	}
+
+	steps := make([]domain.WorkflowStep, len(definition.Steps))
+	copy(steps, definition.Steps)
+
+	sequential := true
+	for _, step := range steps {
+		if len(step.Needs) > 0 {
+			sequential = false
+			break
+		}
+	}
+
+	types := make(map[string]string, len(steps))
+	for i := range steps {
+		step := &steps[i]
+		if step.ID == "" {
+			return definition, fmt.Errorf("step %d has no id", i+1)
+		}
+		if _, exists := types[step.ID]; exists {
+			return definition, fmt.Errorf("step %s is defined more than once", step.ID)
+		}
+		types[step.ID] = step.Type
+		if sequential && i > 0 {
+			step.Needs = []string{steps[i-1].ID}
+		}
+	}
+
+	for i := range steps {
+		step := &steps[i]
+		if err := validateStep(step, types); err != nil {
+			return definition, fmt.Errorf("step %s: %w", step.ID, err)
+		}
+	}
+
+	if err := checkAcyclic(steps); err != nil {
+		return definition, err
+	}
+
+	definition.Steps = steps
+	return definition, nil
+}
+
+func validateStep(step *domain.WorkflowStep, types map[string]string) error {
+	for _, need := range step.Needs {
+		if _, ok := types[need]; !ok {
+			return fmt.Errorf("needs unknown step %s", need)
+		}
+	}
+
+	switch step.Type {
+	case domain.StepTypeTriggerPipeline:
+		if step.Repository == "" {
+			return fmt.Errorf("repository is required")
+		}
+	case domain.StepTypeWaitForStatus:
+		if types[step.Run] != domain.StepTypeTriggerPipeline {
+			return fmt.Errorf("run must name a %s step", domain.StepTypeTriggerPipeline)
+		}
+		// The awaited run must be triggered before the wait starts
+		if !slices.Contains(step.Needs, step.Run) {
+			step.Needs = append(step.Needs, step.Run)
+		}
+	case domain.StepTypeApproval:
+	case domain.StepTypePostMessage:
+		if step.Message == "" {
+			return fmt.Errorf("message is required")
+		}
+	case domain.StepTypeWebhook:
+		if step.URL == "" {
+			return fmt.Errorf("url is required")
+		}
+	default:
+		return fmt.Errorf("unknown step type %q", step.Type)
+	}
+
+	for _, text := range stepTemplates(step) {
+		if _, err := parseStepTemplate(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAcyclic rejects definitions whose needs form a cycle
+func checkAcyclic(steps []domain.WorkflowStep) error {
+	needs := make(map[string][]string, len(steps))
+	for _, step := range steps {
+		needs[step.ID] = step.Needs
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(steps))
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("step %s depends on itself", id)
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		for _, need := range needs[id] {
+			if err := visit(need); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+
+	for _, step := range steps {
+		if err := visit(step.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stepTemplates(step *domain.WorkflowStep) []string {
+	templates := []string{step.If, step.Repository, step.Pipeline, step.Environment, step.Ref,
+		step.Channel, step.Message, step.URL, step.Body}
+	for _, value := range step.Headers {
+		templates = append(templates, value)
+	}
+	return templates
+}
+
+func parseStepTemplate(text string) (*template.Template, error) {
+	return template.New("step").Option("missingkey=zero").Parse(text)
+}
+
+// templateData exposes the workflow to step templates as .workflow, .inputs and .steps,
+// where each step has a status, error and outputs
+func templateData(workflow *domain.Workflow) map[string]interface{} {
+	steps := make(map[string]interface{}, len(workflow.Steps))
+	for id, state := range workflow.Steps {
+		steps[id] = map[string]interface{}{
+			"status":  state.Status,
+			"error":   state.Error,
+			"outputs": state.Outputs,
+		}
+	}
+
+	return map[string]interface{}{
+		"workflow": map[string]interface{}{
+			"id":         workflow.ID,
+			"name":       workflow.Name,
+			"started_by": workflow.StartedBy.ID,
+		},
+		"inputs": workflow.Parameters,
+		"steps":  steps,
+	}
+}
+
+func renderTemplate(text string, data map[string]interface{}) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := parseStepTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// evaluateCondition renders a condition, which must produce true or false
+func evaluateCondition(condition string, data map[string]interface{}) (bool, error) {
+	rendered, err := renderTemplate(condition, data)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition: %w", err)
+	}
+
+	switch strings.TrimSpace(rendered) {
+	case "true":
+		return true, nil
+	case "false", "":
+		return false, nil
+	default:
+		return false, fmt.Errorf("condition rendered %q instead of true or false", rendered)
+	}
+}
+
+// runStep runs a started step, leaving it finished or waiting
+func (e *WorkflowEngine) runStep(ctx context.Context, workflow *domain.Workflow, step *domain.WorkflowStep, state *domain.StepState, data map[string]interface{}) {
+	var err error
+	switch step.Type {
+	case domain.StepTypeTriggerPipeline:
+		err = e.triggerPipeline(ctx, workflow, step, state, data)
+	case domain.StepTypeWaitForStatus:
+		run := workflow.Steps[step.Run]
+		runID, _ := run.Outputs["run_id"].(string)
+		if runID == "" {
+			err = fmt.Errorf("step %s did not report a run ID", step.Run)
+			break
+		}
+		state.Status = domain.StepStatusWaiting
+		state.Outputs = map[string]interface{}{"run_id": runID, "repository": run.Outputs["repository"]}
+		e.pollStep(ctx, workflow, step, state)
+	case domain.StepTypeApproval:
+		err = e.requestApproval(ctx, workflow, step, state, data)
+	case domain.StepTypePostMessage:
+		err = e.postMessage(ctx, workflow, step, state, data)
+	case domain.StepTypeWebhook:
+		err = e.callWebhook(ctx, step, state, data)
+	}
+
+	if err != nil {
+		e.finishStep(state, domain.StepStatusFailure, err.Error())
+	}
+}
+
+// pollStep checks a waiting step and reports whether it finished
+func (e *WorkflowEngine) pollStep(ctx context.Context, workflow *domain.Workflow, step *domain.WorkflowStep, state *domain.StepState) bool {
+	if step.Timeout > 0 && e.now().Sub(state.StartedAt) > step.Timeout {
+		e.finishStep(state, domain.StepStatusFailure, fmt.Sprintf("timed out after %s", step.Timeout))
+		return true
+	}
+	if step.Type != domain.StepTypeWaitForStatus {
+		// Approvals finish through ResolveApproval
+		return false
+	}
+
+	repoName, _ := state.Outputs["repository"].(string)
+	runID, _ := state.Outputs["run_id"].(string)
+	runID, status, err := e.runStatus(ctx, repoName, runID)
+	if errors.Is(err, domain.ErrConflict) {
+		e.finishStep(state, domain.StepStatusFailure, err.Error())
+		return true
+	}
+	state.Outputs["run_id"] = runID
+	if err != nil {
+		e.logger.Warn("failed to get workflow run status",
+			zap.String("workflow_id", workflow.ID),
+			zap.String("step", step.ID),
+			zap.String("run_id", runID),
+			zap.Error(err))
+		return false
+	}
+
+	switch deploymentStatus(status) {
+	case domain.DeploymentStatusSuccess:
+		state.Outputs["status"] = status
+		e.finishStep(state, domain.StepStatusSuccess, "")
+	case domain.DeploymentStatusFailure, domain.DeploymentStatusError:
+		state.Outputs["status"] = status
+		e.finishStep(state, domain.StepStatusFailure, fmt.Sprintf("run %s finished with status %s", runID, status))
+	default:
+		return false
+	}
+	return true
+}
+
+// runStatus returns the ID of a run, resolved when it is pending, and its status. The
+// status is empty while the provider has not created the run.
+func (e *WorkflowEngine) runStatus(ctx context.Context, repoName, runID string) (string, string, error) {
+	repo, err := e.processor.repoService.GetRepository(ctx, repoName)
+	if err != nil {
+		return runID, "", fmt.Errorf("failed to get repository: %w", err)
+	}
+	provider, err := e.processor.providers.ProviderFor(repo.URL)
+	if err != nil {
+		return runID, "", err
+	}
+	if domain.IsPendingRunID(runID) {
+		run, err := resolvePendingRun(ctx, provider, repo.URL, runID)
+		if err != nil || run == nil {
+			return runID, "", err
+		}
+		runID = run.ID
+	}
+	status, err := provider.GetRunStatus(ctx, repo.URL, runID)
+	if err != nil {
+		return runID, "", err
+	}
+	return runID, status.Status, nil
+}
+
+// triggerPipeline runs the pipeline, or deploys the environment, through the command
+// processor as the user who started the workflow, so permissions and guards apply
+func (e *WorkflowEngine) triggerPipeline(ctx context.Context, workflow *domain.Workflow, step *domain.WorkflowStep, state *domain.StepState, data map[string]interface{}) error {
+	fields := map[string]string{
+		"repository_name": step.Repository,
+		"pipeline_name":   step.Pipeline,
+		"environment":     step.Environment,
+		"ref":             step.Ref,
+	}
+	params := make(map[string]interface{}, len(fields))
+	for name, text := range fields {
+		value, err := renderTemplate(text, data)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		if value != "" {
+			params[name] = value
+		}
+	}
+
+	commandType := domain.CommandTypeVerifyRepo
+	if params["environment"] != nil {
+		commandType = domain.CommandTypeDeploy
+		delete(params, "pipeline_name")
+	}
+
+	result, err := e.processor.ProcessCommand(ctx, &domain.Command{
+		ID:         fmt.Sprintf("workflow-%s-%s", workflow.ID, step.ID),
+		Type:       commandType,
+		Parameters: params,
+		User:       workflow.StartedBy,
+		Source: domain.CommandSource{
+			Platform:   "workflow",
+			ChannelID:  workflow.ChannelID,
+			WorkflowID: workflow.ID,
+			StepID:     step.ID,
+		},
+		Timestamp: e.now(),
+	})
+	if err != nil {
+		return err
+	}
+	if result.Status != "success" {
+		return fmt.Errorf("%s", result.Message)
+	}
+
+	details, _ := result.Details.(map[string]interface{})
+	runID, _ := details["run_id"].(string)
+	if pending, _ := details["pending_run"].(string); runID == "" && pending != "" {
+		runID = domain.PendingRunID(pending)
+	}
+	state.Outputs = map[string]interface{}{
+		"repository": params["repository_name"],
+		"message":    result.Message,
+		"run_id":     runID,
+		"url":        details["url"],
+	}
+	e.finishStep(state, domain.StepStatusSuccess, "")
+	return nil
+}
+
+// requestApproval leaves the step waiting and announces it in the channel
+func (e *WorkflowEngine) requestApproval(ctx context.Context, workflow *domain.Workflow, step *domain.WorkflowStep, state *domain.StepState, data map[string]interface{}) error {
+	message, err := renderTemplate(step.Message, data)
+	if err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	channel, err := renderTemplate(step.Channel, data)
+	if err != nil {
+		return fmt.Errorf("invalid channel: %w", err)
+	}
+	if channel == "" {
+		channel = workflow.ChannelID
+	}
+
+	state.Status = domain.StepStatusWaiting
+	announcement := fmt.Sprintf("Workflow %s (#%s) is waiting for approval of step %s", workflow.Name, workflow.ID, step.ID)
+	if message != "" {
+		announcement = fmt.Sprintf("%s: %s", announcement, message)
+	}
+	if len(step.Approvers) > 0 {
+		announcement = fmt.Sprintf("%s\nApprovers: %s", announcement, strings.Join(step.Approvers, ", "))
+	}
+	announcement = fmt.Sprintf("%s\nReply `approve %s %s` or `reject %s %s`", announcement, workflow.ID, step.ID, workflow.ID, step.ID)
+	e.notify(ctx, channel, announcement)
+	e.processor.publish(ctx, &domain.Event{
+		Type:    domain.EventApprovalRequested,
+		Message: announcement,
+		Payload: &domain.PendingApproval{
+			WorkflowID:   workflow.ID,
+			WorkflowName: workflow.Name,
+			StepID:       step.ID,
+			RequestedAt:  state.StartedAt,
+		},
+	})
+	return nil
+}
+
+func (e *WorkflowEngine) postMessage(ctx context.Context, workflow *domain.Workflow, step *domain.WorkflowStep, state *domain.StepState, data map[string]interface{}) error {
+	if e.notifier == nil {
+		return fmt.Errorf("no messaging platform is configured")
+	}
+
+	message, err := renderTemplate(step.Message, data)
+	if err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	channel, err := renderTemplate(step.Channel, data)
+	if err != nil {
+		return fmt.Errorf("invalid channel: %w", err)
+	}
+	if channel == "" {
+		channel = workflow.ChannelID
+	}
+	if channel == "" {
+		return fmt.Errorf("channel is required when the workflow was not started from a channel")
+	}
+
+	if err := e.notifier.Notify(ctx, channel, message); err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	state.Outputs = map[string]interface{}{"channel": channel}
+	e.finishStep(state, domain.StepStatusSuccess, "")
+	return nil
+}
+
+// callWebhook sends the request and succeeds on a 2xx response. The response body is
+// kept in the outputs, decoded as json when possible.
+func (e *WorkflowEngine) callWebhook(ctx context.Context, step *domain.WorkflowStep, state *domain.StepState, data map[string]interface{}) error {
+	url, err := renderTemplate(step.URL, data)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	body, err := renderTemplate(step.Body, data)
+	if err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+	method := strings.ToUpper(step.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	for name, text := range step.Headers {
+		value, err := renderTemplate(text, data)
+		if err != nil {
+			return fmt.Errorf("invalid header %s: %w", name, err)
+		}
+		req.Header.Set(name, value)
+	}
+	if body != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	response, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+	if err != nil {
+		return fmt.Errorf("failed to read webhook response: %w", err)
+	}
+
+	state.Outputs = map[string]interface{}{
+		"status_code": resp.StatusCode,
+		"body":        string(response),
+	}
+	var decoded interface{}
+	if json.Unmarshal(response, &decoded) == nil {
+		state.Outputs["json"] = decoded
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	e.finishStep(state, domain.StepStatusSuccess, "")
+	return nil
+}
@@ -1,14 +1,25 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	GitHub   GitHubConfig   `mapstructure:"github"`
-	Slack    SlackConfig    `mapstructure:"slack"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	GitHub      GitHubConfig      `mapstructure:"github"`
+	GitLab      GitLabConfig      `mapstructure:"gitlab"`
+	Jenkins     JenkinsConfig     `mapstructure:"jenkins"`
+	Slack       SlackConfig       `mapstructure:"slack"`
+	Deployments DeploymentsConfig `mapstructure:"deployments"`
+	Scheduler   SchedulerConfig   `mapstructure:"scheduler"`
+	Workflows   WorkflowsConfig   `mapstructure:"workflows"`
+	Events      EventsConfig      `mapstructure:"events"`
+	Webhooks    WebhooksConfig    `mapstructure:"webhooks"`
+	API         APIConfig         `mapstructure:"api"`
+	GRPC        GRPCConfig        `mapstructure:"grpc"`
 }
 
 type ServerConfig struct {
@@ -17,21 +28,92 @@ type ServerConfig struct {
 }
 
 type DatabaseConfig struct {
+	Driver   string `mapstructure:"driver"` // Optional: postgres (default), sqlite or memory
+	Path     string `mapstructure:"path"`   // Optional: database file of the sqlite driver
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	User     string `mapstructure:"user"`
 	Password string `mapstructure:"password"`
 	DBName   string `mapstructure:"dbname"`
 	SSLMode  string `mapstructure:"sslmode"`
+
+	AutoMigrate bool `mapstructure:"auto_migrate"` // Optional: apply pending migrations when connecting
 }
 
 type GitHubConfig struct {
-	Token string `mapstructure:"token"`
+	BaseURL string `mapstructure:"base_url"` // Optional: GitHub Enterprise Server URL
+	Token   string `mapstructure:"token"`
+}
+
+type GitLabConfig struct {
+	BaseURL string `mapstructure:"base_url"`
+	Token   string `mapstructure:"token"`
+}
+
+type JenkinsConfig struct {
+	BaseURL  string `mapstructure:"base_url"`
+	User     string `mapstructure:"user"`
+	APIToken string `mapstructure:"api_token"`
 }
 
 type SlackConfig struct {
 	BotToken   string `mapstructure:"bot_token"`
 	SigningKey string `mapstructure:"signing_key"`
+	APIURL     string `mapstructure:"api_url"` // Optional: overrides the Slack Web API URL
+}
+
+type DeploymentsConfig struct {
+	PollInterval   time.Duration        `mapstructure:"poll_interval"`    // Optional: how often deployment runs are checked
+	DefaultLockTTL time.Duration        `mapstructure:"default_lock_ttl"` // Optional: lock TTL when none is given
+	Freezes        []FreezeWindowConfig `mapstructure:"freezes"`
+}
+
+// FreezeWindowConfig configures a change freeze, either recurring on a cron schedule
+// for a duration or between a start and end date (RFC 3339)
+type FreezeWindowConfig struct {
+	Name        string        `mapstructure:"name"`
+	Repository  string        `mapstructure:"repository"`  // Optional: every repository when empty
+	Environment string        `mapstructure:"environment"` // Optional: every trigger when empty
+	Reason      string        `mapstructure:"reason"`
+	Cron        string        `mapstructure:"cron"`
+	Duration    time.Duration `mapstructure:"duration"`
+	Timezone    string        `mapstructure:"timezone"`
+	Start       string        `mapstructure:"start"`
+	End         string        `mapstructure:"end"`
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration `mapstructure:"interval"` // Optional: how often due schedules are checked
+	LockKey  int64         `mapstructure:"lock_key"` // Optional: Postgres advisory lock key for leader election
+}
+
+type WorkflowsConfig struct {
+	Dir      string        `mapstructure:"dir"`      // Optional: directory of workflow YAML files, defaults to ./config/workflows
+	Interval time.Duration `mapstructure:"interval"` // Optional: how often waiting workflow steps are polled
+	LockKey  int64         `mapstructure:"lock_key"` // Optional: Postgres advisory lock key for leader election
+}
+
+type EventsConfig struct {
+	Outbox        bool          `mapstructure:"outbox"`         // Optional: store events in Postgres for at-least-once delivery
+	RelayInterval time.Duration `mapstructure:"relay_interval"` // Optional: how often undelivered events are relayed
+}
+
+type WebhooksConfig struct {
+	Interval    time.Duration `mapstructure:"interval"`     // Optional: how often due deliveries are attempted
+	MaxAttempts int           `mapstructure:"max_attempts"` // Optional: attempts before a delivery fails
+	Backoff     time.Duration `mapstructure:"backoff"`      // Optional: delay before the first retry, doubled on every retry
+	LockKey     int64         `mapstructure:"lock_key"`     // Optional: Postgres advisory lock key for leader election
+}
+
+type APIConfig struct {
+	AdminToken        string `mapstructure:"admin_token"`         // Optional: bearer token acting as the "api" user with every scope
+	AllowWithoutRoles bool   `mapstructure:"allow_without_roles"` // Optional: lets API and gRPC tokens run every command while no role is stored
+}
+
+type GRPCConfig struct {
+	Port         int           `mapstructure:"port"`          // Optional: the gRPC server is disabled when 0
+	Host         string        `mapstructure:"host"`          // Optional: defaults to all interfaces
+	PollInterval time.Duration `mapstructure:"poll_interval"` // Optional: how often watched runs are polled, defaults to 5 seconds
 }
 
 func Load() (*Config, error) {
@@ -45,15 +127,25 @@ func Load() (*Config, error) {
 	viper.AutomaticEnv()
 
 	// Map environment variables to config fields
+	viper.BindEnv("github.base_url", "CHATOPS_GITHUB_BASE_URL")
 	viper.BindEnv("github.token", "CHATOPS_GITHUB_TOKEN")
+	viper.BindEnv("gitlab.base_url", "CHATOPS_GITLAB_BASE_URL")
+	viper.BindEnv("gitlab.token", "CHATOPS_GITLAB_TOKEN")
+	viper.BindEnv("jenkins.base_url", "CHATOPS_JENKINS_BASE_URL")
+	viper.BindEnv("jenkins.user", "CHATOPS_JENKINS_USER")
+	viper.BindEnv("jenkins.api_token", "CHATOPS_JENKINS_API_TOKEN")
 	viper.BindEnv("slack.bot_token", "CHATOPS_SLACK_BOT_TOKEN")
 	viper.BindEnv("slack.signing_key", "CHATOPS_SLACK_SIGNING_KEY")
+	viper.BindEnv("api.admin_token", "CHATOPS_API_ADMIN_TOKEN")
+	viper.BindEnv("database.driver", "CHATOPS_DB_DRIVER")
+	viper.BindEnv("database.path", "CHATOPS_DB_PATH")
 	viper.BindEnv("database.host", "CHATOPS_DB_HOST")
 	viper.BindEnv("database.port", "CHATOPS_DB_PORT")
 	viper.BindEnv("database.user", "CHATOPS_DB_USER")
 	viper.BindEnv("database.password", "CHATOPS_DB_PASSWORD")
 	viper.BindEnv("database.dbname", "CHATOPS_DB_NAME")
 	viper.BindEnv("database.sslmode", "CHATOPS_DB_SSLMODE")
+	viper.BindEnv("database.auto_migrate", "CHATOPS_DB_AUTO_MIGRATE")
 
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, err
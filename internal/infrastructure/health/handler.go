@@ -1,7 +1,7 @@
 package health
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -9,9 +9,14 @@ import (
 	"go.uber.org/zap"
 )
 
+// Pinger checks the database can be reached
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
 type Handler struct {
 	logger *zap.Logger
-	db     *sql.DB
+	db     Pinger
 }
 
 type HealthResponse struct {
@@ -20,7 +25,7 @@ type HealthResponse struct {
 	Services  map[string]string `json:"services"`
 }
 
-func NewHandler(logger *zap.Logger, db *sql.DB) *Handler {
+func NewHandler(logger *zap.Logger, db Pinger) *Handler {
 	return &Handler{
 		logger: logger,
 		db:     db,
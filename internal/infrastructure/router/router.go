@@ -1,7 +1,9 @@
 package router
 
 import (
+	"github.com/Tovli/chatops/internal/adapters/rest"
 	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
 	"github.com/Tovli/chatops/internal/infrastructure/health"
 	"github.com/Tovli/chatops/internal/infrastructure/middleware"
 	"github.com/gorilla/mux"
@@ -12,6 +14,7 @@ import (
 type Config struct {
 	Logger        *zap.Logger
 	SlackAdapter  *slack.SlackAdapter
+	RESTAdapter   *rest.RESTAdapter // Optional: serves the JSON API
 	HealthHandler *health.Handler
 }
 
@@ -28,8 +31,33 @@ func NewRouter(cfg *Config) *mux.Router {
 
 	// API routes
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
+	apiRouter.HandleFunc("/openapi.json", rest.ServeOpenAPI).Methods("GET")
 	apiRouter.HandleFunc("/slack/commands", cfg.SlackAdapter.HandleSlashCommand).Methods("POST")
 	apiRouter.HandleFunc("/slack/webhooks", cfg.SlackAdapter.HandleWebhook).Methods("POST")
+	apiRouter.HandleFunc("/slack/interactions", cfg.SlackAdapter.HandleInteraction).Methods("POST")
+
+	// JSON API, authenticated with a bearer token and scoped per route
+	if api := cfg.RESTAdapter; api != nil {
+		restRouter := apiRouter.NewRoute().Subrouter()
+		restRouter.Use(api.Authenticate)
+		restRouter.HandleFunc("/commands", api.RequireScope(domain.ScopeRunCommands, api.ProcessCommand)).Methods("POST")
+		restRouter.HandleFunc("/repositories", api.RequireScope(domain.ScopeReadRepositories, api.ListRepositories)).Methods("GET")
+		restRouter.HandleFunc("/repositories/{name}/pipelines", api.RequireScope(domain.ScopeReadRepositories, api.ListPipelines)).Methods("GET")
+		restRouter.HandleFunc("/runs", api.RequireScope(domain.ScopeReadRuns, api.ListRuns)).Methods("GET")
+		restRouter.HandleFunc("/runs/{id}", api.RequireScope(domain.ScopeReadRuns, api.GetRun)).Methods("GET")
+		restRouter.HandleFunc("/audit", api.RequireScope(domain.ScopeReadAudit, api.ListAuditEvents)).Methods("GET")
+
+		restRouter.HandleFunc("/webhooks", api.RequireScope(domain.ScopeManageWebhooks, api.ListWebhooks)).Methods("GET")
+		restRouter.HandleFunc("/webhooks", api.RequireScope(domain.ScopeManageWebhooks, api.CreateWebhook)).Methods("POST")
+		restRouter.HandleFunc("/webhooks/{id}", api.RequireScope(domain.ScopeManageWebhooks, api.GetWebhook)).Methods("GET")
+		restRouter.HandleFunc("/webhooks/{id}", api.RequireScope(domain.ScopeManageWebhooks, api.UpdateWebhook)).Methods("PUT")
+		restRouter.HandleFunc("/webhooks/{id}", api.RequireScope(domain.ScopeManageWebhooks, api.DeleteWebhook)).Methods("DELETE")
+		restRouter.HandleFunc("/webhooks/{id}/deliveries", api.RequireScope(domain.ScopeManageWebhooks, api.ListDeliveries)).Methods("GET")
+
+		restRouter.HandleFunc("/tokens", api.RequireScope(domain.ScopeManageTokens, api.ListTokens)).Methods("GET")
+		restRouter.HandleFunc("/tokens", api.RequireScope(domain.ScopeManageTokens, api.CreateToken)).Methods("POST")
+		restRouter.HandleFunc("/tokens/{id}", api.RequireScope(domain.ScopeManageTokens, api.RevokeToken)).Methods("DELETE")
+	}
 
 	return router
 }
@@ -0,0 +1,157 @@
+// Package seed stores the repositories and roles of a YAML seed file, for test and
+// development databases
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"gopkg.in/yaml.v3"
+)
+
+// SeedUser is recorded as the user adding the seeded repositories
+const SeedUser = "seed"
+
+// File is the YAML layout of a seed file
+type File struct {
+	Repositories []RepositoryFile `yaml:"repositories"`
+	Roles        []RoleFile       `yaml:"roles"`
+}
+
+type RepositoryFile struct {
+	Name          string            `yaml:"name"`
+	URL           string            `yaml:"url"`
+	DefaultBranch string            `yaml:"default_branch"`
+	Pipelines     []PipelineFile    `yaml:"pipelines"`
+	Environments  []EnvironmentFile `yaml:"environments"`
+}
+
+type PipelineFile struct {
+	Name    string `yaml:"name"`
+	Path    string `yaml:"path"`
+	Default bool   `yaml:"default"`
+}
+
+type EnvironmentFile struct {
+	Name     string `yaml:"name"`
+	Pipeline string `yaml:"pipeline"`
+	Ref      string `yaml:"ref"`
+}
+
+type RoleFile struct {
+	Name        string   `yaml:"name"`
+	Permissions []string `yaml:"permissions"`
+	Users       []string `yaml:"users"`
+}
+
+// Result counts the stored records
+type Result struct {
+	Added   int // Repositories added
+	Updated int // Existing repositories replaced
+	Roles   int // Roles saved
+}
+
+// Load reads and validates a seed file
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse seed file %s: %w", path, err)
+	}
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+func (f *File) validate() error {
+	for _, repo := range f.Repositories {
+		if repo.Name == "" || repo.URL == "" {
+			return fmt.Errorf("repositories need a name and a URL")
+		}
+		pipelines := make(map[string]bool)
+		for _, pipeline := range repo.Pipelines {
+			if pipeline.Name == "" || pipeline.Path == "" {
+				return fmt.Errorf("pipelines of %s need a name and a path", repo.Name)
+			}
+			pipelines[pipeline.Name] = true
+		}
+		for _, env := range repo.Environments {
+			if !pipelines[env.Pipeline] {
+				return fmt.Errorf("environment %s of %s maps to unknown pipeline %q", env.Name, repo.Name, env.Pipeline)
+			}
+		}
+	}
+	for _, role := range f.Roles {
+		if role.Name == "" || len(role.Permissions) == 0 {
+			return fmt.Errorf("roles need a name and permissions")
+		}
+	}
+	return nil
+}
+
+// Apply stores the repositories and roles of the file. Existing repositories are replaced
+// and existing roles get the permissions of the file, so seeding twice is harmless.
+func Apply(ctx context.Context, file *File, repos ports.RepositoryStorage, roles ports.RoleStorage) (*Result, error) {
+	result := &Result{}
+
+	for _, seeded := range file.Repositories {
+		repo := seeded.toDomain()
+
+		existing, err := repos.GetRepository(ctx, repo.Name)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			if err := repos.AddRepository(ctx, repo); err != nil {
+				return nil, fmt.Errorf("failed to add repository %s: %w", repo.Name, err)
+			}
+			result.Added++
+		case err != nil:
+			return nil, fmt.Errorf("failed to get repository %s: %w", repo.Name, err)
+		default:
+			repo.ID, repo.AddedBy, repo.AddedAt, repo.Version = existing.ID, existing.AddedBy, existing.AddedAt, existing.Version
+			if err := repos.UpdateRepository(ctx, repo); err != nil {
+				return nil, fmt.Errorf("failed to update repository %s: %w", repo.Name, err)
+			}
+			result.Updated++
+		}
+	}
+
+	for _, role := range file.Roles {
+		if err := roles.SaveRole(ctx, &domain.Role{Name: role.Name, Permissions: role.Permissions, Users: role.Users}); err != nil {
+			return nil, fmt.Errorf("failed to save role %s: %w", role.Name, err)
+		}
+		result.Roles++
+	}
+
+	return result, nil
+}
+
+func (r RepositoryFile) toDomain() *domain.Repository {
+	repo := &domain.Repository{
+		Name:          r.Name,
+		URL:           r.URL,
+		DefaultBranch: r.DefaultBranch,
+		AddedBy:       SeedUser,
+		AddedAt:       time.Now(),
+	}
+	if repo.DefaultBranch == "" {
+		repo.DefaultBranch = "main"
+	}
+	for _, pipeline := range r.Pipelines {
+		repo.Pipelines = append(repo.Pipelines, domain.Pipeline{Name: pipeline.Name, Path: pipeline.Path, IsDefault: pipeline.Default})
+	}
+	for _, env := range r.Environments {
+		repo.Environments = append(repo.Environments, domain.Environment{Name: env.Name, Pipeline: env.Pipeline, Ref: env.Ref})
+	}
+	return repo
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddToken(ctx context.Context, token *domain.APIToken) error {
+	stored, err := clone(token)
+	if err != nil {
+		return err
+	}
+	stored.LastUsedAt, stored.RevokedAt = time.Time{}, time.Time{}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.tokens {
+		if existing.Hash == token.Hash {
+			return domain.AlreadyExists("a token with the same hash already exists")
+		}
+	}
+	stored.ID = s.nextID("api_tokens")
+	s.tokens = append(s.tokens, stored)
+	token.ID = stored.ID
+	return nil
+}
+
+func (s *Storage) GetTokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, token := range s.tokens {
+		if token.Hash == hash {
+			return copyOf(token), nil
+		}
+	}
+	return nil, notFound("token not found")
+}
+
+func (s *Storage) ListTokens(ctx context.Context) ([]*domain.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyAll(s.tokens), nil
+}
+
+func (s *Storage) RevokeToken(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, token := range s.tokens {
+		if token.ID == id {
+			if token.RevokedAt.IsZero() {
+				token.RevokedAt = at.UTC()
+			}
+			return nil
+		}
+	}
+	return domain.NotFound("token %s not found", id)
+}
+
+func (s *Storage) TouchToken(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, token := range s.tokens {
+		if token.ID == id {
+			token.LastUsedAt = at.UTC()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	stored, err := clone(event)
+	if err != nil {
+		return err
+	}
+	if len(stored.Payload) == 0 {
+		stored.Payload = nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored.ID = s.nextID("audit_events")
+	s.audit = append(s.audit, stored)
+	event.ID = stored.ID
+	return nil
+}
+
+func (s *Storage) ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*domain.AuditEvent
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		event := s.audit[i]
+		if (filter.Type == "" || domain.MatchesEventFilter(filter.Type, event.Type)) &&
+			(filter.Repository == "" || event.Repository == filter.Repository) &&
+			(filter.Actor == "" || event.Actor == filter.Actor) &&
+			!event.OccurredAt.Before(filter.Since) {
+			events = append(events, event)
+		}
+	}
+	// Newest first, the most recently recorded first among simultaneous events
+	sort.SliceStable(events, func(i, j int) bool { return events[i].OccurredAt.After(events[j].OccurredAt) })
+	return copyAll(limit(events, filter.Limit)), nil
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddDeployment(ctx context.Context, deployment *domain.Deployment) error {
+	stored, err := clone(deployment)
+	if err != nil {
+		return err
+	}
+	stored.UpdatedAt = stored.CreatedAt
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored.ID = s.nextID("deployments")
+	s.deployments = append(s.deployments, stored)
+	deployment.ID = stored.ID
+	return nil
+}
+
+func (s *Storage) UpdateDeploymentStatus(ctx context.Context, id, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, deployment := range s.deployments {
+		if deployment.ID == id {
+			deployment.Status, deployment.UpdatedAt = status, time.Now().UTC()
+			return nil
+		}
+	}
+	return domain.NotFound("deployment %s not found", id)
+}
+
+func (s *Storage) SetDeploymentRun(ctx context.Context, id, runID, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, deployment := range s.deployments {
+		if deployment.ID == id {
+			deployment.RunID, deployment.URL, deployment.UpdatedAt = runID, url, time.Now().UTC()
+			return nil
+		}
+	}
+	return domain.NotFound("deployment %s not found", id)
+}
+
+func (s *Storage) ListCurrentDeployments(ctx context.Context, repository string) ([]*domain.Deployment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := make(map[string]*domain.Deployment)
+	for _, deployment := range s.deployments {
+		if deployment.Repository != repository {
+			continue
+		}
+		if latest, ok := current[deployment.Environment]; !ok || deployment.CreatedAt.After(latest.CreatedAt) {
+			current[deployment.Environment] = deployment
+		}
+	}
+
+	var deployments []*domain.Deployment
+	for _, deployment := range current {
+		deployments = append(deployments, deployment)
+	}
+	sort.Slice(deployments, func(i, j int) bool { return deployments[i].Environment < deployments[j].Environment })
+	return copyAll(deployments), nil
+}
+
+func (s *Storage) ListPendingDeployments(ctx context.Context) ([]*domain.Deployment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deployments []*domain.Deployment
+	for _, deployment := range s.deployments {
+		if deployment.Status == domain.DeploymentStatusQueued || deployment.Status == domain.DeploymentStatusInProgress {
+			deployments = append(deployments, deployment)
+		}
+	}
+	sort.SliceStable(deployments, func(i, j int) bool { return deployments[i].CreatedAt.Before(deployments[j].CreatedAt) })
+	return copyAll(deployments), nil
+}
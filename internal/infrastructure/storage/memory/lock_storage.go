@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// AcquireLock stores the lock, or takes over an existing lock when it expired or
+// belongs to the same owner
+func (s *Storage) AcquireLock(ctx context.Context, lock *domain.DeploymentLock) (*domain.DeploymentLock, error) {
+	stored, err := clone(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findLock(lock.Repository, lock.Environment)
+	switch {
+	case i < 0:
+		s.locks = append(s.locks, stored)
+	case !s.locks[i].ExpiresAt.After(lock.CreatedAt) || s.locks[i].Owner == lock.Owner:
+		s.locks[i] = stored
+	}
+
+	return s.activeLock(lock.Repository, lock.Environment), nil
+}
+
+func (s *Storage) GetLock(ctx context.Context, repository, environment string) (*domain.DeploymentLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.activeLock(repository, environment), nil
+}
+
+func (s *Storage) ReleaseLock(ctx context.Context, repository, environment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.findLock(repository, environment); i >= 0 {
+		s.locks = append(s.locks[:i], s.locks[i+1:]...)
+	}
+	return nil
+}
+
+func (s *Storage) ListLocks(ctx context.Context) ([]*domain.DeploymentLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var locks []*domain.DeploymentLock
+	for _, lock := range s.locks {
+		if lock.ExpiresAt.After(now) {
+			locks = append(locks, lock)
+		}
+	}
+	sort.Slice(locks, func(i, j int) bool {
+		if locks[i].Repository != locks[j].Repository {
+			return locks[i].Repository < locks[j].Repository
+		}
+		return locks[i].Environment < locks[j].Environment
+	})
+	return copyAll(locks), nil
+}
+
+func (s *Storage) findLock(repository, environment string) int {
+	for i, lock := range s.locks {
+		if lock.Repository == repository && lock.Environment == environment {
+			return i
+		}
+	}
+	return -1
+}
+
+// activeLock returns a copy of the unexpired lock of a repository environment, or nil
+func (s *Storage) activeLock(repository, environment string) *domain.DeploymentLock {
+	i := s.findLock(repository, environment)
+	if i < 0 || !s.locks[i].ExpiresAt.After(time.Now()) {
+		return nil
+	}
+	return copyOf(s.locks[i])
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// outboxEvent is a stored event, its payload encoded as the SQL backends store it
+type outboxEvent struct {
+	event        domain.Event
+	payload      []byte
+	claimedUntil time.Time
+	attempts     int
+	delivered    bool
+}
+
+func (s *Storage) AppendEvent(ctx context.Context, event *domain.Event, lease time.Duration) error {
+	stored := &outboxEvent{event: *event, claimedUntil: time.Now().Add(lease)}
+	stored.event.Payload = nil
+	if event.Payload != nil {
+		var err error
+		if stored.payload, err = json.Marshal(event.Payload); err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored.event.ID = s.nextID("event_outbox")
+	s.outbox = append(s.outbox, stored)
+	event.ID = stored.event.ID
+	return nil
+}
+
+func (s *Storage) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var events []*domain.Event
+	for _, stored := range s.outbox {
+		if len(events) >= limit {
+			break
+		}
+		if stored.delivered || !stored.claimedUntil.Before(now) {
+			continue
+		}
+		stored.claimedUntil = now.Add(lease)
+		stored.attempts++
+
+		event := stored.event
+		if event.Payload = domain.NewEventPayload(event.Type); event.Payload != nil && len(stored.payload) > 0 {
+			if err := json.Unmarshal(stored.payload, event.Payload); err != nil {
+				return nil, fmt.Errorf("failed to decode payload of event %s: %w", event.ID, err)
+			}
+		}
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+func (s *Storage) MarkEventDelivered(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.outbox {
+		if stored.event.ID == id {
+			stored.delivered = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddRepository(ctx context.Context, repo *domain.Repository) error {
+	stored, err := clone(repo)
+	if err != nil {
+		return err
+	}
+	if stored.Environments == nil {
+		stored.Environments = []domain.Environment{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findRepository(repo.Name) != nil {
+		return domain.AlreadyExists("repository %s already exists", repo.Name)
+	}
+	stored.Version, stored.UpdatedAt = 1, time.Now()
+	s.repositories = append(s.repositories, stored)
+	repo.Version, repo.UpdatedAt = stored.Version, stored.UpdatedAt
+	return nil
+}
+
+func (s *Storage) GetRepository(ctx context.Context, name string) (*domain.Repository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repo := s.findRepository(name)
+	if repo == nil {
+		return nil, notFound("repository %s not found", name)
+	}
+	return copyOf(repo), nil
+}
+
+func (s *Storage) ListRepositories(ctx context.Context) ([]*domain.Repository, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyAll(s.repositories), nil
+}
+
+func (s *Storage) UpdateRepository(ctx context.Context, repo *domain.Repository) error {
+	updated, err := clone(repo)
+	if err != nil {
+		return err
+	}
+	if updated.Environments == nil {
+		updated.Environments = []domain.Environment{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.findRepository(repo.Name)
+	if stored == nil {
+		return domain.NotFound("repository %s not found", repo.Name)
+	}
+	if stored.Version != repo.Version {
+		return domain.Conflict("repository %s was updated concurrently", repo.Name)
+	}
+	stored.URL = updated.URL
+	stored.DefaultBranch = updated.DefaultBranch
+	stored.Pipelines = updated.Pipelines
+	stored.Environments = updated.Environments
+	stored.Version++
+	stored.UpdatedAt = time.Now()
+	repo.Version, repo.UpdatedAt = stored.Version, stored.UpdatedAt
+	return nil
+}
+
+func (s *Storage) UpsertRepository(ctx context.Context, repo *domain.Repository) error {
+	updated, err := clone(repo)
+	if err != nil {
+		return err
+	}
+	if updated.Environments == nil {
+		updated.Environments = []domain.Environment{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.findRepository(repo.Name)
+	if stored == nil {
+		updated.Version, updated.UpdatedAt = 1, time.Now()
+		s.repositories = append(s.repositories, updated)
+		stored = updated
+	} else {
+		if stored.URL != repo.URL {
+			return domain.AlreadyExists("a repository named %s is already managed from %s", repo.Name, stored.URL)
+		}
+		if stored.Version != repo.Version {
+			return domain.Conflict("repository %s was updated concurrently", repo.Name)
+		}
+		stored.DefaultBranch = updated.DefaultBranch
+		stored.Pipelines = updated.Pipelines
+		stored.Version++
+		stored.UpdatedAt = time.Now()
+	}
+
+	*repo = *copyOf(stored)
+	return nil
+}
+
+func (s *Storage) findRepository(name string) *domain.Repository {
+	for _, repo := range s.repositories {
+		if repo.Name == name {
+			return repo
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) SaveRole(ctx context.Context, role *domain.Role) error {
+	saved, err := clone(role)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stored *domain.Role
+	for _, existing := range s.roles {
+		if existing.Name == role.Name {
+			stored = existing
+		}
+	}
+	if stored == nil {
+		stored = &domain.Role{Name: role.Name, Users: []string{}}
+		s.roles = append(s.roles, stored)
+	}
+
+	stored.Permissions = saved.Permissions
+	for _, user := range saved.Users {
+		if !slices.Contains(stored.Users, user) {
+			stored.Users = append(stored.Users, user)
+		}
+	}
+	sort.Strings(stored.Users)
+	return nil
+}
+
+func (s *Storage) ListRoles(ctx context.Context) ([]*domain.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := copyAll(s.roles)
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddRun(ctx context.Context, run *domain.Run) error {
+	stored, err := clone(run)
+	if err != nil {
+		return err
+	}
+	stored.UpdatedAt = stored.CreatedAt
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runs = append(s.runs, stored)
+	return nil
+}
+
+func (s *Storage) GetRun(ctx context.Context, runID string) (*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := s.newestRuns(func(run *domain.Run) bool { return run.ID == runID })
+	if len(runs) == 0 {
+		return nil, notFound("run %s not found", runID)
+	}
+	return copyOf(runs[0]), nil
+}
+
+func (s *Storage) GetLatestRun(ctx context.Context, repository, pipeline string) (*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := s.newestRuns(func(run *domain.Run) bool {
+		return run.Repository == repository && (pipeline == "" || run.Pipeline == pipeline)
+	})
+	if len(runs) == 0 {
+		return nil, notFound("no run of %s found", repository)
+	}
+	return copyOf(runs[0]), nil
+}
+
+func (s *Storage) UpdateRunStatus(ctx context.Context, runID, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated := false
+	for _, run := range s.runs {
+		if run.ID == runID {
+			run.Status, run.UpdatedAt = status, time.Now().UTC()
+			updated = true
+		}
+	}
+	if !updated {
+		return domain.NotFound("run %s not found", runID)
+	}
+	return nil
+}
+
+func (s *Storage) ListActiveRuns(ctx context.Context, since time.Time) ([]*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var runs []*domain.Run
+	for _, run := range s.runs {
+		switch run.Status {
+		case domain.RunStatusQueued, domain.RunStatusInProgress, domain.RunStatusCancelling:
+			if !run.CreatedAt.Before(since) {
+				runs = append(runs, run)
+			}
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].CreatedAt.Before(runs[j].CreatedAt) })
+	return copyAll(runs), nil
+}
+
+func (s *Storage) TransitionRunStatus(ctx context.Context, runID, from, to string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transitioned := false
+	for _, run := range s.runs {
+		if run.ID == runID && run.Status == from {
+			run.Status, run.UpdatedAt = to, time.Now().UTC()
+			transitioned = true
+		}
+	}
+	return transitioned, nil
+}
+
+func (s *Storage) ResolvePendingRun(ctx context.Context, pendingID, runID, url string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resolved := false
+	for _, run := range s.runs {
+		if run.ID == pendingID {
+			run.ID, run.URL, run.UpdatedAt = runID, url, time.Now().UTC()
+			resolved = true
+		}
+	}
+	return resolved, nil
+}
+
+func (s *Storage) ListUserRuns(ctx context.Context, userID string, n int) ([]*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := s.newestRuns(func(run *domain.Run) bool { return run.TriggeredBy == userID })
+	return copyAll(limit(runs, n)), nil
+}
+
+func (s *Storage) ListRuns(ctx context.Context, filter domain.RunFilter) ([]*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := s.newestRuns(func(run *domain.Run) bool {
+		return (filter.Repository == "" || run.Repository == filter.Repository) &&
+			(filter.Pipeline == "" || run.Pipeline == filter.Pipeline) &&
+			(filter.TriggeredBy == "" || run.TriggeredBy == filter.TriggeredBy)
+	})
+	return copyAll(limit(runs, filter.Limit)), nil
+}
+
+// newestRuns returns the matching runs, newest first
+func (s *Storage) newestRuns(match func(*domain.Run) bool) []*domain.Run {
+	var runs []*domain.Run
+	for _, run := range s.runs {
+		if match(run) {
+			runs = append(runs, run)
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].CreatedAt.After(runs[j].CreatedAt) })
+	return runs
+}
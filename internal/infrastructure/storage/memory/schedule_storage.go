@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddSchedule(ctx context.Context, schedule *domain.Schedule) error {
+	stored, err := clone(schedule)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored.ID = s.nextID("schedules")
+	s.schedules = append(s.schedules, stored)
+	schedule.ID = stored.ID
+	return nil
+}
+
+func (s *Storage) GetSchedule(ctx context.Context, id string) (*domain.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findSchedule(id)
+	if i < 0 {
+		return nil, notFound("schedule %s not found", id)
+	}
+	return copyOf(s.schedules[i]), nil
+}
+
+func (s *Storage) ListSchedules(ctx context.Context) ([]*domain.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyAll(s.schedules), nil
+}
+
+func (s *Storage) DeleteSchedule(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findSchedule(id)
+	if i < 0 {
+		return domain.NotFound("schedule %s not found", id)
+	}
+	s.schedules = append(s.schedules[:i], s.schedules[i+1:]...)
+	return nil
+}
+
+func (s *Storage) ListDueSchedules(ctx context.Context, at time.Time) ([]*domain.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var schedules []*domain.Schedule
+	for _, schedule := range s.schedules {
+		if !schedule.NextRunAt.After(at) {
+			schedules = append(schedules, schedule)
+		}
+	}
+	sort.SliceStable(schedules, func(i, j int) bool { return schedules[i].NextRunAt.Before(schedules[j].NextRunAt) })
+	return copyAll(schedules), nil
+}
+
+func (s *Storage) ClaimScheduleRun(ctx context.Context, id string, expectedNext, ranAt, next time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findSchedule(id)
+	if i < 0 || !s.schedules[i].NextRunAt.Equal(expectedNext) {
+		return false, nil
+	}
+	s.schedules[i].LastRunAt, s.schedules[i].NextRunAt = ranAt.UTC(), next.UTC()
+	return true, nil
+}
+
+func (s *Storage) findSchedule(id string) int {
+	for i, schedule := range s.schedules {
+		if schedule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,94 @@
+// Package memory stores every record of ChatOps in process memory. Records are lost on
+// restart, so it suits tests and trying ChatOps out.
+package memory
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+// Storage implements every store of ports.Storage. Records are copied in and out, so
+// callers never share maps or slices with stored records.
+type Storage struct {
+	mu  sync.Mutex
+	ids map[string]int64 // Last ID assigned per table
+
+	repositories  []*domain.Repository
+	runs          []*domain.Run
+	deployments   []*domain.Deployment
+	locks         []*domain.DeploymentLock
+	schedules     []*domain.Schedule
+	subscriptions []*domain.Subscription
+	mutes         []*domain.ChannelMute
+	workflows     []*domain.Workflow
+	outbox        []*outboxEvent
+	webhooks      []*domain.Webhook
+	deliveries    []*domain.WebhookDelivery
+	audit         []*domain.AuditEvent
+	tokens        []*domain.APIToken
+	roles         []*domain.Role
+}
+
+// NewStorage creates an empty storage
+func NewStorage() *Storage {
+	return &Storage{ids: make(map[string]int64)}
+}
+
+// nextID returns the next ID of a table, starting at 1 like a SQL sequence
+func (s *Storage) nextID(table string) string {
+	if s.ids == nil {
+		s.ids = make(map[string]int64)
+	}
+	s.ids[table]++
+	return strconv.FormatInt(s.ids[table], 10)
+}
+
+// clone deep-copies a record through JSON, the way the SQL backends store its fields
+func clone[T any](v *T) (*T, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var c T
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// copyOf clones a stored record, which encoded fine when it was stored
+func copyOf[T any](v *T) *T {
+	c, err := clone(v)
+	if err != nil {
+		panic(fmt.Sprintf("memory: stored record no longer encodes: %v", err))
+	}
+	return c
+}
+
+// copyAll clones stored records
+func copyAll[T any](records []*T) []*T {
+	var copies []*T
+	for _, record := range records {
+		copies = append(copies, copyOf(record))
+	}
+	return copies
+}
+
+// limit truncates a listing to n records, as SQL LIMIT does
+func limit[T any](records []*T, n int) []*T {
+	if n >= 0 && n < len(records) {
+		return records[:n]
+	}
+	return records
+}
+
+// notFound reports a missing record, matching both domain.ErrNotFound and sql.ErrNoRows
+// like the database backends
+func notFound(format string, args ...interface{}) error {
+	return domain.NewError(domain.ErrNotFound, sql.ErrNoRows, format, args...)
+}
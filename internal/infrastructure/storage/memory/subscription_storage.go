@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	stored, err := clone(subscription)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored.ID = s.nextID("subscriptions")
+	s.subscriptions = append(s.subscriptions, stored)
+	subscription.ID = stored.ID
+	return nil
+}
+
+func (s *Storage) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findSubscription(id)
+	if i < 0 {
+		return nil, notFound("subscription %s not found", id)
+	}
+	return copyOf(s.subscriptions[i]), nil
+}
+
+func (s *Storage) DeleteSubscription(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findSubscription(id)
+	if i < 0 {
+		return domain.NotFound("subscription %s not found", id)
+	}
+	s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+	return nil
+}
+
+func (s *Storage) ListSubscriptions(ctx context.Context, repository string) ([]*domain.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var subscriptions []*domain.Subscription
+	for _, subscription := range s.subscriptions {
+		if subscription.Repository == repository || subscription.Repository == domain.AllRepositories {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return copyAll(subscriptions), nil
+}
+
+func (s *Storage) ListChannelSubscriptions(ctx context.Context, platform, channelID string) ([]*domain.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var subscriptions []*domain.Subscription
+	for _, subscription := range s.subscriptions {
+		if subscription.Platform == platform && subscription.ChannelID == channelID {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return copyAll(subscriptions), nil
+}
+
+func (s *Storage) MuteChannel(ctx context.Context, mute *domain.ChannelMute) error {
+	stored, err := clone(mute)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.findMute(mute.Platform, mute.ChannelID); i >= 0 {
+		s.mutes[i] = stored
+	} else {
+		s.mutes = append(s.mutes, stored)
+	}
+	return nil
+}
+
+func (s *Storage) UnmuteChannel(ctx context.Context, platform, channelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.findMute(platform, channelID); i >= 0 {
+		s.mutes = append(s.mutes[:i], s.mutes[i+1:]...)
+	}
+	return nil
+}
+
+func (s *Storage) GetChannelMute(ctx context.Context, platform, channelID string) (*domain.ChannelMute, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findMute(platform, channelID)
+	if i < 0 {
+		return nil, nil
+	}
+	return copyOf(s.mutes[i]), nil
+}
+
+func (s *Storage) findSubscription(id string) int {
+	for i, subscription := range s.subscriptions {
+		if subscription.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Storage) findMute(platform, channelID string) int {
+	for i, mute := range s.mutes {
+		if mute.Platform == platform && mute.ChannelID == channelID {
+			return i
+		}
+	}
+	return -1
+}
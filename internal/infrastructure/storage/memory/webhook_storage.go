@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	stored, err := clone(webhook)
+	if err != nil {
+		return err
+	}
+	if stored.Headers == nil {
+		stored.Headers = map[string]string{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored.ID = s.nextID("webhooks")
+	s.webhooks = append(s.webhooks, stored)
+	webhook.ID = stored.ID
+	return nil
+}
+
+func (s *Storage) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findWebhook(id)
+	if i < 0 {
+		return nil, notFound("webhook %s not found", id)
+	}
+	return copyOf(s.webhooks[i]), nil
+}
+
+func (s *Storage) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return copyAll(s.webhooks), nil
+}
+
+func (s *Storage) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	updated, err := clone(webhook)
+	if err != nil {
+		return err
+	}
+	if updated.Headers == nil {
+		updated.Headers = map[string]string{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findWebhook(webhook.ID)
+	if i < 0 {
+		return domain.NotFound("webhook %s not found", webhook.ID)
+	}
+	updated.CreatedBy, updated.CreatedAt = s.webhooks[i].CreatedBy, s.webhooks[i].CreatedAt
+	s.webhooks[i] = updated
+	return nil
+}
+
+// DeleteWebhook removes the webhook and its deliveries
+func (s *Storage) DeleteWebhook(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findWebhook(id)
+	if i < 0 {
+		return domain.NotFound("webhook %s not found", id)
+	}
+	s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+
+	deliveries := s.deliveries[:0]
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookID != id {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	s.deliveries = deliveries
+	return nil
+}
+
+func (s *Storage) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	stored, err := clone(delivery)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findWebhook(delivery.WebhookID) < 0 {
+		return domain.NotFound("webhook %s not found", delivery.WebhookID)
+	}
+	stored.ID = s.nextID("webhook_deliveries")
+	s.deliveries = append(s.deliveries, stored)
+	delivery.ID = stored.ID
+	return nil
+}
+
+func (s *Storage) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.deliveries {
+		if stored.ID == delivery.ID {
+			stored.Status = delivery.Status
+			stored.Attempts = delivery.Attempts
+			stored.ResponseCode = delivery.ResponseCode
+			stored.Error = delivery.Error
+			stored.NextAttemptAt = delivery.NextAttemptAt.UTC()
+			stored.DeliveredAt = delivery.DeliveredAt
+			if !stored.DeliveredAt.IsZero() {
+				stored.DeliveredAt = stored.DeliveredAt.UTC()
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Storage) ListDueDeliveries(ctx context.Context, at time.Time, n int) ([]*domain.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deliveries []*domain.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status == domain.DeliveryStatusPending && !delivery.NextAttemptAt.After(at) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return copyAll(limit(deliveries, n)), nil
+}
+
+func (s *Storage) ListDeliveries(ctx context.Context, webhookID string, n int) ([]*domain.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deliveries []*domain.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	// Newest first; deliveries are stored in ID order
+	slices.Reverse(deliveries)
+	return copyAll(limit(deliveries, n)), nil
+}
+
+func (s *Storage) findWebhook(id string) int {
+	for i, webhook := range s.webhooks {
+		if webhook.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AddWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	stored, err := clone(workflow)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored.ID = s.nextID("workflows")
+	stored.Version = 1
+	s.workflows = append(s.workflows, stored)
+	workflow.ID, workflow.Version = stored.ID, stored.Version
+	return nil
+}
+
+func (s *Storage) GetWorkflow(ctx context.Context, id string) (*domain.Workflow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workflow := s.findWorkflow(id)
+	if workflow == nil {
+		return nil, notFound("workflow %s not found", id)
+	}
+	return copyOf(workflow), nil
+}
+
+func (s *Storage) UpdateWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	updated, err := clone(workflow)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.findWorkflow(workflow.ID)
+	if stored == nil {
+		return domain.NotFound("workflow %s not found", workflow.ID)
+	}
+	if stored.Version != workflow.Version {
+		return domain.Conflict("workflow %s was updated concurrently", workflow.ID)
+	}
+	stored.Status, stored.Steps, stored.UpdatedAt = updated.Status, updated.Steps, updated.UpdatedAt
+	stored.Version++
+	workflow.Version = stored.Version
+	return nil
+}
+
+func (s *Storage) ListActiveWorkflows(ctx context.Context) ([]*domain.Workflow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var workflows []*domain.Workflow
+	for _, workflow := range s.workflows {
+		if workflow.Status == domain.WorkflowStatusRunning {
+			workflows = append(workflows, workflow)
+		}
+	}
+	return copyAll(workflows), nil
+}
+
+func (s *Storage) findWorkflow(id string) *domain.Workflow {
+	for _, workflow := range s.workflows {
+		if workflow.ID == id {
+			return workflow
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// AdvisoryLock is a session-level Postgres advisory lock used for leader election.
+// The lock is held by a dedicated connection, so it is released by Postgres when the
+// holding replica dies.
+type AdvisoryLock struct {
+	db  *sql.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewAdvisoryLock creates an advisory lock identified by key
+func NewAdvisoryLock(db *sql.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// TryAcquire returns whether this process holds the lock, acquiring it when free
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Still the leader as long as the holding connection is alive
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Release releases the lock when held
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	defer func() {
+		l.conn.Close()
+		l.conn = nil
+	}()
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	return err
+}
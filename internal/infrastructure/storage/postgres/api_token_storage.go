@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const tokenColumns = `id::text, name, user_id, scopes, hash, created_by, created_at, expires_at, last_used_at, revoked_at`
+
+func (s *PostgresStorage) AddToken(ctx context.Context, token *domain.APIToken) error {
+	scopes, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_tokens (name, user_id, scopes, hash, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id::text
+	`
+
+	err = s.db.QueryRowContext(ctx, query,
+		token.Name,
+		token.UserID,
+		scopes,
+		token.Hash,
+		token.CreatedBy,
+		token.CreatedAt.UTC(),
+		nullTime(token.ExpiresAt),
+	).Scan(&token.ID)
+	if isUniqueViolation(err) {
+		return domain.AlreadyExists("a token with the same hash already exists")
+	}
+	return err
+}
+
+func (s *PostgresStorage) GetTokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM api_tokens
+		WHERE hash = $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens, err := scanTokens(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, notFound("token not found")
+	}
+	return tokens[0], nil
+}
+
+func (s *PostgresStorage) ListTokens(ctx context.Context) ([]*domain.APIToken, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM api_tokens
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTokens(rows)
+}
+
+func (s *PostgresStorage) RevokeToken(ctx context.Context, id string, at time.Time) error {
+	query := `
+		UPDATE api_tokens
+		SET revoked_at = COALESCE(revoked_at, $1)
+		WHERE id::text = $2
+	`
+
+	result, err := s.db.ExecContext(ctx, query, at.UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("token %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) TouchToken(ctx context.Context, id string, at time.Time) error {
+	query := `
+		UPDATE api_tokens
+		SET last_used_at = $1
+		WHERE id::text = $2
+	`
+
+	_, err := s.db.ExecContext(ctx, query, at.UTC(), id)
+	return err
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func scanTokens(rows *sql.Rows) ([]*domain.APIToken, error) {
+	var tokens []*domain.APIToken
+	for rows.Next() {
+		var token domain.APIToken
+		var scopesJSON []byte
+		var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+		err := rows.Scan(
+			&token.ID,
+			&token.Name,
+			&token.UserID,
+			&scopesJSON,
+			&token.Hash,
+			&token.CreatedBy,
+			&token.CreatedAt,
+			&expiresAt,
+			&lastUsedAt,
+			&revokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(scopesJSON, &token.Scopes); err != nil {
+			return nil, err
+		}
+		token.ExpiresAt = expiresAt.Time
+		token.LastUsedAt = lastUsedAt.Time
+		token.RevokedAt = revokedAt.Time
+
+		tokens = append(tokens, &token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
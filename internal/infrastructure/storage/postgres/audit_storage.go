@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const auditColumns = `id::text, type, repository, actor, message, payload, occurred_at`
+
+func (s *PostgresStorage) AddAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (type, repository, actor, message, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id::text
+	`
+
+	var payload []byte
+	if len(event.Payload) > 0 {
+		payload = event.Payload
+	}
+
+	return s.db.QueryRowContext(ctx, query,
+		event.Type,
+		event.Repository,
+		event.Actor,
+		event.Message,
+		payload,
+		event.OccurredAt.UTC(),
+	).Scan(&event.ID)
+}
+
+func (s *PostgresStorage) ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_events
+		WHERE ($1 IN ('', '*') OR type = $1 OR starts_with(type, $1 || '.'))
+			AND ($2 = '' OR repository = $2)
+			AND ($3 = '' OR actor = $3)
+			AND occurred_at >= $4
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $5
+	`
+
+	rows, err := s.db.QueryContext(ctx, query,
+		filter.Type,
+		filter.Repository,
+		filter.Actor,
+		filter.Since.UTC(),
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.AuditEvent
+	for rows.Next() {
+		var event domain.AuditEvent
+
+		err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.Repository,
+			&event.Actor,
+			&event.Message,
+			&event.Payload,
+			&event.OccurredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -10,7 +10,8 @@ import (
 	_ "github.com/lib/pq"
 )
 
-// NewConnection establishes a new connection to the PostgreSQL database
+// NewConnection establishes a new connection to the PostgreSQL database. With
+// AutoMigrate set, pending migrations are applied before it returns.
 func NewConnection(cfg config.DatabaseConfig) (*sql.DB, error) {
 	// Build connection string from configuration
 	connStr := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=%s",
@@ -37,8 +38,16 @@ func NewConnection(cfg config.DatabaseConfig) (*sql.DB, error) {
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if cfg.AutoMigrate {
+		if err := Migrate(context.Background(), db); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
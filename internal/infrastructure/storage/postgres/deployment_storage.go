@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const deploymentColumns = `id::text, provider_deployment_id, repository, environment, ref, run_id, status, url, deployed_by, created_at, updated_at`
+
+func (s *PostgresStorage) AddDeployment(ctx context.Context, deployment *domain.Deployment) error {
+	query := `
+		INSERT INTO deployments (provider_deployment_id, repository, environment, ref, run_id, status, url, deployed_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		RETURNING id::text
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		deployment.ProviderDeploymentID,
+		deployment.Repository,
+		deployment.Environment,
+		deployment.Ref,
+		deployment.RunID,
+		deployment.Status,
+		deployment.URL,
+		deployment.DeployedBy,
+		deployment.CreatedAt,
+	).Scan(&deployment.ID)
+}
+
+func (s *PostgresStorage) UpdateDeploymentStatus(ctx context.Context, id, status string) error {
+	query := `
+		UPDATE deployments
+		SET status = $1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id::text = $2
+	`
+
+	result, err := s.db.ExecContext(ctx, query, status, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("deployment %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) SetDeploymentRun(ctx context.Context, id, runID, url string) error {
+	query := `
+		UPDATE deployments
+		SET run_id = $1,
+			url = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id::text = $3
+	`
+
+	result, err := s.db.ExecContext(ctx, query, runID, url, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("deployment %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) ListCurrentDeployments(ctx context.Context, repository string) ([]*domain.Deployment, error) {
+	query := `
+		SELECT DISTINCT ON (environment) ` + deploymentColumns + `
+		FROM deployments
+		WHERE repository = $1
+		ORDER BY environment, created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, repository)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeployments(rows)
+}
+
+func (s *PostgresStorage) ListPendingDeployments(ctx context.Context) ([]*domain.Deployment, error) {
+	query := `
+		SELECT ` + deploymentColumns + `
+		FROM deployments
+		WHERE status IN ($1, $2)
+		ORDER BY created_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, domain.DeploymentStatusQueued, domain.DeploymentStatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeployments(rows)
+}
+
+func scanDeployments(rows *sql.Rows) ([]*domain.Deployment, error) {
+	var deployments []*domain.Deployment
+	for rows.Next() {
+		var deployment domain.Deployment
+		err := rows.Scan(
+			&deployment.ID,
+			&deployment.ProviderDeploymentID,
+			&deployment.Repository,
+			&deployment.Environment,
+			&deployment.Ref,
+			&deployment.RunID,
+			&deployment.Status,
+			&deployment.URL,
+			&deployment.DeployedBy,
+			&deployment.CreatedAt,
+			&deployment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, &deployment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code of a violated unique constraint
+const uniqueViolation = "23505"
+
+// notFound reports a missing record. The error matches both domain.ErrNotFound and
+// sql.ErrNoRows.
+func notFound(format string, args ...interface{}) error {
+	return domain.NewError(domain.ErrNotFound, sql.ErrNoRows, format, args...)
+}
+
+// isUniqueViolation reports whether an insert failed on a unique constraint
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
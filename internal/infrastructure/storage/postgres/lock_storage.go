@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const lockColumns = `repository, environment, owner, reason, created_at, expires_at`
+
+// AcquireLock inserts the lock, or takes over an existing lock when it expired or
+// belongs to the same owner, in a single statement
+func (s *PostgresStorage) AcquireLock(ctx context.Context, lock *domain.DeploymentLock) (*domain.DeploymentLock, error) {
+	query := `
+		INSERT INTO deployment_locks (repository, environment, owner, reason, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (repository, environment) DO UPDATE
+		SET owner = EXCLUDED.owner,
+			reason = EXCLUDED.reason,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE deployment_locks.expires_at <= EXCLUDED.created_at
+			OR deployment_locks.owner = EXCLUDED.owner
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		lock.Repository,
+		lock.Environment,
+		lock.Owner,
+		lock.Reason,
+		lock.CreatedAt.UTC(),
+		lock.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetLock(ctx, lock.Repository, lock.Environment)
+}
+
+func (s *PostgresStorage) GetLock(ctx context.Context, repository, environment string) (*domain.DeploymentLock, error) {
+	query := `
+		SELECT ` + lockColumns + `
+		FROM deployment_locks
+		WHERE repository = $1 AND environment = $2 AND expires_at > $3
+	`
+
+	var lock domain.DeploymentLock
+	err := s.db.QueryRowContext(ctx, query, repository, environment, time.Now().UTC()).Scan(
+		&lock.Repository,
+		&lock.Environment,
+		&lock.Owner,
+		&lock.Reason,
+		&lock.CreatedAt,
+		&lock.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &lock, nil
+}
+
+func (s *PostgresStorage) ReleaseLock(ctx context.Context, repository, environment string) error {
+	query := `
+		DELETE FROM deployment_locks
+		WHERE repository = $1 AND environment = $2
+	`
+
+	_, err := s.db.ExecContext(ctx, query, repository, environment)
+	return err
+}
+
+func (s *PostgresStorage) ListLocks(ctx context.Context) ([]*domain.DeploymentLock, error) {
+	query := `
+		SELECT ` + lockColumns + `
+		FROM deployment_locks
+		WHERE expires_at > $1
+		ORDER BY repository, environment
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locks []*domain.DeploymentLock
+	for rows.Next() {
+		var lock domain.DeploymentLock
+		err := rows.Scan(
+			&lock.Repository,
+			&lock.Environment,
+			&lock.Owner,
+			&lock.Reason,
+			&lock.CreatedAt,
+			&lock.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		locks = append(locks, &lock)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return locks, nil
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Tovli/chatops/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	migratepg "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// ErrDirtySchema is returned when a previous migration failed midway. The schema must be
+// repaired by hand, then marked with the version it matches.
+var ErrDirtySchema = errors.New("database schema is dirty")
+
+// MigrationStatus reports the schema version of a database against the embedded migrations
+type MigrationStatus struct {
+	Version uint   // Applied version, 0 when no migration was applied
+	Dirty   bool   // Set when the migration to Version failed midway
+	Latest  uint   // Version of the newest embedded migration
+	Pending []uint // Embedded versions newer than Version
+}
+
+// Migrator applies the migrations embedded in the migrations package. Migrations run on a
+// dedicated connection holding a Postgres advisory lock, so replicas do not run them
+// concurrently.
+type Migrator struct {
+	migrate *migrate.Migrate
+	source  source.Driver
+}
+
+// NewMigrator creates a migrator over the embedded migrations
+func NewMigrator(ctx context.Context, db *sql.DB) (*Migrator, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	// The driver closes the connection it is given, leaving the pool open
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	driver, err := migratepg.WithConnection(ctx, conn, &migratepg.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to prepare migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("failed to prepare migrations: %w", err)
+	}
+
+	return &Migrator{migrate: m, source: src}, nil
+}
+
+// Migrate applies every pending embedded migration to the database
+func Migrate(ctx context.Context, db *sql.DB) error {
+	migrator, err := NewMigrator(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.Up()
+}
+
+// Up applies every pending migration. It fails with ErrDirtySchema, without running
+// anything, when a previous migration failed midway.
+func (m *Migrator) Up() error {
+	if err := m.checkDirty(); err != nil {
+		return err
+	}
+	if err := m.migrate.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return m.wrap(err)
+	}
+	return nil
+}
+
+// Down reverts the given number of applied migrations, or all of them when steps is not
+// positive
+func (m *Migrator) Down(steps int) error {
+	if err := m.checkDirty(); err != nil {
+		return err
+	}
+
+	var err error
+	if steps > 0 {
+		err = m.migrate.Steps(-steps)
+	} else {
+		err = m.migrate.Down()
+	}
+	// Reverting more steps than were applied reverts them all
+	var short migrate.ErrShortLimit
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) && !errors.As(err, &short) {
+		return m.wrap(err)
+	}
+	return nil
+}
+
+// Force records the version as applied and clears the dirty flag, without running
+// migrations. It is used once a failed migration has been repaired by hand.
+func (m *Migrator) Force(version uint) error {
+	if version == 0 {
+		return m.migrate.Force(database.NilVersion)
+	}
+	up, _, err := m.source.ReadUp(version)
+	if err != nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	up.Close()
+	return m.migrate.Force(int(version))
+}
+
+// Status reports the applied version and the pending migrations
+func (m *Migrator) Status() (*MigrationStatus, error) {
+	status := &MigrationStatus{}
+
+	version, dirty, err := m.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	status.Version, status.Dirty = version, dirty
+
+	next, err := m.source.First()
+	for err == nil {
+		status.Latest = next
+		if next > status.Version {
+			status.Pending = append(status.Pending, next)
+		}
+		next, err = m.source.Next(next)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	return status, nil
+}
+
+// Close releases the connection of the migrator
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+func (m *Migrator) checkDirty() error {
+	version, dirty, err := m.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if dirty {
+		return dirtyError(version)
+	}
+	return nil
+}
+
+func (m *Migrator) wrap(err error) error {
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return dirtyError(uint(dirty.Version))
+	}
+	return fmt.Errorf("failed to migrate: %w", err)
+}
+
+func dirtyError(version uint) error {
+	return fmt.Errorf("%w: migration %d failed midway; repair the schema, then record the version it matches with \"chatops migrate force VERSION\"", ErrDirtySchema, version)
+}
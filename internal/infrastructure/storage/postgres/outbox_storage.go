@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *PostgresStorage) AppendEvent(ctx context.Context, event *domain.Event, lease time.Duration) error {
+	var payload []byte
+	if event.Payload != nil {
+		var err error
+		if payload, err = json.Marshal(event.Payload); err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO event_outbox (type, repository, actor, message, payload, occurred_at, claimed_until)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, query,
+		event.Type,
+		event.Repository,
+		event.Actor,
+		event.Message,
+		payload,
+		event.OccurredAt.UTC(),
+		time.Now().Add(lease).UTC(),
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	event.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+// ClaimEvents skips rows locked by concurrent claimers, so replicas relaying the outbox
+// never claim the same event
+func (s *PostgresStorage) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*domain.Event, error) {
+	now := time.Now().UTC()
+	query := `
+		UPDATE event_outbox
+		SET claimed_until = $1,
+			attempts = attempts + 1
+		WHERE id IN (
+			SELECT id
+			FROM event_outbox
+			WHERE delivered_at IS NULL AND claimed_until < $2
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, type, repository, actor, message, payload, occurred_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, now.Add(lease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type claimedEvent struct {
+		id    int64
+		event *domain.Event
+	}
+	var claimed []claimedEvent
+	for rows.Next() {
+		var id int64
+		var event domain.Event
+		var payload []byte
+
+		if err := rows.Scan(&id, &event.Type, &event.Repository, &event.Actor, &event.Message, &payload, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+
+		event.ID = strconv.FormatInt(id, 10)
+		if event.Payload = domain.NewEventPayload(event.Type); event.Payload != nil && len(payload) > 0 {
+			if err := json.Unmarshal(payload, event.Payload); err != nil {
+				return nil, fmt.Errorf("failed to decode payload of event %d: %w", id, err)
+			}
+		}
+
+		claimed = append(claimed, claimedEvent{id: id, event: &event})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not preserve the order of the subquery
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].id < claimed[j].id })
+	events := make([]*domain.Event, len(claimed))
+	for i, c := range claimed {
+		events[i] = c.event
+	}
+
+	return events, nil
+}
+
+func (s *PostgresStorage) MarkEventDelivered(ctx context.Context, id string) error {
+	query := `
+		UPDATE event_outbox
+		SET delivered_at = $1
+		WHERE id::text = $2
+	`
+
+	_, err := s.db.ExecContext(ctx, query, time.Now().UTC(), id)
+	return err
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
 
 	"github.com/Tovli/chatops/internal/core/domain"
 )
 
+const repositoryColumns = `id::text, name, url, default_branch, added_by, added_at, pipelines, environments, version, updated_at`
+
 type PostgresStorage struct {
 	db *sql.DB
 }
@@ -23,58 +24,52 @@ func (s *PostgresStorage) AddRepository(ctx context.Context, repo *domain.Reposi
 		return err
 	}
 
+	environments, err := json.Marshal(environmentsOf(repo))
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO repositories (name, url, default_branch, added_by, added_at, pipelines)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO repositories (name, url, default_branch, added_by, added_at, pipelines, environments)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id::text, version, updated_at
 	`
 
-	_, err = s.db.ExecContext(ctx, query,
+	err = s.db.QueryRowContext(ctx, query,
 		repo.Name,
 		repo.URL,
 		repo.DefaultBranch,
 		repo.AddedBy,
 		repo.AddedAt,
 		pipelines,
-	)
+		environments,
+	).Scan(&repo.ID, &repo.Version, &repo.UpdatedAt)
+	if isUniqueViolation(err) {
+		return domain.AlreadyExists("repository %s already exists", repo.Name)
+	}
 
 	return err
 }
 
 func (s *PostgresStorage) GetRepository(ctx context.Context, name string) (*domain.Repository, error) {
 	query := `
-		SELECT name, url, default_branch, added_by, added_at, pipelines
+		SELECT ` + repositoryColumns + `
 		FROM repositories
 		WHERE name = $1
 	`
 
-	var repo domain.Repository
-	var pipelinesJSON []byte
-
-	err := s.db.QueryRowContext(ctx, query, name).Scan(
-		&repo.Name,
-		&repo.URL,
-		&repo.DefaultBranch,
-		&repo.AddedBy,
-		&repo.AddedAt,
-		&pipelinesJSON,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	err = json.Unmarshal(pipelinesJSON, &repo.Pipelines)
-	if err != nil {
-		return nil, err
+	repo, err := scanRepository(s.db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, notFound("repository %s not found", name)
 	}
-
-	return &repo, nil
+	return repo, err
 }
 
 func (s *PostgresStorage) ListRepositories(ctx context.Context) ([]*domain.Repository, error) {
 	query := `
-		SELECT name, url, default_branch, added_by, added_at, pipelines
+		SELECT ` + repositoryColumns + `
 		FROM repositories
+		ORDER BY id
 	`
 
 	rows, err := s.db.QueryContext(ctx, query)
@@ -85,27 +80,11 @@ func (s *PostgresStorage) ListRepositories(ctx context.Context) ([]*domain.Repos
 
 	var repositories []*domain.Repository
 	for rows.Next() {
-		var repo domain.Repository
-		var pipelinesJSON []byte
-
-		err := rows.Scan(
-			&repo.Name,
-			&repo.URL,
-			&repo.DefaultBranch,
-			&repo.AddedBy,
-			&repo.AddedAt,
-			&pipelinesJSON,
-		)
+		repo, err := scanRepository(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		err = json.Unmarshal(pipelinesJSON, &repo.Pipelines)
-		if err != nil {
-			return nil, err
-		}
-
-		repositories = append(repositories, &repo)
+		repositories = append(repositories, repo)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -121,32 +100,141 @@ func (s *PostgresStorage) UpdateRepository(ctx context.Context, repo *domain.Rep
 		return err
 	}
 
+	environments, err := json.Marshal(environmentsOf(repo))
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE repositories
 		SET url = $1,
 			default_branch = $2,
-			pipelines = $3
-		WHERE name = $4
+			pipelines = $3,
+			environments = $4,
+			version = version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE name = $5 AND version = $6
+		RETURNING version, updated_at
 	`
 
-	result, err := s.db.ExecContext(ctx, query,
+	err = s.db.QueryRowContext(ctx, query,
 		repo.URL,
 		repo.DefaultBranch,
 		pipelines,
+		environments,
 		repo.Name,
-	)
+		repo.Version,
+	).Scan(&repo.Version, &repo.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return s.updateMiss(ctx, repo.Name)
+	}
+
+	return err
+}
+
+func (s *PostgresStorage) UpsertRepository(ctx context.Context, repo *domain.Repository) error {
+	pipelines, err := json.Marshal(repo.Pipelines)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	environments, err := json.Marshal(environmentsOf(repo))
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("repository %s not found", repo.Name)
+	query := `
+		INSERT INTO repositories (name, url, default_branch, added_by, added_at, pipelines, environments)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (name) DO UPDATE SET
+			default_branch = excluded.default_branch,
+			pipelines = excluded.pipelines,
+			version = repositories.version + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE repositories.version = $8 AND repositories.url = excluded.url
+		RETURNING ` + repositoryColumns + `
+	`
+
+	upserted, err := scanRepository(s.db.QueryRowContext(ctx, query,
+		repo.Name,
+		repo.URL,
+		repo.DefaultBranch,
+		repo.AddedBy,
+		repo.AddedAt,
+		pipelines,
+		environments,
+		repo.Version,
+	))
+	if err == sql.ErrNoRows {
+		return s.upsertMiss(ctx, repo)
+	}
+	if err != nil {
+		return err
 	}
 
+	*repo = *upserted
 	return nil
 }
+
+// upsertMiss reports why an upsert of a stored repository matched no row
+func (s *PostgresStorage) upsertMiss(ctx context.Context, repo *domain.Repository) error {
+	var url string
+	err := s.db.QueryRowContext(ctx, `SELECT url FROM repositories WHERE name = $1`, repo.Name).Scan(&url)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && url != repo.URL {
+		return domain.AlreadyExists("a repository named %s is already managed from %s", repo.Name, url)
+	}
+	return domain.Conflict("repository %s was updated concurrently", repo.Name)
+}
+
+// updateMiss reports why a compare-and-swap update of a repository matched no row
+func (s *PostgresStorage) updateMiss(ctx context.Context, name string) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM repositories WHERE name = $1)`, name).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.NotFound("repository %s not found", name)
+	}
+	return domain.Conflict("repository %s was updated concurrently", name)
+}
+
+func scanRepository(row rowScanner) (*domain.Repository, error) {
+	var repo domain.Repository
+	var pipelinesJSON, environmentsJSON []byte
+
+	err := row.Scan(
+		&repo.ID,
+		&repo.Name,
+		&repo.URL,
+		&repo.DefaultBranch,
+		&repo.AddedBy,
+		&repo.AddedAt,
+		&pipelinesJSON,
+		&environmentsJSON,
+		&repo.Version,
+		&repo.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(pipelinesJSON, &repo.Pipelines); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(environmentsJSON, &repo.Environments); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// environmentsOf returns the repository environments, never nil so they are stored as an empty array
+func environmentsOf(repo *domain.Repository) []domain.Environment {
+	if repo.Environments == nil {
+		return []domain.Environment{}
+	}
+	return repo.Environments
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const scheduleColumns = `id::text, cron, timezone, command_type, parameters, command_text, user_id, platform, channel_id, created_at, last_run_at, next_run_at`
+
+func (s *PostgresStorage) AddSchedule(ctx context.Context, schedule *domain.Schedule) error {
+	parameters, err := json.Marshal(schedule.Parameters)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO schedules (cron, timezone, command_type, parameters, command_text, user_id, platform, channel_id, created_at, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id::text
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		schedule.Cron,
+		schedule.Timezone,
+		schedule.CommandType,
+		parameters,
+		schedule.CommandText,
+		schedule.User.ID,
+		schedule.User.Platform,
+		schedule.ChannelID,
+		schedule.CreatedAt.UTC(),
+		schedule.NextRunAt.UTC(),
+	).Scan(&schedule.ID)
+}
+
+func (s *PostgresStorage) GetSchedule(ctx context.Context, id string) (*domain.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE id::text = $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules, err := scanSchedules(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return nil, notFound("schedule %s not found", id)
+	}
+	return schedules[0], nil
+}
+
+func (s *PostgresStorage) ListSchedules(ctx context.Context) ([]*domain.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSchedules(rows)
+}
+
+func (s *PostgresStorage) DeleteSchedule(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM schedules
+		WHERE id::text = $1
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("schedule %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) ListDueSchedules(ctx context.Context, at time.Time) ([]*domain.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE next_run_at <= $1
+		ORDER BY next_run_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, at.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSchedules(rows)
+}
+
+func (s *PostgresStorage) ClaimScheduleRun(ctx context.Context, id string, expectedNext, ranAt, next time.Time) (bool, error) {
+	query := `
+		UPDATE schedules
+		SET last_run_at = $1,
+			next_run_at = $2
+		WHERE id::text = $3 AND next_run_at = $4
+	`
+
+	result, err := s.db.ExecContext(ctx, query, ranAt.UTC(), next.UTC(), id, expectedNext.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func scanSchedules(rows *sql.Rows) ([]*domain.Schedule, error) {
+	var schedules []*domain.Schedule
+	for rows.Next() {
+		var schedule domain.Schedule
+		var parametersJSON []byte
+		var lastRunAt sql.NullTime
+
+		err := rows.Scan(
+			&schedule.ID,
+			&schedule.Cron,
+			&schedule.Timezone,
+			&schedule.CommandType,
+			&parametersJSON,
+			&schedule.CommandText,
+			&schedule.User.ID,
+			&schedule.User.Platform,
+			&schedule.ChannelID,
+			&schedule.CreatedAt,
+			&lastRunAt,
+			&schedule.NextRunAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(parametersJSON, &schedule.Parameters); err != nil {
+			return nil, err
+		}
+		schedule.LastRunAt = lastRunAt.Time
+
+		schedules = append(schedules, &schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const subscriptionColumns = `id::text, platform, channel_id, repository, events, created_by, created_at`
+
+func (s *PostgresStorage) AddSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	events, err := json.Marshal(subscription.Events)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO subscriptions (platform, channel_id, repository, events, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id::text
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		subscription.Platform,
+		subscription.ChannelID,
+		subscription.Repository,
+		events,
+		subscription.CreatedBy,
+		subscription.CreatedAt.UTC(),
+	).Scan(&subscription.ID)
+}
+
+func (s *PostgresStorage) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE id::text = $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions, err := scanSubscriptions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(subscriptions) == 0 {
+		return nil, notFound("subscription %s not found", id)
+	}
+	return subscriptions[0], nil
+}
+
+func (s *PostgresStorage) DeleteSubscription(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM subscriptions
+		WHERE id::text = $1
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("subscription %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) ListSubscriptions(ctx context.Context, repository string) ([]*domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE repository = $1 OR repository = $2
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, repository, domain.AllRepositories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSubscriptions(rows)
+}
+
+func (s *PostgresStorage) ListChannelSubscriptions(ctx context.Context, platform, channelID string) ([]*domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE platform = $1 AND channel_id = $2
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, platform, channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSubscriptions(rows)
+}
+
+func (s *PostgresStorage) MuteChannel(ctx context.Context, mute *domain.ChannelMute) error {
+	var until sql.NullTime
+	if !mute.Until.IsZero() {
+		until = sql.NullTime{Time: mute.Until.UTC(), Valid: true}
+	}
+
+	query := `
+		INSERT INTO channel_mutes (platform, channel_id, muted_until, muted_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (platform, channel_id) DO UPDATE
+		SET muted_until = EXCLUDED.muted_until,
+			muted_by = EXCLUDED.muted_by
+	`
+
+	_, err := s.db.ExecContext(ctx, query, mute.Platform, mute.ChannelID, until, mute.MutedBy)
+	return err
+}
+
+func (s *PostgresStorage) UnmuteChannel(ctx context.Context, platform, channelID string) error {
+	query := `
+		DELETE FROM channel_mutes
+		WHERE platform = $1 AND channel_id = $2
+	`
+
+	_, err := s.db.ExecContext(ctx, query, platform, channelID)
+	return err
+}
+
+func (s *PostgresStorage) GetChannelMute(ctx context.Context, platform, channelID string) (*domain.ChannelMute, error) {
+	query := `
+		SELECT platform, channel_id, muted_until, muted_by
+		FROM channel_mutes
+		WHERE platform = $1 AND channel_id = $2
+	`
+
+	var mute domain.ChannelMute
+	var until sql.NullTime
+	err := s.db.QueryRowContext(ctx, query, platform, channelID).Scan(
+		&mute.Platform,
+		&mute.ChannelID,
+		&until,
+		&mute.MutedBy,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mute.Until = until.Time
+
+	return &mute, nil
+}
+
+func scanSubscriptions(rows *sql.Rows) ([]*domain.Subscription, error) {
+	var subscriptions []*domain.Subscription
+	for rows.Next() {
+		var subscription domain.Subscription
+		var eventsJSON []byte
+
+		err := rows.Scan(
+			&subscription.ID,
+			&subscription.Platform,
+			&subscription.ChannelID,
+			&subscription.Repository,
+			&eventsJSON,
+			&subscription.CreatedBy,
+			&subscription.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(eventsJSON, &subscription.Events); err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
@@ -0,0 +1,313 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const (
+	webhookColumns  = `id::text, url, events, secret, headers, active, created_by, created_at, updated_at`
+	deliveryColumns = `id::text, webhook_id::text, event_id, event_type, body, status, attempts, response_code, error, next_attempt_at, created_at, delivered_at`
+)
+
+func (s *PostgresStorage) AddWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	events, headers, err := marshalWebhook(webhook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhooks (url, events, secret, headers, active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id::text
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		webhook.URL,
+		events,
+		webhook.Secret,
+		headers,
+		webhook.Active,
+		webhook.CreatedBy,
+		webhook.CreatedAt.UTC(),
+		webhook.UpdatedAt.UTC(),
+	).Scan(&webhook.ID)
+}
+
+func (s *PostgresStorage) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE id::text = $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks, err := scanWebhooks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooks) == 0 {
+		return nil, notFound("webhook %s not found", id)
+	}
+	return webhooks[0], nil
+}
+
+func (s *PostgresStorage) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhooks(rows)
+}
+
+func (s *PostgresStorage) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	events, headers, err := marshalWebhook(webhook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = $1,
+			events = $2,
+			secret = $3,
+			headers = $4,
+			active = $5,
+			updated_at = $6
+		WHERE id::text = $7
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		webhook.URL,
+		events,
+		webhook.Secret,
+		headers,
+		webhook.Active,
+		webhook.UpdatedAt.UTC(),
+		webhook.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("webhook %s not found", webhook.ID)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) DeleteWebhook(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM webhooks
+		WHERE id::text = $1
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("webhook %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorage) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, body, status, attempts, next_attempt_at, created_at)
+		VALUES ($1::integer, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id::text
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Body,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt.UTC(),
+		delivery.CreatedAt.UTC(),
+	).Scan(&delivery.ID)
+}
+
+func (s *PostgresStorage) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	var deliveredAt sql.NullTime
+	if !delivery.DeliveredAt.IsZero() {
+		deliveredAt = sql.NullTime{Time: delivery.DeliveredAt.UTC(), Valid: true}
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1,
+			attempts = $2,
+			response_code = $3,
+			error = $4,
+			next_attempt_at = $5,
+			delivered_at = $6
+		WHERE id::text = $7
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.Error,
+		delivery.NextAttemptAt.UTC(),
+		deliveredAt,
+		delivery.ID,
+	)
+	return err
+}
+
+func (s *PostgresStorage) ListDueDeliveries(ctx context.Context, at time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, domain.DeliveryStatusPending, at.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func (s *PostgresStorage) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id::text = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func marshalWebhook(webhook *domain.Webhook) (events, headers []byte, err error) {
+	if events, err = json.Marshal(webhook.Events); err != nil {
+		return nil, nil, err
+	}
+	if webhook.Headers == nil {
+		return events, []byte("{}"), nil
+	}
+	if headers, err = json.Marshal(webhook.Headers); err != nil {
+		return nil, nil, err
+	}
+	return events, headers, nil
+}
+
+func scanWebhooks(rows *sql.Rows) ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook
+	for rows.Next() {
+		var webhook domain.Webhook
+		var eventsJSON, headersJSON []byte
+
+		err := rows.Scan(
+			&webhook.ID,
+			&webhook.URL,
+			&eventsJSON,
+			&webhook.Secret,
+			&headersJSON,
+			&webhook.Active,
+			&webhook.CreatedBy,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(eventsJSON, &webhook.Events); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(headersJSON, &webhook.Headers); err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, &webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		var deliveredAt sql.NullTime
+
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Body,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.ResponseCode,
+			&delivery.Error,
+			&delivery.NextAttemptAt,
+			&delivery.CreatedAt,
+			&deliveredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		delivery.DeliveredAt = deliveredAt.Time
+
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
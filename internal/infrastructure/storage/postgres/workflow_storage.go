@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const workflowColumns = `id::text, name, path, parameters, status, steps, user_id, platform, channel_id, version, created_at, updated_at`
+
+func (s *PostgresStorage) AddWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	parameters, err := json.Marshal(workflow.Parameters)
+	if err != nil {
+		return err
+	}
+	steps, err := json.Marshal(workflow.Steps)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO workflows (name, path, parameters, status, steps, user_id, platform, channel_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id::text, version
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		workflow.Name,
+		workflow.Path,
+		parameters,
+		workflow.Status,
+		steps,
+		workflow.StartedBy.ID,
+		workflow.StartedBy.Platform,
+		workflow.ChannelID,
+		workflow.CreatedAt.UTC(),
+		workflow.UpdatedAt.UTC(),
+	).Scan(&workflow.ID, &workflow.Version)
+}
+
+func (s *PostgresStorage) GetWorkflow(ctx context.Context, id string) (*domain.Workflow, error) {
+	query := `
+		SELECT ` + workflowColumns + `
+		FROM workflows
+		WHERE id::text = $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workflows, err := scanWorkflows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(workflows) == 0 {
+		return nil, notFound("workflow %s not found", id)
+	}
+	return workflows[0], nil
+}
+
+func (s *PostgresStorage) UpdateWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	steps, err := json.Marshal(workflow.Steps)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE workflows
+		SET status = $1,
+			steps = $2,
+			version = version + 1,
+			updated_at = $3
+		WHERE id::text = $4 AND version = $5
+		RETURNING version
+	`
+
+	err = s.db.QueryRowContext(ctx, query,
+		workflow.Status,
+		steps,
+		workflow.UpdatedAt.UTC(),
+		workflow.ID,
+		workflow.Version,
+	).Scan(&workflow.Version)
+	if err == sql.ErrNoRows {
+		return s.workflowUpdateMiss(ctx, workflow.ID)
+	}
+
+	return err
+}
+
+// workflowUpdateMiss reports why a compare-and-swap update of a workflow matched no row
+func (s *PostgresStorage) workflowUpdateMiss(ctx context.Context, id string) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM workflows WHERE id::text = $1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.NotFound("workflow %s not found", id)
+	}
+	return domain.Conflict("workflow %s was updated concurrently", id)
+}
+
+func (s *PostgresStorage) ListActiveWorkflows(ctx context.Context) ([]*domain.Workflow, error) {
+	query := `
+		SELECT ` + workflowColumns + `
+		FROM workflows
+		WHERE status = $1
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, domain.WorkflowStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWorkflows(rows)
+}
+
+func scanWorkflows(rows *sql.Rows) ([]*domain.Workflow, error) {
+	var workflows []*domain.Workflow
+	for rows.Next() {
+		var workflow domain.Workflow
+		var parametersJSON, stepsJSON []byte
+
+		err := rows.Scan(
+			&workflow.ID,
+			&workflow.Name,
+			&workflow.Path,
+			&parametersJSON,
+			&workflow.Status,
+			&stepsJSON,
+			&workflow.StartedBy.ID,
+			&workflow.StartedBy.Platform,
+			&workflow.ChannelID,
+			&workflow.Version,
+			&workflow.CreatedAt,
+			&workflow.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(parametersJSON, &workflow.Parameters); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(stepsJSON, &workflow.Steps); err != nil {
+			return nil, err
+		}
+
+		workflows = append(workflows, &workflow)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return workflows, nil
+}
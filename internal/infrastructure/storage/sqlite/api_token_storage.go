@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const tokenColumns = `id, name, user_id, scopes, hash, created_by, created_at, expires_at, last_used_at, revoked_at`
+
+func (s *Storage) AddToken(ctx context.Context, token *domain.APIToken) error {
+	scopes, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_tokens (name, user_id, scopes, hash, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		token.Name,
+		token.UserID,
+		scopes,
+		token.Hash,
+		token.CreatedBy,
+		timeArg(token.CreatedAt),
+		nullTimeArg(token.ExpiresAt),
+	)
+	if isUniqueViolation(err) {
+		return domain.AlreadyExists("a token with the same hash already exists")
+	}
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	token.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+func (s *Storage) GetTokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM api_tokens
+		WHERE hash = ?
+	`
+
+	token, err := scanToken(s.db.QueryRowContext(ctx, query, hash))
+	if err == sql.ErrNoRows {
+		return nil, notFound("token not found")
+	}
+	return token, err
+}
+
+func (s *Storage) ListTokens(ctx context.Context) ([]*domain.APIToken, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM api_tokens
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanToken)
+}
+
+func (s *Storage) RevokeToken(ctx context.Context, id string, at time.Time) error {
+	query := `
+		UPDATE api_tokens
+		SET revoked_at = COALESCE(revoked_at, ?)
+		WHERE id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, timeArg(at), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("token %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *Storage) TouchToken(ctx context.Context, id string, at time.Time) error {
+	query := `
+		UPDATE api_tokens
+		SET last_used_at = ?
+		WHERE id = ?
+	`
+
+	_, err := s.db.ExecContext(ctx, query, timeArg(at), id)
+	return err
+}
+
+func scanToken(row rowScanner) (*domain.APIToken, error) {
+	var token domain.APIToken
+	var scopesJSON []byte
+
+	err := row.Scan(
+		&token.ID,
+		&token.Name,
+		&token.UserID,
+		&scopesJSON,
+		&token.Hash,
+		&token.CreatedBy,
+		timestamp{&token.CreatedAt},
+		timestamp{&token.ExpiresAt},
+		timestamp{&token.LastUsedAt},
+		timestamp{&token.RevokedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(scopesJSON, &token.Scopes); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
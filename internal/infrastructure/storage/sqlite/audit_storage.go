@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const auditColumns = `id, type, repository, actor, message, payload, occurred_at`
+
+func (s *Storage) AddAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (type, repository, actor, message, payload, occurred_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	var payload []byte
+	if len(event.Payload) > 0 {
+		payload = event.Payload
+	}
+
+	result, err := s.db.ExecContext(ctx, query,
+		event.Type,
+		event.Repository,
+		event.Actor,
+		event.Message,
+		payload,
+		timeArg(event.OccurredAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	event.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+func (s *Storage) ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_events
+		WHERE (?1 IN ('', '*') OR type = ?1 OR substr(type, 1, length(?1) + 1) = ?1 || '.')
+			AND (?2 = '' OR repository = ?2)
+			AND (?3 = '' OR actor = ?3)
+			AND occurred_at >= ?4
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT ?5
+	`
+
+	rows, err := s.db.QueryContext(ctx, query,
+		filter.Type,
+		filter.Repository,
+		filter.Actor,
+		timeArg(filter.Since),
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, func(row rowScanner) (*domain.AuditEvent, error) {
+		var event domain.AuditEvent
+		err := row.Scan(
+			&event.ID,
+			&event.Type,
+			&event.Repository,
+			&event.Actor,
+			&event.Message,
+			&event.Payload,
+			timestamp{&event.OccurredAt},
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &event, nil
+	})
+}
@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const deploymentColumns = `id, provider_deployment_id, repository, environment, ref, run_id, status, url, deployed_by, created_at, updated_at`
+
+func (s *Storage) AddDeployment(ctx context.Context, deployment *domain.Deployment) error {
+	query := `
+		INSERT INTO deployments (provider_deployment_id, repository, environment, ref, run_id, status, url, deployed_by, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?9)
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		deployment.ProviderDeploymentID,
+		deployment.Repository,
+		deployment.Environment,
+		deployment.Ref,
+		deployment.RunID,
+		deployment.Status,
+		deployment.URL,
+		deployment.DeployedBy,
+		timeArg(deployment.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	deployment.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+func (s *Storage) UpdateDeploymentStatus(ctx context.Context, id, status string) error {
+	query := `
+		UPDATE deployments
+		SET status = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, status, timeArg(time.Now()), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("deployment %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *Storage) SetDeploymentRun(ctx context.Context, id, runID, url string) error {
+	query := `
+		UPDATE deployments
+		SET run_id = ?,
+			url = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, runID, url, timeArg(time.Now()), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("deployment %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListCurrentDeployments(ctx context.Context, repository string) ([]*domain.Deployment, error) {
+	query := `
+		SELECT ` + deploymentColumns + `
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY environment ORDER BY created_at DESC, id DESC) AS position
+			FROM deployments
+			WHERE repository = ?
+		)
+		WHERE position = 1
+		ORDER BY environment
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, repository)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanDeployment)
+}
+
+func (s *Storage) ListPendingDeployments(ctx context.Context) ([]*domain.Deployment, error) {
+	query := `
+		SELECT ` + deploymentColumns + `
+		FROM deployments
+		WHERE status IN (?, ?)
+		ORDER BY created_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, domain.DeploymentStatusQueued, domain.DeploymentStatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanDeployment)
+}
+
+func scanDeployment(row rowScanner) (*domain.Deployment, error) {
+	var deployment domain.Deployment
+	err := row.Scan(
+		&deployment.ID,
+		&deployment.ProviderDeploymentID,
+		&deployment.Repository,
+		&deployment.Environment,
+		&deployment.Ref,
+		&deployment.RunID,
+		&deployment.Status,
+		&deployment.URL,
+		&deployment.DeployedBy,
+		timestamp{&deployment.CreatedAt},
+		timestamp{&deployment.UpdatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deployment, nil
+}
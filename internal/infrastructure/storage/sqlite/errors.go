@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// notFound reports a missing record. The error matches both domain.ErrNotFound and
+// sql.ErrNoRows.
+func notFound(format string, args ...interface{}) error {
+	return domain.NewError(domain.ErrNotFound, sql.ErrNoRows, format, args...)
+}
+
+// isUniqueViolation reports whether an insert failed on a unique constraint
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
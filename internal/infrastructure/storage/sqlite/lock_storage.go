@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const lockColumns = `repository, environment, owner, reason, created_at, expires_at`
+
+// AcquireLock inserts the lock, or takes over an existing lock when it expired or
+// belongs to the same owner, in a single statement
+func (s *Storage) AcquireLock(ctx context.Context, lock *domain.DeploymentLock) (*domain.DeploymentLock, error) {
+	query := `
+		INSERT INTO deployment_locks (repository, environment, owner, reason, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (repository, environment) DO UPDATE
+		SET owner = excluded.owner,
+			reason = excluded.reason,
+			created_at = excluded.created_at,
+			expires_at = excluded.expires_at
+		WHERE deployment_locks.expires_at <= excluded.created_at
+			OR deployment_locks.owner = excluded.owner
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		lock.Repository,
+		lock.Environment,
+		lock.Owner,
+		lock.Reason,
+		timeArg(lock.CreatedAt),
+		timeArg(lock.ExpiresAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetLock(ctx, lock.Repository, lock.Environment)
+}
+
+func (s *Storage) GetLock(ctx context.Context, repository, environment string) (*domain.DeploymentLock, error) {
+	query := `
+		SELECT ` + lockColumns + `
+		FROM deployment_locks
+		WHERE repository = ? AND environment = ? AND expires_at > ?
+	`
+
+	lock, err := scanLock(s.db.QueryRowContext(ctx, query, repository, environment, timeArg(time.Now())))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return lock, err
+}
+
+func (s *Storage) ReleaseLock(ctx context.Context, repository, environment string) error {
+	query := `
+		DELETE FROM deployment_locks
+		WHERE repository = ? AND environment = ?
+	`
+
+	_, err := s.db.ExecContext(ctx, query, repository, environment)
+	return err
+}
+
+func (s *Storage) ListLocks(ctx context.Context) ([]*domain.DeploymentLock, error) {
+	query := `
+		SELECT ` + lockColumns + `
+		FROM deployment_locks
+		WHERE expires_at > ?
+		ORDER BY repository, environment
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, timeArg(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanLock)
+}
+
+func scanLock(row rowScanner) (*domain.DeploymentLock, error) {
+	var lock domain.DeploymentLock
+	err := row.Scan(
+		&lock.Repository,
+		&lock.Environment,
+		&lock.Owner,
+		&lock.Reason,
+		timestamp{&lock.CreatedAt},
+		timestamp{&lock.ExpiresAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lock, nil
+}
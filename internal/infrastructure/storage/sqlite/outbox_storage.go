@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) AppendEvent(ctx context.Context, event *domain.Event, lease time.Duration) error {
+	var payload []byte
+	if event.Payload != nil {
+		var err error
+		if payload, err = json.Marshal(event.Payload); err != nil {
+			return fmt.Errorf("failed to encode payload: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO event_outbox (type, repository, actor, message, payload, occurred_at, claimed_until)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		event.Type,
+		event.Repository,
+		event.Actor,
+		event.Message,
+		payload,
+		timeArg(event.OccurredAt),
+		timeArg(time.Now().Add(lease)),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	event.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+// ClaimEvents claims events in a single statement; the storage has a single connection,
+// so concurrent claimers never claim the same event
+func (s *Storage) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*domain.Event, error) {
+	now := time.Now()
+	query := `
+		UPDATE event_outbox
+		SET claimed_until = ?,
+			attempts = attempts + 1
+		WHERE id IN (
+			SELECT id
+			FROM event_outbox
+			WHERE delivered_at IS NULL AND claimed_until < ?
+			ORDER BY id
+			LIMIT ?
+		)
+		RETURNING id, type, repository, actor, message, payload, occurred_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, timeArg(now.Add(lease)), timeArg(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type claimedEvent struct {
+		id    int64
+		event *domain.Event
+	}
+	var claimed []claimedEvent
+	for rows.Next() {
+		var id int64
+		var event domain.Event
+		var payload []byte
+
+		if err := rows.Scan(&id, &event.Type, &event.Repository, &event.Actor, &event.Message, &payload, timestamp{&event.OccurredAt}); err != nil {
+			return nil, err
+		}
+
+		event.ID = strconv.FormatInt(id, 10)
+		if event.Payload = domain.NewEventPayload(event.Type); event.Payload != nil && len(payload) > 0 {
+			if err := json.Unmarshal(payload, event.Payload); err != nil {
+				return nil, fmt.Errorf("failed to decode payload of event %d: %w", id, err)
+			}
+		}
+
+		claimed = append(claimed, claimedEvent{id: id, event: &event})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not preserve the order of the subquery
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].id < claimed[j].id })
+	events := make([]*domain.Event, len(claimed))
+	for i, c := range claimed {
+		events[i] = c.event
+	}
+
+	return events, nil
+}
+
+func (s *Storage) MarkEventDelivered(ctx context.Context, id string) error {
+	query := `
+		UPDATE event_outbox
+		SET delivered_at = ?
+		WHERE id = ?
+	`
+
+	_, err := s.db.ExecContext(ctx, query, timeArg(time.Now()), id)
+	return err
+}
@@ -0,0 +1,214 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const repositoryColumns = `id, name, url, default_branch, added_by, added_at, pipelines, environments, version, updated_at`
+
+func (s *Storage) AddRepository(ctx context.Context, repo *domain.Repository) error {
+	pipelines, environments, err := marshalRepository(repo)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO repositories (name, url, default_branch, added_by, added_at, pipelines, environments, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, version, updated_at
+	`
+
+	err = s.db.QueryRowContext(ctx, query,
+		repo.Name,
+		repo.URL,
+		repo.DefaultBranch,
+		repo.AddedBy,
+		timeArg(repo.AddedAt),
+		pipelines,
+		environments,
+		timeArg(time.Now()),
+	).Scan(&repo.ID, &repo.Version, timestamp{&repo.UpdatedAt})
+	if isUniqueViolation(err) {
+		return domain.AlreadyExists("repository %s already exists", repo.Name)
+	}
+
+	return err
+}
+
+func (s *Storage) GetRepository(ctx context.Context, name string) (*domain.Repository, error) {
+	query := `
+		SELECT ` + repositoryColumns + `
+		FROM repositories
+		WHERE name = ?
+	`
+
+	repo, err := scanRepository(s.db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, notFound("repository %s not found", name)
+	}
+	return repo, err
+}
+
+func (s *Storage) ListRepositories(ctx context.Context) ([]*domain.Repository, error) {
+	query := `
+		SELECT ` + repositoryColumns + `
+		FROM repositories
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanRepository)
+}
+
+func (s *Storage) UpdateRepository(ctx context.Context, repo *domain.Repository) error {
+	pipelines, environments, err := marshalRepository(repo)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE repositories
+		SET url = ?,
+			default_branch = ?,
+			pipelines = ?,
+			environments = ?,
+			version = version + 1,
+			updated_at = ?
+		WHERE name = ? AND version = ?
+		RETURNING version, updated_at
+	`
+
+	err = s.db.QueryRowContext(ctx, query,
+		repo.URL,
+		repo.DefaultBranch,
+		pipelines,
+		environments,
+		timeArg(time.Now()),
+		repo.Name,
+		repo.Version,
+	).Scan(&repo.Version, timestamp{&repo.UpdatedAt})
+	if err == sql.ErrNoRows {
+		return s.updateMiss(ctx, repo.Name)
+	}
+
+	return err
+}
+
+func (s *Storage) UpsertRepository(ctx context.Context, repo *domain.Repository) error {
+	pipelines, environments, err := marshalRepository(repo)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO repositories (name, url, default_branch, added_by, added_at, pipelines, environments, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			default_branch = excluded.default_branch,
+			pipelines = excluded.pipelines,
+			version = version + 1,
+			updated_at = excluded.updated_at
+		WHERE version = ? AND url = excluded.url
+		RETURNING ` + repositoryColumns + `
+	`
+
+	upserted, err := scanRepository(s.db.QueryRowContext(ctx, query,
+		repo.Name,
+		repo.URL,
+		repo.DefaultBranch,
+		repo.AddedBy,
+		timeArg(repo.AddedAt),
+		pipelines,
+		environments,
+		timeArg(time.Now()),
+		repo.Version,
+	))
+	if err == sql.ErrNoRows {
+		return s.upsertMiss(ctx, repo)
+	}
+	if err != nil {
+		return err
+	}
+
+	*repo = *upserted
+	return nil
+}
+
+// upsertMiss reports why an upsert of a stored repository matched no row
+func (s *Storage) upsertMiss(ctx context.Context, repo *domain.Repository) error {
+	var url string
+	err := s.db.QueryRowContext(ctx, `SELECT url FROM repositories WHERE name = ?`, repo.Name).Scan(&url)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && url != repo.URL {
+		return domain.AlreadyExists("a repository named %s is already managed from %s", repo.Name, url)
+	}
+	return domain.Conflict("repository %s was updated concurrently", repo.Name)
+}
+
+// updateMiss reports why a compare-and-swap update of a repository matched no row
+func (s *Storage) updateMiss(ctx context.Context, name string) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM repositories WHERE name = ?)`, name).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.NotFound("repository %s not found", name)
+	}
+	return domain.Conflict("repository %s was updated concurrently", name)
+}
+
+// marshalRepository encodes the pipelines and environments, never storing null environments
+func marshalRepository(repo *domain.Repository) (pipelines, environments []byte, err error) {
+	if pipelines, err = json.Marshal(repo.Pipelines); err != nil {
+		return nil, nil, err
+	}
+	if repo.Environments == nil {
+		return pipelines, []byte("[]"), nil
+	}
+	if environments, err = json.Marshal(repo.Environments); err != nil {
+		return nil, nil, err
+	}
+	return pipelines, environments, nil
+}
+
+func scanRepository(row rowScanner) (*domain.Repository, error) {
+	var repo domain.Repository
+	var pipelinesJSON, environmentsJSON []byte
+
+	err := row.Scan(
+		&repo.ID,
+		&repo.Name,
+		&repo.URL,
+		&repo.DefaultBranch,
+		&repo.AddedBy,
+		timestamp{&repo.AddedAt},
+		&pipelinesJSON,
+		&environmentsJSON,
+		&repo.Version,
+		timestamp{&repo.UpdatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(pipelinesJSON, &repo.Pipelines); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(environmentsJSON, &repo.Environments); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
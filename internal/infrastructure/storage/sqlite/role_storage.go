@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+func (s *Storage) SaveRole(ctx context.Context, role *domain.Role) error {
+	permissions, err := json.Marshal(role.Permissions)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO roles (name, permissions)
+		VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET permissions = excluded.permissions
+	`
+	if _, err := tx.ExecContext(ctx, query, role.Name, permissions); err != nil {
+		return fmt.Errorf("failed to save role %s: %w", role.Name, err)
+	}
+
+	for _, user := range role.Users {
+		query := `
+			INSERT INTO role_assignments (user_id, role)
+			VALUES (?, ?)
+			ON CONFLICT DO NOTHING
+		`
+		if _, err := tx.ExecContext(ctx, query, user, role.Name); err != nil {
+			return fmt.Errorf("failed to assign role %s: %w", role.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) ListRoles(ctx context.Context) ([]*domain.Role, error) {
+	query := `
+		SELECT r.name, r.permissions, json_group_array(a.user_id ORDER BY a.user_id) FILTER (WHERE a.user_id IS NOT NULL)
+		FROM roles r
+		LEFT JOIN role_assignments a ON a.role = r.name
+		GROUP BY r.name, r.permissions
+		ORDER BY r.name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, func(row rowScanner) (*domain.Role, error) {
+		var role domain.Role
+		var permissions, users []byte
+		if err := row.Scan(&role.Name, &permissions, &users); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(permissions, &role.Permissions); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(users, &role.Users); err != nil {
+			return nil, err
+		}
+		return &role, nil
+	})
+}
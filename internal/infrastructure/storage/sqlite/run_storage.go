@@ -0,0 +1,211 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const runColumns = `run_id, provider, repository, pipeline, environment, type, status, url, triggered_by, created_at, updated_at`
+
+func (s *Storage) AddRun(ctx context.Context, run *domain.Run) error {
+	query := `
+		INSERT INTO runs (run_id, provider, repository, pipeline, environment, type, status, url, triggered_by, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?10)
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		run.ID,
+		run.Provider,
+		run.Repository,
+		run.Pipeline,
+		run.Environment,
+		run.Type,
+		run.Status,
+		run.URL,
+		run.TriggeredBy,
+		timeArg(run.CreatedAt),
+	)
+
+	return err
+}
+
+func (s *Storage) GetRun(ctx context.Context, runID string) (*domain.Run, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM runs
+		WHERE run_id = ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	run, err := scanRun(s.db.QueryRowContext(ctx, query, runID))
+	if err == sql.ErrNoRows {
+		return nil, notFound("run %s not found", runID)
+	}
+	return run, err
+}
+
+func (s *Storage) GetLatestRun(ctx context.Context, repository, pipeline string) (*domain.Run, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM runs
+		WHERE repository = ?1 AND (?2 = '' OR pipeline = ?2)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	run, err := scanRun(s.db.QueryRowContext(ctx, query, repository, pipeline))
+	if err == sql.ErrNoRows {
+		return nil, notFound("no run of %s found", repository)
+	}
+	return run, err
+}
+
+func (s *Storage) UpdateRunStatus(ctx context.Context, runID, status string) error {
+	query := `
+		UPDATE runs
+		SET status = ?,
+			updated_at = ?
+		WHERE run_id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, status, timeArg(time.Now()), runID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("run %s not found", runID)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListActiveRuns(ctx context.Context, since time.Time) ([]*domain.Run, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM runs
+		WHERE status IN (?, ?, ?) AND created_at >= ?
+		ORDER BY created_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query,
+		domain.RunStatusQueued, domain.RunStatusInProgress, domain.RunStatusCancelling, timeArg(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanRun)
+}
+
+func (s *Storage) TransitionRunStatus(ctx context.Context, runID, from, to string) (bool, error) {
+	query := `
+		UPDATE runs
+		SET status = ?,
+			updated_at = ?
+		WHERE run_id = ? AND status = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, to, timeArg(time.Now()), runID, from)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (s *Storage) ResolvePendingRun(ctx context.Context, pendingID, runID, url string) (bool, error) {
+	query := `
+		UPDATE runs
+		SET run_id = ?,
+			url = ?,
+			updated_at = ?
+		WHERE run_id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, runID, url, timeArg(time.Now()), pendingID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (s *Storage) ListUserRuns(ctx context.Context, userID string, limit int) ([]*domain.Run, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM runs
+		WHERE triggered_by = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanRun)
+}
+
+func (s *Storage) ListRuns(ctx context.Context, filter domain.RunFilter) ([]*domain.Run, error) {
+	query := `
+		SELECT ` + runColumns + `
+		FROM runs
+		WHERE (?1 = '' OR repository = ?1)
+			AND (?2 = '' OR pipeline = ?2)
+			AND (?3 = '' OR triggered_by = ?3)
+		ORDER BY created_at DESC
+		LIMIT ?4
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, filter.Repository, filter.Pipeline, filter.TriggeredBy, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanRun)
+}
+
+func scanRun(row rowScanner) (*domain.Run, error) {
+	var run domain.Run
+	err := row.Scan(
+		&run.ID,
+		&run.Provider,
+		&run.Repository,
+		&run.Pipeline,
+		&run.Environment,
+		&run.Type,
+		&run.Status,
+		&run.URL,
+		&run.TriggeredBy,
+		timestamp{&run.CreatedAt},
+		timestamp{&run.UpdatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
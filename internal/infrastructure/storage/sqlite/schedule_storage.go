@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const scheduleColumns = `id, cron, timezone, command_type, parameters, command_text, user_id, platform, channel_id, created_at, last_run_at, next_run_at`
+
+func (s *Storage) AddSchedule(ctx context.Context, schedule *domain.Schedule) error {
+	parameters, err := json.Marshal(schedule.Parameters)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO schedules (cron, timezone, command_type, parameters, command_text, user_id, platform, channel_id, created_at, next_run_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		schedule.Cron,
+		schedule.Timezone,
+		schedule.CommandType,
+		parameters,
+		schedule.CommandText,
+		schedule.User.ID,
+		schedule.User.Platform,
+		schedule.ChannelID,
+		timeArg(schedule.CreatedAt),
+		timeArg(schedule.NextRunAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	schedule.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+func (s *Storage) GetSchedule(ctx context.Context, id string) (*domain.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE id = ?
+	`
+
+	schedule, err := scanSchedule(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound("schedule %s not found", id)
+	}
+	return schedule, err
+}
+
+func (s *Storage) ListSchedules(ctx context.Context) ([]*domain.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanSchedule)
+}
+
+func (s *Storage) DeleteSchedule(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM schedules
+		WHERE id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("schedule %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListDueSchedules(ctx context.Context, at time.Time) ([]*domain.Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE next_run_at <= ?
+		ORDER BY next_run_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, timeArg(at))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanSchedule)
+}
+
+func (s *Storage) ClaimScheduleRun(ctx context.Context, id string, expectedNext, ranAt, next time.Time) (bool, error) {
+	query := `
+		UPDATE schedules
+		SET last_run_at = ?,
+			next_run_at = ?
+		WHERE id = ? AND next_run_at = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, timeArg(ranAt), timeArg(next), id, timeArg(expectedNext))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func scanSchedule(row rowScanner) (*domain.Schedule, error) {
+	var schedule domain.Schedule
+	var parametersJSON []byte
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Cron,
+		&schedule.Timezone,
+		&schedule.CommandType,
+		&parametersJSON,
+		&schedule.CommandText,
+		&schedule.User.ID,
+		&schedule.User.Platform,
+		&schedule.ChannelID,
+		timestamp{&schedule.CreatedAt},
+		timestamp{&schedule.LastRunAt},
+		timestamp{&schedule.NextRunAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(parametersJSON, &schedule.Parameters); err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
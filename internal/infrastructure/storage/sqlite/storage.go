@@ -0,0 +1,171 @@
+// Package sqlite stores every record of ChatOps in a SQLite database file, for single
+// replica deployments without Postgres
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/migrations"
+	_ "modernc.org/sqlite"
+)
+
+// timeLayout stores timestamps in UTC with a fixed width, so they compare as text
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Storage implements every store of ports.Storage over a SQLite database
+type Storage struct {
+	db *sql.DB
+}
+
+// Open opens the database file at path, creating it when missing, and migrates its
+// schema. The path ":memory:" opens a database living as long as the storage.
+func Open(ctx context.Context, file string) (*Storage, error) {
+	dsn := "file:" + file + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", file, err)
+	}
+
+	// A single connection serializes writes, which SQLite does anyway, and keeps
+	// in-memory databases alive
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Storage{db: db}, nil
+}
+
+// PingContext checks the database can be queried
+func (s *Storage) PingContext(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Close closes the database
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+// migrate applies the embedded SQLite migrations newer than the user_version of the
+// database, each in a transaction recording its version
+func migrate(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	files, err := fs.Glob(migrations.SQLiteFS, "sqlite/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(path.Base(file), "_")
+		next, err := strconv.Atoi(prefix)
+		if err != nil {
+			return fmt.Errorf("invalid migration file name %s", file)
+		}
+		if next <= version {
+			continue
+		}
+
+		script, err := migrations.SQLiteFS.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+		if err := applyMigration(ctx, db, next, string(script)); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, version int, script string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// timeArg formats a timestamp argument
+func timeArg(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullTimeArg formats a timestamp argument, storing the zero time as NULL
+func nullTimeArg(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return timeArg(t)
+}
+
+// timestamp scans a stored timestamp, NULL scanning to the zero time
+type timestamp struct {
+	t *time.Time
+}
+
+func (ts timestamp) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		*ts.t = time.Time{}
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case time.Time:
+		*ts.t = v.UTC()
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", text, err)
+	}
+	*ts.t = t
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAll scans every row with scan
+func scanAll[T any](rows *sql.Rows, scan func(rowScanner) (*T, error)) ([]*T, error) {
+	var records []*T
+	for rows.Next() {
+		record, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const subscriptionColumns = `id, platform, channel_id, repository, events, created_by, created_at`
+
+func (s *Storage) AddSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	events, err := json.Marshal(subscription.Events)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO subscriptions (platform, channel_id, repository, events, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		subscription.Platform,
+		subscription.ChannelID,
+		subscription.Repository,
+		events,
+		subscription.CreatedBy,
+		timeArg(subscription.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	subscription.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+func (s *Storage) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE id = ?
+	`
+
+	subscription, err := scanSubscription(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound("subscription %s not found", id)
+	}
+	return subscription, err
+}
+
+func (s *Storage) DeleteSubscription(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM subscriptions
+		WHERE id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("subscription %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *Storage) ListSubscriptions(ctx context.Context, repository string) ([]*domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE repository = ? OR repository = ?
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, repository, domain.AllRepositories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanSubscription)
+}
+
+func (s *Storage) ListChannelSubscriptions(ctx context.Context, platform, channelID string) ([]*domain.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE platform = ? AND channel_id = ?
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, platform, channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanSubscription)
+}
+
+func (s *Storage) MuteChannel(ctx context.Context, mute *domain.ChannelMute) error {
+	query := `
+		INSERT INTO channel_mutes (platform, channel_id, muted_until, muted_by)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (platform, channel_id) DO UPDATE
+		SET muted_until = excluded.muted_until,
+			muted_by = excluded.muted_by
+	`
+
+	_, err := s.db.ExecContext(ctx, query, mute.Platform, mute.ChannelID, nullTimeArg(mute.Until), mute.MutedBy)
+	return err
+}
+
+func (s *Storage) UnmuteChannel(ctx context.Context, platform, channelID string) error {
+	query := `
+		DELETE FROM channel_mutes
+		WHERE platform = ? AND channel_id = ?
+	`
+
+	_, err := s.db.ExecContext(ctx, query, platform, channelID)
+	return err
+}
+
+func (s *Storage) GetChannelMute(ctx context.Context, platform, channelID string) (*domain.ChannelMute, error) {
+	query := `
+		SELECT platform, channel_id, muted_until, muted_by
+		FROM channel_mutes
+		WHERE platform = ? AND channel_id = ?
+	`
+
+	var mute domain.ChannelMute
+	err := s.db.QueryRowContext(ctx, query, platform, channelID).Scan(
+		&mute.Platform,
+		&mute.ChannelID,
+		timestamp{&mute.Until},
+		&mute.MutedBy,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &mute, nil
+}
+
+func scanSubscription(row rowScanner) (*domain.Subscription, error) {
+	var subscription domain.Subscription
+	var eventsJSON []byte
+
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.Platform,
+		&subscription.ChannelID,
+		&subscription.Repository,
+		&eventsJSON,
+		&subscription.CreatedBy,
+		timestamp{&subscription.CreatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(eventsJSON, &subscription.Events); err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
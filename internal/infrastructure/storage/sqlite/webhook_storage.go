@@ -0,0 +1,299 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const (
+	webhookColumns  = `id, url, events, secret, headers, active, created_by, created_at, updated_at`
+	deliveryColumns = `id, webhook_id, event_id, event_type, body, status, attempts, response_code, error, next_attempt_at, created_at, delivered_at`
+)
+
+func (s *Storage) AddWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	events, headers, err := marshalWebhook(webhook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhooks (url, events, secret, headers, active, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		webhook.URL,
+		events,
+		webhook.Secret,
+		headers,
+		webhook.Active,
+		webhook.CreatedBy,
+		timeArg(webhook.CreatedAt),
+		timeArg(webhook.UpdatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	webhook.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+func (s *Storage) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE id = ?
+	`
+
+	webhook, err := scanWebhook(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound("webhook %s not found", id)
+	}
+	return webhook, err
+}
+
+func (s *Storage) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanWebhook)
+}
+
+func (s *Storage) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	events, headers, err := marshalWebhook(webhook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = ?,
+			events = ?,
+			secret = ?,
+			headers = ?,
+			active = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		webhook.URL,
+		events,
+		webhook.Secret,
+		headers,
+		webhook.Active,
+		timeArg(webhook.UpdatedAt),
+		webhook.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("webhook %s not found", webhook.ID)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteWebhook(ctx context.Context, id string) error {
+	query := `
+		DELETE FROM webhooks
+		WHERE id = ?
+	`
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domain.NotFound("webhook %s not found", id)
+	}
+
+	return nil
+}
+
+func (s *Storage) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, body, status, attempts, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Body,
+		delivery.Status,
+		delivery.Attempts,
+		timeArg(delivery.NextAttemptAt),
+		timeArg(delivery.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	delivery.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+func (s *Storage) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = ?,
+			attempts = ?,
+			response_code = ?,
+			error = ?,
+			next_attempt_at = ?,
+			delivered_at = ?
+		WHERE id = ?
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.Error,
+		timeArg(delivery.NextAttemptAt),
+		nullTimeArg(delivery.DeliveredAt),
+		delivery.ID,
+	)
+	return err
+}
+
+func (s *Storage) ListDueDeliveries(ctx context.Context, at time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY id
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, domain.DeliveryStatusPending, timeArg(at), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanDelivery)
+}
+
+func (s *Storage) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanDelivery)
+}
+
+func marshalWebhook(webhook *domain.Webhook) (events, headers []byte, err error) {
+	if events, err = json.Marshal(webhook.Events); err != nil {
+		return nil, nil, err
+	}
+	if webhook.Headers == nil {
+		return events, []byte("{}"), nil
+	}
+	if headers, err = json.Marshal(webhook.Headers); err != nil {
+		return nil, nil, err
+	}
+	return events, headers, nil
+}
+
+func scanWebhook(row rowScanner) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	var eventsJSON, headersJSON []byte
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&eventsJSON,
+		&webhook.Secret,
+		&headersJSON,
+		&webhook.Active,
+		&webhook.CreatedBy,
+		timestamp{&webhook.CreatedAt},
+		timestamp{&webhook.UpdatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(eventsJSON, &webhook.Events); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(headersJSON, &webhook.Headers); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func scanDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Body,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.ResponseCode,
+		&delivery.Error,
+		timestamp{&delivery.NextAttemptAt},
+		timestamp{&delivery.CreatedAt},
+		timestamp{&delivery.DeliveredAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
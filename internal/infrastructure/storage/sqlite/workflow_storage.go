@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+)
+
+const workflowColumns = `id, name, path, parameters, status, steps, user_id, platform, channel_id, version, created_at, updated_at`
+
+func (s *Storage) AddWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	parameters, err := json.Marshal(workflow.Parameters)
+	if err != nil {
+		return err
+	}
+	steps, err := json.Marshal(workflow.Steps)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO workflows (name, path, parameters, status, steps, user_id, platform, channel_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.ExecContext(ctx, query,
+		workflow.Name,
+		workflow.Path,
+		parameters,
+		workflow.Status,
+		steps,
+		workflow.StartedBy.ID,
+		workflow.StartedBy.Platform,
+		workflow.ChannelID,
+		timeArg(workflow.CreatedAt),
+		timeArg(workflow.UpdatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	workflow.ID = strconv.FormatInt(id, 10)
+	workflow.Version = 1
+	return nil
+}
+
+func (s *Storage) GetWorkflow(ctx context.Context, id string) (*domain.Workflow, error) {
+	query := `
+		SELECT ` + workflowColumns + `
+		FROM workflows
+		WHERE id = ?
+	`
+
+	workflow, err := scanWorkflow(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound("workflow %s not found", id)
+	}
+	return workflow, err
+}
+
+func (s *Storage) UpdateWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	steps, err := json.Marshal(workflow.Steps)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE workflows
+		SET status = ?,
+			steps = ?,
+			version = version + 1,
+			updated_at = ?
+		WHERE id = ? AND version = ?
+		RETURNING version
+	`
+
+	err = s.db.QueryRowContext(ctx, query,
+		workflow.Status,
+		steps,
+		timeArg(workflow.UpdatedAt),
+		workflow.ID,
+		workflow.Version,
+	).Scan(&workflow.Version)
+	if err == sql.ErrNoRows {
+		return s.workflowUpdateMiss(ctx, workflow.ID)
+	}
+
+	return err
+}
+
+// workflowUpdateMiss reports why a compare-and-swap update of a workflow matched no row
+func (s *Storage) workflowUpdateMiss(ctx context.Context, id string) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM workflows WHERE id = ?)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.NotFound("workflow %s not found", id)
+	}
+	return domain.Conflict("workflow %s was updated concurrently", id)
+}
+
+func (s *Storage) ListActiveWorkflows(ctx context.Context) ([]*domain.Workflow, error) {
+	query := `
+		SELECT ` + workflowColumns + `
+		FROM workflows
+		WHERE status = ?
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, domain.WorkflowStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, scanWorkflow)
+}
+
+func scanWorkflow(row rowScanner) (*domain.Workflow, error) {
+	var workflow domain.Workflow
+	var parametersJSON, stepsJSON []byte
+
+	err := row.Scan(
+		&workflow.ID,
+		&workflow.Name,
+		&workflow.Path,
+		&parametersJSON,
+		&workflow.Status,
+		&stepsJSON,
+		&workflow.StartedBy.ID,
+		&workflow.StartedBy.Platform,
+		&workflow.ChannelID,
+		&workflow.Version,
+		timestamp{&workflow.CreatedAt},
+		timestamp{&workflow.UpdatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(parametersJSON, &workflow.Parameters); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(stepsJSON, &workflow.Steps); err != nil {
+		return nil, err
+	}
+
+	return &workflow, nil
+}
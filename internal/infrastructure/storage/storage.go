@@ -0,0 +1,118 @@
+// Package storage opens the storage backend selected by database.driver
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/ports"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/memory"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/postgres"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/sqlite"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+
+	defaultSQLitePath = "./chatops.db"
+)
+
+// Backend is an open storage backend
+type Backend interface {
+	ports.Storage
+
+	// NewLeaderLock creates a lock electing a single leader among the replicas
+	// sharing the backend
+	NewLeaderLock(key int64) ports.LeaderLock
+	PingContext(ctx context.Context) error
+	Close() error
+}
+
+// Open opens the backend of cfg.Driver, postgres when empty
+func Open(cfg config.DatabaseConfig) (Backend, error) {
+	switch cfg.Driver {
+	case "", DriverPostgres:
+		db, err := postgres.NewConnection(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &postgresBackend{PostgresStorage: postgres.NewPostgresStorage(db), db: db}, nil
+	case DriverSQLite:
+		path := cfg.Path
+		if path == "" {
+			path = defaultSQLitePath
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		s, err := sqlite.Open(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		return &sqliteBackend{Storage: s}, nil
+	case DriverMemory:
+		return &memoryBackend{Storage: memory.NewStorage()}, nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+}
+
+type postgresBackend struct {
+	*postgres.PostgresStorage
+	db *sql.DB
+}
+
+func (b *postgresBackend) NewLeaderLock(key int64) ports.LeaderLock {
+	return postgres.NewAdvisoryLock(b.db, key)
+}
+
+func (b *postgresBackend) PingContext(ctx context.Context) error {
+	return b.db.PingContext(ctx)
+}
+
+func (b *postgresBackend) Close() error {
+	return b.db.Close()
+}
+
+// sqliteBackend serves a single replica, which always leads
+type sqliteBackend struct {
+	*sqlite.Storage
+}
+
+func (b *sqliteBackend) NewLeaderLock(int64) ports.LeaderLock {
+	return localLock{}
+}
+
+// memoryBackend serves a single replica, which always leads
+type memoryBackend struct {
+	*memory.Storage
+}
+
+func (b *memoryBackend) NewLeaderLock(int64) ports.LeaderLock {
+	return localLock{}
+}
+
+func (b *memoryBackend) PingContext(context.Context) error {
+	return nil
+}
+
+func (b *memoryBackend) Close() error {
+	return nil
+}
+
+// localLock is always held by the only replica
+type localLock struct{}
+
+func (localLock) TryAcquire(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (localLock) Release(context.Context) error {
+	return nil
+}
@@ -0,0 +1,138 @@
+// Package workflows loads workflow definitions from YAML files
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowFile is the YAML layout of a workflow definition
+type workflowFile struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Inputs      map[string]string `yaml:"inputs"` // Input names with their defaults, required when empty
+	Steps       []stepFile        `yaml:"steps"`
+}
+
+type stepFile struct {
+	ID          string            `yaml:"id"`
+	Type        string            `yaml:"type"`
+	Needs       []string          `yaml:"needs"`
+	If          string            `yaml:"if"`
+	Repository  string            `yaml:"repository"`
+	Pipeline    string            `yaml:"pipeline"`
+	Environment string            `yaml:"environment"`
+	Ref         string            `yaml:"ref"`
+	Run         string            `yaml:"run"`
+	Approvers   []string          `yaml:"approvers"`
+	Channel     string            `yaml:"channel"`
+	Message     string            `yaml:"message"`
+	URL         string            `yaml:"url"`
+	Method      string            `yaml:"method"`
+	Headers     map[string]string `yaml:"headers"`
+	Body        string            `yaml:"body"`
+	Timeout     string            `yaml:"timeout"`
+}
+
+// LoadDir loads every .yaml and .yml file in a directory. A missing directory holds no workflows.
+func LoadDir(dir string) ([]domain.WorkflowDefinition, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	definitions := make([]domain.WorkflowDefinition, 0, len(paths))
+	for _, path := range paths {
+		definition, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, *definition)
+	}
+	return definitions, nil
+}
+
+// LoadFile loads a workflow definition. The file name without extension is the
+// workflow name unless the file declares one.
+func LoadFile(path string) (*domain.WorkflowDefinition, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow %s: %w", path, err)
+	}
+
+	definition, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow %s: %w", path, err)
+	}
+	if definition.Name == "" {
+		definition.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	definition.Path = path
+	return definition, nil
+}
+
+// Parse parses a workflow definition. Step semantics are validated by the workflow engine.
+func Parse(content []byte) (*domain.WorkflowDefinition, error) {
+	var file workflowFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+
+	definition := &domain.WorkflowDefinition{
+		Name:        file.Name,
+		Description: file.Description,
+		Inputs:      file.Inputs,
+		Steps:       make([]domain.WorkflowStep, 0, len(file.Steps)),
+	}
+	for _, step := range file.Steps {
+		var timeout time.Duration
+		if step.Timeout != "" {
+			var err error
+			timeout, err = time.ParseDuration(step.Timeout)
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("step %s: invalid timeout %q", step.ID, step.Timeout)
+			}
+		}
+		definition.Steps = append(definition.Steps, domain.WorkflowStep{
+			ID:          step.ID,
+			Type:        step.Type,
+			Needs:       step.Needs,
+			If:          step.If,
+			Repository:  step.Repository,
+			Pipeline:    step.Pipeline,
+			Environment: step.Environment,
+			Ref:         step.Ref,
+			Run:         step.Run,
+			Approvers:   step.Approvers,
+			Channel:     step.Channel,
+			Message:     step.Message,
+			URL:         step.URL,
+			Method:      step.Method,
+			Headers:     step.Headers,
+			Body:        step.Body,
+			Timeout:     timeout,
+		})
+	}
+	return definition, nil
+}
@@ -3,29 +3,54 @@ package rbac
 import (
 	"context"
 	"fmt"
+	"sync"
+
+	"github.com/Tovli/chatops/internal/core/domain"
 )
 
 // Service handles Role-Based Access Control
 type Service struct {
-	roles map[string][]string
+	mu        sync.RWMutex
+	roles     map[string][]string
+	userRoles map[string][]string
 }
 
 // NewService creates a new RBAC service
 func NewService() *Service {
 	return &Service{
-		roles: make(map[string][]string),
+		roles:     make(map[string][]string),
+		userRoles: make(map[string][]string),
 	}
 }
 
+// NewServiceWithRoles creates a RBAC service holding the roles and their assigned users
+func NewServiceWithRoles(roles []*domain.Role) (*Service, error) {
+	s := NewService()
+	for _, role := range roles {
+		if err := s.AddRole(role.Name, role.Permissions); err != nil {
+			return nil, err
+		}
+		for _, user := range role.Users {
+			if err := s.AssignRole(user, role.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
 // HasPermission checks if a user has the required permission
 func (s *Service) HasPermission(ctx context.Context, userRole string, requiredPermission string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	permissions, exists := s.roles[userRole]
 	if !exists {
 		return false
 	}
 
 	for _, p := range permissions {
-		if p == requiredPermission {
+		if p == requiredPermission || p == domain.PermissionAll {
 			return true
 		}
 	}
@@ -35,6 +60,9 @@ func (s *Service) HasPermission(ctx context.Context, userRole string, requiredPe
 
 // AddRole adds a new role with its permissions
 func (s *Service) AddRole(role string, permissions []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if _, exists := s.roles[role]; exists {
 		return fmt.Errorf("role %s already exists", role)
 	}
@@ -42,3 +70,42 @@ func (s *Service) AddRole(role string, permissions []string) error {
 	s.roles[role] = permissions
 	return nil
 }
+
+// AssignRole grants a role to a user
+func (s *Service) AssignRole(userID string, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.roles[role]; !exists {
+		return fmt.Errorf("role %s does not exist", role)
+	}
+
+	for _, r := range s.userRoles[userID] {
+		if r == role {
+			return nil
+		}
+	}
+	s.userRoles[userID] = append(s.userRoles[userID], role)
+	return nil
+}
+
+// IsAllowed checks the permissions carried by the user and those granted through assigned roles
+func (s *Service) IsAllowed(ctx context.Context, user domain.User, requiredPermission string) bool {
+	for _, p := range user.Permissions {
+		if p == requiredPermission || p == domain.PermissionAll {
+			return true
+		}
+	}
+
+	s.mu.RLock()
+	roles := s.userRoles[user.ID]
+	s.mu.RUnlock()
+
+	for _, role := range roles {
+		if s.HasPermission(ctx, role, requiredPermission) {
+			return true
+		}
+	}
+
+	return false
+}
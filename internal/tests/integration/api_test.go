@@ -3,126 +3,454 @@ package integration
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
-	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
-	"crypto/hmac"
-	"crypto/sha256"
-
 	"github.com/Tovli/chatops/internal/adapters/github"
+	"github.com/Tovli/chatops/internal/adapters/rest"
 	"github.com/Tovli/chatops/internal/adapters/slack"
 	"github.com/Tovli/chatops/internal/core/domain"
 	"github.com/Tovli/chatops/internal/core/services"
 	"github.com/Tovli/chatops/internal/infrastructure/config"
-	"github.com/Tovli/chatops/internal/infrastructure/env"
 	"github.com/Tovli/chatops/internal/infrastructure/router"
-	storagepg "github.com/Tovli/chatops/internal/infrastructure/storage/postgres"
-	"github.com/joho/godotenv"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/memory"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
-func init() {
-	// Set test environment
-	if env.GetEnvWithDefault("APP_ENV", "") == "" {
-		os.Setenv("APP_ENV", "test")
-	}
+// memoryTokenStorage keeps API tokens in memory
+type memoryTokenStorage struct {
+	tokens []*domain.APIToken
+}
 
-	// Load environment variables in correct order
-	environment := env.GetEnvWithDefault("APP_ENV", "test")
-	godotenv.Load(".env." + environment + ".local") // .env.test.local
-	if environment != "test" {
-		godotenv.Load(".env.local") // Skip in test environment
+func (s *memoryTokenStorage) AddToken(ctx context.Context, token *domain.APIToken) error {
+	token.ID = strconv.Itoa(len(s.tokens) + 1)
+	stored := *token
+	s.tokens = append(s.tokens, &stored)
+	return nil
+}
+
+func (s *memoryTokenStorage) GetTokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	for _, token := range s.tokens {
+		if token.Hash == hash {
+			stored := *token
+			return &stored, nil
+		}
 	}
-	godotenv.Load(".env." + environment) // .env.test
-	godotenv.Load()                      // The Original .env
+	return nil, domain.NotFound("token not found")
+}
 
-	// Set default test values if not provided
-	setDefaultTestEnv("CHATOPS_GITHUB_TOKEN", "test_github_token")
-	setDefaultTestEnv("CHATOPS_SLACK_BOT_TOKEN", "test_slack_bot_token")
-	setDefaultTestEnv("CHATOPS_SLACK_SIGNING_KEY", "test_slack_signing_key")
+func (s *memoryTokenStorage) ListTokens(ctx context.Context) ([]*domain.APIToken, error) {
+	return s.tokens, nil
 }
 
-// setDefaultTestEnv sets a default value for an environment variable if it's not already set
-func setDefaultTestEnv(key, defaultValue string) {
-	if env.GetEnvWithDefault(key, "") == "" {
-		os.Setenv(key, defaultValue)
+func (s *memoryTokenStorage) RevokeToken(ctx context.Context, id string, at time.Time) error {
+	for _, token := range s.tokens {
+		if token.ID == id {
+			token.RevokedAt = at
+			return nil
+		}
 	}
+	return fmt.Errorf("token %s not found", id)
 }
 
-type testServer struct {
-	router     *router.Config
-	httpServer *httptest.Server
-	storage    *storagepg.PostgresStorage
-	cleanup    func()
+func (s *memoryTokenStorage) TouchToken(ctx context.Context, id string, at time.Time) error {
+	for _, token := range s.tokens {
+		if token.ID == id {
+			token.LastUsedAt = at
+		}
+	}
+	return nil
 }
 
-func setupTestServer(t *testing.T) *testServer {
-	// Initialize logger with test configuration
-	loggerConfig := zap.NewDevelopmentConfig()
-	loggerConfig.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
-	logger, err := loggerConfig.Build()
-	require.NoError(t, err)
+// memoryAuditStorage keeps audit events in insertion order
+type memoryAuditStorage struct {
+	events []*domain.AuditEvent
+}
 
-	// Setup test database
-	db, cleanup := setupTestDB(t)
-	storage := storagepg.NewPostgresStorage(db)
+func (s *memoryAuditStorage) AddAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	event.ID = strconv.Itoa(len(s.events) + 1)
+	stored := *event
+	s.events = append(s.events, &stored)
+	return nil
+}
 
-	// Get GitHub token from environment (will use test token if not set)
-	githubToken := env.GetEnvWithDefault("CHATOPS_GITHUB_TOKEN", "")
-	githubConfig := &config.GitHubConfig{
-		Token: githubToken,
+func (s *memoryAuditStorage) ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	var events []*domain.AuditEvent
+	for i := len(s.events) - 1; i >= 0 && len(events) < filter.Limit; i-- {
+		event := s.events[i]
+		if (filter.Type == "" || domain.MatchesEventFilter(filter.Type, event.Type)) &&
+			(filter.Repository == "" || event.Repository == filter.Repository) &&
+			(filter.Actor == "" || event.Actor == filter.Actor) &&
+			!event.OccurredAt.Before(filter.Since) {
+			events = append(events, event)
+		}
 	}
+	return events, nil
+}
 
-	// Initialize GitHub adapter with test configuration
-	githubAdapter, err := github.NewGitHubAdapter(logger, githubConfig)
-	require.NoError(t, err)
+func TestRESTAPI(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
 
-	// Initialize repository service
 	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
-		Logger:     logger,
-		GitHubPort: githubAdapter,
-		Storage:    storage,
+		Logger: zap.NewNop(),
+		Storage: &recordingStorage{
+			added: &domain.Repository{
+				Name: "payments",
+				URL:  "https://github.com/Tovli/payments",
+				Pipelines: []domain.Pipeline{
+					{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			return &domain.CommandResult{
+				Status:  "success",
+				Message: "Pipeline triggered",
+				Details: map[string]interface{}{"run_id": "1001", "url": "https://github.com/Tovli/payments/actions/runs/1001"},
+			}, nil
+		},
+	}
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline}))
+	require.NoError(t, access.AssignRole("U123456", "developer"))
+
+	audit := &memoryAuditStorage{}
+	auditLog, err := services.NewAuditLog(services.AuditLogOptions{Logger: zap.NewNop(), Storage: audit})
+	require.NoError(t, err)
+	bus, err := services.NewEventBus(services.EventBusOptions{Logger: zap.NewNop(), Clock: func() time.Time { return now }})
+	require.NoError(t, err)
+	bus.Subscribe("*", auditLog)
+
+	runs := &memoryRunStorage{}
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+		Runs:        runs,
+		Events:      bus,
+		RBAC:        access,
+	})
+	require.NoError(t, err)
+
+	tokenStorage := &memoryTokenStorage{}
+	tokens, err := services.NewTokenService(services.TokenServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: tokenStorage,
+		Clock:   func() time.Time { return now },
 	})
 	require.NoError(t, err)
 
-	// Initialize command processor
-	cmdProcessor, err := services.NewCommandProcessor(logger, repoService, githubAdapter)
+	adapter, err := rest.NewRESTAdapter(rest.RESTAdapterOptions{
+		Logger:    zap.NewNop(),
+		Auth:      services.NewAuthenticator(services.AuthenticatorOptions{AdminToken: "admin-token", Tokens: tokens}),
+		Processor: processor,
+		Tokens:    tokens,
+		Audit:     auditLog,
+	})
 	require.NoError(t, err)
+	server := httptest.NewServer(router.NewRouter(&router.Config{Logger: zap.NewNop(), RESTAdapter: adapter}))
+	defer server.Close()
+
+	call := func(method, path, token string, body interface{}, response interface{}) int {
+		var reader io.Reader
+		if body != nil {
+			encoded, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(encoded)
+		}
+		req, err := http.NewRequest(method, server.URL+"/api/v1"+path, reader)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		if response != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(response))
+		}
+		return resp.StatusCode
+	}
 
-	// Initialize Slack adapter with test configuration
-	slackConfig := &config.SlackConfig{
-		BotToken:   env.GetEnvWithDefault("CHATOPS_SLACK_BOT_TOKEN", ""),
-		SigningKey: env.GetEnvWithDefault("CHATOPS_SLACK_SIGNING_KEY", ""),
+	createToken := func(userID string, scopes ...string) string {
+		var created map[string]interface{}
+		status := call("POST", "/tokens", "admin-token", map[string]interface{}{
+			"name":    userID + " automation",
+			"user_id": userID,
+			"scopes":  scopes,
+		}, &created)
+		require.Equal(t, http.StatusCreated, status)
+		return created["token"].(string)
 	}
-	slackAdapter, err := slack.NewSlackAdapter(logger, slackConfig, cmdProcessor)
+
+	developer := createToken("U123456", domain.ScopeRunCommands, domain.ScopeReadRepositories, domain.ScopeReadRuns, domain.ScopeManageTokens)
+	stranger := createToken("U999999", domain.ScopeRunCommands)
+	auditor := createToken("U777777", domain.ScopeReadAudit)
+
+	t.Run("Tokens Are Stored Hashed", func(t *testing.T) {
+		require.Len(t, tokenStorage.tokens, 3)
+		assert.True(t, strings.HasPrefix(developer, services.APITokenPrefix))
+		assert.Equal(t, services.HashToken(developer), tokenStorage.tokens[0].Hash)
+		assert.NotContains(t, tokenStorage.tokens[0].Hash, developer)
+
+		var listed []map[string]interface{}
+		require.Equal(t, http.StatusOK, call("GET", "/tokens", "admin-token", nil, &listed))
+		require.Len(t, listed, 3)
+		assert.NotContains(t, listed[0], "token")
+	})
+
+	t.Run("Tokens Are Managed By Their Owner", func(t *testing.T) {
+		var listed []map[string]interface{}
+		require.Equal(t, http.StatusOK, call("GET", "/tokens", developer, nil, &listed))
+		require.Len(t, listed, 1)
+		assert.Equal(t, "U123456", listed[0]["user_id"])
+		assert.NotEmpty(t, listed[0]["last_used_at"])
+
+		assert.Equal(t, http.StatusForbidden, call("DELETE", "/tokens/3", developer, nil, nil))
+		assert.True(t, tokenStorage.tokens[2].RevokedAt.IsZero())
+	})
+
+	t.Run("Requests Need A Valid Token With The Scope", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, call("GET", "/repositories", "", nil, nil))
+		assert.Equal(t, http.StatusUnauthorized, call("GET", "/repositories", services.APITokenPrefix+"unknown", nil, nil))
+		assert.Equal(t, http.StatusForbidden, call("GET", "/repositories", stranger, nil, nil))
+		assert.Equal(t, http.StatusForbidden, call("GET", "/audit", developer, nil, nil))
+	})
+
+	t.Run("Tokens Cannot Grant More Than They Hold", func(t *testing.T) {
+		status := call("POST", "/tokens", developer, map[string]interface{}{
+			"name":   "escalation",
+			"scopes": []string{domain.ScopeReadAudit},
+		}, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		status = call("POST", "/tokens", developer, map[string]interface{}{
+			"name":    "impersonation",
+			"user_id": "U777777",
+			"scopes":  []string{domain.ScopeRunCommands},
+		}, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("Repositories And Pipelines", func(t *testing.T) {
+		var repos []map[string]interface{}
+		require.Equal(t, http.StatusOK, call("GET", "/repositories", developer, nil, &repos))
+		require.Len(t, repos, 1)
+		assert.Equal(t, "payments", repos[0]["name"])
+
+		var pipelines []map[string]interface{}
+		require.Equal(t, http.StatusOK, call("GET", "/repositories/payments/pipelines", developer, nil, &pipelines))
+		require.Len(t, pipelines, 1)
+		assert.Equal(t, "CI", pipelines[0]["name"])
+		assert.Equal(t, true, pipelines[0]["is_default"])
+	})
+
+	t.Run("Commands Run As The Token User", func(t *testing.T) {
+		command := map[string]interface{}{
+			"type":       domain.CommandTypeVerifyRepo,
+			"parameters": map[string]interface{}{"repository_name": "payments"},
+		}
+
+		var result map[string]interface{}
+		require.Equal(t, http.StatusForbidden, call("POST", "/commands", stranger, command, &result))
+		assert.Contains(t, result["message"], "not allowed")
+		assert.Empty(t, runs.runs)
+
+		// A user smuggled into the body is ignored
+		command["user"] = map[string]interface{}{"id": "U123456"}
+		require.Equal(t, http.StatusForbidden, call("POST", "/commands", stranger, command, &result))
+
+		require.Equal(t, http.StatusOK, call("POST", "/commands", developer, command, &result))
+		assert.Equal(t, "success", result["status"])
+		require.Len(t, runs.runs, 1)
+		assert.Equal(t, "U123456", runs.runs[0].TriggeredBy)
+	})
+
+	t.Run("Runs", func(t *testing.T) {
+		var listed []map[string]interface{}
+		require.Equal(t, http.StatusOK, call("GET", "/runs?repository=payments&user=U123456", developer, nil, &listed))
+		require.Len(t, listed, 1)
+		assert.Equal(t, "1001", listed[0]["id"])
+		assert.Equal(t, domain.RunStatusQueued, listed[0]["status"])
+
+		require.Equal(t, http.StatusOK, call("GET", "/runs?pipeline=Deploy", developer, nil, &listed))
+		assert.Empty(t, listed)
+
+		var run map[string]interface{}
+		require.Equal(t, http.StatusOK, call("GET", "/runs/1001", developer, nil, &run))
+		assert.Equal(t, "CI", run["pipeline"])
+		assert.Equal(t, http.StatusNotFound, call("GET", "/runs/42", developer, nil, nil))
+		assert.Equal(t, http.StatusBadRequest, call("GET", "/runs?limit=0", developer, nil, nil))
+	})
+
+	t.Run("Audit Events", func(t *testing.T) {
+		var events []map[string]interface{}
+		require.Equal(t, http.StatusOK, call("GET", "/audit?actor=U123456", auditor, nil, &events))
+		require.Len(t, events, 2)
+		assert.Equal(t, domain.EventCommandCompleted, events[0]["type"])
+		assert.Equal(t, domain.EventRunTriggered, events[1]["type"])
+		assert.Equal(t, "payments", events[1]["repository"])
+		assert.NotEmpty(t, events[1]["payload"])
+
+		require.Equal(t, http.StatusOK, call("GET", "/audit?type=command", auditor, nil, &events))
+		assert.Len(t, events, 3)
+		for _, event := range events {
+			assert.NotEqual(t, domain.EventCommandReceived, event["type"])
+		}
+
+		assert.Equal(t, http.StatusBadRequest, call("GET", "/audit?type=build", auditor, nil, nil))
+	})
+
+	t.Run("Revoked Tokens Are Rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, call("DELETE", "/tokens/2", "admin-token", nil, nil))
+		assert.Equal(t, http.StatusUnauthorized, call("POST", "/commands", stranger, map[string]interface{}{"type": domain.CommandTypeListEnvs}, nil))
+		assert.Equal(t, http.StatusNotFound, call("DELETE", "/tokens/42", developer, nil, nil))
+
+		_, err := tokens.Authenticate(ctx, stranger)
+		assert.ErrorIs(t, err, services.ErrInvalidToken)
+	})
+}
+
+func TestRemoteCommandsWithoutRoles(t *testing.T) {
+	ctx := context.Background()
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: &recordingStorage{},
+	})
 	require.NoError(t, err)
 
-	// Initialize router
-	routerConfig := &router.Config{
-		Logger:        logger,
-		SlackAdapter:  slackAdapter,
-		HealthHandler: nil, // Not needed for this test
+	listEnvs := func(platform string) *domain.Command {
+		return &domain.Command{
+			Type:       domain.CommandTypeListEnvs,
+			Parameters: map[string]interface{}{"repository_name": "payments"},
+			User:       domain.User{ID: "U123456", Platform: platform},
+		}
 	}
 
+	t.Run("Denied Unless Allowed", func(t *testing.T) {
+		processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{Logger: zap.NewNop(), RepoService: repoService})
+		require.NoError(t, err)
+
+		for _, platform := range []string{"api", "grpc"} {
+			_, err := processor.ProcessCommand(ctx, listEnvs(platform))
+			assert.ErrorIs(t, err, domain.ErrForbidden, platform)
+		}
+
+		// Chat users keep running every command
+		_, err = processor.ProcessCommand(ctx, listEnvs("slack"))
+		assert.NotErrorIs(t, err, domain.ErrForbidden)
+	})
+
+	t.Run("Allowed By The Option", func(t *testing.T) {
+		processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+			Logger:            zap.NewNop(),
+			RepoService:       repoService,
+			RemoteWithoutRBAC: true,
+		})
+		require.NoError(t, err)
+
+		_, err = processor.ProcessCommand(ctx, listEnvs("api"))
+		assert.NotErrorIs(t, err, domain.ErrForbidden)
+	})
+}
+
+type testServer struct {
+	router  *router.Config
+	storage *memory.Storage
+	repoURL func(name string) string
+}
+
+// setupTestServer serves the Slack endpoints over memory storage, with GitHub stubbed
+// by an httptest server knowing the ChatOps and test-repo repositories
+func setupTestServer(t *testing.T) *testServer {
+	githubAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/Tovli/ChatOps":
+			w.Write([]byte(`{"name": "ChatOps", "default_branch": "main"}`))
+		case "/api/v3/repos/Tovli/test-repo":
+			w.Write([]byte(`{"name": "test-repo", "default_branch": "main"}`))
+		case "/api/v3/repos/Tovli/ChatOps/actions/workflows", "/api/v3/repos/Tovli/test-repo/actions/workflows":
+			w.Write([]byte(`{"total_count": 1, "workflows": [{"id": 1, "name": "CI", "path": ".github/workflows/ci.yml"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	t.Cleanup(githubAPI.Close)
+
+	logger := zap.NewNop()
+	storage := memory.NewStorage()
+
+	githubAdapter, err := github.NewGitHubAdapter(logger, &config.GitHubConfig{BaseURL: githubAPI.URL, Token: "test_github_token"})
+	require.NoError(t, err)
+	providers := services.NewProviderRegistry(githubAdapter)
+
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:    logger,
+		Providers: providers,
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+
+	cmdProcessor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      logger,
+		RepoService: repoService,
+		Providers:   providers,
+		Runs:        storage,
+	})
+	require.NoError(t, err)
+
+	slackAPI := newSlackAPIStub(t)
+	slackAdapter, err := slack.NewSlackAdapter(logger, &config.SlackConfig{
+		BotToken:   "test_slack_bot_token",
+		SigningKey: testSigningKey,
+		APIURL:     slackAPI.URL + "/",
+	}, cmdProcessor)
+	require.NoError(t, err)
+
 	return &testServer{
-		router:  routerConfig,
+		router: &router.Config{
+			Logger:       logger,
+			SlackAdapter: slackAdapter,
+		},
 		storage: storage,
-		cleanup: cleanup,
+		repoURL: func(name string) string { return githubAPI.URL + "/Tovli/" + name },
 	}
 }
 
+// signSlackRequestAt adds Slack signature headers to a request as signed at a given time
+func signSlackRequestAt(req *http.Request, body string, at time.Time) {
+	timestamp := fmt.Sprintf("%d", at.Unix())
+	mac := hmac.New(sha256.New, []byte(testSigningKey))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:%s", timestamp, body)))
+
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", fmt.Sprintf("v0=%x", mac.Sum(nil)))
+}
+
 func TestSlackCommandsEndpoint(t *testing.T) {
 	server := setupTestServer(t)
-	defer server.cleanup()
 
 	appRouter := router.NewRouter(server.router)
 	testServer := httptest.NewServer(appRouter)
@@ -131,9 +459,8 @@ func TestSlackCommandsEndpoint(t *testing.T) {
 	// Initialize test data
 	ctx := context.Background()
 	testRepo := &domain.Repository{
-		ID:            "test-repo-id",
 		Name:          "test-repo",
-		URL:           "https://github.com/Tovli/test-repo",
+		URL:           server.repoURL("test-repo"),
 		DefaultBranch: "main",
 		AddedBy:       "U123456",
 		AddedAt:       time.Now(),
@@ -142,9 +469,24 @@ func TestSlackCommandsEndpoint(t *testing.T) {
 	err := server.storage.AddRepository(ctx, testRepo)
 	require.NoError(t, err)
 
+	post := func(form url.Values, sign func(req *http.Request, body string)) (int, map[string]interface{}) {
+		formEncoded := form.Encode()
+		req, err := http.NewRequest("POST", testServer.URL+"/api/v1/slack/commands", strings.NewReader(formEncoded))
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		sign(req, formEncoded)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
 	t.Run("Add GitHub Repository Command", func(t *testing.T) {
-		// Use a real public GitHub repository for testing
-		repoURL := "https://github.com/Tovli/ChatOps"
+		repoURL := server.repoURL("ChatOps")
 
 		// Prepare the Slack slash command payload
 		form := url.Values{}
@@ -160,46 +502,13 @@ func TestSlackCommandsEndpoint(t *testing.T) {
 		form.Add("response_url", "https://hooks.slack.com/commands/123456")
 		form.Add("trigger_id", "123456.123456")
 
-		// Create request
-		formEncoded := form.Encode()
-		req, err := http.NewRequest(
-			"POST",
-			testServer.URL+"/api/v1/slack/commands",
-			strings.NewReader(formEncoded),
-		)
-		require.NoError(t, err)
-
-		// Add Slack signature headers
-		timestamp := fmt.Sprintf("%d", time.Now().Unix())
-		baseString := fmt.Sprintf("v0:%s:%s", timestamp, formEncoded)
-		mac := hmac.New(sha256.New, []byte(env.GetEnvWithDefault("CHATOPS_SLACK_SIGNING_KEY", "")))
-		mac.Write([]byte(baseString))
-		signature := fmt.Sprintf("v0=%x", mac.Sum(nil))
-
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-		req.Header.Add("X-Slack-Request-Timestamp", timestamp)
-		req.Header.Add("X-Slack-Signature", signature)
-
-		// Perform the request
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		require.NoError(t, err)
-		defer resp.Body.Close()
-
-		// Check response
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-		// Decode response
-		var response map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&response)
-		require.NoError(t, err)
-
-		// Verify response contains expected fields
+		code, response := post(form, signSlackRequest)
+		assert.Equal(t, http.StatusOK, code)
 		assert.Contains(t, response, "status")
 		assert.Contains(t, response, "message")
 
 		// Verify repository was added to storage
-		repo, err := server.storage.GetRepository(context.Background(), "ChatOps")
+		repo, err := server.storage.GetRepository(ctx, "ChatOps")
 		require.NoError(t, err)
 		assert.Equal(t, repoURL, repo.URL)
 		assert.Equal(t, "U123456", repo.AddedBy)
@@ -213,37 +522,8 @@ func TestSlackCommandsEndpoint(t *testing.T) {
 		form.Add("command", "/chatops")
 		form.Add("text", "invalid") // Invalid command format
 
-		// Create request
-		formEncoded := form.Encode()
-		req, err := http.NewRequest(
-			"POST",
-			testServer.URL+"/api/v1/slack/commands",
-			strings.NewReader(formEncoded),
-		)
-		require.NoError(t, err)
-
-		// Add Slack signature headers
-		timestamp := fmt.Sprintf("%d", time.Now().Unix())
-		baseString := fmt.Sprintf("v0:%s:%s", timestamp, formEncoded)
-		mac := hmac.New(sha256.New, []byte(env.GetEnvWithDefault("CHATOPS_SLACK_SIGNING_KEY", "")))
-		mac.Write([]byte(baseString))
-		signature := fmt.Sprintf("v0=%x", mac.Sum(nil))
-
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-		req.Header.Add("X-Slack-Request-Timestamp", timestamp)
-		req.Header.Add("X-Slack-Signature", signature)
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		require.NoError(t, err)
-		defer resp.Body.Close()
-
-		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
-
-		var response map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&response)
-		require.NoError(t, err)
-
+		code, response := post(form, signSlackRequest)
+		assert.Equal(t, http.StatusBadRequest, code)
 		assert.Equal(t, "error", response["status"])
 		assert.Contains(t, response["message"], "invalid command format")
 	})
@@ -256,38 +536,31 @@ func TestSlackCommandsEndpoint(t *testing.T) {
 		form.Add("command", "/chatops")
 		form.Add("text", "manage https://github.com/test/repo")
 
-		formEncoded := form.Encode()
-		req, err := http.NewRequest(
-			"POST",
-			testServer.URL+"/api/v1/slack/commands",
-			strings.NewReader(formEncoded),
-		)
-		require.NoError(t, err)
-
-		// Add invalid signature
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-		req.Header.Add("X-Slack-Request-Timestamp", fmt.Sprintf("%d", time.Now().Unix()))
-		req.Header.Add("X-Slack-Signature", "v0=invalid")
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		require.NoError(t, err)
-		defer resp.Body.Close()
-
-		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		code, response := post(form, func(req *http.Request, body string) {
+			req.Header.Add("X-Slack-Request-Timestamp", fmt.Sprintf("%d", time.Now().Unix()))
+			req.Header.Add("X-Slack-Signature", "v0=invalid")
+		})
+		assert.Equal(t, http.StatusUnauthorized, code)
+		assert.Equal(t, "error", response["status"])
+		assert.Equal(t, "Invalid signature", response["message"])
+	})
 
-		var response map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&response)
-		require.NoError(t, err)
+	t.Run("Expired Timestamp", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("user_id", "U123456")
+		form.Add("command", "/chatops")
+		form.Add("text", "verify test-repo")
 
+		code, response := post(form, func(req *http.Request, body string) {
+			signSlackRequestAt(req, body, time.Now().Add(-6*time.Minute))
+		})
+		assert.Equal(t, http.StatusUnauthorized, code)
 		assert.Equal(t, "error", response["status"])
-		assert.Equal(t, "Invalid signature", response["message"])
 	})
 }
 
 func TestSlackWebhookEndpoint(t *testing.T) {
 	server := setupTestServer(t)
-	defer server.cleanup()
 
 	appRouter := router.NewRouter(server.router)
 	testServer := httptest.NewServer(appRouter)
@@ -296,9 +569,8 @@ func TestSlackWebhookEndpoint(t *testing.T) {
 	// Initialize test data
 	ctx := context.Background()
 	testRepo := &domain.Repository{
-		ID:            "test-repo-id",
 		Name:          "test-repo",
-		URL:           "https://github.com/Tovli/test-repo",
+		URL:           server.repoURL("test-repo"),
 		DefaultBranch: "main",
 		AddedBy:       "workflow",
 		AddedAt:       time.Now(),
@@ -307,6 +579,24 @@ func TestSlackWebhookEndpoint(t *testing.T) {
 	err := server.storage.AddRepository(ctx, testRepo)
 	require.NoError(t, err)
 
+	post := func(payload interface{}, sign func(req *http.Request, body string)) (int, map[string]interface{}) {
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", testServer.URL+"/api/v1/slack/webhooks", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		sign(req, string(body))
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
 	t.Run("Valid Webhook Request", func(t *testing.T) {
 		payload := map[string]interface{}{
 			"type": "workflow_step_execute",
@@ -322,36 +612,10 @@ func TestSlackWebhookEndpoint(t *testing.T) {
 			},
 		}
 
-		body, err := json.Marshal(payload)
-		require.NoError(t, err)
-
-		req, err := http.NewRequest("POST", testServer.URL+"/api/v1/slack/webhooks", bytes.NewBuffer(body))
-		require.NoError(t, err)
-
-		// Add Slack signature headers
-		timestamp := fmt.Sprintf("%d", time.Now().Unix())
-		baseString := fmt.Sprintf("v0:%s:%s", timestamp, string(body))
-		mac := hmac.New(sha256.New, []byte(env.GetEnvWithDefault("CHATOPS_SLACK_SIGNING_KEY", "")))
-		mac.Write([]byte(baseString))
-		signature := fmt.Sprintf("v0=%x", mac.Sum(nil))
-
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("X-Slack-Request-Timestamp", timestamp)
-		req.Header.Add("X-Slack-Signature", signature)
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		require.NoError(t, err)
-		defer resp.Body.Close()
-
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-		var response map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&response)
-		require.NoError(t, err)
-
+		code, response := post(payload, signSlackRequest)
+		assert.Equal(t, http.StatusOK, code)
 		assert.Equal(t, "success", response["status"])
-		assert.Contains(t, response["message"], "Webhook processed successfully")
+		assert.Contains(t, response["message"], "Workflow step accepted")
 	})
 
 	t.Run("Invalid Signature", func(t *testing.T) {
@@ -360,28 +624,11 @@ func TestSlackWebhookEndpoint(t *testing.T) {
 			"event": map[string]interface{}{},
 		}
 
-		body, err := json.Marshal(payload)
-		require.NoError(t, err)
-
-		req, err := http.NewRequest("POST", testServer.URL+"/api/v1/slack/webhooks", bytes.NewBuffer(body))
-		require.NoError(t, err)
-
-		// Add invalid signature
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("X-Slack-Request-Timestamp", fmt.Sprintf("%d", time.Now().Unix()))
-		req.Header.Add("X-Slack-Signature", "v0=invalid")
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		require.NoError(t, err)
-		defer resp.Body.Close()
-
-		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
-
-		var response map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&response)
-		require.NoError(t, err)
-
+		code, response := post(payload, func(req *http.Request, body string) {
+			req.Header.Add("X-Slack-Request-Timestamp", fmt.Sprintf("%d", time.Now().Unix()))
+			req.Header.Add("X-Slack-Signature", "v0=invalid")
+		})
+		assert.Equal(t, http.StatusUnauthorized, code)
 		assert.Equal(t, "error", response["status"])
 		assert.Equal(t, "Invalid signature", response["message"])
 	})
@@ -392,34 +639,10 @@ func TestSlackWebhookEndpoint(t *testing.T) {
 			"event": map[string]interface{}{},
 		}
 
-		body, err := json.Marshal(payload)
-		require.NoError(t, err)
-
-		req, err := http.NewRequest("POST", testServer.URL+"/api/v1/slack/webhooks", bytes.NewBuffer(body))
-		require.NoError(t, err)
-
-		// Add expired timestamp
-		oldTimestamp := fmt.Sprintf("%d", time.Now().Add(-6*time.Minute).Unix())
-		baseString := fmt.Sprintf("v0:%s:%s", oldTimestamp, string(body))
-		mac := hmac.New(sha256.New, []byte(env.GetEnvWithDefault("CHATOPS_SLACK_SIGNING_KEY", "")))
-		mac.Write([]byte(baseString))
-		signature := fmt.Sprintf("v0=%x", mac.Sum(nil))
-
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("X-Slack-Request-Timestamp", oldTimestamp)
-		req.Header.Add("X-Slack-Signature", signature)
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		require.NoError(t, err)
-		defer resp.Body.Close()
-
-		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
-
-		var response map[string]interface{}
-		err = json.NewDecoder(resp.Body).Decode(&response)
-		require.NoError(t, err)
-
+		code, response := post(payload, func(req *http.Request, body string) {
+			signSlackRequestAt(req, body, time.Now().Add(-6*time.Minute))
+		})
+		assert.Equal(t, http.StatusUnauthorized, code)
 		assert.Equal(t, "error", response["status"])
 		assert.Equal(t, "Request timestamp is too old", response["message"])
 	})
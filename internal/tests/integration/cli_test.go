@@ -0,0 +1,193 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/rest"
+	"github.com/Tovli/chatops/internal/cli"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/router"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/Tovli/chatops/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCLI(t *testing.T) {
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger: zap.NewNop(),
+		Storage: &recordingStorage{
+			added: &domain.Repository{
+				Name: "payments",
+				URL:  "https://github.com/Tovli/payments",
+				Pipelines: []domain.Pipeline{
+					{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+					{Name: "Nightly", Path: ".github/workflows/nightly.yml"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	auditLog, err := services.NewAuditLog(services.AuditLogOptions{Logger: zap.NewNop(), Storage: &memoryAuditStorage{}})
+	require.NoError(t, err)
+	bus, err := services.NewEventBus(services.EventBusOptions{Logger: zap.NewNop()})
+	require.NoError(t, err)
+	bus.Subscribe("*", auditLog)
+
+	runs := &memoryRunStorage{}
+	nextRun := 1000
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers: services.NewProviderRegistry(&mocks.MockGitHubAdapter{
+			TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+				nextRun++
+				return &domain.CommandResult{Status: "success", Message: "Pipeline triggered", Details: map[string]interface{}{"run_id": strconv.Itoa(nextRun)}}, nil
+			},
+		}),
+		Runs:   runs,
+		Events: bus,
+
+		RemoteWithoutRBAC: true,
+	})
+	require.NoError(t, err)
+
+	adapter, err := rest.NewRESTAdapter(rest.RESTAdapterOptions{
+		Logger:    zap.NewNop(),
+		Auth:      services.NewAuthenticator(services.AuthenticatorOptions{AdminToken: "admin-token"}),
+		Processor: processor,
+		Audit:     auditLog,
+	})
+	require.NoError(t, err)
+	server := httptest.NewServer(router.NewRouter(&router.Config{Logger: zap.NewNop(), RESTAdapter: adapter}))
+	defer server.Close()
+
+	dotfile := filepath.Join(t.TempDir(), ".chatops.yaml")
+	require.NoError(t, os.WriteFile(dotfile, []byte("server: "+server.URL+"\ntoken: admin-token\n"), 0o600))
+
+	run := func(env map[string]string, args ...string) (string, string, error) {
+		var stdout, stderr bytes.Buffer
+		app, err := cli.New(cli.Options{
+			Stdout:       &stdout,
+			Stderr:       &stderr,
+			Getenv:       func(key string) string { return env[key] },
+			PollInterval: 10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		err = app.Run(context.Background(), args)
+		return stdout.String(), stderr.String(), err
+	}
+	withDotfile := map[string]string{"CHATOPS_CONFIG": dotfile}
+
+	t.Run("Settings Come From The Dotfile", func(t *testing.T) {
+		stdout, stderr, err := run(withDotfile, "repos")
+		require.NoError(t, err)
+		assert.Empty(t, stderr)
+		assert.Contains(t, stdout, "NAME")
+		assert.Contains(t, stdout, "CI*, Nightly")
+
+		_, _, err = run(map[string]string{"CHATOPS_CONFIG": dotfile, "CHATOPS_TOKEN": "wrong"}, "repos")
+		var apiErr *client.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, 401, apiErr.StatusCode)
+
+		_, _, err = run(withDotfile, "--token", "wrong", "repos", "--token", "admin-token")
+		assert.NoError(t, err, "flags after the command take precedence")
+
+		readable := filepath.Join(t.TempDir(), ".chatops.yaml")
+		require.NoError(t, os.WriteFile(readable, []byte("server: "+server.URL+"\ntoken: admin-token\n"), 0o644))
+		_, stderr, err = run(map[string]string{}, "--config", readable, "repos")
+		require.NoError(t, err)
+		assert.Contains(t, stderr, "readable by other users")
+	})
+
+	t.Run("Output Formats", func(t *testing.T) {
+		stdout, _, err := run(withDotfile, "pipelines", "payments", "-o", "json")
+		require.NoError(t, err)
+		var pipelines []client.Pipeline
+		require.NoError(t, json.Unmarshal([]byte(stdout), &pipelines))
+		assert.Len(t, pipelines, 2)
+
+		stdout, _, err = run(withDotfile, "-o", "yaml", "pipelines", "payments")
+		require.NoError(t, err)
+		var decoded []map[string]interface{}
+		require.NoError(t, yaml.Unmarshal([]byte(stdout), &decoded))
+		assert.Equal(t, "CI", decoded[0]["name"])
+		assert.Equal(t, true, decoded[0]["is_default"])
+
+		_, _, err = run(withDotfile, "repos", "-o", "xml")
+		assert.ErrorContains(t, err, "unknown output format")
+	})
+
+	t.Run("Run Follows The Triggered Run", func(t *testing.T) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			time.Sleep(50 * time.Millisecond)
+			assert.NoError(t, runs.UpdateRunStatus(context.Background(), "1001", domain.RunStatusInProgress))
+			time.Sleep(50 * time.Millisecond)
+			assert.NoError(t, runs.UpdateRunStatus(context.Background(), "1001", domain.RunStatusSuccess))
+		}()
+
+		stdout, _, err := run(withDotfile, "run", "payments", "--follow")
+		<-done
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "Pipeline triggered")
+		assert.Contains(t, stdout, "run 1001 of payments/CI is queued")
+		assert.Contains(t, stdout, "is in_progress")
+		assert.Contains(t, stdout, "is success")
+
+		stdout, _, err = run(withDotfile, "status", "1001", "-o", "yaml")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "status: success")
+	})
+
+	t.Run("Failed Runs And Commands Fail", func(t *testing.T) {
+		stdout, _, err := run(withDotfile, "run", "payments", "Nightly")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "Pipeline triggered")
+
+		require.NoError(t, runs.UpdateRunStatus(context.Background(), "1002", domain.RunStatusFailure))
+		_, _, err = run(withDotfile, "status", "--follow", "1002")
+		assert.ErrorContains(t, err, "finished with status failure")
+
+		_, _, err = run(withDotfile, "run", "payments", "Release")
+		assert.ErrorContains(t, err, "Pipeline Release not found")
+
+		_, _, err = run(withDotfile, "approve", "7", "deploy")
+		assert.ErrorContains(t, err, "Workflows are not configured")
+
+		_, stderr, err := run(withDotfile, "approve", "7")
+		assert.ErrorIs(t, err, cli.ErrUsage)
+		assert.Contains(t, stderr, "Usage: chatops-cli approve")
+	})
+
+	t.Run("Status Lists Runs And Audit Lists Events", func(t *testing.T) {
+		stdout, _, err := run(withDotfile, "status", "--pipeline", "Nightly", "-o", "json")
+		require.NoError(t, err)
+		var listed []client.Run
+		require.NoError(t, json.Unmarshal([]byte(stdout), &listed))
+		require.Len(t, listed, 1)
+		assert.Equal(t, "1002", listed[0].ID)
+
+		stdout, _, err = run(withDotfile, "audit", "--type", domain.EventKindRun, "--since", "1h")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, domain.EventRunTriggered)
+		assert.Contains(t, stdout, services.AdminUser)
+
+		_, _, err = run(withDotfile, "audit", "--since", "yesterday")
+		assert.ErrorContains(t, err, "invalid --since")
+	})
+}
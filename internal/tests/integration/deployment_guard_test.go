@@ -0,0 +1,270 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryLockStorage keeps deployment locks keyed by repository and environment
+type memoryLockStorage struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	locks map[string]*domain.DeploymentLock
+}
+
+func newMemoryLockStorage(now func() time.Time) *memoryLockStorage {
+	return &memoryLockStorage{now: now, locks: make(map[string]*domain.DeploymentLock)}
+}
+
+func (s *memoryLockStorage) AcquireLock(ctx context.Context, lock *domain.DeploymentLock) (*domain.DeploymentLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := lock.Repository + "/" + lock.Environment
+	if existing, ok := s.locks[key]; ok && existing.Owner != lock.Owner && !existing.IsExpired(lock.CreatedAt) {
+		return existing, nil
+	}
+	stored := *lock
+	s.locks[key] = &stored
+	return &stored, nil
+}
+
+func (s *memoryLockStorage) GetLock(ctx context.Context, repository, environment string) (*domain.DeploymentLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.locks[repository+"/"+environment]
+	if !ok || lock.IsExpired(s.now()) {
+		return nil, nil
+	}
+	return lock, nil
+}
+
+func (s *memoryLockStorage) ReleaseLock(ctx context.Context, repository, environment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.locks, repository+"/"+environment)
+	return nil
+}
+
+func (s *memoryLockStorage) ListLocks(ctx context.Context) ([]*domain.DeploymentLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var locks []*domain.DeploymentLock
+	for _, lock := range s.locks {
+		if !lock.IsExpired(s.now()) {
+			locks = append(locks, lock)
+		}
+	}
+	return locks, nil
+}
+
+func TestFreezeWindows(t *testing.T) {
+	// Friday 2025-03-07 12:00 UTC
+	now := time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	guard, err := services.NewDeploymentGuard(services.DeploymentGuardOptions{
+		Clock: clock,
+		Freezes: []domain.FreezeWindow{
+			{Name: "weekend", Environment: "production", Cron: "0 18 * * 5", Duration: 62 * time.Hour},
+			{Name: "migration", Repository: "payments", Start: now.Add(24 * time.Hour), End: now.Add(48 * time.Hour)},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Nil(t, guard.ActiveFreeze("payments", "production"))
+
+	now = time.Date(2025, 3, 8, 13, 0, 0, 0, time.UTC) // Saturday
+	freeze := guard.ActiveFreeze("billing", "production")
+	require.NotNil(t, freeze)
+	assert.Equal(t, "weekend", freeze.Name)
+	assert.Nil(t, guard.ActiveFreeze("billing", "staging"))
+
+	// Date range windows without an environment apply to every trigger of the repository
+	freeze = guard.ActiveFreeze("payments", "")
+	require.NotNil(t, freeze)
+	assert.Equal(t, "migration", freeze.Name)
+
+	now = time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC) // Monday, after both windows
+	assert.Nil(t, guard.ActiveFreeze("payments", "production"))
+
+	_, err = services.NewDeploymentGuard(services.DeploymentGuardOptions{
+		Freezes: []domain.FreezeWindow{{Name: "broken", Cron: "not a cron", Duration: time.Hour}},
+	})
+	assert.Error(t, err)
+}
+
+func TestDeploymentGuard(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name:          "payments",
+			URL:           "https://github.com/Tovli/payments",
+			DefaultBranch: "main",
+			Pipelines: []domain.Pipeline{
+				{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+				{Name: "Deploy", Path: ".github/workflows/deploy.yml"},
+			},
+			Environments: []domain.Environment{{Name: "production", Pipeline: "Deploy"}},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var triggered int
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			triggered++
+			return &domain.CommandResult{Status: "success"}, nil
+		},
+	}
+
+	guard, err := services.NewDeploymentGuard(services.DeploymentGuardOptions{
+		Locks: newMemoryLockStorage(clock),
+		Clock: clock,
+		Freezes: []domain.FreezeWindow{
+			{Name: "code-freeze", Repository: "payments", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour), Reason: "quarter close"},
+		},
+	})
+	require.NoError(t, err)
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("release-manager", []string{domain.PermissionDeploy, domain.PermissionTriggerPipeline}))
+	require.NoError(t, access.AddRole("admin", []string{domain.PermissionAll}))
+	require.NoError(t, access.AssignRole("U111111", "release-manager"))
+	require.NoError(t, access.AssignRole("U222222", "release-manager"))
+	require.NoError(t, access.AssignRole("U999999", "admin"))
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+		Guard:       guard,
+		RBAC:        access,
+	})
+	require.NoError(t, err)
+
+	alice := domain.User{ID: "U111111", Platform: "slack"}
+	bob := domain.User{ID: "U222222", Platform: "slack"}
+	admin := domain.User{ID: "U999999", Platform: "slack"}
+	deploy := func(user domain.User, force bool) *domain.CommandResult {
+		params := map[string]interface{}{"repository_name": "payments", "environment": "production"}
+		if force {
+			params["force"] = true
+		}
+		result, err := processor.ProcessCommand(ctx, &domain.Command{Type: domain.CommandTypeDeploy, Parameters: params, User: user})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Lock Blocks Other Users", func(t *testing.T) {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type: domain.CommandTypeLock,
+			Parameters: map[string]interface{}{
+				"repository_name": "payments",
+				"environment":     "production",
+				"ttl":             30 * time.Minute,
+				"reason":          "hotfix in progress",
+			},
+			User: alice,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "success", result.Status)
+
+		result, err = processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeLock,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production"},
+			User:       bob,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "error", result.Status)
+		assert.Contains(t, result.Message, "locked by U111111")
+
+		result = deploy(bob, false)
+		assert.Equal(t, "error", result.Status)
+		assert.Contains(t, result.Message, "hotfix in progress")
+		assert.Equal(t, 0, triggered)
+
+		assert.Equal(t, "success", deploy(alice, false).Status)
+		assert.Equal(t, 1, triggered)
+	})
+
+	t.Run("Override Requires Permission", func(t *testing.T) {
+		_, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeDeploy,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production", "force": true},
+			User:       bob,
+		})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Contains(t, err.Error(), "not allowed")
+
+		assert.Equal(t, "success", deploy(admin, true).Status)
+		assert.Equal(t, 2, triggered)
+	})
+
+	t.Run("Unlock", func(t *testing.T) {
+		_, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeUnlock,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production"},
+			User:       bob,
+		})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Contains(t, err.Error(), "held by "+alice.ID)
+
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeUnlock,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production"},
+			User:       alice,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "success", result.Status)
+		assert.Equal(t, "success", deploy(bob, false).Status)
+	})
+
+	t.Run("Lock Expires", func(t *testing.T) {
+		_, acquired, err := guard.Lock(ctx, "payments", "production", alice.ID, "", 10*time.Minute)
+		require.NoError(t, err)
+		require.True(t, acquired)
+		assert.Equal(t, "error", deploy(bob, false).Status)
+
+		now = now.Add(15 * time.Minute)
+		assert.Equal(t, "success", deploy(bob, false).Status)
+	})
+
+	t.Run("Freeze Blocks Every Trigger", func(t *testing.T) {
+		now = now.Add(time.Hour)
+
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeVerifyRepo,
+			Parameters: map[string]interface{}{"repository_name": "payments"},
+			User:       alice,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "error", result.Status)
+		assert.Contains(t, result.Message, "quarter close")
+
+		before := triggered
+		assert.Equal(t, "error", deploy(alice, false).Status)
+		assert.Equal(t, "success", deploy(admin, true).Status)
+		assert.Equal(t, before+1, triggered)
+	})
+}
@@ -0,0 +1,341 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryDeploymentStorage keeps deployments in insertion order
+type memoryDeploymentStorage struct {
+	deployments []*domain.Deployment
+}
+
+func (s *memoryDeploymentStorage) AddDeployment(ctx context.Context, deployment *domain.Deployment) error {
+	deployment.ID = fmt.Sprint(len(s.deployments) + 1)
+	stored := *deployment
+	s.deployments = append(s.deployments, &stored)
+	return nil
+}
+
+func (s *memoryDeploymentStorage) UpdateDeploymentStatus(ctx context.Context, id, status string) error {
+	for _, d := range s.deployments {
+		if d.ID == id {
+			d.Status = status
+			return nil
+		}
+	}
+	return fmt.Errorf("deployment %s not found", id)
+}
+
+func (s *memoryDeploymentStorage) SetDeploymentRun(ctx context.Context, id, runID, url string) error {
+	for _, d := range s.deployments {
+		if d.ID == id {
+			d.RunID, d.URL = runID, url
+			return nil
+		}
+	}
+	return fmt.Errorf("deployment %s not found", id)
+}
+
+func (s *memoryDeploymentStorage) ListCurrentDeployments(ctx context.Context, repository string) ([]*domain.Deployment, error) {
+	latest := make(map[string]*domain.Deployment)
+	var result []*domain.Deployment
+	for i := len(s.deployments) - 1; i >= 0; i-- {
+		d := s.deployments[i]
+		if d.Repository != repository || latest[d.Environment] != nil {
+			continue
+		}
+		latest[d.Environment] = d
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (s *memoryDeploymentStorage) ListPendingDeployments(ctx context.Context) ([]*domain.Deployment, error) {
+	var result []*domain.Deployment
+	for _, d := range s.deployments {
+		if !d.IsFinal() {
+			copied := *d
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func TestDeployCommand(t *testing.T) {
+	ctx := context.Background()
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name:          "payments",
+			URL:           "https://github.com/Tovli/payments",
+			DefaultBranch: "main",
+			Pipelines: []domain.Pipeline{
+				{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+				{Name: "Deploy", Path: ".github/workflows/deploy.yml"},
+			},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var triggers []*domain.WorkflowTrigger
+	var statuses []string
+	runStatus := "in_progress"
+	triggerDetails := map[string]interface{}{"run_id": "2001", "url": "https://github.com/Tovli/payments/actions/runs/2001"}
+	var createdRun *domain.Run
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			triggers = append(triggers, trigger)
+			return &domain.CommandResult{Status: "success", Details: triggerDetails}, nil
+		},
+		ResolveRunFn: func(ctx context.Context, url, pendingRun string) (*domain.Run, error) {
+			if pendingRun == "lost" {
+				return nil, domain.Conflict("run of %s cannot be identified", pendingRun)
+			}
+			return createdRun, nil
+		},
+		CreateDeploymentFn: func(ctx context.Context, url string, deployment *domain.Deployment) (string, error) {
+			return "42", nil
+		},
+		UpdateDeploymentFn: func(ctx context.Context, url string, deployment *domain.Deployment) error {
+			assert.Equal(t, "42", deployment.ProviderDeploymentID)
+			statuses = append(statuses, deployment.Status)
+			return nil
+		},
+		GetRunStatusFn: func(ctx context.Context, url string, runID string) (*domain.WorkflowStatus, error) {
+			return &domain.WorkflowStatus{ID: runID, Status: runStatus}, nil
+		},
+	}
+	providers := services.NewProviderRegistry(github)
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline}))
+	require.NoError(t, access.AddRole("release-manager", []string{domain.PermissionDeploy, domain.PermissionManageEnvironments}))
+	require.NoError(t, access.AssignRole("U123456", "release-manager"))
+	require.NoError(t, access.AssignRole("U999999", "developer"))
+
+	deployments := &memoryDeploymentStorage{}
+	runs := &memoryRunStorage{}
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   providers,
+		Runs:        runs,
+		Deployments: deployments,
+		RBAC:        access,
+	})
+	require.NoError(t, err)
+
+	releaseManager := domain.User{ID: "U123456", Platform: "slack"}
+
+	t.Run("Unknown Environment", func(t *testing.T) {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeDeploy,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production"},
+			User:       releaseManager,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "error", result.Status)
+		assert.Contains(t, result.Message, "not configured")
+		assert.Empty(t, triggers)
+	})
+
+	t.Run("Set Environment", func(t *testing.T) {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type: domain.CommandTypeSetEnv,
+			Parameters: map[string]interface{}{
+				"repository_name": "payments",
+				"environment":     "production",
+				"pipeline_name":   "Deploy",
+			},
+			User: releaseManager,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "success", result.Status)
+		require.Len(t, storage.added.Environments, 1)
+		assert.Equal(t, "Deploy", storage.added.Environments[0].Pipeline)
+
+		result, err = processor.ProcessCommand(ctx, &domain.Command{
+			Type: domain.CommandTypeSetEnv,
+			Parameters: map[string]interface{}{
+				"repository_name": "payments",
+				"environment":     "staging",
+				"pipeline_name":   "Missing",
+			},
+			User: releaseManager,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "error", result.Status)
+	})
+
+	t.Run("Forbidden Without Deploy Permission", func(t *testing.T) {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeDeploy,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production"},
+			User:       domain.User{ID: "U999999", Platform: "slack"},
+		})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Nil(t, result)
+		assert.Empty(t, triggers)
+	})
+
+	t.Run("Deploy Ref", func(t *testing.T) {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeDeploy,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production", "ref": "v1.2.3"},
+			User:       releaseManager,
+			Timestamp:  time.Now(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "success", result.Status)
+		assert.Contains(t, result.Message, "payments@v1.2.3 to production")
+
+		require.Len(t, triggers, 1)
+		assert.Equal(t, "v1.2.3", triggers[0].Ref)
+		assert.Equal(t, ".github/workflows/deploy.yml", triggers[0].Workflow)
+
+		require.Len(t, deployments.deployments, 1)
+		deployment := deployments.deployments[0]
+		assert.Equal(t, "42", deployment.ProviderDeploymentID)
+		assert.Equal(t, "2001", deployment.RunID)
+		assert.Equal(t, domain.DeploymentStatusQueued, deployment.Status)
+		assert.Equal(t, []string{domain.DeploymentStatusQueued}, statuses)
+
+		require.Len(t, runs.runs, 1)
+		assert.Equal(t, "deployment", runs.runs[0].Type)
+	})
+
+	t.Run("Tracker Follows Run", func(t *testing.T) {
+		tracker, err := services.NewDeploymentTracker(services.DeploymentTrackerOptions{
+			Logger:      zap.NewNop(),
+			RepoService: repoService,
+			Providers:   providers,
+			Deployments: deployments,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, domain.DeploymentStatusInProgress, deployments.deployments[0].Status)
+
+		// Unchanged statuses are not reported again
+		require.NoError(t, tracker.Sync(ctx))
+		runStatus = "success"
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, domain.DeploymentStatusSuccess, deployments.deployments[0].Status)
+		assert.Equal(t, []string{
+			domain.DeploymentStatusQueued,
+			domain.DeploymentStatusInProgress,
+			domain.DeploymentStatusSuccess,
+		}, statuses)
+	})
+
+	t.Run("List Environments", func(t *testing.T) {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeListEnvs,
+			Parameters: map[string]interface{}{"repository_name": "payments"},
+			User:       releaseManager,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "success", result.Status)
+		assert.Contains(t, result.Message, "production (Deploy): v1.2.3, success by U123456")
+	})
+
+	t.Run("Control Of Deployment Run Requires Deploy Permission", func(t *testing.T) {
+		_, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeCancelRun,
+			Parameters: map[string]interface{}{"run_id": "2001"},
+			User:       domain.User{ID: "U999999", Platform: "slack"},
+		})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Contains(t, err.Error(), "not allowed")
+	})
+
+	deploy := func() *domain.Deployment {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeDeploy,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production"},
+			User:       releaseManager,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "success", result.Status, result.Message)
+		return deployments.deployments[len(deployments.deployments)-1]
+	}
+	now := time.Now()
+	tracker, err := services.NewDeploymentTracker(services.DeploymentTrackerOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   providers,
+		Deployments: deployments,
+		Clock:       func() time.Time { return now },
+	})
+	require.NoError(t, err)
+
+	t.Run("Tracker Resolves Pending Deployment Runs", func(t *testing.T) {
+		triggerDetails = map[string]interface{}{"pending_run": "dispatch-1"}
+		runStatus = "in_progress"
+		deployment := deploy()
+		assert.Equal(t, domain.PendingRunID("dispatch-1"), deployment.RunID)
+		assert.Equal(t, domain.DeploymentStatusQueued, deployment.Status)
+
+		// The deployment waits while the provider has not created the run
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, domain.DeploymentStatusQueued, deployment.Status)
+		assert.Equal(t, domain.PendingRunID("dispatch-1"), deployment.RunID)
+
+		createdRun = &domain.Run{ID: "2002", URL: "https://github.com/Tovli/payments/actions/runs/2002"}
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, "2002", deployment.RunID)
+		assert.Equal(t, "https://github.com/Tovli/payments/actions/runs/2002", deployment.URL)
+		assert.Equal(t, domain.DeploymentStatusInProgress, deployment.Status)
+
+		runStatus = "success"
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, domain.DeploymentStatusSuccess, deployment.Status)
+	})
+
+	t.Run("Untrackable Deployments End With An Error", func(t *testing.T) {
+		createdRun = nil
+		triggerDetails = map[string]interface{}{"pending_run": "lost"}
+		lost := deploy()
+		triggerDetails = map[string]interface{}{"pending_run": "late"}
+		late := deploy()
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, domain.DeploymentStatusError, lost.Status)
+		assert.Equal(t, domain.DeploymentStatusQueued, late.Status)
+
+		now = now.Add(2 * time.Hour)
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, domain.DeploymentStatusError, late.Status)
+
+		// Deployments without any run are never pending
+		statuses = nil
+		triggerDetails = nil
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeDeploy,
+			Parameters: map[string]interface{}{"repository_name": "payments", "environment": "production"},
+			User:       releaseManager,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "success", result.Status)
+		assert.Contains(t, result.Message, "not tracked")
+		assert.Equal(t, domain.DeploymentStatusError, deployments.deployments[len(deployments.deployments)-1].Status)
+		assert.Equal(t, []string{domain.DeploymentStatusError}, statuses)
+
+		pending, err := deployments.ListPendingDeployments(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+}
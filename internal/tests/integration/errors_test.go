@@ -0,0 +1,245 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	githubadapter "github.com/Tovli/chatops/internal/adapters/github"
+	grpcadapter "github.com/Tovli/chatops/internal/adapters/grpc"
+	"github.com/Tovli/chatops/internal/adapters/rest"
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/router"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/memory"
+	"github.com/Tovli/chatops/pkg/chatopsv1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TestDomainErrors checks that storage and GitHub failures keep their kind up to the
+// adapters, which answer with a matching status or chat message
+func TestDomainErrors(t *testing.T) {
+	ctx := context.Background()
+
+	githubAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/Tovli/payments":
+			w.Write([]byte(`{"name": "payments", "default_branch": "main"}`))
+		case "/api/v3/repos/Tovli/payments/actions/workflows":
+			w.Write([]byte(`{"total_count": 1, "workflows": [{"id": 1, "name": "CI", "path": ".github/workflows/ci.yml"}]}`))
+		case "/api/v3/repos/Tovli/private":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+		case "/api/v3/repos/Tovli/flaky":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message": "Server Error"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer githubAPI.Close()
+
+	github, err := githubadapter.NewGitHubAdapter(zap.NewNop(), &config.GitHubConfig{BaseURL: githubAPI.URL, Token: "test-token"})
+	require.NoError(t, err)
+	repoURL := func(name string) string { return githubAPI.URL + "/Tovli/" + name }
+
+	providers := services.NewProviderRegistry(github)
+	storage := memory.NewStorage()
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:    zap.NewNop(),
+		Providers: providers,
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   providers,
+
+		RemoteWithoutRBAC: true,
+	})
+	require.NoError(t, err)
+
+	manage := func(url string) *domain.Command {
+		return &domain.Command{
+			Type:       domain.CommandTypeManageRepo,
+			Parameters: map[string]interface{}{"repository_url": url},
+			User:       domain.User{ID: "U123456"},
+		}
+	}
+	_, err = processor.ProcessCommand(ctx, manage(repoURL("payments")))
+	require.NoError(t, err)
+
+	t.Run("Errors Keep Their Kind", func(t *testing.T) {
+		stale, err := storage.GetRepository(ctx, "payments")
+		require.NoError(t, err)
+		assert.ErrorIs(t, storage.AddRepository(ctx, stale), domain.ErrAlreadyExists)
+		require.NoError(t, repoService.SetDefaultPipeline(ctx, "payments", "CI"))
+		assert.ErrorIs(t, storage.UpdateRepository(ctx, stale), domain.ErrConflict)
+
+		_, err = processor.ProcessCommand(ctx, manage(repoURL("missing")))
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+
+		// Denied access is a failure of the credentials of ChatOps, not of the user
+		_, err = processor.ProcessCommand(ctx, manage(repoURL("private")))
+		assert.ErrorIs(t, err, domain.ErrUpstream)
+		assert.NotErrorIs(t, err, domain.ErrForbidden)
+
+		_, err = processor.ProcessCommand(ctx, manage(repoURL("flaky")))
+		assert.ErrorIs(t, err, domain.ErrUpstream)
+
+		_, err = processor.ProcessCommand(ctx, manage("https://example.com/Tovli/payments"))
+		assert.ErrorIs(t, err, domain.ErrInvalid)
+
+		_, err = processor.GetRun(ctx, "42")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("REST Answers With The Matching Status", func(t *testing.T) {
+		adapter, err := rest.NewRESTAdapter(rest.RESTAdapterOptions{
+			Logger:    zap.NewNop(),
+			Auth:      services.NewAuthenticator(services.AuthenticatorOptions{AdminToken: "admin-token"}),
+			Processor: processor,
+		})
+		require.NoError(t, err)
+		server := httptest.NewServer(router.NewRouter(&router.Config{Logger: zap.NewNop(), RESTAdapter: adapter}))
+		defer server.Close()
+
+		call := func(method, path string, body interface{}) (int, string) {
+			encoded, err := json.Marshal(body)
+			require.NoError(t, err)
+			req, err := http.NewRequest(method, server.URL+"/api/v1"+path, bytes.NewReader(encoded))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer admin-token")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			message, _ := response["message"].(string)
+			return resp.StatusCode, message
+		}
+		command := func(url string) map[string]interface{} {
+			return map[string]interface{}{
+				"type":       domain.CommandTypeManageRepo,
+				"parameters": map[string]interface{}{"repository_url": url},
+			}
+		}
+
+		code, message := call("POST", "/commands", command(repoURL("missing")))
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Contains(t, message, "not found on GitHub")
+
+		code, _ = call("POST", "/commands", command(repoURL("private")))
+		assert.Equal(t, http.StatusBadGateway, code)
+
+		code, _ = call("POST", "/commands", command(repoURL("flaky")))
+		assert.Equal(t, http.StatusBadGateway, code)
+
+		code, message = call("POST", "/commands", map[string]interface{}{"type": "launch_rocket"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, "Unknown command type: launch_rocket", message)
+
+		code, message = call("GET", "/repositories/unknown/pipelines", nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Equal(t, "Repository unknown not found", message)
+	})
+
+	t.Run("gRPC Answers With The Matching Code", func(t *testing.T) {
+		server, err := grpcadapter.NewServer(grpcadapter.ServerOptions{
+			Logger:    zap.NewNop(),
+			Auth:      services.NewAuthenticator(services.AuthenticatorOptions{AdminToken: "admin-token"}),
+			Processor: processor,
+		})
+		require.NoError(t, err)
+
+		lis := bufconn.Listen(1024 * 1024)
+		serveCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		go server.Serve(serveCtx, lis)
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		require.NoError(t, err)
+		defer conn.Close()
+		client := chatopsv1.NewChatOpsServiceClient(conn)
+		callCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer admin-token")
+
+		manage := func(url string) *chatopsv1.ProcessCommandRequest {
+			return &chatopsv1.ProcessCommandRequest{
+				Type:       domain.CommandTypeManageRepo,
+				Parameters: &structpb.Struct{Fields: map[string]*structpb.Value{"repository_url": structpb.NewStringValue(url)}},
+			}
+		}
+
+		_, err = client.ProcessCommand(callCtx, manage(repoURL("private")))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		_, err = client.ProcessCommand(callCtx, manage(repoURL("flaky")))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		_, err = client.ProcessCommand(callCtx, manage("https://example.com/Tovli/payments"))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Slack Answers With A Distinct Message", func(t *testing.T) {
+		slackAPI := newSlackAPIStub(t)
+		adapter, err := slack.NewSlackAdapter(zap.NewNop(), &config.SlackConfig{
+			BotToken:   "xoxb-test",
+			SigningKey: testSigningKey,
+			APIURL:     slackAPI.URL + "/",
+		}, processor)
+		require.NoError(t, err)
+
+		slash := func(text string) (int, string) {
+			form := url.Values{}
+			form.Set("command", "/chatops")
+			form.Set("text", text)
+			form.Set("user_id", "U123456")
+			form.Set("channel_id", "C123456")
+			body := form.Encode()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/commands", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			signSlackRequest(req, body)
+			rec := httptest.NewRecorder()
+			adapter.HandleSlashCommand(rec, req)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			message, _ := response["message"].(string)
+			return rec.Code, message
+		}
+
+		code, message := slash("verify unknown")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "Not found: repository unknown not found", message)
+
+		_, message = slash("manage " + repoURL("private"))
+		assert.True(t, strings.HasPrefix(message, "The CI provider failed, please try again later: "), message)
+
+		_, message = slash("manage " + repoURL("flaky"))
+		assert.True(t, strings.HasPrefix(message, "The CI provider failed, please try again later: "), message)
+		assert.NotContains(t, message, "Server Error")
+	})
+}
@@ -0,0 +1,161 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryOutbox keeps outbox events in memory, with claims expiring on a fake clock
+type memoryOutbox struct {
+	now       *time.Time
+	events    []*domain.Event
+	claimed   map[string]time.Time
+	delivered map[string]bool
+}
+
+func newMemoryOutbox(now *time.Time) *memoryOutbox {
+	return &memoryOutbox{now: now, claimed: make(map[string]time.Time), delivered: make(map[string]bool)}
+}
+
+func (o *memoryOutbox) AppendEvent(ctx context.Context, event *domain.Event, lease time.Duration) error {
+	event.ID = strconv.Itoa(len(o.events) + 1)
+	o.events = append(o.events, event)
+	o.claimed[event.ID] = o.now.Add(lease)
+	return nil
+}
+
+func (o *memoryOutbox) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]*domain.Event, error) {
+	var events []*domain.Event
+	for _, event := range o.events {
+		if len(events) == limit {
+			break
+		}
+		if o.delivered[event.ID] || o.now.Before(o.claimed[event.ID]) {
+			continue
+		}
+		o.claimed[event.ID] = o.now.Add(lease)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (o *memoryOutbox) MarkEventDelivered(ctx context.Context, id string) error {
+	o.delivered[id] = true
+	return nil
+}
+
+// recordingHandler records the events it handles and fails while failing is set
+type recordingHandler struct {
+	events  []*domain.Event
+	failing bool
+}
+
+func (h *recordingHandler) HandleEvent(ctx context.Context, event *domain.Event) error {
+	h.events = append(h.events, event)
+	if h.failing {
+		return fmt.Errorf("handler unavailable")
+	}
+	return nil
+}
+
+func (h *recordingHandler) types() []string {
+	var types []string
+	for _, event := range h.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestEventBus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Processor Publishes Typed Events", func(t *testing.T) {
+		storage := &recordingStorage{}
+		repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+			Logger:    zap.NewNop(),
+			Storage:   storage,
+			Providers: services.NewProviderRegistry(&mocks.MockGitHubAdapter{}),
+		})
+		require.NoError(t, err)
+
+		bus, err := services.NewEventBus(services.EventBusOptions{Logger: zap.NewNop()})
+		require.NoError(t, err)
+		all := &recordingHandler{}
+		repositories := &recordingHandler{}
+		bus.Subscribe("*", all)
+		bus.Subscribe(domain.EventRepositoryAdded, repositories)
+
+		processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+			Logger:      zap.NewNop(),
+			RepoService: repoService,
+			Events:      bus,
+		})
+		require.NoError(t, err)
+
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeManageRepo,
+			Parameters: map[string]interface{}{"repository_url": "https://github.com/Tovli/payments"},
+			User:       domain.User{ID: "U123456", Platform: "slack"},
+			Timestamp:  time.Now(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "success", result.Status)
+
+		assert.Equal(t, []string{domain.EventCommandReceived, domain.EventRepositoryAdded, domain.EventCommandCompleted}, all.types())
+		require.Len(t, repositories.events, 1)
+		added, ok := repositories.events[0].Payload.(*domain.RepositoryAdded)
+		require.True(t, ok)
+		assert.Equal(t, "https://github.com/Tovli/payments", added.Repository.URL)
+
+		completed, ok := all.events[2].Payload.(*domain.CommandCompleted)
+		require.True(t, ok)
+		assert.Equal(t, "success", completed.Status)
+		assert.Equal(t, domain.CommandTypeManageRepo, completed.Command.Type)
+	})
+
+	t.Run("Outbox Redelivers Failed Events", func(t *testing.T) {
+		now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+		outbox := newMemoryOutbox(&now)
+		bus, err := services.NewEventBus(services.EventBusOptions{
+			Logger: zap.NewNop(),
+			Outbox: outbox,
+			Clock:  func() time.Time { return now },
+		})
+		require.NoError(t, err)
+		handler := &recordingHandler{failing: true}
+		bus.Subscribe(domain.EventKindRun, handler)
+
+		bus.Publish(ctx, &domain.Event{Type: domain.EventRunTriggered, Repository: "payments"})
+		bus.Publish(ctx, &domain.Event{Type: domain.EventLockTaken, Repository: "payments"})
+		require.Len(t, outbox.events, 2)
+		assert.Equal(t, now, outbox.events[0].OccurredAt)
+		assert.Len(t, handler.events, 1)
+		assert.False(t, outbox.delivered["1"])
+		// Events no handler is subscribed to are delivered trivially
+		assert.True(t, outbox.delivered["2"])
+
+		// The publisher keeps its claim until the lease expires
+		require.NoError(t, bus.Relay(ctx))
+		assert.Len(t, handler.events, 1)
+
+		now = now.Add(2 * time.Minute)
+		handler.failing = false
+		require.NoError(t, bus.Relay(ctx))
+		assert.Len(t, handler.events, 2)
+		assert.True(t, outbox.delivered["1"])
+
+		now = now.Add(2 * time.Minute)
+		require.NoError(t, bus.Relay(ctx))
+		assert.Len(t, handler.events, 2)
+	})
+}
@@ -0,0 +1,188 @@
+package integration
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	githubadapter "github.com/Tovli/chatops/internal/adapters/github"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestGitHubDispatchCorrelation checks that dispatched workflows are answered at once
+// and that the run tracker later records the run created by that very dispatch
+func TestGitHubDispatchCorrelation(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var dispatched []map[string]interface{}
+	var plainRuns []map[string]interface{}
+	githubAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		file := func(content string) {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"type": "file", "encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+		}
+
+		switch r.URL.Path {
+		case "/api/v3/repos/Tovli/payments/contents/.github/workflows/ci.yml":
+			file("name: CI\nrun-name: CI ${{ inputs.chatops_run_id }}\non:\n  workflow_dispatch:\n    inputs:\n      chatops_run_id:\n        required: false\n")
+		case "/api/v3/repos/Tovli/payments/contents/.github/workflows/plain.yml":
+			file("name: Plain\non: workflow_dispatch\n")
+		case "/api/v3/repos/Tovli/payments/actions/workflows/ci.yml/dispatches",
+			"/api/v3/repos/Tovli/payments/actions/workflows/plain.yml/dispatches":
+			var body struct {
+				Inputs map[string]interface{} `json:"inputs"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			dispatched = append(dispatched, body.Inputs)
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/v3/repos/Tovli/payments/actions/workflows/ci.yml/runs":
+			assert.Equal(t, "workflow_dispatch", r.URL.Query().Get("event"))
+			// A concurrent dispatch created the newest run
+			var runs []map[string]interface{}
+			for i, inputs := range dispatched {
+				runs = append([]map[string]interface{}{{
+					"id": 200 + i, "status": "in_progress", "head_branch": "main",
+					"display_title": fmt.Sprintf("CI %v", inputs["chatops_run_id"]),
+					"html_url":      fmt.Sprintf("https://github.com/Tovli/payments/actions/runs/%d", 200+i),
+				}}, runs...)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"workflow_runs": runs})
+		case "/api/v3/repos/Tovli/payments/actions/workflows/plain.yml/runs":
+			json.NewEncoder(w).Encode(map[string]interface{}{"workflow_runs": plainRuns})
+		case "/api/v3/repos/Tovli/payments/actions/runs/200", "/api/v3/repos/Tovli/payments/actions/runs/201":
+			w.Write([]byte(`{"status": "in_progress"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer githubAPI.Close()
+
+	github, err := githubadapter.NewGitHubAdapter(zap.NewNop(), &config.GitHubConfig{BaseURL: githubAPI.URL, Token: "test-token"})
+	require.NoError(t, err)
+	repoURL := githubAPI.URL + "/Tovli/payments"
+
+	t.Run("Correlated Dispatches Resolve Their Own Run", func(t *testing.T) {
+		storage := &recordingStorage{
+			added: &domain.Repository{
+				Name:      "payments",
+				URL:       repoURL,
+				Pipelines: []domain.Pipeline{{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true}},
+			},
+		}
+		repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{Logger: zap.NewNop(), Storage: storage})
+		require.NoError(t, err)
+		providers := services.NewProviderRegistry(github)
+		runs := &memoryRunStorage{}
+		processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+			Logger:      zap.NewNop(),
+			RepoService: repoService,
+			Providers:   providers,
+			Runs:        runs,
+		})
+		require.NoError(t, err)
+		tracker, err := services.NewRunTracker(services.RunTrackerOptions{
+			Logger:      zap.NewNop(),
+			RepoService: repoService,
+			Providers:   providers,
+			Runs:        runs,
+		})
+		require.NoError(t, err)
+
+		verify := func() {
+			result, err := processor.ProcessCommand(ctx, &domain.Command{
+				Type:       domain.CommandTypeVerifyRepo,
+				Parameters: map[string]interface{}{"repository_name": "payments"},
+				User:       domain.User{ID: "U123456"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, "success", result.Status, result.Message)
+			assert.NotContains(t, result.Details, "run_id")
+		}
+		verify()
+		verify()
+
+		require.Len(t, dispatched, 2)
+		assert.Len(t, dispatched[0]["chatops_run_id"], 16)
+		assert.NotEqual(t, dispatched[0]["chatops_run_id"], dispatched[1]["chatops_run_id"])
+		stored, err := runs.ListRuns(ctx, domain.RunFilter{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, stored, 2)
+		assert.True(t, domain.IsPendingRunID(stored[0].ID))
+
+		// Log and control commands wait for the run to exist
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeCancelRun,
+			Parameters: map[string]interface{}{"repository_name": "payments"},
+			User:       domain.User{ID: "U123456"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result.Message, "has not started yet")
+
+		require.NoError(t, tracker.Sync(ctx))
+		stored, err = runs.ListRuns(ctx, domain.RunFilter{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"201", "200"}, runIDs(stored))
+		assert.Equal(t, "https://github.com/Tovli/payments/actions/runs/200", stored[1].URL)
+		assert.Equal(t, domain.RunStatusInProgress, stored[1].Status)
+	})
+
+	t.Run("Uncorrelated Dispatches Match Only An Unambiguous Run", func(t *testing.T) {
+		result, err := github.TriggerWorkflow(ctx, &domain.WorkflowTrigger{Repository: repoURL, Workflow: ".github/workflows/plain.yml"})
+		require.NoError(t, err)
+		details := result.Details.(map[string]interface{})
+		pending, err := url.ParseQuery(details["pending_run"].(string))
+		require.NoError(t, err)
+		assert.Empty(t, pending.Get("id"))
+
+		// Runs are not matched while concurrent dispatches may still create theirs
+		run, err := github.ResolveRun(ctx, repoURL, details["pending_run"].(string))
+		require.NoError(t, err)
+		assert.Nil(t, run)
+
+		dispatchedAt := time.Now().Add(-5 * time.Minute)
+		reference := url.Values{
+			"workflow": {"plain.yml"},
+			"ref":      {"main"},
+			"since":    {strconv.FormatInt(dispatchedAt.Unix(), 10)},
+		}.Encode()
+		plainRun := func(id int, branch string, created time.Time) map[string]interface{} {
+			return map[string]interface{}{"id": id, "status": "queued", "head_branch": branch, "created_at": created.UTC().Format(time.RFC3339)}
+		}
+		mu.Lock()
+		plainRuns = []map[string]interface{}{
+			plainRun(301, "main", dispatchedAt.Add(20*time.Second)),
+			plainRun(302, "release", dispatchedAt.Add(20*time.Second)),
+			plainRun(303, "main", dispatchedAt.Add(4*time.Minute)),
+		}
+		mu.Unlock()
+		run, err = github.ResolveRun(ctx, repoURL, reference)
+		require.NoError(t, err)
+		require.NotNil(t, run)
+		assert.Equal(t, "301", run.ID)
+
+		mu.Lock()
+		plainRuns = append(plainRuns, plainRun(304, "main", dispatchedAt.Add(30*time.Second)))
+		mu.Unlock()
+		_, err = github.ResolveRun(ctx, repoURL, reference)
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+}
@@ -0,0 +1,221 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tovli/chatops/internal/adapters/gitlab"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newGitLabStub starts an httptest stand-in for the GitLab v4 API
+func newGitLabStub(t *testing.T) (*httptest.Server, *gitLabPipelineRequest) {
+	var created gitLabPipelineRequest
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test_gitlab_token", r.Header.Get("PRIVATE-TOKEN"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/platform%2Fpayments":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":                  42,
+				"name":                "payments",
+				"path_with_namespace": "platform/payments",
+				"web_url":             "http://" + r.Host + "/platform/payments",
+				"default_branch":      "develop",
+			})
+		case "/api/v4/projects/42/repository/files/.gitlab-ci.yml/raw":
+			assert.Equal(t, "develop", r.URL.Query().Get("ref"))
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("workflow:\n  name: payments-ci\nstages:\n  - test\nunit:\n  stage: test\n  script: go test ./...\n"))
+		case "/api/v4/projects/42/pipeline":
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":      1001,
+				"status":  "created",
+				"ref":     created.Ref,
+				"web_url": "http://" + r.Host + "/platform/payments/-/pipelines/1001",
+			})
+		case "/api/v4/projects/platform%2Fpayments/pipelines/1001":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":         1001,
+				"status":     "failed",
+				"updated_at": "2024-05-01T10:00:00Z",
+			})
+		case "/api/v4/projects/platform%2Fpayments/pipelines/1001/cancel":
+			assert.Equal(t, http.MethodPost, r.Method)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 1001, "status": "canceled"})
+		case "/api/v4/projects/platform%2Fpayments/pipelines/1001/jobs":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": 7, "name": "unit", "stage": "test", "status": "failed"},
+			})
+		case "/api/v4/projects/platform%2Fpayments/jobs/7/trace":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("$ go test ./...\n--- FAIL: TestCharge\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	return httptest.NewServer(handler), &created
+}
+
+// gitLabPipelineRequest is the body of a pipeline creation
+type gitLabPipelineRequest struct {
+	Ref       string `json:"ref"`
+	Variables []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"variables"`
+}
+
+func TestGitLabAdapter(t *testing.T) {
+	stub, created := newGitLabStub(t)
+	defer stub.Close()
+
+	adapter, err := gitlab.NewGitLabAdapter(zap.NewNop(), &config.GitLabConfig{
+		BaseURL: stub.URL,
+		Token:   "test_gitlab_token",
+	})
+	require.NoError(t, err)
+
+	repoURL := stub.URL + "/platform/payments"
+	ctx := context.Background()
+
+	t.Run("Host", func(t *testing.T) {
+		assert.Equal(t, "gitlab", adapter.Name())
+		assert.Equal(t, services.RepositoryHost(repoURL), adapter.Host())
+	})
+
+	t.Run("GetRepositoryDetails", func(t *testing.T) {
+		repo, err := adapter.GetRepositoryDetails(ctx, repoURL)
+		require.NoError(t, err)
+		assert.Equal(t, "payments", repo.Name)
+		assert.Equal(t, "develop", repo.DefaultBranch)
+		require.Len(t, repo.Pipelines, 1)
+		assert.Equal(t, "payments-ci", repo.Pipelines[0].Name)
+		assert.Equal(t, ".gitlab-ci.yml", repo.Pipelines[0].Path)
+	})
+
+	t.Run("TriggerWorkflow", func(t *testing.T) {
+		result, err := adapter.TriggerWorkflow(ctx, &domain.WorkflowTrigger{
+			Repository: repoURL,
+			Workflow:   ".gitlab-ci.yml",
+			Parameters: map[string]interface{}{
+				"ref":         "release",
+				"ENVIRONMENT": "staging",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "success", result.Status)
+
+		// The pipeline is created with the API token checked by the stub
+		assert.Equal(t, "release", created.Ref)
+		require.Len(t, created.Variables, 1)
+		assert.Equal(t, "ENVIRONMENT", created.Variables[0].Key)
+		assert.Equal(t, "staging", created.Variables[0].Value)
+
+		details, ok := result.Details.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, 1001, details["pipeline_id"])
+	})
+
+	t.Run("GetRunStatus", func(t *testing.T) {
+		status, err := adapter.GetRunStatus(ctx, repoURL, "1001")
+		require.NoError(t, err)
+		assert.Equal(t, "failed", status.Status)
+		assert.Equal(t, 100, status.Progress)
+		assert.NotEmpty(t, status.Error)
+	})
+
+	t.Run("CancelRun", func(t *testing.T) {
+		require.NoError(t, adapter.CancelRun(ctx, repoURL, "1001"))
+		assert.Error(t, adapter.CancelRun(ctx, repoURL, "9999"))
+	})
+
+	t.Run("GetRunLogs", func(t *testing.T) {
+		logs, err := adapter.GetRunLogs(ctx, repoURL, "1001")
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, "unit", logs[0].Name)
+		assert.Equal(t, "test", logs[0].FailedStep)
+		assert.Contains(t, logs[0].Content, "FAIL")
+	})
+
+	t.Run("Unknown Project", func(t *testing.T) {
+		_, err := adapter.GetRepositoryDetails(ctx, stub.URL+"/platform/unknown")
+		assert.Error(t, err)
+	})
+}
+
+func TestRepositoryServiceSelectsGitLab(t *testing.T) {
+	stub, _ := newGitLabStub(t)
+	defer stub.Close()
+
+	adapter, err := gitlab.NewGitLabAdapter(zap.NewNop(), &config.GitLabConfig{
+		BaseURL: stub.URL,
+		Token:   "test_gitlab_token",
+	})
+	require.NoError(t, err)
+
+	storage := &recordingStorage{}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:    zap.NewNop(),
+		Providers: services.NewProviderRegistry(&mocks.MockGitHubAdapter{}, adapter),
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+
+	err = repoService.AddRepository(context.Background(), &domain.Repository{
+		URL:     stub.URL + "/platform/payments",
+		AddedBy: "U123456",
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, storage.added)
+	assert.Equal(t, "payments", storage.added.Name)
+	require.Len(t, storage.added.Pipelines, 1)
+	assert.Equal(t, ".gitlab-ci.yml", storage.added.Pipelines[0].Path)
+}
+
+// recordingStorage captures the repository passed to AddRepository
+type recordingStorage struct {
+	added *domain.Repository
+}
+
+func (s *recordingStorage) AddRepository(ctx context.Context, repo *domain.Repository) error {
+	s.added = repo
+	return nil
+}
+
+func (s *recordingStorage) GetRepository(ctx context.Context, name string) (*domain.Repository, error) {
+	if s.added == nil {
+		return nil, domain.NotFound("repository %s not found", name)
+	}
+	return s.added, nil
+}
+
+func (s *recordingStorage) ListRepositories(ctx context.Context) ([]*domain.Repository, error) {
+	return []*domain.Repository{s.added}, nil
+}
+
+func (s *recordingStorage) UpdateRepository(ctx context.Context, repo *domain.Repository) error {
+	s.added = repo
+	return nil
+}
+
+func (s *recordingStorage) UpsertRepository(ctx context.Context, repo *domain.Repository) error {
+	s.added = repo
+	return nil
+}
@@ -0,0 +1,192 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	grpcadapter "github.com/Tovli/chatops/internal/adapters/grpc"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/Tovli/chatops/pkg/chatopsv1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestGRPCService(t *testing.T) {
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger: zap.NewNop(),
+		Storage: &recordingStorage{
+			added: &domain.Repository{
+				Name:      "payments",
+				URL:       "https://github.com/Tovli/payments",
+				Pipelines: []domain.Pipeline{{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline}))
+	require.NoError(t, access.AssignRole("U123456", "developer"))
+
+	runs := &memoryRunStorage{}
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers: services.NewProviderRegistry(&mocks.MockGitHubAdapter{
+			TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+				return &domain.CommandResult{Status: "success", Message: "Pipeline triggered", Details: map[string]interface{}{"run_id": "1001"}}, nil
+			},
+		}),
+		Runs: runs,
+		RBAC: access,
+	})
+	require.NoError(t, err)
+
+	tokens, err := services.NewTokenService(services.TokenServiceOptions{Logger: zap.NewNop(), Storage: &memoryTokenStorage{}})
+	require.NoError(t, err)
+	developer, err := tokens.CreateToken(context.Background(), &domain.APIToken{
+		Name:   "deploy bot",
+		UserID: "U123456",
+		Scopes: []string{domain.ScopeRunCommands, domain.ScopeReadRuns},
+	})
+	require.NoError(t, err)
+
+	server, err := grpcadapter.NewServer(grpcadapter.ServerOptions{
+		Logger:       zap.NewNop(),
+		Auth:         services.NewAuthenticator(services.AuthenticatorOptions{AdminToken: "admin-token", Tokens: tokens}),
+		Processor:    processor,
+		PollInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	serveCtx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(serveCtx, lis) }()
+	defer stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := chatopsv1.NewChatOpsServiceClient(conn)
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+	verify := &chatopsv1.ProcessCommandRequest{
+		Type:       domain.CommandTypeVerifyRepo,
+		Parameters: &structpb.Struct{Fields: map[string]*structpb.Value{"repository_name": structpb.NewStringValue("payments")}},
+	}
+
+	t.Run("Calls Need A Token With The Scope", func(t *testing.T) {
+		_, err := client.ListRepositories(context.Background(), &chatopsv1.ListRepositoriesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = client.ListRepositories(withToken(developer), &chatopsv1.ListRepositoriesRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		repos, err := client.ListRepositories(withToken("admin-token"), &chatopsv1.ListRepositoriesRequest{})
+		require.NoError(t, err)
+		require.Len(t, repos.Repositories, 1)
+		assert.Equal(t, "payments", repos.Repositories[0].Name)
+		assert.True(t, repos.Repositories[0].Pipelines[0].IsDefault)
+	})
+
+	t.Run("Commands Run As The Token User", func(t *testing.T) {
+		_, err := client.ProcessCommand(withToken("admin-token"), verify)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), "not allowed")
+
+		result, err := client.ProcessCommand(withToken(developer), verify)
+		require.NoError(t, err)
+		assert.Equal(t, "success", result.Status)
+		assert.Equal(t, "1001", result.Details.GetStructValue().Fields["run_id"].GetStringValue())
+		require.Len(t, runs.runs, 1)
+		assert.Equal(t, "U123456", runs.runs[0].TriggeredBy)
+
+		_, err = client.ProcessCommand(withToken(developer), &chatopsv1.ProcessCommandRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Watching A Run Streams Its Status Until It Finished", func(t *testing.T) {
+		stream, err := client.WatchRuns(withToken(developer), &chatopsv1.WatchRunsRequest{RunId: "1001"})
+		require.NoError(t, err)
+
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, domain.RunStatusQueued, update.Run.Status)
+		assert.Empty(t, update.PreviousStatus)
+
+		require.NoError(t, runs.UpdateRunStatus(context.Background(), "1001", domain.RunStatusInProgress))
+		update, err = stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, domain.RunStatusInProgress, update.Run.Status)
+		assert.Equal(t, domain.RunStatusQueued, update.PreviousStatus)
+
+		require.NoError(t, runs.UpdateRunStatus(context.Background(), "1001", domain.RunStatusSuccess))
+		update, err = stream.Recv()
+		require.NoError(t, err)
+		assert.True(t, update.Run.Finished)
+
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+
+		stream, err = client.WatchRuns(withToken(developer), &chatopsv1.WatchRunsRequest{RunId: "42"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Watching All Runs Streams New Runs", func(t *testing.T) {
+		stream, err := client.WatchRuns(withToken(developer), &chatopsv1.WatchRunsRequest{Repository: "payments"})
+		require.NoError(t, err)
+
+		// Let the stream take its first snapshot before the run is recorded
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, runs.AddRun(context.Background(), &domain.Run{
+			ID:         "1002",
+			Repository: "payments",
+			Pipeline:   "CI",
+			Status:     domain.RunStatusQueued,
+		}))
+
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "1002", update.Run.Id)
+		assert.Empty(t, update.PreviousStatus)
+	})
+
+	t.Run("Shutdown Ends Streams", func(t *testing.T) {
+		stream, err := client.WatchRuns(withToken(developer), &chatopsv1.WatchRunsRequest{})
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond)
+
+		stop()
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+
+		select {
+		case err := <-served:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("server did not stop")
+		}
+	})
+}
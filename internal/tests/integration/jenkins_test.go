@@ -0,0 +1,216 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/jenkins"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const jenkinsCrumb = "test-crumb"
+
+// jenkinsStub is an httptest stand-in for a Jenkins controller with CSRF protection enabled
+type jenkinsStub struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	buildParams url.Values
+	queuePolls  int
+	stopped     bool
+}
+
+func newJenkinsStub(t *testing.T) *jenkinsStub {
+	stub := &jenkinsStub{}
+
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	requireCrumb := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Jenkins-Crumb") != jenkinsCrumb {
+			http.Error(w, "No valid crumb was included in the request", http.StatusForbidden)
+			return false
+		}
+		return true
+	}
+
+	mux.HandleFunc("/crumbIssuer/api/json", func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "chatops", user)
+		assert.Equal(t, "test_api_token", token)
+		writeJSON(w, map[string]string{
+			"crumb":             jenkinsCrumb,
+			"crumbRequestField": "Jenkins-Crumb",
+		})
+	})
+	mux.HandleFunc("/job/payments/api/json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"name": "payments",
+			"url":  stub.URL + "/job/payments/",
+			"jobs": []map[string]string{
+				{"name": "build", "url": stub.URL + "/job/payments/job/build/"},
+				{"name": "deploy", "url": stub.URL + "/job/payments/job/deploy/"},
+			},
+		})
+	})
+	mux.HandleFunc("/job/payments/job/build/buildWithParameters", func(w http.ResponseWriter, r *http.Request) {
+		if !requireCrumb(w, r) {
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		stub.mu.Lock()
+		stub.buildParams = r.PostForm
+		stub.mu.Unlock()
+		w.Header().Set("Location", stub.URL+"/queue/item/17/")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/queue/item/17/api/json", func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		stub.queuePolls++
+		polls := stub.queuePolls
+		stub.mu.Unlock()
+
+		// The first poll sees the item still waiting for an executor
+		if polls == 1 {
+			writeJSON(w, map[string]interface{}{"id": 17, "why": "Waiting for next available executor"})
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"id": 17,
+			"executable": map[string]interface{}{
+				"number": 42,
+				"url":    stub.URL + "/job/payments/job/build/42/",
+			},
+		})
+	})
+	mux.HandleFunc("/job/payments/job/build/42/api/json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"number":    42,
+			"result":    "FAILURE",
+			"building":  false,
+			"timestamp": time.Now().Add(-time.Minute).UnixMilli(),
+			"duration":  30000,
+		})
+	})
+	mux.HandleFunc("/job/payments/job/build/42/consoleText", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("[Pipeline] { (Checkout)\n[Pipeline] }\n[Pipeline] { (Unit Tests)\n--- FAIL: TestCharge\n[Pipeline] }\nFinished: FAILURE\n"))
+	})
+	mux.HandleFunc("/job/payments/job/build/42/stop", func(w http.ResponseWriter, r *http.Request) {
+		if !requireCrumb(w, r) {
+			return
+		}
+		stub.mu.Lock()
+		stub.stopped = true
+		stub.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	stub.Server = httptest.NewServer(mux)
+	return stub
+}
+
+func TestJenkinsAdapter(t *testing.T) {
+	stub := newJenkinsStub(t)
+	defer stub.Close()
+
+	adapter, err := jenkins.NewJenkinsAdapter(zap.NewNop(), &config.JenkinsConfig{
+		BaseURL:  stub.URL,
+		User:     "chatops",
+		APIToken: "test_api_token",
+	})
+	require.NoError(t, err)
+
+	repoURL := stub.URL + "/job/payments"
+	ctx := context.Background()
+
+	t.Run("GetRepositoryDetails", func(t *testing.T) {
+		repo, err := adapter.GetRepositoryDetails(ctx, repoURL)
+		require.NoError(t, err)
+		assert.Equal(t, "payments", repo.Name)
+		assert.Equal(t, []domain.Pipeline{
+			{Name: "build", Path: "job/payments/job/build"},
+			{Name: "deploy", Path: "job/payments/job/deploy"},
+		}, repo.Pipelines)
+
+		// Job URLs keep the scheme of the controller, and other hosts get no credentials
+		repo, err = adapter.GetRepositoryDetails(ctx, "https://"+strings.TrimPrefix(repoURL, "http://"))
+		require.NoError(t, err)
+		assert.Equal(t, "payments", repo.Name)
+		_, err = adapter.GetRepositoryDetails(ctx, "https://jenkins.example.com/job/payments")
+		assert.ErrorIs(t, err, domain.ErrInvalid)
+	})
+
+	t.Run("TriggerWorkflow", func(t *testing.T) {
+		result, err := adapter.TriggerWorkflow(ctx, &domain.WorkflowTrigger{
+			Repository: repoURL,
+			Workflow:   "job/payments/job/build",
+			Parameters: map[string]interface{}{"BRANCH": "release"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "success", result.Status)
+
+		// The trigger returns without waiting for an executor
+		details, ok := result.Details.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "queue/item/17", details["pending_run"])
+		assert.NotContains(t, details, "run_id")
+
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+		assert.Equal(t, "release", stub.buildParams.Get("BRANCH"))
+		assert.Zero(t, stub.queuePolls)
+	})
+
+	t.Run("ResolveRun", func(t *testing.T) {
+		run, err := adapter.ResolveRun(ctx, repoURL, "queue/item/17")
+		require.NoError(t, err)
+		assert.Nil(t, run)
+
+		run, err = adapter.ResolveRun(ctx, repoURL, "queue/item/17")
+		require.NoError(t, err)
+		require.NotNil(t, run)
+		assert.Equal(t, "job/payments/job/build/42", run.ID)
+		assert.Equal(t, stub.URL+"/job/payments/job/build/42/", run.URL)
+
+		_, err = adapter.ResolveRun(ctx, repoURL, "queue/item/18")
+		assert.ErrorIs(t, err, domain.ErrConflict)
+	})
+
+	t.Run("GetRunStatus", func(t *testing.T) {
+		status, err := adapter.GetRunStatus(ctx, repoURL, "job/payments/job/build/42")
+		require.NoError(t, err)
+		assert.Equal(t, "failure", status.Status)
+		assert.Equal(t, 100, status.Progress)
+		assert.NotEmpty(t, status.Error)
+	})
+
+	t.Run("GetRunLogs", func(t *testing.T) {
+		logs, err := adapter.GetRunLogs(ctx, repoURL, "job/payments/job/build/42")
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, "Unit Tests", logs[0].FailedStep)
+		assert.Contains(t, logs[0].Content, "--- FAIL: TestCharge")
+	})
+
+	t.Run("CancelRun", func(t *testing.T) {
+		require.NoError(t, adapter.CancelRun(ctx, repoURL, "job/payments/job/build/42"))
+
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+		assert.True(t, stub.stopped)
+	})
+}
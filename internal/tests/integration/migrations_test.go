@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/Tovli/chatops/migrations"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	// Every migration can be reverted
+	for _, file := range files {
+		if up, ok := strings.CutSuffix(file, ".up.sql"); ok {
+			_, err := fs.Stat(migrations.FS, up+".down.sql")
+			assert.NoError(t, err, "%s has no down migration", file)
+		}
+	}
+
+	// Versions are read as golang-migrate reads them, one up and down per version
+	src, err := iofs.New(migrations.FS, ".")
+	require.NoError(t, err)
+	defer src.Close()
+
+	var versions []uint
+	version, err := src.First()
+	for err == nil {
+		versions = append(versions, version)
+		version, err = src.Next(version)
+	}
+	assert.Equal(t, len(files)/2, len(versions))
+	assert.Equal(t, uint(1), versions[0])
+	for i := 1; i < len(versions); i++ {
+		assert.Equal(t, versions[i-1]+1, versions[i], "migration versions must not skip numbers")
+	}
+}
@@ -6,10 +6,29 @@ import (
 	"github.com/Tovli/chatops/internal/core/domain"
 )
 
-// MockGitHubAdapter is a mock implementation of the GitHubPort interface for testing
+// MockGitHubAdapter is a mock implementation of the CIProviderPort, DeploymentPort, PipelineInputPort,
+// RefPort and RunResolver interfaces for testing
 type MockGitHubAdapter struct {
 	GetRepositoryDetailsFn func(ctx context.Context, url string) (*domain.Repository, error)
+	ListPipelinesFn        func(ctx context.Context, url string) ([]domain.Pipeline, error)
 	TriggerWorkflowFn      func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error)
+	GetRunStatusFn         func(ctx context.Context, url string, runID string) (*domain.WorkflowStatus, error)
+	CancelRunFn            func(ctx context.Context, url string, runID string) error
+	RetryRunFn             func(ctx context.Context, url string, runID string, failedOnly bool) (*domain.CommandResult, error)
+	GetRunLogsFn           func(ctx context.Context, url string, runID string) ([]domain.JobLog, error)
+	CreateDeploymentFn     func(ctx context.Context, url string, deployment *domain.Deployment) (string, error)
+	UpdateDeploymentFn     func(ctx context.Context, url string, deployment *domain.Deployment) error
+	GetPipelineInputsFn    func(ctx context.Context, url, pipelinePath, ref string) ([]domain.PipelineInput, error)
+	ListRefsFn             func(ctx context.Context, url, query string, limit int) ([]string, error)
+	ResolveRunFn           func(ctx context.Context, url, pendingRun string) (*domain.Run, error)
+}
+
+func (m *MockGitHubAdapter) Name() string {
+	return "github"
+}
+
+func (m *MockGitHubAdapter) Host() string {
+	return "github.com"
 }
 
 func (m *MockGitHubAdapter) GetRepositoryDetails(ctx context.Context, url string) (*domain.Repository, error) {
@@ -25,6 +44,13 @@ func (m *MockGitHubAdapter) GetRepositoryDetails(ctx context.Context, url string
 	}, nil
 }
 
+func (m *MockGitHubAdapter) ListPipelines(ctx context.Context, url string) ([]domain.Pipeline, error) {
+	if m.ListPipelinesFn != nil {
+		return m.ListPipelinesFn(ctx, url)
+	}
+	return []domain.Pipeline{}, nil
+}
+
 func (m *MockGitHubAdapter) TriggerWorkflow(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
 	if m.TriggerWorkflowFn != nil {
 		return m.TriggerWorkflowFn(ctx, trigger)
@@ -34,3 +60,74 @@ func (m *MockGitHubAdapter) TriggerWorkflow(ctx context.Context, trigger *domain
 		Message: "Workflow triggered successfully",
 	}, nil
 }
+
+func (m *MockGitHubAdapter) GetRunStatus(ctx context.Context, url string, runID string) (*domain.WorkflowStatus, error) {
+	if m.GetRunStatusFn != nil {
+		return m.GetRunStatusFn(ctx, url, runID)
+	}
+	return &domain.WorkflowStatus{
+		ID:       runID,
+		Status:   "success",
+		Progress: 100,
+	}, nil
+}
+
+func (m *MockGitHubAdapter) CancelRun(ctx context.Context, url string, runID string) error {
+	if m.CancelRunFn != nil {
+		return m.CancelRunFn(ctx, url, runID)
+	}
+	return nil
+}
+
+func (m *MockGitHubAdapter) RetryRun(ctx context.Context, url string, runID string, failedOnly bool) (*domain.CommandResult, error) {
+	if m.RetryRunFn != nil {
+		return m.RetryRunFn(ctx, url, runID, failedOnly)
+	}
+	return &domain.CommandResult{
+		Status:  "success",
+		Message: "Workflow run re-run requested",
+		Details: map[string]interface{}{"run_id": runID},
+	}, nil
+}
+
+func (m *MockGitHubAdapter) GetRunLogs(ctx context.Context, url string, runID string) ([]domain.JobLog, error) {
+	if m.GetRunLogsFn != nil {
+		return m.GetRunLogsFn(ctx, url, runID)
+	}
+	return []domain.JobLog{}, nil
+}
+
+func (m *MockGitHubAdapter) CreateDeployment(ctx context.Context, url string, deployment *domain.Deployment) (string, error) {
+	if m.CreateDeploymentFn != nil {
+		return m.CreateDeploymentFn(ctx, url, deployment)
+	}
+	return "1", nil
+}
+
+func (m *MockGitHubAdapter) UpdateDeploymentStatus(ctx context.Context, url string, deployment *domain.Deployment) error {
+	if m.UpdateDeploymentFn != nil {
+		return m.UpdateDeploymentFn(ctx, url, deployment)
+	}
+	return nil
+}
+
+func (m *MockGitHubAdapter) GetPipelineInputs(ctx context.Context, url, pipelinePath, ref string) ([]domain.PipelineInput, error) {
+	if m.GetPipelineInputsFn != nil {
+		return m.GetPipelineInputsFn(ctx, url, pipelinePath, ref)
+	}
+	return nil, nil
+}
+
+func (m *MockGitHubAdapter) ListRefs(ctx context.Context, url, query string, limit int) ([]string, error) {
+	if m.ListRefsFn != nil {
+		return m.ListRefsFn(ctx, url, query, limit)
+	}
+	return []string{}, nil
+}
+
+func (m *MockGitHubAdapter) ResolveRun(ctx context.Context, url, pendingRun string) (*domain.Run, error) {
+	if m.ResolveRunFn != nil {
+		return m.ResolveRunFn(ctx, url, pendingRun)
+	}
+	return nil, domain.Conflict("run of %s cannot be identified", pendingRun)
+}
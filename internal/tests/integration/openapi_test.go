@@ -0,0 +1,268 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Tovli/chatops/internal/adapters/rest"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/router"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/Tovli/chatops/pkg/client"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type openAPIDocument struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+// newTestRESTAdapter creates an adapter over a single payments repository whose pipelines
+// trigger run 1001
+func newTestRESTAdapter(t *testing.T) *rest.RESTAdapter {
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger: zap.NewNop(),
+		Storage: &recordingStorage{
+			added: &domain.Repository{
+				Name:      "payments",
+				URL:       "https://github.com/Tovli/payments",
+				Pipelines: []domain.Pipeline{{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers: services.NewProviderRegistry(&mocks.MockGitHubAdapter{
+			TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+				return &domain.CommandResult{Status: "success", Message: "Pipeline triggered", Details: map[string]interface{}{"run_id": "1001"}}, nil
+			},
+		}),
+		Runs: &memoryRunStorage{},
+
+		RemoteWithoutRBAC: true,
+	})
+	require.NoError(t, err)
+
+	tokens, err := services.NewTokenService(services.TokenServiceOptions{Logger: zap.NewNop(), Storage: &memoryTokenStorage{}})
+	require.NoError(t, err)
+
+	adapter, err := rest.NewRESTAdapter(rest.RESTAdapterOptions{
+		Logger:    zap.NewNop(),
+		Auth:      services.NewAuthenticator(services.AuthenticatorOptions{AdminToken: "admin-token", Tokens: tokens}),
+		Processor: processor,
+		Tokens:    tokens,
+	})
+	require.NoError(t, err)
+	return adapter
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	var doc openAPIDocument
+	require.NoError(t, json.Unmarshal(rest.OpenAPISpec(), &doc))
+	assert.True(t, strings.HasPrefix(doc.OpenAPI, "3."))
+
+	t.Run("Spec And Router Stay In Sync", func(t *testing.T) {
+		r := router.NewRouter(&router.Config{Logger: zap.NewNop(), RESTAdapter: newTestRESTAdapter(t)})
+
+		var routes []string
+		err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+			path, err := route.GetPathTemplate()
+			if err != nil {
+				return nil
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return nil // Subrouters carry no methods
+			}
+			for _, method := range methods {
+				routes = append(routes, method+" "+path)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		var documented []string
+		for path, operations := range doc.Paths {
+			for method := range operations {
+				if method == "parameters" {
+					continue
+				}
+				documented = append(documented, strings.ToUpper(method)+" "+path)
+			}
+		}
+
+		sort.Strings(routes)
+		sort.Strings(documented)
+		assert.Equal(t, routes, documented)
+	})
+
+	t.Run("References Resolve", func(t *testing.T) {
+		var components map[string]map[string]json.RawMessage
+		var raw map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(rest.OpenAPISpec(), &raw))
+		require.NoError(t, json.Unmarshal(raw["components"], &components))
+
+		for _, ref := range collectRefs(raw["paths"], raw["components"]) {
+			parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+			require.Len(t, parts, 2, ref)
+			assert.Contains(t, components[parts[0]], parts[1], "unresolved %s", ref)
+		}
+	})
+
+	t.Run("Client Types Match The Schemas", func(t *testing.T) {
+		types := map[string]interface{}{
+			"Command":         client.Command{},
+			"CommandResult":   client.CommandResult{},
+			"Repository":      client.Repository{},
+			"Pipeline":        client.Pipeline{},
+			"Environment":     client.Environment{},
+			"Run":             client.Run{},
+			"AuditEvent":      client.AuditEvent{},
+			"Webhook":         client.Webhook{},
+			"WebhookRequest":  client.WebhookRequest{},
+			"WebhookDelivery": client.WebhookDelivery{},
+			"Token":           client.Token{},
+			"TokenRequest":    client.TokenRequest{},
+			"Health":          client.Health{},
+		}
+		for name, value := range types {
+			schema, ok := doc.Components.Schemas[name]
+			require.True(t, ok, name)
+
+			var properties []string
+			for property := range schema.Properties {
+				properties = append(properties, property)
+			}
+			sort.Strings(properties)
+			assert.Equal(t, properties, jsonFields(reflect.TypeOf(value)), name)
+		}
+	})
+
+	t.Run("Spec Is Served", func(t *testing.T) {
+		server := httptest.NewServer(router.NewRouter(&router.Config{Logger: zap.NewNop()}))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/api/v1/openapi.json")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, rest.OpenAPISpec(), body)
+	})
+}
+
+func TestAPIClient(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(router.NewRouter(&router.Config{Logger: zap.NewNop(), RESTAdapter: newTestRESTAdapter(t)}))
+	defer server.Close()
+
+	admin, err := client.New(client.Options{BaseURL: server.URL, Token: "admin-token"})
+	require.NoError(t, err)
+
+	token, err := admin.CreateToken(ctx, &client.TokenRequest{
+		Name:   "deploy bot",
+		UserID: "U123456",
+		Scopes: []string{client.ScopeRunCommands, client.ScopeReadRepositories},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, token.Token)
+
+	bot, err := client.New(client.Options{BaseURL: server.URL + "/", Token: token.Token})
+	require.NoError(t, err)
+
+	repos, err := bot.ListRepositories(ctx)
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "payments", repos[0].Name)
+
+	pipelines, err := bot.ListPipelines(ctx, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, []client.Pipeline{{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true}}, pipelines)
+
+	result, err := bot.RunCommand(ctx, &client.Command{
+		Type:       domain.CommandTypeVerifyRepo,
+		Parameters: map[string]interface{}{"repository_name": "payments"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "success", result.Status)
+	assert.JSONEq(t, `{"run_id": "1001"}`, string(result.Details))
+
+	_, err = bot.ListRuns(ctx, client.RunFilter{Repository: "payments"})
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	assert.Contains(t, apiErr.Message, client.ScopeReadRuns)
+
+	runs, err := admin.ListRuns(ctx, client.RunFilter{Repository: "payments", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "U123456", runs[0].TriggeredBy)
+
+	require.NoError(t, admin.RevokeToken(ctx, token.ID))
+	_, err = bot.ListRepositories(ctx)
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+// collectRefs returns every $ref of the JSON documents
+func collectRefs(documents ...json.RawMessage) []string {
+	var refs []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				if ref, ok := child.(string); ok && key == "$ref" {
+					refs = append(refs, ref)
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	for _, document := range documents {
+		var value interface{}
+		if json.Unmarshal(document, &value) == nil {
+			walk(value)
+		}
+	}
+	return refs
+}
+
+// jsonFields returns the sorted JSON field names of a struct type
+func jsonFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	github := &mocks.MockGitHubAdapter{}
+	registry := services.NewProviderRegistry(github)
+
+	t.Run("Resolves By Host", func(t *testing.T) {
+		for _, url := range []string{
+			"https://github.com/Tovli/ChatOps",
+			"https://GitHub.com/Tovli/ChatOps.git",
+			"git@github.com:Tovli/ChatOps.git",
+		} {
+			provider, err := registry.ProviderFor(url)
+			require.NoError(t, err, url)
+			assert.Equal(t, "github", provider.Name())
+		}
+	})
+
+	t.Run("Unknown Host", func(t *testing.T) {
+		_, err := registry.ProviderFor("https://bitbucket.org/Tovli/ChatOps")
+		assert.Error(t, err)
+	})
+}
+
+func TestCommandProcessorWithoutProviders(t *testing.T) {
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name: "payments",
+			URL:  "https://github.com/Tovli/payments",
+			Pipelines: []domain.Pipeline{
+				{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+			},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+	})
+	require.NoError(t, err)
+
+	result, err := processor.ProcessCommand(context.Background(), &domain.Command{
+		Type:       domain.CommandTypeVerifyRepo,
+		Parameters: map[string]interface{}{"repository_name": "payments"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "error", result.Status)
+	assert.Contains(t, result.Message, "no CI provider configured")
+}
@@ -5,8 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 	"testing"
 	"time"
 
@@ -71,25 +69,8 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 	// Ensure the connection works
 	require.NoError(t, db.PingContext(ctx))
 
-	// Run migrations
-	workDir, err := os.Getwd()
-	require.NoError(t, err)
-	projectRoot := filepath.Join(filepath.Dir(workDir), "..", "..")
-	migrationsPath := filepath.Join(projectRoot, "migrations")
-
-	// Read and execute migrations manually
-	files, err := os.ReadDir(migrationsPath)
-	require.NoError(t, err)
-
-	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".up.sql") {
-			content, err := os.ReadFile(filepath.Join(migrationsPath, file.Name()))
-			require.NoError(t, err)
-
-			_, err = db.Exec(string(content))
-			require.NoError(t, err, "Failed to execute migration: "+file.Name())
-		}
-	}
+	// Run the embedded migrations
+	require.NoError(t, storagepg.Migrate(ctx, db))
 
 	return db, func() {
 		db.Close()
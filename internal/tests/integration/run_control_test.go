@@ -0,0 +1,248 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryRunStorage keeps runs in insertion order and hands out copies, so it is safe
+// to share with streaming goroutines
+type memoryRunStorage struct {
+	mu   sync.Mutex
+	runs []*domain.Run
+}
+
+func (s *memoryRunStorage) AddRun(ctx context.Context, run *domain.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *run
+	s.runs = append(s.runs, &stored)
+	return nil
+}
+
+func (s *memoryRunStorage) GetRun(ctx context.Context, runID string) (*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run := s.find(runID); run != nil {
+		return copyRun(run), nil
+	}
+	return nil, domain.NotFound("run %s not found", runID)
+}
+
+func (s *memoryRunStorage) GetLatestRun(ctx context.Context, repository, pipeline string) (*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		if s.runs[i].Repository == repository && (pipeline == "" || s.runs[i].Pipeline == pipeline) {
+			return copyRun(s.runs[i]), nil
+		}
+	}
+	return nil, domain.NotFound("no run of %s found", repository)
+}
+
+func (s *memoryRunStorage) UpdateRunStatus(ctx context.Context, runID, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := s.find(runID)
+	if run == nil {
+		return domain.NotFound("run %s not found", runID)
+	}
+	run.Status = status
+	return nil
+}
+
+func (s *memoryRunStorage) ListUserRuns(ctx context.Context, userID string, limit int) ([]*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runs []*domain.Run
+	for i := len(s.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		if s.runs[i].TriggeredBy == userID {
+			runs = append(runs, copyRun(s.runs[i]))
+		}
+	}
+	return runs, nil
+}
+
+func (s *memoryRunStorage) ListRuns(ctx context.Context, filter domain.RunFilter) ([]*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runs []*domain.Run
+	for i := len(s.runs) - 1; i >= 0 && len(runs) < filter.Limit; i-- {
+		run := s.runs[i]
+		if (filter.Repository == "" || run.Repository == filter.Repository) &&
+			(filter.Pipeline == "" || run.Pipeline == filter.Pipeline) &&
+			(filter.TriggeredBy == "" || run.TriggeredBy == filter.TriggeredBy) {
+			runs = append(runs, copyRun(run))
+		}
+	}
+	return runs, nil
+}
+
+func (s *memoryRunStorage) ListActiveRuns(ctx context.Context, since time.Time) ([]*domain.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runs []*domain.Run
+	for _, run := range s.runs {
+		if !run.IsFinished() && !run.CreatedAt.Before(since) {
+			runs = append(runs, copyRun(run))
+		}
+	}
+	return runs, nil
+}
+
+func (s *memoryRunStorage) TransitionRunStatus(ctx context.Context, runID, from, to string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := s.find(runID)
+	if run == nil {
+		return false, domain.NotFound("run %s not found", runID)
+	}
+	if run.Status != from {
+		return false, nil
+	}
+	run.Status = to
+	return true, nil
+}
+
+func (s *memoryRunStorage) ResolvePendingRun(ctx context.Context, pendingID, runID, url string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := s.find(pendingID)
+	if run == nil {
+		return false, nil
+	}
+	run.ID, run.URL = runID, url
+	return true, nil
+}
+
+// find returns the newest stored run with the ID; callers hold the lock
+func (s *memoryRunStorage) find(runID string) *domain.Run {
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		if s.runs[i].ID == runID {
+			return s.runs[i]
+		}
+	}
+	return nil
+}
+
+func copyRun(run *domain.Run) *domain.Run {
+	copied := *run
+	return &copied
+}
+
+func TestRunControlCommands(t *testing.T) {
+	ctx := context.Background()
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name: "payments",
+			URL:  "https://github.com/Tovli/payments",
+			Pipelines: []domain.Pipeline{
+				{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+			},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var cancelled []string
+	var retried []bool
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			return &domain.CommandResult{
+				Status:  "success",
+				Details: map[string]interface{}{"run_id": "1001", "url": "https://github.com/Tovli/payments/actions/runs/1001"},
+			}, nil
+		},
+		CancelRunFn: func(ctx context.Context, url string, runID string) error {
+			cancelled = append(cancelled, runID)
+			return nil
+		},
+		RetryRunFn: func(ctx context.Context, url string, runID string, failedOnly bool) (*domain.CommandResult, error) {
+			retried = append(retried, failedOnly)
+			return &domain.CommandResult{Status: "success", Details: map[string]interface{}{"run_id": runID}}, nil
+		},
+	}
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline}))
+	require.NoError(t, access.AssignRole("U123456", "developer"))
+
+	runs := &memoryRunStorage{}
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+		Runs:        runs,
+		RBAC:        access,
+	})
+	require.NoError(t, err)
+
+	developer := domain.User{ID: "U123456", Platform: "slack"}
+	result, err := processor.ProcessCommand(ctx, &domain.Command{
+		Type:       domain.CommandTypeVerifyRepo,
+		Parameters: map[string]interface{}{"repository_name": "payments"},
+		User:       developer,
+		Timestamp:  time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "success", result.Status)
+	require.Len(t, runs.runs, 1)
+	assert.Equal(t, "CI", runs.runs[0].Pipeline)
+	assert.Equal(t, "U123456", runs.runs[0].TriggeredBy)
+
+	t.Run("Cancel By Run ID", func(t *testing.T) {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeCancelRun,
+			Parameters: map[string]interface{}{"run_id": "1001"},
+			User:       developer,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "success", result.Status)
+		assert.Equal(t, []string{"1001"}, cancelled)
+		assert.Equal(t, "cancelling", runs.runs[0].Status)
+	})
+
+	t.Run("Rerun Failed Of Last Run", func(t *testing.T) {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeRerunFailed,
+			Parameters: map[string]interface{}{"repository_name": "payments", "pipeline_name": "CI"},
+			User:       developer,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "success", result.Status)
+		assert.Equal(t, []bool{true}, retried)
+	})
+
+	t.Run("Forbidden Without Trigger Permission", func(t *testing.T) {
+		_, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeRerunRun,
+			Parameters: map[string]interface{}{"run_id": "1001"},
+			User:       domain.User{ID: "U999999", Platform: "slack"},
+		})
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Contains(t, err.Error(), "not allowed")
+		assert.Len(t, retried, 1)
+	})
+
+	t.Run("Unknown Run", func(t *testing.T) {
+		_, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeCancelRun,
+			Parameters: map[string]interface{}{"run_id": "404"},
+			User:       developer,
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,293 @@
+package integration
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSigningKey = "test_slack_signing_key"
+
+// signSlackRequest adds valid Slack signature headers to a request
+func signSlackRequest(req *http.Request, body string) {
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte(testSigningKey))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:%s", timestamp, body)))
+
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", fmt.Sprintf("v0=%x", mac.Sum(nil)))
+}
+
+// slackAPIStub is an httptest stand-in for the Slack Web API that records calls
+type slackAPIStub struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	calls map[string][]url.Values
+	files map[string]string
+}
+
+func newSlackAPIStub(t *testing.T) *slackAPIStub {
+	stub := &slackAPIStub{
+		calls: make(map[string][]url.Values),
+		files: make(map[string]string),
+	}
+
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/")
+		w.Header().Set("Content-Type", "application/json")
+
+		if method == "upload" {
+			require.NoError(t, r.ParseMultipartForm(1<<20))
+			file, header, err := r.FormFile("file")
+			require.NoError(t, err)
+			content, err := io.ReadAll(file)
+			require.NoError(t, err)
+
+			stub.mu.Lock()
+			stub.files[header.Filename] = string(content)
+			stub.mu.Unlock()
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+
+		values := url.Values{}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			var payload map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			for key, value := range payload {
+				encoded, _ := json.Marshal(value)
+				values.Set(key, strings.Trim(string(encoded), `"`))
+			}
+		} else {
+			require.NoError(t, r.ParseForm())
+			values = r.PostForm
+		}
+
+		stub.mu.Lock()
+		stub.calls[method] = append(stub.calls[method], values)
+		stub.mu.Unlock()
+
+		switch method {
+		case "chat.postMessage":
+			fmt.Fprintf(w, `{"ok":true,"channel":%q,"ts":"1700000000.000100"}`, values.Get("channel"))
+		case "files.getUploadURLExternal":
+			fmt.Fprintf(w, `{"ok":true,"upload_url":%q,"file_id":"F123"}`, stub.URL+"/upload")
+		case "files.completeUploadExternal":
+			w.Write([]byte(`{"ok":true,"files":[{"id":"F123","title":"log"}]}`))
+		default:
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+
+	return stub
+}
+
+func (s *slackAPIStub) Calls(method string) []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+func TestRunLogsCommand(t *testing.T) {
+	slackAPI := newSlackAPIStub(t)
+	defer slackAPI.Close()
+
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name: "payments",
+			URL:  "https://github.com/Tovli/payments",
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var logLines []string
+	for i := 1; i <= 100; i++ {
+		logLines = append(logLines, fmt.Sprintf("line %d", i))
+	}
+	logLines[59] = "##[error]TestCharge failed"
+	fullLog := strings.Join(logLines, "\n")
+
+	github := &mocks.MockGitHubAdapter{
+		GetRunLogsFn: func(ctx context.Context, url string, runID string) ([]domain.JobLog, error) {
+			return []domain.JobLog{
+				{JobID: "1", Name: "lint", Status: "success", Content: "ok"},
+				{JobID: "2", Name: "test", Status: "failure", FailedStep: "Run tests", Content: fullLog},
+			}, nil
+		},
+	}
+
+	runs := &memoryRunStorage{}
+	require.NoError(t, runs.AddRun(context.Background(), &domain.Run{
+		ID:         "1001",
+		Provider:   "github",
+		Repository: "payments",
+		Pipeline:   "CI",
+		Type:       "verification",
+		URL:        "https://github.com/Tovli/payments/actions/runs/1001",
+	}))
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+		Runs:        runs,
+	})
+	require.NoError(t, err)
+
+	t.Run("Summary Around Error", func(t *testing.T) {
+		result, err := processor.ProcessCommand(context.Background(), &domain.Command{
+			Type:       domain.CommandTypeRunLogs,
+			Parameters: map[string]interface{}{"run_id": "1001", "lines": 10},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "success", result.Status)
+		assert.Contains(t, result.Message, "Run tests")
+
+		summary, ok := result.Details.(*domain.LogSummary)
+		require.True(t, ok)
+		assert.Equal(t, "test", summary.Job)
+		excerpt := strings.Split(summary.Excerpt, "\n")
+		require.Len(t, excerpt, 10)
+		assert.Equal(t, "line 55", excerpt[0])
+		assert.Equal(t, "line 64", excerpt[9])
+		assert.Contains(t, summary.Excerpt, "##[error]TestCharge failed")
+		assert.Equal(t, fullLog, summary.FullLog)
+	})
+
+	t.Run("Lines Parameter Types", func(t *testing.T) {
+		excerptLines := func(lines interface{}) (int, error) {
+			result, err := processor.ProcessCommand(context.Background(), &domain.Command{
+				Type:       domain.CommandTypeRunLogs,
+				Parameters: map[string]interface{}{"run_id": "1001", "lines": lines},
+			})
+			if err != nil {
+				return 0, err
+			}
+			require.Equal(t, "success", result.Status)
+			return len(strings.Split(result.Details.(*domain.LogSummary).Excerpt, "\n")), nil
+		}
+
+		// Chat adapters pass ints, JSON bodies float64 and form values strings
+		for _, lines := range []interface{}{7, float64(7), "7", " 7 "} {
+			n, err := excerptLines(lines)
+			require.NoError(t, err, "%#v", lines)
+			assert.Equal(t, 7, n, "%#v", lines)
+		}
+
+		for _, lines := range []interface{}{"seven", 7.5, true} {
+			_, err := excerptLines(lines)
+			assert.ErrorIs(t, err, domain.ErrInvalid, "%#v", lines)
+		}
+	})
+
+	t.Run("Posted To Slack Thread", func(t *testing.T) {
+		adapter, err := slack.NewSlackAdapter(zap.NewNop(), &config.SlackConfig{
+			BotToken:   "xoxb-test",
+			SigningKey: testSigningKey,
+			APIURL:     slackAPI.URL + "/",
+		}, processor)
+		require.NoError(t, err)
+
+		form := url.Values{}
+		form.Set("command", "/chatops")
+		form.Set("text", "logs last payments lines=5")
+		form.Set("user_id", "U123456")
+		form.Set("channel_id", "C123456")
+		body := form.Encode()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/commands", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		signSlackRequest(req, body)
+
+		rec := httptest.NewRecorder()
+		adapter.HandleSlashCommand(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Contains(t, response["message"], "Posted logs of run 1001")
+
+		posts := slackAPI.Calls("chat.postMessage")
+		require.Len(t, posts, 1)
+		assert.Equal(t, "C123456", posts[0].Get("channel"))
+		assert.Contains(t, posts[0].Get("text"), "##[error]TestCharge failed")
+
+		completed := slackAPI.Calls("files.completeUploadExternal")
+		require.Len(t, completed, 1)
+		assert.Equal(t, "1700000000.000100", completed[0].Get("thread_ts"))
+		assert.Equal(t, fullLog, slackAPI.files["run-1001-test.log"])
+	})
+
+	t.Run("Requires Permission To Run The Pipeline", func(t *testing.T) {
+		var fetched []string
+		guarded := &mocks.MockGitHubAdapter{
+			GetRunLogsFn: func(ctx context.Context, url string, runID string) ([]domain.JobLog, error) {
+				fetched = append(fetched, runID)
+				return github.GetRunLogsFn(ctx, url, runID)
+			},
+		}
+		require.NoError(t, runs.AddRun(context.Background(), &domain.Run{
+			ID:         "1002",
+			Provider:   "github",
+			Repository: "payments",
+			Pipeline:   "Deploy",
+			Type:       "deployment",
+		}))
+
+		access := rbac.NewService()
+		require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline}))
+		require.NoError(t, access.AssignRole("U123456", "developer"))
+		guardedProcessor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+			Logger:      zap.NewNop(),
+			RepoService: repoService,
+			Providers:   services.NewProviderRegistry(guarded),
+			Runs:        runs,
+			RBAC:        access,
+		})
+		require.NoError(t, err)
+
+		logs := func(userID, runID string) (*domain.CommandResult, error) {
+			return guardedProcessor.ProcessCommand(context.Background(), &domain.Command{
+				Type:       domain.CommandTypeRunLogs,
+				Parameters: map[string]interface{}{"run_id": runID},
+				User:       domain.User{ID: userID, Platform: "slack"},
+			})
+		}
+
+		_, err = logs("U999999", "1001")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		_, err = logs("U123456", "1002")
+		assert.ErrorIs(t, err, domain.ErrForbidden)
+		assert.Empty(t, fetched)
+
+		result, err := logs("U123456", "1001")
+		require.NoError(t, err)
+		assert.Equal(t, "success", result.Status)
+		assert.Equal(t, []string{"1001"}, fetched)
+	})
+}
@@ -0,0 +1,297 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryScheduleStorage keeps schedules in insertion order
+type memoryScheduleStorage struct {
+	mu        sync.Mutex
+	schedules []*domain.Schedule
+}
+
+func (s *memoryScheduleStorage) AddSchedule(ctx context.Context, schedule *domain.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule.ID = fmt.Sprint(len(s.schedules) + 1)
+	stored := *schedule
+	s.schedules = append(s.schedules, &stored)
+	return nil
+}
+
+func (s *memoryScheduleStorage) GetSchedule(ctx context.Context, id string) (*domain.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, schedule := range s.schedules {
+		if schedule.ID == id {
+			return schedule, nil
+		}
+	}
+	return nil, fmt.Errorf("schedule %s not found", id)
+}
+
+func (s *memoryScheduleStorage) ListSchedules(ctx context.Context) ([]*domain.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*domain.Schedule(nil), s.schedules...), nil
+}
+
+func (s *memoryScheduleStorage) DeleteSchedule(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, schedule := range s.schedules {
+		if schedule.ID == id {
+			s.schedules = append(s.schedules[:i], s.schedules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("schedule %s not found", id)
+}
+
+func (s *memoryScheduleStorage) ListDueSchedules(ctx context.Context, at time.Time) ([]*domain.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*domain.Schedule
+	for _, schedule := range s.schedules {
+		if !schedule.NextRunAt.After(at) {
+			copied := *schedule
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryScheduleStorage) ClaimScheduleRun(ctx context.Context, id string, expectedNext, ranAt, next time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, schedule := range s.schedules {
+		if schedule.ID == id && schedule.NextRunAt.Equal(expectedNext) {
+			schedule.LastRunAt = ranAt
+			schedule.NextRunAt = next
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// staticLeader is a leader lock with a fixed outcome
+type staticLeader struct {
+	leader bool
+}
+
+func (l *staticLeader) TryAcquire(ctx context.Context) (bool, error) {
+	return l.leader, nil
+}
+
+func (l *staticLeader) Release(ctx context.Context) error {
+	return nil
+}
+
+// recordingNotifier captures notifications per channel
+type recordingNotifier struct {
+	messages map[string][]string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, channelID, message string) error {
+	if n.messages == nil {
+		n.messages = make(map[string][]string)
+	}
+	n.messages[channelID] = append(n.messages[channelID], message)
+	return nil
+}
+
+func TestScheduledCommands(t *testing.T) {
+	ctx := context.Background()
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name:          "payments",
+			URL:           "https://github.com/Tovli/payments",
+			DefaultBranch: "main",
+			Pipelines: []domain.Pipeline{
+				{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+				{Name: "nightly-verify", Path: ".github/workflows/nightly.yml"},
+			},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var triggers []*domain.WorkflowTrigger
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			triggers = append(triggers, trigger)
+			return &domain.CommandResult{Status: "success", Message: "Workflow triggered successfully"}, nil
+		},
+	}
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline, domain.PermissionManageSchedules}))
+	require.NoError(t, access.AssignRole("U123456", "developer"))
+	require.NoError(t, access.AddRole("scheduler", []string{domain.PermissionManageSchedules}))
+	require.NoError(t, access.AssignRole("U222222", "scheduler"))
+
+	schedules := &memoryScheduleStorage{}
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+		Schedules:   schedules,
+		RBAC:        access,
+	})
+	require.NoError(t, err)
+
+	adapter, err := slack.NewSlackAdapter(zap.NewNop(), &config.SlackConfig{
+		BotToken:   "xoxb-test",
+		SigningKey: testSigningKey,
+	}, processor)
+	require.NoError(t, err)
+
+	slashCommand := func(userID, text string) map[string]interface{} {
+		form := url.Values{}
+		form.Set("command", "/chatops")
+		form.Set("text", text)
+		form.Set("user_id", userID)
+		form.Set("channel_id", "C123456")
+		body := form.Encode()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/commands", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		signSlackRequest(req, body)
+
+		rec := httptest.NewRecorder()
+		adapter.HandleSlashCommand(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		return response
+	}
+
+	t.Run("Add From Chat", func(t *testing.T) {
+		response := slashCommand("U123456", "schedule add 0 2 * * 1-5 tz=Europe/Berlin verify payments nightly-verify")
+		require.Equal(t, "success", response["status"], response["message"])
+
+		require.Len(t, schedules.schedules, 1)
+		schedule := schedules.schedules[0]
+		assert.Equal(t, "0 2 * * 1-5", schedule.Cron)
+		assert.Equal(t, "Europe/Berlin", schedule.Timezone)
+		assert.Equal(t, domain.CommandTypeVerifyRepo, schedule.CommandType)
+		assert.Equal(t, "nightly-verify", schedule.Parameters["pipeline_name"])
+		assert.Equal(t, "U123456", schedule.User.ID)
+
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		require.NoError(t, err)
+		next := schedule.NextRunAt.In(berlin)
+		assert.Equal(t, 2, next.Hour())
+		assert.NotEqual(t, time.Saturday, next.Weekday())
+		assert.NotEqual(t, time.Sunday, next.Weekday())
+
+		assert.Empty(t, triggers)
+	})
+
+	t.Run("Only Verify And Deploy Can Be Scheduled", func(t *testing.T) {
+		response := slashCommand("U123456", "schedule add @daily cancel last payments")
+		assert.Equal(t, "error", response["status"])
+		assert.Len(t, schedules.schedules, 1)
+	})
+
+	t.Run("Forbidden Without Permission", func(t *testing.T) {
+		response := slashCommand("U999999", "schedule add @hourly verify payments")
+		assert.Equal(t, "error", response["status"])
+		assert.Contains(t, response["message"], "not allowed")
+	})
+
+	t.Run("Scheduling Requires Permission To Run The Command", func(t *testing.T) {
+		response := slashCommand("U222222", "schedule add @hourly verify payments")
+		assert.Equal(t, "error", response["status"])
+		assert.Contains(t, response["message"], "not allowed")
+
+		// Developers may verify but not deploy
+		response = slashCommand("U123456", "schedule add @daily deploy payments production")
+		assert.Equal(t, "error", response["status"])
+		assert.Contains(t, response["message"], "not allowed")
+		assert.Len(t, schedules.schedules, 1)
+	})
+
+	t.Run("Listing Requires Permission", func(t *testing.T) {
+		response := slashCommand("U999999", "schedule list")
+		assert.Equal(t, "error", response["status"])
+		assert.Contains(t, response["message"], "not allowed")
+
+		response = slashCommand("U222222", "schedule list")
+		require.Equal(t, "success", response["status"], response["message"])
+		assert.Contains(t, response["message"], "verify payments nightly-verify")
+	})
+
+	t.Run("Fires Once As Scheduling User", func(t *testing.T) {
+		now := schedules.schedules[0].NextRunAt.Add(time.Second)
+		notifier := &recordingNotifier{}
+
+		follower, err := services.NewScheduler(services.SchedulerOptions{
+			Logger:    zap.NewNop(),
+			Processor: processor,
+			Schedules: schedules,
+			Leader:    &staticLeader{leader: false},
+			Clock:     func() time.Time { return now },
+		})
+		require.NoError(t, err)
+		require.NoError(t, follower.Tick(ctx))
+		assert.Empty(t, triggers)
+
+		leader, err := services.NewScheduler(services.SchedulerOptions{
+			Logger:    zap.NewNop(),
+			Processor: processor,
+			Schedules: schedules,
+			Leader:    &staticLeader{leader: true},
+			Notifier:  notifier,
+			Clock:     func() time.Time { return now },
+		})
+		require.NoError(t, err)
+		require.NoError(t, leader.Tick(ctx))
+		require.NoError(t, leader.Tick(ctx))
+
+		require.Len(t, triggers, 1)
+		assert.Equal(t, ".github/workflows/nightly.yml", triggers[0].Workflow)
+		assert.True(t, schedules.schedules[0].NextRunAt.After(now))
+		require.Len(t, notifier.messages["C123456"], 1)
+		assert.Contains(t, notifier.messages["C123456"][0], "ran")
+	})
+
+	t.Run("List And Remove", func(t *testing.T) {
+		response := slashCommand("U123456", "schedule list")
+		require.Equal(t, "success", response["status"])
+		assert.Contains(t, response["message"], "verify payments nightly-verify")
+
+		response = slashCommand("U123456", "schedule remove #1")
+		require.Equal(t, "success", response["status"], response["message"])
+		assert.Empty(t, schedules.schedules)
+	})
+}
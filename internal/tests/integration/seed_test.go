@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/infrastructure/seed"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/memory"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Bundled Seed File Is Valid", func(t *testing.T) {
+		file, err := seed.Load("../../../config/seed.yaml")
+		require.NoError(t, err)
+		assert.NotEmpty(t, file.Repositories)
+		assert.NotEmpty(t, file.Roles)
+	})
+
+	t.Run("Seeding Twice Updates Records", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "seed.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+repositories:
+  - name: payments
+    url: https://github.com/Tovli/payments
+    pipelines:
+      - name: CI
+        path: .github/workflows/ci.yml
+        default: true
+    environments:
+      - name: staging
+        pipeline: CI
+roles:
+  - name: developer
+    permissions: [pipeline:trigger]
+    users: [U123456]
+`), 0o644))
+		file, err := seed.Load(path)
+		require.NoError(t, err)
+
+		storage := memory.NewStorage()
+		result, err := seed.Apply(ctx, file, storage, storage)
+		require.NoError(t, err)
+		assert.Equal(t, &seed.Result{Added: 1, Roles: 1}, result)
+
+		repo, err := storage.GetRepository(ctx, "payments")
+		require.NoError(t, err)
+		assert.Equal(t, "main", repo.DefaultBranch)
+		assert.Equal(t, seed.SeedUser, repo.AddedBy)
+		assert.True(t, repo.Pipelines[0].IsDefault)
+		assert.Equal(t, "CI", repo.Environment("staging").Pipeline)
+
+		result, err = seed.Apply(ctx, file, storage, storage)
+		require.NoError(t, err)
+		assert.Equal(t, &seed.Result{Updated: 1, Roles: 1}, result)
+
+		stored, err := storage.ListRoles(ctx)
+		require.NoError(t, err)
+		access, err := rbac.NewServiceWithRoles(stored)
+		require.NoError(t, err)
+		assert.True(t, access.IsAllowed(ctx, domain.User{ID: "U123456"}, domain.PermissionTriggerPipeline))
+		assert.False(t, access.IsAllowed(ctx, domain.User{ID: "U123456"}, domain.PermissionDeploy))
+		assert.False(t, access.IsAllowed(ctx, domain.User{ID: "U999999"}, domain.PermissionTriggerPipeline))
+	})
+
+	t.Run("Invalid Seed Files Are Rejected", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "seed.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`
+repositories:
+  - name: payments
+    url: https://github.com/Tovli/payments
+    pipelines:
+      - name: CI
+        path: .github/workflows/ci.yml
+    environments:
+      - name: production
+        pipeline: Deploy
+`), 0o644))
+		_, err := seed.Load(path)
+		assert.ErrorContains(t, err, `unknown pipeline "Deploy"`)
+	})
+}
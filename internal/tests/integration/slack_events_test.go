@@ -0,0 +1,156 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSlackMessageEvents(t *testing.T) {
+	slackAPI := newSlackAPIStub(t)
+	defer slackAPI.Close()
+
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name:          "payments",
+			URL:           "https://github.com/Tovli/payments",
+			DefaultBranch: "main",
+			Pipelines:     []domain.Pipeline{{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true}},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var triggers []*domain.WorkflowTrigger
+	triggerCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(triggers)
+	}
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			triggers = append(triggers, trigger)
+			return &domain.CommandResult{Status: "success", Message: "Triggered CI on payments"}, nil
+		},
+	}
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+	})
+	require.NoError(t, err)
+
+	adapter, err := slack.NewSlackAdapter(zap.NewNop(), &config.SlackConfig{
+		BotToken:   "xoxb-test",
+		SigningKey: testSigningKey,
+		APIURL:     slackAPI.URL + "/",
+	}, processor)
+	require.NoError(t, err)
+
+	send := func(payload string, retry string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/webhooks", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		if retry != "" {
+			req.Header.Set("X-Slack-Retry-Num", retry)
+			req.Header.Set("X-Slack-Retry-Reason", "http_timeout")
+		}
+		signSlackRequest(req, payload)
+
+		rec := httptest.NewRecorder()
+		adapter.HandleWebhook(rec, req)
+		return rec
+	}
+	repliesEventually := func(n int) {
+		require.Eventually(t, func() bool { return len(slackAPI.Calls("chat.postMessage")) >= n }, time.Second, 10*time.Millisecond)
+	}
+
+	mention := `{
+		"type": "event_callback",
+		"event_id": "Ev001",
+		"event": {"type": "app_mention", "user": "U111111", "channel": "C123", "ts": "1700000000.000100", "text": "<@U0BOT> verify payments"}
+	}`
+
+	t.Run("Mention Replies In Thread", func(t *testing.T) {
+		rec := send(mention, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		repliesEventually(1)
+		reply := slackAPI.Calls("chat.postMessage")[0]
+		assert.Equal(t, "C123", reply.Get("channel"))
+		assert.Equal(t, "1700000000.000100", reply.Get("thread_ts"))
+		assert.Contains(t, reply.Get("text"), "Triggered CI on payments")
+		assert.Equal(t, 1, triggerCount())
+	})
+
+	t.Run("Retries Are Dropped", func(t *testing.T) {
+		rec := send(mention, "1")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 1, triggerCount())
+		assert.Len(t, slackAPI.Calls("chat.postMessage"), 1)
+	})
+
+	t.Run("Direct Messages Use The Command Grammar", func(t *testing.T) {
+		rec := send(`{
+			"type": "event_callback",
+			"event_id": "Ev002",
+			"event": {"type": "message", "channel_type": "im", "user": "U111111", "channel": "D123", "ts": "1700000001.000100", "thread_ts": "1700000000.000900", "text": "deploy payments"}
+		}`, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		repliesEventually(2)
+		reply := slackAPI.Calls("chat.postMessage")[1]
+		assert.Equal(t, "D123", reply.Get("channel"))
+		assert.Equal(t, "1700000000.000900", reply.Get("thread_ts"))
+		assert.Contains(t, reply.Get("text"), "expected deploy <repository> <environment>")
+	})
+
+	t.Run("Bot Messages Are Ignored", func(t *testing.T) {
+		rec := send(`{
+			"type": "event_callback",
+			"event_id": "Ev003",
+			"event": {"type": "message", "channel_type": "im", "bot_id": "B123", "channel": "D123", "ts": "1700000002.000100", "text": "verify payments"}
+		}`, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Len(t, slackAPI.Calls("chat.postMessage"), 2)
+		assert.Equal(t, 1, triggerCount())
+	})
+
+	t.Run("Unhandled Events Are Acknowledged", func(t *testing.T) {
+		for _, event := range []string{
+			`{"type": "message", "channel_type": "channel", "user": "U111111", "channel": "C123", "ts": "1700000003.000100", "text": "verify payments"}`,
+			`{"type": "reaction_added", "user": "U111111", "reaction": "tada"}`,
+			`"malformed"`,
+		} {
+			rec := send(`{"type": "event_callback", "event_id": "Ev004", "event": `+event+`}`, "")
+			assert.Equal(t, http.StatusOK, rec.Code, event)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Len(t, slackAPI.Calls("chat.postMessage"), 2)
+		assert.Equal(t, 1, triggerCount())
+	})
+}
@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/workflows"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const signOffWorkflow = `
+name: sign-off
+steps:
+  - id: review
+    type: approval
+    approvers: [U111111]
+`
+
+func TestSlackHomeTab(t *testing.T) {
+	ctx := context.Background()
+	slackAPI := newSlackAPIStub(t)
+	defer slackAPI.Close()
+
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name:          "payments",
+			URL:           "https://github.com/Tovli/payments",
+			DefaultBranch: "main",
+			Pipelines:     []domain.Pipeline{{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true}},
+			Environments:  []domain.Environment{{Name: "production", Pipeline: "CI"}},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	runs := &memoryRunStorage{}
+	require.NoError(t, runs.AddRun(ctx, &domain.Run{ID: "1001", Repository: "payments", Pipeline: "CI", Status: "success", TriggeredBy: "U111111", URL: "https://github.com/Tovli/payments/actions/runs/1001", CreatedAt: now}))
+	require.NoError(t, runs.AddRun(ctx, &domain.Run{ID: "1002", Repository: "payments", Pipeline: "CI", Status: "failure", TriggeredBy: "U222222", CreatedAt: now}))
+
+	locks := newMemoryLockStorage(time.Now)
+	guard, err := services.NewDeploymentGuard(services.DeploymentGuardOptions{Locks: locks})
+	require.NoError(t, err)
+	_, _, err = guard.Lock(ctx, "payments", "production", "U222222", "database migration", time.Hour)
+	require.NoError(t, err)
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline, domain.PermissionRunWorkflow, domain.PermissionApproveWorkflow}))
+	require.NoError(t, access.AssignRole("U111111", "developer"))
+
+	audit := &memoryAuditStorage{}
+	auditLog, err := services.NewAuditLog(services.AuditLogOptions{Logger: zap.NewNop(), Storage: audit})
+	require.NoError(t, err)
+	bus, err := services.NewEventBus(services.EventBusOptions{Logger: zap.NewNop()})
+	require.NoError(t, err)
+	bus.Subscribe("*", auditLog)
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Runs:        runs,
+		Guard:       guard,
+		Events:      bus,
+		Audit:       audit,
+		RBAC:        access,
+	})
+	require.NoError(t, err)
+
+	definition, err := workflows.Parse([]byte(signOffWorkflow))
+	require.NoError(t, err)
+	engine, err := services.NewWorkflowEngine(services.WorkflowEngineOptions{
+		Logger:      zap.NewNop(),
+		Processor:   processor,
+		Storage:     &memoryWorkflowStorage{},
+		Definitions: []domain.WorkflowDefinition{*definition},
+		Notifier:    &recordingNotifier{},
+	})
+	require.NoError(t, err)
+	processor.SetWorkflows(engine)
+
+	result, err := processor.ProcessCommand(ctx, &domain.Command{
+		Type:       domain.CommandTypeStartWorkflow,
+		Parameters: map[string]interface{}{"workflow_name": "sign-off"},
+		User:       domain.User{ID: "U111111", Platform: "slack"},
+		Source:     domain.CommandSource{Platform: "slack", ChannelID: "C123"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "success", result.Status, result.Message)
+
+	adapter, err := slack.NewSlackAdapter(zap.NewNop(), &config.SlackConfig{
+		BotToken:   "xoxb-test",
+		SigningKey: testSigningKey,
+		APIURL:     slackAPI.URL + "/",
+	}, processor)
+	require.NoError(t, err)
+
+	open := func(user, tab string) {
+		payload := `{"type": "event_callback", "event_id": "Ev` + user + tab + `",
+			"event": {"type": "app_home_opened", "user": "` + user + `", "channel": "D123", "tab": "` + tab + `"}}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/webhooks", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		signSlackRequest(req, payload)
+
+		rec := httptest.NewRecorder()
+		adapter.HandleWebhook(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	t.Run("Home Shows The State Of The User", func(t *testing.T) {
+		open("U111111", "home")
+		require.Eventually(t, func() bool { return len(slackAPI.Calls("views.publish")) == 1 }, time.Second, 10*time.Millisecond)
+
+		publish := slackAPI.Calls("views.publish")[0]
+		assert.Equal(t, "U111111", publish.Get("user_id"))
+		view := publish.Get("view")
+		assert.Contains(t, view, "actions/runs/1001")
+		assert.Contains(t, view, "Your recent commands")
+		assert.Contains(t, view, "`start_workflow`: `success`")
+		assert.NotContains(t, view, "1002")
+		assert.Contains(t, view, "approve 1 review")
+		assert.Contains(t, view, "pipelines CI")
+		assert.NotContains(t, view, "deploys to production")
+		assert.Contains(t, view, "database migration")
+	})
+
+	t.Run("Users Without Permissions See No Repositories Or Approvals", func(t *testing.T) {
+		open("U333333", "home")
+		require.Eventually(t, func() bool { return len(slackAPI.Calls("views.publish")) == 2 }, time.Second, 10*time.Millisecond)
+
+		view := slackAPI.Calls("views.publish")[1].Get("view")
+		assert.Contains(t, view, "You have not triggered any runs yet.")
+		assert.Contains(t, view, "You have not run any commands yet.")
+		assert.Contains(t, view, "Nothing is waiting for your approval.")
+		assert.Contains(t, view, "You cannot run pipelines or deploy any repository.")
+		assert.Contains(t, view, "database migration")
+	})
+
+	t.Run("Messages Tab Is Ignored", func(t *testing.T) {
+		open("U111111", "messages")
+		time.Sleep(50 * time.Millisecond)
+		assert.Len(t, slackAPI.Calls("views.publish"), 2)
+	})
+}
@@ -0,0 +1,270 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/workflows"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const hotfixWorkflow = `
+name: hotfix
+description: Ship a hotfix
+inputs:
+  ref: main
+  version: ""
+steps:
+  - id: announce
+    type: post_message
+    message: "Shipping {{ .inputs.version }}"
+`
+
+func TestSlackRunModal(t *testing.T) {
+	slackAPI := newSlackAPIStub(t)
+	defer slackAPI.Close()
+
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name:          "payments",
+			URL:           "https://github.com/Tovli/payments",
+			DefaultBranch: "main",
+			Pipelines: []domain.Pipeline{
+				{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+				{Name: "Nightly", Path: ".github/workflows/nightly.yml"},
+			},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var triggers []*domain.WorkflowTrigger
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			triggers = append(triggers, trigger)
+			return &domain.CommandResult{Status: "success", Message: "Triggered Nightly on payments"}, nil
+		},
+		GetPipelineInputsFn: func(ctx context.Context, url, pipelinePath, ref string) ([]domain.PipelineInput, error) {
+			if pipelinePath != ".github/workflows/nightly.yml" {
+				return nil, nil
+			}
+			return []domain.PipelineInput{
+				{Name: "environment", Required: true, Default: "staging", Options: []string{"staging", "production"}},
+				{Name: "suite", Description: "Test suite to run"},
+			}, nil
+		},
+		ListRefsFn: func(ctx context.Context, url, query string, limit int) ([]string, error) {
+			var refs []string
+			for _, ref := range []string{"main", "release/1.2", "v1.2.0"} {
+				if strings.Contains(ref, query) {
+					refs = append(refs, ref)
+				}
+			}
+			return refs, nil
+		},
+	}
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+	})
+	require.NoError(t, err)
+
+	definition, err := workflows.Parse([]byte(hotfixWorkflow))
+	require.NoError(t, err)
+	executions := &memoryWorkflowStorage{}
+	engine, err := services.NewWorkflowEngine(services.WorkflowEngineOptions{
+		Logger:      zap.NewNop(),
+		Processor:   processor,
+		Storage:     executions,
+		Definitions: []domain.WorkflowDefinition{*definition},
+		Notifier:    &recordingNotifier{},
+	})
+	require.NoError(t, err)
+	processor.SetWorkflows(engine)
+
+	adapter, err := slack.NewSlackAdapter(zap.NewNop(), &config.SlackConfig{
+		BotToken:   "xoxb-test",
+		SigningKey: testSigningKey,
+		APIURL:     slackAPI.URL + "/",
+	}, processor)
+	require.NoError(t, err)
+
+	slash := func(text string) *httptest.ResponseRecorder {
+		form := url.Values{}
+		form.Set("command", "/chatops")
+		form.Set("text", text)
+		form.Set("user_id", "U111111")
+		form.Set("channel_id", "C123")
+		form.Set("trigger_id", "T123")
+		body := form.Encode()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/commands", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		signSlackRequest(req, body)
+
+		rec := httptest.NewRecorder()
+		adapter.HandleSlashCommand(rec, req)
+		return rec
+	}
+	interact := func(payload string) *httptest.ResponseRecorder {
+		body := url.Values{"payload": {payload}}.Encode()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/interactions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		signSlackRequest(req, body)
+
+		rec := httptest.NewRecorder()
+		adapter.HandleInteraction(rec, req)
+		return rec
+	}
+	suggest := func(blockID, query, state string) []string {
+		rec := interact(`{"type": "block_suggestion", "block_id": "` + blockID + `", "action_id": "value", "value": "` + query + `",
+			"view": {"callback_id": "chatops_run", "state": {"values": ` + state + `}}}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response struct {
+			Options []struct {
+				Value string `json:"value"`
+			} `json:"options"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		var values []string
+		for _, option := range response.Options {
+			values = append(values, option.Value)
+		}
+		return values
+	}
+
+	t.Run("Run Opens Pipeline Modal", func(t *testing.T) {
+		rec := slash("run")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		opens := slackAPI.Calls("views.open")
+		require.Len(t, opens, 1)
+		assert.Equal(t, "T123", opens[0].Get("trigger_id"))
+		assert.Contains(t, opens[0].Get("view"), "external_select")
+		assert.Contains(t, opens[0].Get("view"), "C123")
+	})
+
+	t.Run("Selects Suggest Repositories And Their Pipelines", func(t *testing.T) {
+		assert.Equal(t, []string{"payments"}, suggest("repository", "", `{}`))
+		assert.Empty(t, suggest("repository", "billing", `{}`))
+
+		selected := `{"repository": {"value": {"type": "external_select", "selected_option": {"value": "payments"}}}}`
+		assert.Equal(t, []string{"CI", "Nightly"}, suggest("pipeline", "", selected))
+		assert.Equal(t, []string{"Nightly"}, suggest("pipeline", "night", selected))
+		assert.Empty(t, suggest("pipeline", "", `{}`))
+	})
+
+	t.Run("Ref Select Suggests Branches And Tags", func(t *testing.T) {
+		selected := `{"repository": {"value": {"type": "external_select", "selected_option": {"value": "payments"}}}}`
+		assert.Equal(t, []string{"main", "release/1.2", "v1.2.0"}, suggest("ref", "", selected))
+		// The typed text comes first, so commits and unlisted refs can be run too
+		assert.Equal(t, []string{"1.2", "release/1.2", "v1.2.0"}, suggest("ref", "1.2", selected))
+		assert.Equal(t, []string{"3f2a9c1"}, suggest("ref", "3f2a9c1", selected))
+		assert.Equal(t, []string{"main"}, suggest("ref", "main", selected))
+		assert.Empty(t, suggest("ref", "", `{}`))
+	})
+
+	t.Run("Choosing A Pipeline Adds Its Inputs", func(t *testing.T) {
+		rec := interact(`{"type": "block_actions", "user": {"id": "U111111"}, "actions": [{"block_id": "pipeline", "action_id": "value"}],
+			"view": {"id": "V123", "hash": "h1", "callback_id": "chatops_run", "private_metadata": "{\"channel_id\":\"C123\"}",
+			"state": {"values": {
+				"repository": {"value": {"type": "external_select", "selected_option": {"value": "payments"}}},
+				"pipeline": {"value": {"type": "external_select", "selected_option": {"value": "Nightly"}}}
+			}}}}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		updates := slackAPI.Calls("views.update")
+		require.Len(t, updates, 1)
+		assert.Equal(t, "V123", updates[0].Get("view_id"))
+		assert.Equal(t, "h1", updates[0].Get("hash"))
+		view := updates[0].Get("view")
+		assert.Contains(t, view, "input:environment")
+		assert.Contains(t, view, "production")
+		assert.Contains(t, view, "input:suite")
+		assert.Contains(t, view, "Test suite to run")
+		assert.Contains(t, view, "C123")
+	})
+
+	t.Run("Submission Runs The Pipeline", func(t *testing.T) {
+		rec := interact(`{"type": "view_submission", "user": {"id": "U111111"}, "view": {
+			"type": "modal", "callback_id": "chatops_run", "private_metadata": "{\"channel_id\":\"C123\"}",
+			"state": {"values": {
+				"repository": {"value": {"type": "external_select", "selected_option": {"value": "payments"}}},
+				"pipeline": {"value": {"type": "external_select", "selected_option": {"value": "Nightly"}}},
+				"ref": {"value": {"type": "external_select", "selected_option": {"value": "release/1.2"}}},
+				"input:environment": {"value": {"type": "static_select", "selected_option": {"value": "production"}}},
+				"input:suite": {"value": {"type": "plain_text_input", "value": ""}}
+			}}}}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Empty(t, rec.Body.String())
+
+		require.Eventually(t, func() bool { return len(slackAPI.Calls("chat.postEphemeral")) == 1 }, time.Second, 10*time.Millisecond)
+		result := slackAPI.Calls("chat.postEphemeral")[0]
+		assert.Equal(t, "C123", result.Get("channel"))
+		assert.Equal(t, "U111111", result.Get("user"))
+		assert.Contains(t, result.Get("text"), "Triggered Nightly on payments")
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, triggers, 1)
+		assert.Equal(t, ".github/workflows/nightly.yml", triggers[0].Workflow)
+		assert.Equal(t, "release/1.2", triggers[0].Ref)
+		assert.Equal(t, map[string]interface{}{"environment": "production"}, triggers[0].Parameters)
+	})
+
+	t.Run("Workflow Modal Has One Field Per Input", func(t *testing.T) {
+		rec := slash("run hotfix")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		opens := slackAPI.Calls("views.open")
+		require.Len(t, opens, 2)
+		view := opens[1].Get("view")
+		assert.Contains(t, view, "input:ref")
+		assert.Contains(t, view, "input:version")
+		assert.Contains(t, view, "Ship a hotfix")
+
+		rec = slash("run unknown")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unknown workflow: unknown")
+	})
+
+	t.Run("Workflow Submission Starts The Workflow", func(t *testing.T) {
+		rec := interact(`{"type": "view_submission", "user": {"id": "U111111"}, "view": {
+			"type": "modal", "callback_id": "chatops_run", "private_metadata": "{\"channel_id\":\"C123\",\"workflow\":\"hotfix\"}",
+			"state": {"values": {
+				"input:ref": {"value": {"type": "plain_text_input", "value": "main"}},
+				"input:version": {"value": {"type": "plain_text_input", "value": "1.2.3"}}
+			}}}}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		require.Eventually(t, func() bool { return len(slackAPI.Calls("chat.postEphemeral")) == 2 }, time.Second, 10*time.Millisecond)
+		assert.Contains(t, slackAPI.Calls("chat.postEphemeral")[1].Get("text"), "Started workflow hotfix")
+
+		workflow, err := executions.GetWorkflow(context.Background(), "1")
+		require.NoError(t, err)
+		assert.Equal(t, "1.2.3", workflow.Parameters["version"])
+	})
+}
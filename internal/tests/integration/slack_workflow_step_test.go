@@ -0,0 +1,213 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/slack"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSlackWorkflowStep(t *testing.T) {
+	slackAPI := newSlackAPIStub(t)
+	defer slackAPI.Close()
+
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name:          "payments",
+			URL:           "https://github.com/Tovli/payments",
+			DefaultBranch: "main",
+			Pipelines:     []domain.Pipeline{{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true}},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var triggers []*domain.WorkflowTrigger
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			triggers = append(triggers, trigger)
+			return &domain.CommandResult{
+				Status:  "success",
+				Message: "Triggered CI",
+				Details: map[string]interface{}{"run_id": "2001", "url": "https://github.com/Tovli/payments/actions/runs/2001"},
+			}, nil
+		},
+	}
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline}))
+	require.NoError(t, access.AssignRole("U111111", "developer"))
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+		RBAC:        access,
+	})
+	require.NoError(t, err)
+
+	adapter, err := slack.NewSlackAdapter(zap.NewNop(), &config.SlackConfig{
+		BotToken:   "xoxb-test",
+		SigningKey: testSigningKey,
+		APIURL:     slackAPI.URL + "/",
+	}, processor)
+	require.NoError(t, err)
+
+	interact := func(payload string) *httptest.ResponseRecorder {
+		body := url.Values{"payload": {payload}}.Encode()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/interactions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		signSlackRequest(req, body)
+
+		rec := httptest.NewRecorder()
+		adapter.HandleInteraction(rec, req)
+		return rec
+	}
+	execute := func(payload string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/slack/webhooks", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		signSlackRequest(req, payload)
+
+		rec := httptest.NewRecorder()
+		adapter.HandleWebhook(rec, req)
+		return rec
+	}
+
+	t.Run("Edit Opens Configuration Modal", func(t *testing.T) {
+		rec := interact(`{
+			"type": "workflow_step_edit",
+			"trigger_id": "T123",
+			"workflow_step": {"workflow_step_edit_id": "E1", "inputs": {"action": {"value": "deploy"}, "target": {"value": "production"}}}
+		}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		opens := slackAPI.Calls("views.open")
+		require.Len(t, opens, 1)
+		assert.Equal(t, "T123", opens[0].Get("trigger_id"))
+		assert.Contains(t, opens[0].Get("view"), "workflow_step")
+		assert.Contains(t, opens[0].Get("view"), "production")
+	})
+
+	t.Run("Save Validates And Stores Inputs", func(t *testing.T) {
+		submission := func(action, repository, target string) string {
+			return `{
+				"type": "view_submission",
+				"user": {"id": "U111111"},
+				"view": {"type": "workflow_step", "state": {"values": {
+					"action": {"value": {"type": "static_select", "selected_option": {"value": "` + action + `"}}},
+					"repository": {"value": {"type": "plain_text_input", "value": "` + repository + `"}},
+					"target": {"value": {"type": "plain_text_input", "value": "` + target + `"}}
+				}}},
+				"workflow_step": {"workflow_step_edit_id": "E1"}
+			}`
+		}
+
+		rec := interact(submission("deploy", "payments", ""))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, "errors", response["response_action"])
+		assert.Empty(t, slackAPI.Calls("workflows.updateStep"))
+
+		rec = interact(submission("verify", "payments", "CI"))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		updates := slackAPI.Calls("workflows.updateStep")
+		require.Len(t, updates, 1)
+		assert.Equal(t, "E1", updates[0].Get("workflow_step_edit_id"))
+		assert.Contains(t, updates[0].Get("inputs"), "U111111")
+		assert.Contains(t, updates[0].Get("outputs"), "run_url")
+	})
+
+	t.Run("Execute Completes With Outputs", func(t *testing.T) {
+		rec := execute(`{
+			"type": "event_callback",
+			"event_id": "Ev1",
+			"event": {"type": "workflow_step_execute", "workflow_step": {
+				"workflow_step_execute_id": "X1", "workflow_id": "W1", "step_id": "S1",
+				"inputs": {"action": {"value": "verify"}, "repository": {"value": "payments"}, "configured_by": {"value": "U111111"}}
+			}}
+		}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), "Workflow step accepted")
+
+		// The step runs after the acknowledgement
+		require.Eventually(t, func() bool { return len(slackAPI.Calls("workflows.stepCompleted")) == 1 }, time.Second, 10*time.Millisecond)
+		require.Len(t, triggers, 1)
+
+		completions := slackAPI.Calls("workflows.stepCompleted")
+		assert.Equal(t, "X1", completions[0].Get("workflow_step_execute_id"))
+		assert.Contains(t, completions[0].Get("outputs"), "actions/runs/2001")
+		assert.Empty(t, slackAPI.Calls("workflows.stepFailed"))
+	})
+
+	t.Run("Execute Fails Without Permission", func(t *testing.T) {
+		rec := execute(`{
+			"type": "event_callback",
+			"event_id": "Ev2",
+			"event": {"type": "workflow_step_execute", "workflow_step": {
+				"workflow_step_execute_id": "X2", "workflow_id": "W1", "step_id": "S1",
+				"inputs": {"action": {"value": "verify"}, "repository": {"value": "payments"}, "configured_by": {"value": "U999999"}}
+			}}
+		}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		require.Eventually(t, func() bool { return len(slackAPI.Calls("workflows.stepFailed")) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Len(t, triggers, 1)
+
+		failures := slackAPI.Calls("workflows.stepFailed")
+		assert.Equal(t, "X2", failures[0].Get("workflow_step_execute_id"))
+		assert.Contains(t, failures[0].Get("error"), "not allowed")
+	})
+
+	t.Run("Missing Step Identifiers Fail Instead Of Panicking", func(t *testing.T) {
+		rec := execute(`{
+			"type": "event_callback",
+			"event": {"type": "workflow_step_execute", "workflow_step": {
+				"workflow_step_execute_id": "X3",
+				"inputs": {"repository": {"value": "payments"}}
+			}}
+		}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "workflow_id and step_id are required")
+		assert.Len(t, slackAPI.Calls("workflows.stepFailed"), 2)
+	})
+
+	t.Run("Retried Deliveries Run The Step Once", func(t *testing.T) {
+		payload := `{
+			"type": "event_callback",
+			"event_id": "Ev4",
+			"event": {"type": "workflow_step_execute", "workflow_step": {
+				"workflow_step_execute_id": "X4", "workflow_id": "W1", "step_id": "S1",
+				"inputs": {"action": {"value": "verify"}, "repository": {"value": "payments"}, "configured_by": {"value": "U111111"}}
+			}}
+		}`
+		require.Equal(t, http.StatusOK, execute(payload).Code)
+		require.Eventually(t, func() bool { return len(slackAPI.Calls("workflows.stepCompleted")) == 2 }, time.Second, 10*time.Millisecond)
+
+		// The retry is dropped before any processing starts
+		rec := execute(payload, "X-Slack-Retry-Num", "1", "X-Slack-Retry-Reason", "http_timeout")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Len(t, triggers, 2)
+		assert.Len(t, slackAPI.Calls("workflows.stepCompleted"), 2)
+	})
+}
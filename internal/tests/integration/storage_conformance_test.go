@@ -0,0 +1,908 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/ports"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/config"
+	"github.com/Tovli/chatops/internal/infrastructure/storage"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/memory"
+	storagepg "github.com/Tovli/chatops/internal/infrastructure/storage/postgres"
+	"github.com/Tovli/chatops/internal/infrastructure/storage/sqlite"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestStorageConformance runs the same checks against every storage backend, each
+// with a fresh empty storage per store
+func TestStorageConformance(t *testing.T) {
+	backends := []struct {
+		name string
+		open func(t *testing.T) ports.Storage
+	}{
+		{"memory", func(t *testing.T) ports.Storage {
+			return memory.NewStorage()
+		}},
+		{"sqlite", func(t *testing.T) ports.Storage {
+			s, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "chatops.db"))
+			require.NoError(t, err)
+			t.Cleanup(func() { s.Close() })
+			return s
+		}},
+		{"postgres", func(t *testing.T) ports.Storage {
+			if !postgresReachable() {
+				t.Skip("postgres is not reachable")
+			}
+			db, cleanup := setupTestDB(t)
+			t.Cleanup(cleanup)
+			return storagepg.NewPostgresStorage(db)
+		}},
+	}
+
+	checks := []struct {
+		name string
+		run  func(t *testing.T, s ports.Storage)
+	}{
+		{"repositories", checkRepositoryStorage},
+		{"concurrent repository updates", checkConcurrentRepositoryUpdates},
+		{"repository refresh racing an update", checkRepositoryRefreshRace},
+		{"runs", checkRunStorage},
+		{"deployments", checkDeploymentStorage},
+		{"locks", checkLockStorage},
+		{"schedules", checkScheduleStorage},
+		{"subscriptions", checkSubscriptionStorage},
+		{"workflows", checkWorkflowStorage},
+		{"outbox", checkEventOutbox},
+		{"webhooks", checkWebhookStorage},
+		{"audit", checkAuditStorage},
+		{"tokens", checkTokenStorage},
+		{"roles", checkRoleStorage},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			for _, check := range checks {
+				t.Run(check.name, func(t *testing.T) {
+					check.run(t, backend.open(t))
+				})
+			}
+		})
+	}
+}
+
+func TestStorageOpen(t *testing.T) {
+	backend, err := storage.Open(config.DatabaseConfig{Driver: storage.DriverMemory})
+	require.NoError(t, err)
+	defer backend.Close()
+
+	require.NoError(t, backend.PingContext(context.Background()))
+	held, err := backend.NewLeaderLock(1).TryAcquire(context.Background())
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	backend, err = storage.Open(config.DatabaseConfig{Driver: storage.DriverSQLite, Path: filepath.Join(t.TempDir(), "chatops.db")})
+	require.NoError(t, err)
+	defer backend.Close()
+	require.NoError(t, backend.PingContext(context.Background()))
+
+	_, err = storage.Open(config.DatabaseConfig{Driver: "mysql"})
+	assert.ErrorContains(t, err, `unknown database driver "mysql"`)
+}
+
+// postgresReachable reports whether the test Postgres server accepts connections
+func postgresReachable() bool {
+	dbURL := fmt.Sprintf("postgresql://%s:%s@%s:%s/postgres?sslmode=%s",
+		getEnvOrDefault("CHATOPS_DB_USER", "chatops"),
+		getEnvOrDefault("CHATOPS_DB_PASSWORD", "chatops"),
+		getEnvOrDefault("CHATOPS_DB_HOST", "localhost"),
+		getEnvOrDefault("CHATOPS_DB_PORT", "5432"),
+		getEnvOrDefault("CHATOPS_DB_SSLMODE", "disable"))
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return false
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return db.PingContext(ctx) == nil
+}
+
+// conformanceTime is a time every backend stores without loss
+func conformanceTime(offset time.Duration) time.Time {
+	return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC).Add(offset)
+}
+
+func assertSameTime(t *testing.T, want, got time.Time) {
+	t.Helper()
+	assert.True(t, want.Equal(got), "want %s, got %s", want, got)
+}
+
+func checkRepositoryStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetRepository(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	repo := &domain.Repository{
+		Name:          "org/api",
+		URL:           "https://github.com/org/api",
+		DefaultBranch: "main",
+		AddedBy:       "U1",
+		AddedAt:       conformanceTime(0),
+		Pipelines:     []domain.Pipeline{{Name: "ci", Path: ".github/workflows/ci.yml", IsDefault: true}},
+	}
+	require.NoError(t, s.AddRepository(ctx, repo))
+	assert.Equal(t, 1, repo.Version)
+	assert.ErrorIs(t, s.AddRepository(ctx, &domain.Repository{Name: "org/api", AddedAt: conformanceTime(0)}), domain.ErrAlreadyExists)
+	require.NoError(t, s.AddRepository(ctx, &domain.Repository{Name: "org/web", URL: "https://github.com/org/web", AddedAt: conformanceTime(time.Minute)}))
+
+	got, err := s.GetRepository(ctx, "org/api")
+	require.NoError(t, err)
+	assert.Equal(t, repo.URL, got.URL)
+	assert.Equal(t, repo.DefaultBranch, got.DefaultBranch)
+	assert.Equal(t, repo.Pipelines, got.Pipelines)
+	assert.Empty(t, got.Environments)
+	assertSameTime(t, repo.AddedAt, got.AddedAt)
+
+	assert.Equal(t, 1, got.Version)
+
+	repo.Environments = []domain.Environment{{Name: "production", Pipeline: "deploy", Ref: "main"}}
+	require.NoError(t, s.UpdateRepository(ctx, repo))
+	assert.Equal(t, 2, repo.Version)
+	updated, err := s.GetRepository(ctx, "org/api")
+	require.NoError(t, err)
+	assert.Equal(t, repo.Environments, updated.Environments)
+	assert.Equal(t, 2, updated.Version)
+	assertSameTime(t, repo.UpdatedAt, updated.UpdatedAt)
+	assert.False(t, updated.UpdatedAt.Before(got.UpdatedAt))
+
+	// Updates of a stale version conflict instead of overwriting the newer one
+	got.Environments = nil
+	assert.ErrorIs(t, s.UpdateRepository(ctx, got), domain.ErrConflict)
+	assert.ErrorIs(t, s.UpdateRepository(ctx, &domain.Repository{Name: "missing"}), domain.ErrNotFound)
+
+	// Upserts refresh the repository from its provider, keeping what users configured
+	refreshed := &domain.Repository{
+		Name:          "org/api",
+		URL:           "https://github.com/org/api",
+		DefaultBranch: "trunk",
+		AddedBy:       "U2",
+		AddedAt:       conformanceTime(time.Hour),
+		Pipelines:     []domain.Pipeline{{Name: "build", Path: ".github/workflows/build.yml"}},
+		Version:       1,
+	}
+	assert.ErrorIs(t, s.UpsertRepository(ctx, refreshed), domain.ErrConflict)
+	refreshed.Version = 2
+	require.NoError(t, s.UpsertRepository(ctx, refreshed))
+	assert.Equal(t, 3, refreshed.Version)
+	assert.Equal(t, "U1", refreshed.AddedBy)
+	assertSameTime(t, repo.AddedAt, refreshed.AddedAt)
+	assert.Equal(t, repo.Environments, refreshed.Environments)
+
+	got, err = s.GetRepository(ctx, "org/api")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/org/api", got.URL)
+	assert.Equal(t, "trunk", got.DefaultBranch)
+	assert.Equal(t, refreshed.Pipelines, got.Pipelines)
+	assert.Equal(t, 3, got.Version)
+
+	// A repository of the same name from another owner is another repository, which never
+	// overwrites the stored one
+	other := &domain.Repository{
+		Name:      "org/api",
+		URL:       "https://github.com/other-org/api",
+		AddedBy:   "U4",
+		AddedAt:   conformanceTime(2 * time.Hour),
+		Pipelines: []domain.Pipeline{{Name: "release", Path: ".github/workflows/release.yml"}},
+		Version:   3,
+	}
+	err = s.UpsertRepository(ctx, other)
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	assert.NotErrorIs(t, err, domain.ErrConflict)
+
+	got, err = s.GetRepository(ctx, "org/api")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/org/api", got.URL)
+	assert.Equal(t, refreshed.Pipelines, got.Pipelines)
+	assert.Equal(t, 3, got.Version)
+
+	added := &domain.Repository{Name: "org/cli", URL: "https://github.com/org/cli", AddedBy: "U3", AddedAt: conformanceTime(2 * time.Minute)}
+	require.NoError(t, s.UpsertRepository(ctx, added))
+	assert.Equal(t, 1, added.Version)
+	assert.Equal(t, "U3", added.AddedBy)
+
+	repos, err := s.ListRepositories(ctx)
+	require.NoError(t, err)
+	require.Len(t, repos, 3)
+	assert.Equal(t, "org/api", repos[0].Name)
+	assert.Equal(t, "org/web", repos[1].Name)
+	assert.Equal(t, "org/cli", repos[2].Name)
+}
+
+// racingStorage runs beforeSave once, between the first read of a repository and its save
+type racingStorage struct {
+	ports.Storage
+	beforeSave func()
+}
+
+func (s *racingStorage) GetRepository(ctx context.Context, name string) (*domain.Repository, error) {
+	repo, err := s.Storage.GetRepository(ctx, name)
+	if race := s.beforeSave; race != nil {
+		s.beforeSave = nil
+		race()
+	}
+	return repo, err
+}
+
+// checkRepositoryRefreshRace adds a repository again while its default pipeline changes
+// between the read and the save of the refresh, which must keep the new default
+func checkRepositoryRefreshRace(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+	pipelines := []domain.Pipeline{
+		{Name: "ci", Path: ".github/workflows/ci.yml", IsDefault: true},
+		{Name: "deploy", Path: ".github/workflows/deploy.yml"},
+	}
+	provider := &mocks.MockGitHubAdapter{
+		GetRepositoryDetailsFn: func(ctx context.Context, url string) (*domain.Repository, error) {
+			return &domain.Repository{Name: "org/api", DefaultBranch: "main", Pipelines: pipelines}, nil
+		},
+	}
+
+	racing := &racingStorage{Storage: s}
+	refresher, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:    zap.NewNop(),
+		Providers: services.NewProviderRegistry(provider),
+		Storage:   racing,
+	})
+	require.NoError(t, err)
+	updater, err := services.NewRepositoryService(services.RepositoryServiceOptions{Logger: zap.NewNop(), Storage: s})
+	require.NoError(t, err)
+
+	require.NoError(t, refresher.AddRepository(ctx, &domain.Repository{URL: "https://github.com/org/api", AddedAt: conformanceTime(0)}))
+
+	racing.beforeSave = func() {
+		require.NoError(t, updater.SetDefaultPipeline(ctx, "org/api", "deploy"))
+	}
+	repo := &domain.Repository{URL: "https://github.com/org/api", AddedAt: conformanceTime(time.Minute)}
+	require.NoError(t, refresher.AddRepository(ctx, repo))
+	assert.Equal(t, 3, repo.Version)
+
+	got, err := s.GetRepository(ctx, "org/api")
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.Version)
+	require.Len(t, got.Pipelines, 2)
+	assert.False(t, got.Pipelines[0].IsDefault)
+	assert.True(t, got.Pipelines[1].IsDefault)
+
+	// The provider names repositories without their owner: the same name at another URL is
+	// refused, while the same URL spelled differently refreshes the stored repository
+	err = refresher.AddRepository(ctx, &domain.Repository{URL: "https://github.com/other-org/api", AddedAt: conformanceTime(2 * time.Minute)})
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	repo = &domain.Repository{URL: "https://github.com/Org/api.git", AddedAt: conformanceTime(3 * time.Minute)}
+	require.NoError(t, refresher.AddRepository(ctx, repo))
+	assert.Equal(t, "https://github.com/org/api", repo.URL)
+	assert.Equal(t, 4, repo.Version)
+}
+
+// checkConcurrentRepositoryUpdates maps environments of a repository concurrently. Each
+// writer conflicts at most once per other writer, so the retries of the service keep
+// every mapping.
+func checkConcurrentRepositoryUpdates(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{Logger: zap.NewNop(), Storage: s})
+	require.NoError(t, err)
+
+	require.NoError(t, s.AddRepository(ctx, &domain.Repository{
+		Name:      "org/api",
+		URL:       "https://github.com/org/api",
+		AddedAt:   conformanceTime(0),
+		Pipelines: []domain.Pipeline{{Name: "deploy", Path: ".github/workflows/deploy.yml"}},
+	}))
+
+	const writers = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- repoService.SetEnvironment(ctx, "org/api", domain.Environment{Name: fmt.Sprintf("env-%d", i), Pipeline: "deploy"})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	repo, err := s.GetRepository(ctx, "org/api")
+	require.NoError(t, err)
+	assert.Len(t, repo.Environments, writers)
+	assert.Equal(t, writers+1, repo.Version)
+}
+
+func checkRunStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetRun(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = s.GetLatestRun(ctx, "org/api", "")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	runs := []*domain.Run{
+		{ID: "1", Provider: "github", Repository: "org/api", Pipeline: "ci", Status: domain.RunStatusSuccess, TriggeredBy: "U1", CreatedAt: conformanceTime(0)},
+		{ID: "2", Provider: "github", Repository: "org/api", Pipeline: "deploy", Environment: "production", Status: domain.RunStatusInProgress, TriggeredBy: "U2", CreatedAt: conformanceTime(time.Minute)},
+		{ID: "3", Provider: "github", Repository: "org/web", Pipeline: "ci", Status: domain.RunStatusQueued, TriggeredBy: "U1", CreatedAt: conformanceTime(2 * time.Minute)},
+	}
+	for _, run := range runs {
+		run.UpdatedAt = run.CreatedAt
+		require.NoError(t, s.AddRun(ctx, run))
+	}
+
+	got, err := s.GetRun(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, "production", got.Environment)
+	assert.Equal(t, "U2", got.TriggeredBy)
+	assertSameTime(t, runs[1].CreatedAt, got.CreatedAt)
+
+	latest, err := s.GetLatestRun(ctx, "org/api", "")
+	require.NoError(t, err)
+	assert.Equal(t, "2", latest.ID)
+	latest, err = s.GetLatestRun(ctx, "org/api", "ci")
+	require.NoError(t, err)
+	assert.Equal(t, "1", latest.ID)
+
+	active, err := s.ListActiveRuns(ctx, conformanceTime(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, runIDs(active))
+	active, err = s.ListActiveRuns(ctx, conformanceTime(90*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, runIDs(active))
+
+	moved, err := s.TransitionRunStatus(ctx, "3", domain.RunStatusQueued, domain.RunStatusCancelling)
+	require.NoError(t, err)
+	assert.True(t, moved)
+	moved, err = s.TransitionRunStatus(ctx, "3", domain.RunStatusQueued, domain.RunStatusInProgress)
+	require.NoError(t, err)
+	assert.False(t, moved)
+
+	require.NoError(t, s.UpdateRunStatus(ctx, "3", domain.RunStatusCancelled))
+	got, err = s.GetRun(ctx, "3")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusCancelled, got.Status)
+
+	userRuns, err := s.ListUserRuns(ctx, "U1", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "1"}, runIDs(userRuns))
+	userRuns, err = s.ListUserRuns(ctx, "U1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, runIDs(userRuns))
+
+	filtered, err := s.ListRuns(ctx, domain.RunFilter{Repository: "org/api", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "1"}, runIDs(filtered))
+	filtered, err = s.ListRuns(ctx, domain.RunFilter{Pipeline: "ci", TriggeredBy: "U1", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "1"}, runIDs(filtered))
+
+	pending := &domain.Run{ID: domain.PendingRunID("queue/7"), Provider: "jenkins", Repository: "org/web", Pipeline: "ci", Status: domain.RunStatusQueued, CreatedAt: conformanceTime(3 * time.Minute)}
+	require.NoError(t, s.AddRun(ctx, pending))
+	resolved, err := s.ResolvePendingRun(ctx, pending.ID, "42", "https://ci.example.com/job/ci/42/")
+	require.NoError(t, err)
+	assert.True(t, resolved)
+	resolved, err = s.ResolvePendingRun(ctx, pending.ID, "43", "https://ci.example.com/job/ci/43/")
+	require.NoError(t, err)
+	assert.False(t, resolved)
+	got, err = s.GetRun(ctx, "42")
+	require.NoError(t, err)
+	assert.Equal(t, "https://ci.example.com/job/ci/42/", got.URL)
+	assert.Equal(t, domain.RunStatusQueued, got.Status)
+}
+
+func runIDs(runs []*domain.Run) []string {
+	ids := make([]string, len(runs))
+	for i, run := range runs {
+		ids[i] = run.ID
+	}
+	return ids
+}
+
+func checkDeploymentStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	deployments := []*domain.Deployment{
+		{Repository: "org/api", Environment: "staging", Ref: "main", RunID: "1", Status: domain.DeploymentStatusSuccess, DeployedBy: "U1", CreatedAt: conformanceTime(0)},
+		{Repository: "org/api", Environment: "production", Ref: "v1", RunID: "2", Status: domain.DeploymentStatusSuccess, DeployedBy: "U1", CreatedAt: conformanceTime(time.Minute)},
+		{Repository: "org/api", Environment: "production", Ref: "v2", RunID: "3", Status: domain.DeploymentStatusQueued, DeployedBy: "U2", CreatedAt: conformanceTime(2 * time.Minute)},
+		{Repository: "org/web", Environment: "production", Ref: "main", RunID: "4", Status: domain.DeploymentStatusSuccess, DeployedBy: "U2", CreatedAt: conformanceTime(3 * time.Minute)},
+	}
+	for _, deployment := range deployments {
+		deployment.UpdatedAt = deployment.CreatedAt
+		require.NoError(t, s.AddDeployment(ctx, deployment))
+		assert.NotEmpty(t, deployment.ID)
+	}
+
+	current, err := s.ListCurrentDeployments(ctx, "org/api")
+	require.NoError(t, err)
+	refs := map[string]string{}
+	for _, deployment := range current {
+		refs[deployment.Environment] = deployment.Ref
+	}
+	assert.Equal(t, map[string]string{"staging": "main", "production": "v2"}, refs)
+
+	pending, err := s.ListPendingDeployments(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, deployments[2].ID, pending[0].ID)
+
+	require.NoError(t, s.UpdateDeploymentStatus(ctx, deployments[2].ID, domain.DeploymentStatusInProgress))
+	pending, err = s.ListPendingDeployments(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, domain.DeploymentStatusInProgress, pending[0].Status)
+
+	require.NoError(t, s.SetDeploymentRun(ctx, deployments[2].ID, "5", "https://ci.example.com/runs/5"))
+	pending, err = s.ListPendingDeployments(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "5", pending[0].RunID)
+	assert.Equal(t, "https://ci.example.com/runs/5", pending[0].URL)
+	assert.ErrorIs(t, s.SetDeploymentRun(ctx, "999999", "6", ""), domain.ErrNotFound)
+
+	require.NoError(t, s.UpdateDeploymentStatus(ctx, deployments[2].ID, domain.DeploymentStatusFailure))
+	pending, err = s.ListPendingDeployments(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func checkLockStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	lock, err := s.GetLock(ctx, "org/api", "production")
+	require.NoError(t, err)
+	assert.Nil(t, lock)
+
+	held, err := s.AcquireLock(ctx, &domain.DeploymentLock{Repository: "org/api", Environment: "production", Owner: "U1", Reason: "release", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, "U1", held.Owner)
+
+	// Another owner gets the lock in effect
+	held, err = s.AcquireLock(ctx, &domain.DeploymentLock{Repository: "org/api", Environment: "production", Owner: "U2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, "U1", held.Owner)
+	assert.Equal(t, "release", held.Reason)
+
+	// An expired lock is replaced
+	_, err = s.AcquireLock(ctx, &domain.DeploymentLock{Repository: "org/api", Environment: "staging", Owner: "U1", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	lock, err = s.GetLock(ctx, "org/api", "staging")
+	require.NoError(t, err)
+	assert.Nil(t, lock)
+	held, err = s.AcquireLock(ctx, &domain.DeploymentLock{Repository: "org/api", Environment: "staging", Owner: "U2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, "U2", held.Owner)
+
+	lock, err = s.GetLock(ctx, "org/api", "production")
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	assertSameTime(t, now.Add(time.Hour), lock.ExpiresAt)
+
+	locks, err := s.ListLocks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, locks, 2)
+
+	require.NoError(t, s.ReleaseLock(ctx, "org/api", "production"))
+	lock, err = s.GetLock(ctx, "org/api", "production")
+	require.NoError(t, err)
+	assert.Nil(t, lock)
+}
+
+func checkScheduleStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetSchedule(ctx, "404")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	schedule := &domain.Schedule{
+		Cron:        "0 9 * * 1-5",
+		Timezone:    "Europe/Paris",
+		CommandType: domain.CommandTypeVerifyRepo,
+		Parameters:  map[string]interface{}{"repository": "org/api"},
+		CommandText: "/chatops verify org/api",
+		User:        domain.User{ID: "U1", Platform: "slack"},
+		ChannelID:   "C1",
+		CreatedAt:   conformanceTime(0),
+		NextRunAt:   conformanceTime(time.Hour),
+	}
+	require.NoError(t, s.AddSchedule(ctx, schedule))
+	require.NotEmpty(t, schedule.ID)
+	require.NoError(t, s.AddSchedule(ctx, &domain.Schedule{Cron: "@daily", CommandType: domain.CommandTypeVerifyRepo, User: domain.User{ID: "U2", Platform: "slack"}, CreatedAt: conformanceTime(0), NextRunAt: conformanceTime(48 * time.Hour)}))
+
+	got, err := s.GetSchedule(ctx, schedule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, schedule.Cron, got.Cron)
+	assert.Equal(t, schedule.Parameters, got.Parameters)
+	assert.Equal(t, schedule.User.ID, got.User.ID)
+	assert.True(t, got.LastRunAt.IsZero())
+	assertSameTime(t, schedule.NextRunAt, got.NextRunAt)
+
+	due, err := s.ListDueSchedules(ctx, conformanceTime(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, schedule.ID, due[0].ID)
+
+	claimed, err := s.ClaimScheduleRun(ctx, schedule.ID, schedule.NextRunAt, conformanceTime(time.Hour), conformanceTime(25*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = s.ClaimScheduleRun(ctx, schedule.ID, schedule.NextRunAt, conformanceTime(time.Hour), conformanceTime(25*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	got, err = s.GetSchedule(ctx, schedule.ID)
+	require.NoError(t, err)
+	assertSameTime(t, conformanceTime(time.Hour), got.LastRunAt)
+	assertSameTime(t, conformanceTime(25*time.Hour), got.NextRunAt)
+
+	schedules, err := s.ListSchedules(ctx)
+	require.NoError(t, err)
+	assert.Len(t, schedules, 2)
+
+	require.NoError(t, s.DeleteSchedule(ctx, schedule.ID))
+	assert.Error(t, s.DeleteSchedule(ctx, schedule.ID))
+	_, err = s.GetSchedule(ctx, schedule.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func checkSubscriptionStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetSubscription(ctx, "404")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	api := &domain.Subscription{Platform: "slack", ChannelID: "C1", Repository: "org/api", Events: []string{"deployment"}, CreatedBy: "U1", CreatedAt: conformanceTime(0)}
+	all := &domain.Subscription{Platform: "slack", ChannelID: "C2", Repository: domain.AllRepositories, Events: []string{"*"}, CreatedBy: "U1", CreatedAt: conformanceTime(0)}
+	web := &domain.Subscription{Platform: "slack", ChannelID: "C1", Repository: "org/web", Events: []string{"workflow_run.failure"}, CreatedBy: "U2", CreatedAt: conformanceTime(0)}
+	for _, subscription := range []*domain.Subscription{api, all, web} {
+		require.NoError(t, s.AddSubscription(ctx, subscription))
+		require.NotEmpty(t, subscription.ID)
+	}
+
+	got, err := s.GetSubscription(ctx, api.ID)
+	require.NoError(t, err)
+	assert.Equal(t, api.Events, got.Events)
+
+	subscriptions, err := s.ListSubscriptions(ctx, "org/api")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{api.ID, all.ID}, subscriptionIDs(subscriptions))
+
+	subscriptions, err = s.ListChannelSubscriptions(ctx, "slack", "C1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{api.ID, web.ID}, subscriptionIDs(subscriptions))
+
+	require.NoError(t, s.DeleteSubscription(ctx, web.ID))
+	assert.Error(t, s.DeleteSubscription(ctx, web.ID))
+
+	mute, err := s.GetChannelMute(ctx, "slack", "C1")
+	require.NoError(t, err)
+	assert.Nil(t, mute)
+
+	require.NoError(t, s.MuteChannel(ctx, &domain.ChannelMute{Platform: "slack", ChannelID: "C1", Until: conformanceTime(time.Hour), MutedBy: "U1"}))
+	require.NoError(t, s.MuteChannel(ctx, &domain.ChannelMute{Platform: "slack", ChannelID: "C1", MutedBy: "U2"}))
+	mute, err = s.GetChannelMute(ctx, "slack", "C1")
+	require.NoError(t, err)
+	require.NotNil(t, mute)
+	assert.Equal(t, "U2", mute.MutedBy)
+	assert.True(t, mute.Until.IsZero())
+
+	require.NoError(t, s.UnmuteChannel(ctx, "slack", "C1"))
+	mute, err = s.GetChannelMute(ctx, "slack", "C1")
+	require.NoError(t, err)
+	assert.Nil(t, mute)
+}
+
+func subscriptionIDs(subscriptions []*domain.Subscription) []string {
+	ids := make([]string, len(subscriptions))
+	for i, subscription := range subscriptions {
+		ids[i] = subscription.ID
+	}
+	return ids
+}
+
+func checkWorkflowStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetWorkflow(ctx, "404")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	workflow := &domain.Workflow{
+		Name:       "release",
+		Path:       "config/workflows/release.yaml",
+		Parameters: map[string]interface{}{"version": "v1"},
+		Status:     domain.WorkflowStatusRunning,
+		Steps:      map[string]*domain.StepState{"build": {Status: domain.StepStatusRunning}},
+		StartedBy:  domain.User{ID: "U1", Platform: "slack"},
+		ChannelID:  "C1",
+		CreatedAt:  conformanceTime(0),
+		UpdatedAt:  conformanceTime(0),
+	}
+	require.NoError(t, s.AddWorkflow(ctx, workflow))
+	require.NotEmpty(t, workflow.ID)
+
+	got, err := s.GetWorkflow(ctx, workflow.ID)
+	require.NoError(t, err)
+	assert.Equal(t, workflow.Parameters, got.Parameters)
+	assert.Equal(t, workflow.StartedBy.ID, got.StartedBy.ID)
+	assert.Equal(t, domain.StepStatusRunning, got.Steps["build"].Status)
+	assert.Equal(t, workflow.Version, got.Version)
+	stale := got
+
+	active, err := s.ListActiveWorkflows(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+
+	workflow.Status = domain.WorkflowStatusSucceeded
+	workflow.Steps["build"].Status = domain.StepStatusSuccess
+	workflow.UpdatedAt = conformanceTime(time.Minute)
+	require.NoError(t, s.UpdateWorkflow(ctx, workflow))
+
+	got, err = s.GetWorkflow(ctx, workflow.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WorkflowStatusSucceeded, got.Status)
+	assert.Equal(t, domain.StepStatusSuccess, got.Steps["build"].Status)
+	assertSameTime(t, workflow.UpdatedAt, got.UpdatedAt)
+	assert.Equal(t, stale.Version+1, got.Version)
+	assert.Equal(t, got.Version, workflow.Version)
+
+	// Updates of a version read before another update conflict instead of overwriting it
+	stale.Status = domain.WorkflowStatusFailed
+	assert.ErrorIs(t, s.UpdateWorkflow(ctx, stale), domain.ErrConflict)
+	got, err = s.GetWorkflow(ctx, workflow.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WorkflowStatusSucceeded, got.Status)
+
+	active, err = s.ListActiveWorkflows(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	assert.ErrorIs(t, s.UpdateWorkflow(ctx, &domain.Workflow{ID: "404", Status: domain.WorkflowStatusFailed}), domain.ErrNotFound)
+}
+
+func checkEventOutbox(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	added := &domain.Event{
+		Type:       domain.EventRepositoryAdded,
+		Repository: "org/api",
+		Actor:      "U1",
+		Message:    "org/api added",
+		Payload:    domain.RepositoryAdded{Repository: &domain.Repository{Name: "org/api"}},
+		OccurredAt: conformanceTime(0),
+	}
+	require.NoError(t, s.AppendEvent(ctx, added, time.Hour))
+	require.NotEmpty(t, added.ID)
+
+	// Events claimed by their publisher are not claimed again until the lease expires
+	events, err := s.ClaimEvents(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.AppendEvent(ctx, &domain.Event{Type: domain.EventKindRun + ".success", Repository: "org/api", OccurredAt: conformanceTime(time.Duration(i) * time.Minute)}, -time.Second))
+	}
+
+	events, err = s.ClaimEvents(ctx, 2, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Less(t, events[0].ID, events[1].ID)
+	assertSameTime(t, conformanceTime(0), events[0].OccurredAt)
+
+	require.NoError(t, s.MarkEventDelivered(ctx, events[0].ID))
+
+	events, err = s.ClaimEvents(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assertSameTime(t, conformanceTime(2*time.Minute), events[0].OccurredAt)
+
+	events, err = s.ClaimEvents(ctx, 10, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func checkWebhookStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetWebhook(ctx, "404")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	webhook := &domain.Webhook{
+		URL:       "https://example.com/hook",
+		Events:    []string{"deployment"},
+		Secret:    "s3cret",
+		Headers:   map[string]string{"X-Team": "platform"},
+		Active:    true,
+		CreatedBy: "U1",
+		CreatedAt: conformanceTime(0),
+		UpdatedAt: conformanceTime(0),
+	}
+	require.NoError(t, s.AddWebhook(ctx, webhook))
+	require.NotEmpty(t, webhook.ID)
+
+	got, err := s.GetWebhook(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.Events, got.Events)
+	assert.Equal(t, webhook.Headers, got.Headers)
+	assert.True(t, got.Active)
+
+	webhook.Active = false
+	webhook.Headers = nil
+	webhook.UpdatedAt = conformanceTime(time.Minute)
+	require.NoError(t, s.UpdateWebhook(ctx, webhook))
+	got, err = s.GetWebhook(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Active)
+	assert.Empty(t, got.Headers)
+	assert.Error(t, s.UpdateWebhook(ctx, &domain.Webhook{ID: "404"}))
+
+	var deliveries []*domain.WebhookDelivery
+	for i := 0; i < 3; i++ {
+		delivery := &domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     "deployment.production",
+			Body:          `{}`,
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: conformanceTime(time.Duration(i) * time.Minute),
+			CreatedAt:     conformanceTime(0),
+		}
+		require.NoError(t, s.AddDelivery(ctx, delivery))
+		deliveries = append(deliveries, delivery)
+	}
+
+	due, err := s.ListDueDeliveries(ctx, conformanceTime(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, deliveries[0].ID, due[0].ID)
+
+	deliveries[0].Status = domain.DeliveryStatusSuccess
+	deliveries[0].Attempts = 1
+	deliveries[0].ResponseCode = 204
+	deliveries[0].DeliveredAt = conformanceTime(time.Second)
+	require.NoError(t, s.UpdateDelivery(ctx, deliveries[0]))
+
+	due, err = s.ListDueDeliveries(ctx, conformanceTime(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, deliveries[1].ID, due[0].ID)
+	assert.True(t, due[0].DeliveredAt.IsZero())
+
+	latest, err := s.ListDeliveries(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, latest, 3)
+	assert.Equal(t, deliveries[2].ID, latest[0].ID)
+	assert.Equal(t, 204, latest[2].ResponseCode)
+	assertSameTime(t, conformanceTime(time.Second), latest[2].DeliveredAt)
+
+	require.NoError(t, s.DeleteWebhook(ctx, webhook.ID))
+	assert.Error(t, s.DeleteWebhook(ctx, webhook.ID))
+	latest, err = s.ListDeliveries(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, latest)
+}
+
+func checkAuditStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	events := []*domain.AuditEvent{
+		{Type: domain.EventRepositoryAdded, Repository: "org/api", Actor: "U1", OccurredAt: conformanceTime(0), Payload: []byte(`{"Repository":null}`)},
+		{Type: "deployment.production", Repository: "org/api", Actor: "U2", OccurredAt: conformanceTime(time.Minute)},
+		{Type: "deployment.staging", Repository: "org/web", Actor: "U1", OccurredAt: conformanceTime(2 * time.Minute)},
+		{Type: "deploymentx.other", Repository: "org/web", Actor: "U1", OccurredAt: conformanceTime(3 * time.Minute)},
+	}
+	for _, event := range events {
+		require.NoError(t, s.AddAuditEvent(ctx, event))
+		require.NotEmpty(t, event.ID)
+	}
+
+	all, err := s.ListAuditEvents(ctx, domain.AuditFilter{Type: "*", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, events[3].ID, all[0].ID)
+	assert.JSONEq(t, `{"Repository":null}`, string(all[3].Payload))
+	assertSameTime(t, conformanceTime(0), all[3].OccurredAt)
+
+	deployments, err := s.ListAuditEvents(ctx, domain.AuditFilter{Type: "deployment", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{events[2].ID, events[1].ID}, auditIDs(deployments))
+
+	filtered, err := s.ListAuditEvents(ctx, domain.AuditFilter{Type: "deployment.production", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{events[1].ID}, auditIDs(filtered))
+
+	filtered, err = s.ListAuditEvents(ctx, domain.AuditFilter{Repository: "org/web", Actor: "U1", Since: conformanceTime(3 * time.Minute), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{events[3].ID}, auditIDs(filtered))
+
+	limited, err := s.ListAuditEvents(ctx, domain.AuditFilter{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{events[3].ID, events[2].ID}, auditIDs(limited))
+}
+
+func auditIDs(events []*domain.AuditEvent) []string {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func checkTokenStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetTokenByHash(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	token := &domain.APIToken{Name: "ci", UserID: "U1", Scopes: []string{"runs:read"}, Hash: "abc", CreatedBy: "U1", CreatedAt: conformanceTime(0), ExpiresAt: conformanceTime(24 * time.Hour)}
+	require.NoError(t, s.AddToken(ctx, token))
+	require.NotEmpty(t, token.ID)
+	assert.ErrorIs(t, s.AddToken(ctx, &domain.APIToken{Name: "copy", UserID: "U1", Hash: "abc", CreatedAt: conformanceTime(0)}), domain.ErrAlreadyExists)
+	require.NoError(t, s.AddToken(ctx, &domain.APIToken{Name: "bot", UserID: "U2", Scopes: []string{"*"}, Hash: "def", CreatedBy: "U2", CreatedAt: conformanceTime(0)}))
+
+	got, err := s.GetTokenByHash(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, got.ID)
+	assert.Equal(t, token.Scopes, got.Scopes)
+	assertSameTime(t, token.ExpiresAt, got.ExpiresAt)
+	assert.True(t, got.LastUsedAt.IsZero())
+	assert.True(t, got.RevokedAt.IsZero())
+
+	require.NoError(t, s.TouchToken(ctx, token.ID, conformanceTime(time.Hour)))
+	require.NoError(t, s.RevokeToken(ctx, token.ID, conformanceTime(2*time.Hour)))
+	// Revoking again keeps the first revocation time
+	require.NoError(t, s.RevokeToken(ctx, token.ID, conformanceTime(3*time.Hour)))
+	assert.ErrorIs(t, s.RevokeToken(ctx, "404", conformanceTime(0)), domain.ErrNotFound)
+
+	got, err = s.GetTokenByHash(ctx, "abc")
+	require.NoError(t, err)
+	assertSameTime(t, conformanceTime(time.Hour), got.LastUsedAt)
+	assertSameTime(t, conformanceTime(2*time.Hour), got.RevokedAt)
+
+	tokens, err := s.ListTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "ci", tokens[0].Name)
+	assert.True(t, tokens[1].ExpiresAt.IsZero())
+}
+
+func checkRoleStorage(t *testing.T, s ports.Storage) {
+	ctx := context.Background()
+
+	roles, err := s.ListRoles(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	require.NoError(t, s.SaveRole(ctx, &domain.Role{Name: "viewer", Permissions: []string{"status"}}))
+	require.NoError(t, s.SaveRole(ctx, &domain.Role{Name: "deployer", Permissions: []string{"deploy"}, Users: []string{"U2"}}))
+	require.NoError(t, s.SaveRole(ctx, &domain.Role{Name: "deployer", Permissions: []string{"deploy", "rollback"}, Users: []string{"U1", "U2"}}))
+
+	roles, err = s.ListRoles(ctx)
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+	assert.Equal(t, "deployer", roles[0].Name)
+	assert.Equal(t, []string{"deploy", "rollback"}, roles[0].Permissions)
+	assert.Equal(t, []string{"U1", "U2"}, roles[0].Users)
+	assert.Equal(t, "viewer", roles[1].Name)
+	assert.Empty(t, roles[1].Users)
+}
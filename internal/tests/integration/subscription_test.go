@@ -0,0 +1,228 @@
+package integration
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memorySubscriptionStorage keeps subscriptions and channel mutes in memory
+type memorySubscriptionStorage struct {
+	nextID        int
+	subscriptions []*domain.Subscription
+	mutes         map[string]*domain.ChannelMute
+}
+
+func (s *memorySubscriptionStorage) AddSubscription(ctx context.Context, subscription *domain.Subscription) error {
+	s.nextID++
+	subscription.ID = strconv.Itoa(s.nextID)
+	stored := *subscription
+	s.subscriptions = append(s.subscriptions, &stored)
+	return nil
+}
+
+func (s *memorySubscriptionStorage) GetSubscription(ctx context.Context, id string) (*domain.Subscription, error) {
+	for _, subscription := range s.subscriptions {
+		if subscription.ID == id {
+			return subscription, nil
+		}
+	}
+	return nil, domain.NotFound("subscription %s not found", id)
+}
+
+func (s *memorySubscriptionStorage) DeleteSubscription(ctx context.Context, id string) error {
+	for i, subscription := range s.subscriptions {
+		if subscription.ID == id {
+			s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return domain.NotFound("subscription %s not found", id)
+}
+
+func (s *memorySubscriptionStorage) ListSubscriptions(ctx context.Context, repository string) ([]*domain.Subscription, error) {
+	var subscriptions []*domain.Subscription
+	for _, subscription := range s.subscriptions {
+		if subscription.Repository == repository || subscription.Repository == domain.AllRepositories {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (s *memorySubscriptionStorage) ListChannelSubscriptions(ctx context.Context, platform, channelID string) ([]*domain.Subscription, error) {
+	var subscriptions []*domain.Subscription
+	for _, subscription := range s.subscriptions {
+		if subscription.Platform == platform && subscription.ChannelID == channelID {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (s *memorySubscriptionStorage) MuteChannel(ctx context.Context, mute *domain.ChannelMute) error {
+	if s.mutes == nil {
+		s.mutes = make(map[string]*domain.ChannelMute)
+	}
+	stored := *mute
+	s.mutes[mute.Platform+"/"+mute.ChannelID] = &stored
+	return nil
+}
+
+func (s *memorySubscriptionStorage) UnmuteChannel(ctx context.Context, platform, channelID string) error {
+	delete(s.mutes, platform+"/"+channelID)
+	return nil
+}
+
+func (s *memorySubscriptionStorage) GetChannelMute(ctx context.Context, platform, channelID string) (*domain.ChannelMute, error) {
+	return s.mutes[platform+"/"+channelID], nil
+}
+
+func TestChannelSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name: "payments",
+			URL:  "https://github.com/Tovli/payments",
+			Pipelines: []domain.Pipeline{
+				{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true},
+			},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	runStatus := "in_progress"
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			return &domain.CommandResult{
+				Status:  "success",
+				Details: map[string]interface{}{"run_id": "1001", "url": "https://github.com/Tovli/payments/actions/runs/1001"},
+			}, nil
+		},
+		GetRunStatusFn: func(ctx context.Context, url string, runID string) (*domain.WorkflowStatus, error) {
+			return &domain.WorkflowStatus{ID: runID, Status: runStatus}, nil
+		},
+	}
+	providers := services.NewProviderRegistry(github)
+
+	subscriptions := &memorySubscriptionStorage{}
+	dispatcher, err := services.NewNotificationDispatcher(services.NotificationDispatcherOptions{
+		Logger:        zap.NewNop(),
+		Subscriptions: subscriptions,
+	})
+	require.NoError(t, err)
+	notifier := &recordingNotifier{}
+	dispatcher.Register("slack", notifier)
+	bus, err := services.NewEventBus(services.EventBusOptions{Logger: zap.NewNop()})
+	require.NoError(t, err)
+	bus.Subscribe("*", dispatcher)
+
+	runs := &memoryRunStorage{}
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:        zap.NewNop(),
+		RepoService:   repoService,
+		Providers:     providers,
+		Runs:          runs,
+		Subscriptions: subscriptions,
+		Events:        bus,
+	})
+	require.NoError(t, err)
+
+	tracker, err := services.NewRunTracker(services.RunTrackerOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   providers,
+		Runs:        runs,
+		Events:      bus,
+	})
+	require.NoError(t, err)
+
+	user := domain.User{ID: "U123456", Platform: "slack"}
+	inChannel := func(channelID, commandType string, params map[string]interface{}) *domain.CommandResult {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       commandType,
+			Parameters: params,
+			User:       user,
+			Source:     domain.CommandSource{Platform: "slack", ChannelID: channelID},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Subscribe Validates Events", func(t *testing.T) {
+		result := inChannel("C-ALERTS", domain.CommandTypeSubscribe, map[string]interface{}{
+			"repository_name": "payments",
+			"events":          []string{"build.failure"},
+		})
+		assert.Equal(t, "error", result.Status)
+		assert.Contains(t, result.Message, "unknown event")
+	})
+
+	t.Run("Run Completion Reaches Subscribed Channels", func(t *testing.T) {
+		result := inChannel("C-ALERTS", domain.CommandTypeSubscribe, map[string]interface{}{
+			"repository_name": "payments",
+			"events":          []string{"workflow_run.failure"},
+		})
+		require.Equal(t, "success", result.Status, result.Message)
+		result = inChannel("C-ALL", domain.CommandTypeSubscribe, map[string]interface{}{
+			"repository_name": domain.AllRepositories,
+			"events":          []string{"workflow_run", "deployment.production"},
+		})
+		require.Equal(t, "success", result.Status, result.Message)
+
+		result = inChannel("C-DEV", domain.CommandTypeVerifyRepo, map[string]interface{}{"repository_name": "payments"})
+		require.Equal(t, "success", result.Status, result.Message)
+
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, domain.RunStatusInProgress, runs.runs[0].Status)
+		assert.Empty(t, notifier.messages)
+
+		runStatus = "failure"
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Equal(t, domain.RunStatusFailure, runs.runs[0].Status)
+		require.Len(t, notifier.messages["C-ALERTS"], 1)
+		assert.Contains(t, notifier.messages["C-ALERTS"][0], "Run 1001 of CI on payments failed")
+		assert.Len(t, notifier.messages["C-ALL"], 1)
+
+		// Finished runs are not reported twice
+		require.NoError(t, tracker.Sync(ctx))
+		assert.Len(t, notifier.messages["C-ALERTS"], 1)
+	})
+
+	t.Run("Muted Channels Are Skipped", func(t *testing.T) {
+		result := inChannel("C-ALERTS", domain.CommandTypeMuteChannel, map[string]interface{}{"duration": time.Hour})
+		require.Equal(t, "success", result.Status, result.Message)
+
+		bus.Publish(ctx, &domain.Event{Type: "workflow_run.failure", Repository: "payments", Message: "Run 1002 failed"})
+		assert.Len(t, notifier.messages["C-ALERTS"], 1)
+		assert.Len(t, notifier.messages["C-ALL"], 2)
+
+		result = inChannel("C-ALERTS", domain.CommandTypeUnmuteChannel, map[string]interface{}{})
+		require.Equal(t, "success", result.Status, result.Message)
+		bus.Publish(ctx, &domain.Event{Type: "workflow_run.failure", Repository: "payments", Message: "Run 1003 failed"})
+		assert.Len(t, notifier.messages["C-ALERTS"], 2)
+	})
+
+	t.Run("Unsubscribe Only From Own Channel", func(t *testing.T) {
+		result := inChannel("C-DEV", domain.CommandTypeUnsubscribe, map[string]interface{}{"subscription_id": "1"})
+		assert.Equal(t, "error", result.Status)
+
+		result = inChannel("C-ALERTS", domain.CommandTypeUnsubscribe, map[string]interface{}{"subscription_id": "1"})
+		require.Equal(t, "success", result.Status, result.Message)
+
+		result = inChannel("C-ALERTS", domain.CommandTypeListSubscriptions, map[string]interface{}{})
+		assert.Equal(t, "This channel has no subscriptions", result.Message)
+	})
+}
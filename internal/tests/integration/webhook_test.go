@@ -0,0 +1,311 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/adapters/rest"
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryWebhookStorage keeps webhooks and deliveries in memory
+type memoryWebhookStorage struct {
+	webhooks   []*domain.Webhook
+	deliveries []*domain.WebhookDelivery
+}
+
+func (s *memoryWebhookStorage) AddWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	webhook.ID = strconv.Itoa(len(s.webhooks) + 1)
+	stored := *webhook
+	s.webhooks = append(s.webhooks, &stored)
+	return nil
+}
+
+func (s *memoryWebhookStorage) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	for _, webhook := range s.webhooks {
+		if webhook.ID == id {
+			stored := *webhook
+			return &stored, nil
+		}
+	}
+	return nil, domain.NotFound("webhook %s not found", id)
+}
+
+func (s *memoryWebhookStorage) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	return s.webhooks, nil
+}
+
+func (s *memoryWebhookStorage) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	for i, stored := range s.webhooks {
+		if stored.ID == webhook.ID {
+			updated := *webhook
+			s.webhooks[i] = &updated
+			return nil
+		}
+	}
+	return domain.NotFound("webhook %s not found", webhook.ID)
+}
+
+func (s *memoryWebhookStorage) DeleteWebhook(ctx context.Context, id string) error {
+	for i, webhook := range s.webhooks {
+		if webhook.ID == id {
+			s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return domain.NotFound("webhook %s not found", id)
+}
+
+func (s *memoryWebhookStorage) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	delivery.ID = strconv.Itoa(len(s.deliveries) + 1)
+	stored := *delivery
+	s.deliveries = append(s.deliveries, &stored)
+	return nil
+}
+
+func (s *memoryWebhookStorage) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	for i, stored := range s.deliveries {
+		if stored.ID == delivery.ID {
+			updated := *delivery
+			s.deliveries[i] = &updated
+			return nil
+		}
+	}
+	return domain.NotFound("delivery %s not found", delivery.ID)
+}
+
+func (s *memoryWebhookStorage) ListDueDeliveries(ctx context.Context, at time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	var due []*domain.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status == domain.DeliveryStatusPending && !delivery.NextAttemptAt.After(at) && len(due) < limit {
+			stored := *delivery
+			due = append(due, &stored)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryWebhookStorage) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	for i := len(s.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if s.deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, s.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+// webhookReceiver records the requests posted to it and answers with the current status
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	w.WriteHeader(rc.status)
+}
+
+func TestOutgoingWebhooks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+
+	receiver := &webhookReceiver{status: http.StatusOK}
+	receiverServer := httptest.NewServer(receiver)
+	defer receiverServer.Close()
+
+	storage := &memoryWebhookStorage{}
+	webhooks, err := services.NewWebhookService(services.WebhookServiceOptions{
+		Logger:      zap.NewNop(),
+		Storage:     storage,
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+		Clock:       func() time.Time { return now },
+	})
+	require.NoError(t, err)
+
+	bus, err := services.NewEventBus(services.EventBusOptions{Logger: zap.NewNop()})
+	require.NoError(t, err)
+	bus.Subscribe("*", webhooks)
+
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: &recordingStorage{},
+	})
+	require.NoError(t, err)
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+	})
+	require.NoError(t, err)
+
+	adapter, err := rest.NewRESTAdapter(rest.RESTAdapterOptions{
+		Logger:    zap.NewNop(),
+		Auth:      services.NewAuthenticator(services.AuthenticatorOptions{AdminToken: "admin-token"}),
+		Processor: processor,
+		Webhooks:  webhooks,
+	})
+	require.NoError(t, err)
+	server := httptest.NewServer(router.NewRouter(&router.Config{Logger: zap.NewNop(), RESTAdapter: adapter}))
+	defer server.Close()
+
+	call := func(method, path, token string, body interface{}) *http.Response {
+		var reader io.Reader
+		if body != nil {
+			encoded, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(encoded)
+		}
+		req, err := http.NewRequest(method, server.URL+"/api/v1"+path, reader)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("Management Requires The Admin Token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, call("GET", "/webhooks", "", nil).StatusCode)
+		assert.Equal(t, http.StatusUnauthorized, call("GET", "/webhooks", "wrong", nil).StatusCode)
+		assert.Equal(t, http.StatusOK, call("GET", "/webhooks", "admin-token", nil).StatusCode)
+	})
+
+	t.Run("Create Validates The Webhook", func(t *testing.T) {
+		resp := call("POST", "/webhooks", "admin-token", map[string]interface{}{
+			"url":    "ftp://hooks.example.com",
+			"events": []string{"workflow_run"},
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = call("POST", "/webhooks", "admin-token", map[string]interface{}{
+			"url":    receiverServer.URL,
+			"events": []string{"build"},
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	var created map[string]interface{}
+	t.Run("Create Hides The Secret", func(t *testing.T) {
+		resp := call("POST", "/webhooks", "admin-token", map[string]interface{}{
+			"url":     receiverServer.URL,
+			"events":  []string{"workflow_run.triggered", "deployment.production"},
+			"secret":  "s3cret",
+			"headers": map[string]string{"X-Team": "payments"},
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, "1", created["id"])
+		assert.Equal(t, true, created["has_secret"])
+		assert.Equal(t, true, created["active"])
+		assert.NotContains(t, created, "secret")
+	})
+
+	t.Run("Matching Events Are Delivered Signed", func(t *testing.T) {
+		bus.Publish(ctx, &domain.Event{Type: domain.EventLockTaken, Repository: "payments"})
+		bus.Publish(ctx, &domain.Event{
+			Type:       domain.EventRunTriggered,
+			Repository: "payments",
+			Payload:    &domain.WorkflowTriggered{Run: &domain.Run{ID: "1001", Repository: "payments"}},
+		})
+		require.Len(t, storage.deliveries, 1)
+
+		require.NoError(t, webhooks.Tick(ctx))
+		require.Len(t, receiver.requests, 1)
+		req, body := receiver.requests[0], receiver.bodies[0]
+		assert.Equal(t, "sha256="+services.SignWebhookBody("s3cret", body), req.Header.Get(services.WebhookSignatureHeader))
+		assert.Equal(t, domain.EventRunTriggered, req.Header.Get(services.WebhookEventHeader))
+		assert.Equal(t, "1", req.Header.Get(services.WebhookDeliveryHeader))
+		assert.Equal(t, "payments", req.Header.Get("X-Team"))
+
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, domain.EventRunTriggered, payload["event"])
+		assert.Equal(t, "payments", payload["repository"])
+		assert.NotEmpty(t, payload["data"])
+
+		assert.Equal(t, domain.DeliveryStatusSuccess, storage.deliveries[0].Status)
+		assert.Equal(t, http.StatusOK, storage.deliveries[0].ResponseCode)
+	})
+
+	t.Run("Failed Deliveries Are Retried With Backoff", func(t *testing.T) {
+		receiver.status = http.StatusServiceUnavailable
+		bus.Publish(ctx, &domain.Event{Type: "deployment.production", Repository: "payments"})
+		require.Len(t, storage.deliveries, 2)
+
+		var attemptTimes []time.Duration
+		start := now
+		for i := 0; i < 10 && storage.deliveries[1].Status == domain.DeliveryStatusPending; i++ {
+			before := len(receiver.requests)
+			require.NoError(t, webhooks.Tick(ctx))
+			if len(receiver.requests) > before {
+				attemptTimes = append(attemptTimes, now.Sub(start))
+			}
+			now = now.Add(time.Minute)
+		}
+
+		// Attempts at once, after the one minute backoff and after it doubled
+		assert.Equal(t, []time.Duration{0, time.Minute, 3 * time.Minute}, attemptTimes)
+		delivery := storage.deliveries[1]
+		assert.Equal(t, domain.DeliveryStatusFailed, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseCode)
+		assert.Contains(t, delivery.Error, "status 503")
+	})
+
+	t.Run("Delivery Log", func(t *testing.T) {
+		resp := call("GET", "/webhooks/1/deliveries", "admin-token", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var deliveries []map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&deliveries))
+		require.Len(t, deliveries, 2)
+
+		var statuses []string
+		for _, delivery := range deliveries {
+			statuses = append(statuses, delivery["status"].(string))
+		}
+		sort.Strings(statuses)
+		assert.Equal(t, []string{domain.DeliveryStatusFailed, domain.DeliveryStatusSuccess}, statuses)
+		assert.Equal(t, float64(3), deliveries[0]["attempts"])
+	})
+
+	t.Run("Update Keeps The Secret And Delete", func(t *testing.T) {
+		resp := call("PUT", "/webhooks/1", "admin-token", map[string]interface{}{
+			"url":    receiverServer.URL,
+			"events": []string{"*"},
+			"active": false,
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "s3cret", storage.webhooks[0].Secret)
+		assert.False(t, storage.webhooks[0].Active)
+
+		bus.Publish(ctx, &domain.Event{Type: domain.EventRepositoryAdded, Repository: "payments"})
+		assert.Len(t, storage.deliveries, 2)
+
+		assert.Equal(t, http.StatusNoContent, call("DELETE", "/webhooks/1", "admin-token", nil).StatusCode)
+		assert.Equal(t, http.StatusNotFound, call("GET", "/webhooks/1", "admin-token", nil).StatusCode)
+	})
+}
@@ -0,0 +1,462 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tovli/chatops/internal/core/domain"
+	"github.com/Tovli/chatops/internal/core/services"
+	"github.com/Tovli/chatops/internal/infrastructure/workflows"
+	"github.com/Tovli/chatops/internal/rbac"
+	"github.com/Tovli/chatops/internal/tests/integration/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryWorkflowStorage keeps workflows as json, so every read sees only persisted state
+type memoryWorkflowStorage struct {
+	mu        sync.Mutex
+	workflows map[string][]byte
+
+	// afterList runs once active workflows were listed, before they are returned
+	afterList func()
+	// beforeUpdate runs before an update is compared with the stored version
+	beforeUpdate func()
+}
+
+func (s *memoryWorkflowStorage) AddWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.workflows == nil {
+		s.workflows = make(map[string][]byte)
+	}
+	workflow.ID = fmt.Sprint(len(s.workflows) + 1)
+	workflow.Version = 1
+	stored, err := json.Marshal(workflow)
+	if err != nil {
+		return err
+	}
+	s.workflows[workflow.ID] = stored
+	return nil
+}
+
+func (s *memoryWorkflowStorage) GetWorkflow(ctx context.Context, id string) (*domain.Workflow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.workflows[id]
+	if !ok {
+		return nil, fmt.Errorf("workflow %s not found", id)
+	}
+	var workflow domain.Workflow
+	if err := json.Unmarshal(stored, &workflow); err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+func (s *memoryWorkflowStorage) UpdateWorkflow(ctx context.Context, workflow *domain.Workflow) error {
+	if hook := s.beforeUpdate; hook != nil {
+		s.beforeUpdate = nil
+		hook()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var current domain.Workflow
+	if err := json.Unmarshal(s.workflows[workflow.ID], &current); err != nil {
+		return domain.NotFound("workflow %s not found", workflow.ID)
+	}
+	if current.Version != workflow.Version {
+		return domain.Conflict("workflow %s was updated concurrently", workflow.ID)
+	}
+
+	updated := *workflow
+	updated.Version++
+	stored, err := json.Marshal(&updated)
+	if err != nil {
+		return err
+	}
+	s.workflows[workflow.ID] = stored
+	workflow.Version = updated.Version
+	return nil
+}
+
+func (s *memoryWorkflowStorage) ListActiveWorkflows(ctx context.Context) ([]*domain.Workflow, error) {
+	s.mu.Lock()
+	count := len(s.workflows)
+	s.mu.Unlock()
+
+	var active []*domain.Workflow
+	for id := 1; id <= count; id++ {
+		workflow, err := s.GetWorkflow(ctx, fmt.Sprint(id))
+		if err != nil {
+			return nil, err
+		}
+		if workflow.Status == domain.WorkflowStatusRunning {
+			active = append(active, workflow)
+		}
+	}
+	if s.afterList != nil {
+		s.afterList()
+	}
+	return active, nil
+}
+
+const releaseWorkflow = `
+name: release
+inputs:
+  ref: main
+  version: ""
+steps:
+  - id: build
+    type: trigger_pipeline
+    repository: payments
+    pipeline: CI
+    ref: "{{ .inputs.ref }}"
+  - id: build-finished
+    type: wait_for_status
+    run: build
+  - id: sign-off
+    type: approval
+    needs: [build-finished]
+    approvers: [U222222]
+    message: "Release {{ .inputs.version }}?"
+  - id: announce
+    type: webhook
+    needs: [sign-off]
+    url: "{{ .inputs.hook }}"
+    body: '{"version": "{{ .inputs.version }}", "run": "{{ (index .steps "build").outputs.run_id }}"}'
+  - id: report-failure
+    type: post_message
+    needs: [build-finished]
+    if: '{{ eq (index .steps "build-finished").status "failure" }}'
+    message: "Build of {{ .inputs.version }} failed"
+`
+
+func TestWorkflowEngine(t *testing.T) {
+	ctx := context.Background()
+	storage := &recordingStorage{
+		added: &domain.Repository{
+			Name:          "payments",
+			URL:           "https://github.com/Tovli/payments",
+			DefaultBranch: "main",
+			Pipelines:     []domain.Pipeline{{Name: "CI", Path: ".github/workflows/ci.yml", IsDefault: true}},
+		},
+	}
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: storage,
+	})
+	require.NoError(t, err)
+
+	var triggers []*domain.WorkflowTrigger
+	runStatus := "in_progress"
+	github := &mocks.MockGitHubAdapter{
+		TriggerWorkflowFn: func(ctx context.Context, trigger *domain.WorkflowTrigger) (*domain.CommandResult, error) {
+			triggers = append(triggers, trigger)
+			return &domain.CommandResult{
+				Status:  "success",
+				Details: map[string]interface{}{"run_id": fmt.Sprint(100 + len(triggers)), "url": "https://github.com/Tovli/payments/actions/runs/1"},
+			}, nil
+		},
+		GetRunStatusFn: func(ctx context.Context, url string, runID string) (*domain.WorkflowStatus, error) {
+			return &domain.WorkflowStatus{ID: runID, Status: runStatus}, nil
+		},
+	}
+
+	access := rbac.NewService()
+	require.NoError(t, access.AddRole("developer", []string{domain.PermissionTriggerPipeline, domain.PermissionRunWorkflow, domain.PermissionApproveWorkflow}))
+	require.NoError(t, access.AssignRole("U111111", "developer"))
+	require.NoError(t, access.AssignRole("U222222", "developer"))
+
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+		Providers:   services.NewProviderRegistry(github),
+		RBAC:        access,
+	})
+	require.NoError(t, err)
+
+	var hookBodies []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		hookBodies = append(hookBodies, string(body))
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer hook.Close()
+
+	definition, err := workflows.Parse([]byte(releaseWorkflow))
+	require.NoError(t, err)
+	definition.Inputs["hook"] = hook.URL
+
+	executions := &memoryWorkflowStorage{}
+	notifier := &recordingNotifier{}
+	newEngine := func() *services.WorkflowEngine {
+		engine, err := services.NewWorkflowEngine(services.WorkflowEngineOptions{
+			Logger:      zap.NewNop(),
+			Processor:   processor,
+			Storage:     executions,
+			Definitions: []domain.WorkflowDefinition{*definition},
+			Notifier:    notifier,
+		})
+		require.NoError(t, err)
+		processor.SetWorkflows(engine)
+		return engine
+	}
+	engine := newEngine()
+
+	alice := domain.User{ID: "U111111", Platform: "slack"}
+	bob := domain.User{ID: "U222222", Platform: "slack"}
+	start := func(inputs map[string]interface{}) *domain.CommandResult {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       domain.CommandTypeStartWorkflow,
+			Parameters: map[string]interface{}{"workflow_name": "release", "inputs": inputs},
+			User:       alice,
+			Source:     domain.CommandSource{Platform: "slack", ChannelID: "C123"},
+		})
+		require.NoError(t, err)
+		return result
+	}
+	resolve := func(commandType string, user domain.User, id, step string) *domain.CommandResult {
+		result, err := processor.ProcessCommand(ctx, &domain.Command{
+			Type:       commandType,
+			Parameters: map[string]interface{}{"workflow_id": id, "step_id": step},
+			User:       user,
+		})
+		require.NoError(t, err)
+		return result
+	}
+	stepStatus := func(id, step string) string {
+		workflow, err := executions.GetWorkflow(ctx, id)
+		require.NoError(t, err)
+		return workflow.Steps[step].Status
+	}
+
+	t.Run("Inputs Are Validated", func(t *testing.T) {
+		result := start(map[string]interface{}{})
+		assert.Equal(t, "error", result.Status)
+		assert.Contains(t, result.Message, "input version")
+
+		result = start(map[string]interface{}{"version": "1.2.0", "unknown": "x"})
+		assert.Equal(t, "error", result.Status)
+		assert.Empty(t, triggers)
+	})
+
+	t.Run("Runs Until Approved", func(t *testing.T) {
+		result := start(map[string]interface{}{"version": "1.2.0", "ref": "release/1.2"})
+		require.Equal(t, "success", result.Status, result.Message)
+		assert.Contains(t, result.Message, "#1")
+		require.Len(t, triggers, 1)
+		assert.Equal(t, "release/1.2", triggers[0].Ref)
+		assert.Equal(t, domain.StepStatusWaiting, stepStatus("1", "build-finished"))
+
+		// The build finishes; the workflow then waits for sign-off
+		runStatus = "success"
+		require.NoError(t, engine.Tick(ctx))
+		assert.Equal(t, domain.StepStatusSkipped, stepStatus("1", "report-failure"))
+		assert.Equal(t, domain.StepStatusWaiting, stepStatus("1", "sign-off"))
+		require.NotEmpty(t, notifier.messages["C123"])
+		assert.Contains(t, notifier.messages["C123"][0], "Release 1.2.0?")
+
+		result = resolve(domain.CommandTypeApproveStep, alice, "1", "sign-off")
+		assert.Equal(t, "error", result.Status)
+		assert.Contains(t, result.Message, "only U222222")
+
+		result = resolve(domain.CommandTypeApproveStep, bob, "1", "sign-off")
+		require.Equal(t, "success", result.Status, result.Message)
+
+		status, err := engine.GetWorkflowStatus(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.WorkflowStatusSucceeded, status.Status)
+		assert.Equal(t, 100, status.Progress)
+		require.Len(t, hookBodies, 1)
+		assert.JSONEq(t, `{"version": "1.2.0", "run": "101"}`, hookBodies[0])
+	})
+
+	t.Run("Conditions On Failures", func(t *testing.T) {
+		runStatus = "failure"
+		result := start(map[string]interface{}{"version": "1.3.0"})
+		require.Equal(t, "success", result.Status, result.Message)
+
+		workflow, err := executions.GetWorkflow(ctx, "2")
+		require.NoError(t, err)
+		assert.Equal(t, domain.WorkflowStatusFailed, workflow.Status)
+		assert.Equal(t, domain.StepStatusFailure, workflow.Steps["build-finished"].Status)
+		assert.Equal(t, domain.StepStatusSkipped, workflow.Steps["sign-off"].Status)
+		assert.Equal(t, domain.StepStatusSkipped, workflow.Steps["announce"].Status)
+		assert.Equal(t, domain.StepStatusSuccess, workflow.Steps["report-failure"].Status)
+		assert.Contains(t, notifier.messages["C123"], "Build of 1.3.0 failed")
+	})
+
+	t.Run("Resumes After Restart", func(t *testing.T) {
+		runStatus = "in_progress"
+		result := start(map[string]interface{}{"version": "1.4.0"})
+		require.Equal(t, "success", result.Status, result.Message)
+		require.Equal(t, domain.StepStatusWaiting, stepStatus("3", "build-finished"))
+
+		// A fresh engine picks up the waiting step from storage
+		engine = newEngine()
+		runStatus = "success"
+		require.NoError(t, engine.Tick(ctx))
+		assert.Equal(t, domain.StepStatusWaiting, stepStatus("3", "sign-off"))
+
+		result = resolve(domain.CommandTypeRejectStep, bob, "3", "sign-off")
+		require.Equal(t, "success", result.Status, result.Message)
+
+		workflow, err := executions.GetWorkflow(ctx, "3")
+		require.NoError(t, err)
+		assert.Equal(t, domain.WorkflowStatusFailed, workflow.Status)
+		assert.Equal(t, "rejected by U222222", workflow.Steps["sign-off"].Error)
+		assert.Len(t, hookBodies, 1)
+	})
+
+	t.Run("Interrupted Steps Fail", func(t *testing.T) {
+		running := func(startedAt time.Time) *domain.Workflow {
+			workflow := &domain.Workflow{
+				Name:      "release",
+				Status:    domain.WorkflowStatusRunning,
+				StartedBy: alice,
+				Steps: map[string]*domain.StepState{
+					"build":          {Status: domain.StepStatusRunning, StartedAt: startedAt},
+					"build-finished": {Status: domain.StepStatusPending},
+					"sign-off":       {Status: domain.StepStatusPending},
+					"announce":       {Status: domain.StepStatusPending},
+					"report-failure": {Status: domain.StepStatusPending},
+				},
+			}
+			require.NoError(t, executions.AddWorkflow(ctx, workflow))
+			return workflow
+		}
+		// Another replica is running the step right now
+		active := running(time.Now())
+		interrupted := running(time.Now().Add(-time.Hour))
+		before := len(triggers)
+
+		require.NoError(t, engine.Tick(ctx))
+		assert.Equal(t, domain.StepStatusRunning, stepStatus(active.ID, "build"))
+		workflow, err := executions.GetWorkflow(ctx, interrupted.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.StepStatusFailure, workflow.Steps["build"].Status)
+		assert.Equal(t, "interrupted before completion", workflow.Steps["build"].Error)
+		assert.Equal(t, domain.WorkflowStatusFailed, workflow.Status)
+		assert.Len(t, triggers, before)
+	})
+
+	t.Run("Steps Finished After Listing Run Once", func(t *testing.T) {
+		runStatus = "in_progress"
+		result := start(map[string]interface{}{"version": "1.5.0"})
+		require.Equal(t, "success", result.Status, result.Message)
+		workflowID := result.Details.(*domain.Workflow).ID
+		require.Equal(t, domain.StepStatusWaiting, stepStatus(workflowID, "build-finished"))
+
+		// Another replica finishes the build and requests the sign-off while this tick
+		// holds a listing taken before
+		runStatus = "success"
+		replica := newEngine()
+		executions.afterList = func() {
+			executions.afterList = nil
+			require.NoError(t, replica.Tick(ctx))
+		}
+		require.NoError(t, engine.Tick(ctx))
+
+		assert.Equal(t, domain.StepStatusWaiting, stepStatus(workflowID, "sign-off"))
+		requests := 0
+		for _, message := range notifier.messages["C123"] {
+			if strings.Contains(message, "Release 1.5.0?") {
+				requests++
+			}
+		}
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("Conflicting Updates Are Retried", func(t *testing.T) {
+		runStatus = "in_progress"
+		result := start(map[string]interface{}{"version": "1.6.0"})
+		require.Equal(t, "success", result.Status, result.Message)
+		workflowID := result.Details.(*domain.Workflow).ID
+		builds := len(triggers)
+
+		// Another replica updates the workflow between this tick's read and write
+		runStatus = "success"
+		executions.beforeUpdate = func() {
+			other, err := executions.GetWorkflow(ctx, workflowID)
+			require.NoError(t, err)
+			require.NoError(t, executions.UpdateWorkflow(ctx, other))
+		}
+		require.NoError(t, engine.Tick(ctx))
+		assert.Nil(t, executions.beforeUpdate)
+
+		assert.Equal(t, domain.StepStatusSuccess, stepStatus(workflowID, "build-finished"))
+		assert.Equal(t, domain.StepStatusWaiting, stepStatus(workflowID, "sign-off"))
+		assert.Len(t, triggers, builds)
+	})
+}
+
+func TestWorkflowDefinitions(t *testing.T) {
+	repoService, err := services.NewRepositoryService(services.RepositoryServiceOptions{
+		Logger:  zap.NewNop(),
+		Storage: &recordingStorage{},
+	})
+	require.NoError(t, err)
+	processor, err := services.NewCommandProcessor(services.CommandProcessorOptions{
+		Logger:      zap.NewNop(),
+		RepoService: repoService,
+	})
+	require.NoError(t, err)
+
+	newEngine := func(yaml string) error {
+		definition, err := workflows.Parse([]byte(yaml))
+		require.NoError(t, err)
+		_, err = services.NewWorkflowEngine(services.WorkflowEngineOptions{
+			Logger:      zap.NewNop(),
+			Processor:   processor,
+			Storage:     &memoryWorkflowStorage{},
+			Definitions: []domain.WorkflowDefinition{*definition},
+		})
+		return err
+	}
+
+	assert.NoError(t, newEngine(releaseWorkflow))
+
+	err = newEngine(`
+name: cycle
+steps:
+  - {id: a, type: post_message, message: a, needs: [b]}
+  - {id: b, type: post_message, message: b, needs: [a]}
+`)
+	assert.ErrorContains(t, err, "depends on itself")
+
+	err = newEngine(`
+name: unknown
+steps:
+  - {id: a, type: post_message, message: a, needs: [missing]}
+`)
+	assert.ErrorContains(t, err, "unknown step missing")
+
+	err = newEngine(`
+name: wait
+steps:
+  - {id: a, type: post_message, message: a}
+  - {id: b, type: wait_for_status, run: a}
+`)
+	assert.ErrorContains(t, err, "run must name")
+
+	_, err = workflows.Parse([]byte(`
+name: timeout
+steps:
+  - {id: a, type: approval, timeout: soon}
+`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS runs (
+    id SERIAL PRIMARY KEY,
+    run_id VARCHAR(255) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    repository VARCHAR(255) NOT NULL,
+    pipeline VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    triggered_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_runs_run_id ON runs(run_id);
+CREATE INDEX idx_runs_repository_pipeline ON runs(repository, pipeline, created_at DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS deployments;
+
+ALTER TABLE repositories DROP COLUMN IF EXISTS environments;
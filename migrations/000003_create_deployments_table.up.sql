@@ -0,0 +1,18 @@
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS environments JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS deployments (
+    id SERIAL PRIMARY KEY,
+    provider_deployment_id VARCHAR(255) NOT NULL DEFAULT '',
+    repository VARCHAR(255) NOT NULL,
+    environment VARCHAR(255) NOT NULL,
+    ref VARCHAR(255) NOT NULL,
+    run_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(50) NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    deployed_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_deployments_repository_environment ON deployments(repository, environment, created_at DESC);
+CREATE INDEX idx_deployments_status ON deployments(status);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS deployment_locks;
+
+ALTER TABLE runs DROP COLUMN IF EXISTS environment;
@@ -0,0 +1,12 @@
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS environment VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS deployment_locks (
+    id SERIAL PRIMARY KEY,
+    repository VARCHAR(255) NOT NULL,
+    environment VARCHAR(255) NOT NULL,
+    owner VARCHAR(100) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    UNIQUE (repository, environment)
+);
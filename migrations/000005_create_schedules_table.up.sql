@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS schedules (
+    id SERIAL PRIMARY KEY,
+    cron VARCHAR(100) NOT NULL,
+    timezone VARCHAR(100) NOT NULL DEFAULT '',
+    command_type VARCHAR(50) NOT NULL,
+    parameters JSONB NOT NULL DEFAULT '{}',
+    command_text TEXT NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    platform VARCHAR(50) NOT NULL,
+    channel_id VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_run_at TIMESTAMP,
+    next_run_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_schedules_next_run_at ON schedules(next_run_at);
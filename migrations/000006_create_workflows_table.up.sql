@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS workflows (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    path TEXT NOT NULL DEFAULT '',
+    parameters JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(50) NOT NULL,
+    steps JSONB NOT NULL DEFAULT '{}',
+    user_id VARCHAR(100) NOT NULL,
+    platform VARCHAR(50) NOT NULL,
+    channel_id VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_workflows_status ON workflows(status);
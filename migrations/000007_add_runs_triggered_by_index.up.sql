@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_runs_triggered_by ON runs(triggered_by, created_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS channel_mutes;
+DROP TABLE IF EXISTS subscriptions;
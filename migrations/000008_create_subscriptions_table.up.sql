@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id SERIAL PRIMARY KEY,
+    platform VARCHAR(50) NOT NULL,
+    channel_id VARCHAR(100) NOT NULL,
+    repository VARCHAR(255) NOT NULL,
+    events JSONB NOT NULL DEFAULT '[]',
+    created_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscriptions_repository ON subscriptions(repository);
+CREATE INDEX idx_subscriptions_channel ON subscriptions(platform, channel_id);
+
+CREATE TABLE IF NOT EXISTS channel_mutes (
+    platform VARCHAR(50) NOT NULL,
+    channel_id VARCHAR(100) NOT NULL,
+    muted_until TIMESTAMP,
+    muted_by VARCHAR(100) NOT NULL,
+    PRIMARY KEY (platform, channel_id)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(255) NOT NULL,
+    repository VARCHAR(255) NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    payload JSONB,
+    occurred_at TIMESTAMP NOT NULL,
+    claimed_until TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX idx_event_outbox_undelivered ON event_outbox(claimed_until) WHERE delivered_at IS NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(255) NOT NULL,
+    repository VARCHAR(255) NOT NULL DEFAULT '',
+    actor VARCHAR(100) NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    payload JSONB,
+    occurred_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_audit_events_occurred_at ON audit_events(occurred_at DESC);
+CREATE INDEX idx_audit_events_repository ON audit_events(repository, occurred_at DESC);
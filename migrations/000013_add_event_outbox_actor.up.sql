@@ -0,0 +1 @@
+ALTER TABLE event_outbox ADD COLUMN IF NOT EXISTS actor VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS role_assignments;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(100) PRIMARY KEY,
+    permissions JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_assignments (
+    user_id VARCHAR(100) NOT NULL,
+    role VARCHAR(100) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role)
+);
@@ -0,0 +1,16 @@
+// Package migrations embeds the SQL schema migrations, applied in version order by
+// postgres.Migrator and sqlite.Open
+package migrations
+
+import "embed"
+
+// FS holds the NNNNNN_name.up.sql and NNNNNN_name.down.sql migration files
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SQLiteFS holds the sqlite/NNNNNN_name.sql migrations of the SQLite schema, which only
+// migrate up
+//
+//go:embed sqlite/*.sql
+var SQLiteFS embed.FS
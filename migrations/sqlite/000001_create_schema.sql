@@ -0,0 +1,197 @@
+-- SQLite version of the Postgres schema up to 000013. JSONB columns are TEXT holding
+-- JSON, and timestamps are TEXT in UTC with a fixed nine digit fraction so they sort.
+CREATE TABLE repositories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    default_branch TEXT NOT NULL,
+    added_by TEXT NOT NULL,
+    added_at TEXT NOT NULL,
+    pipelines TEXT NOT NULL DEFAULT '[]',
+    environments TEXT NOT NULL DEFAULT '[]',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE TABLE runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    pipeline TEXT NOT NULL,
+    environment TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL,
+    status TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    triggered_by TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_runs_run_id ON runs(run_id);
+CREATE INDEX idx_runs_repository_pipeline ON runs(repository, pipeline, created_at DESC);
+CREATE INDEX idx_runs_triggered_by ON runs(triggered_by, created_at DESC);
+
+CREATE TABLE deployments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider_deployment_id TEXT NOT NULL DEFAULT '',
+    repository TEXT NOT NULL,
+    environment TEXT NOT NULL,
+    ref TEXT NOT NULL,
+    run_id TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    deployed_by TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_deployments_repository_environment ON deployments(repository, environment, created_at DESC);
+CREATE INDEX idx_deployments_status ON deployments(status);
+
+CREATE TABLE deployment_locks (
+    repository TEXT NOT NULL,
+    environment TEXT NOT NULL,
+    owner TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL,
+    PRIMARY KEY (repository, environment)
+);
+
+CREATE TABLE schedules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cron TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT '',
+    command_type TEXT NOT NULL,
+    parameters TEXT NOT NULL DEFAULT '{}',
+    command_text TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    platform TEXT NOT NULL,
+    channel_id TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    last_run_at TEXT,
+    next_run_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_schedules_next_run_at ON schedules(next_run_at);
+
+CREATE TABLE workflows (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    path TEXT NOT NULL DEFAULT '',
+    parameters TEXT NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL,
+    steps TEXT NOT NULL DEFAULT '{}',
+    user_id TEXT NOT NULL,
+    platform TEXT NOT NULL,
+    channel_id TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_workflows_status ON workflows(status);
+
+CREATE TABLE subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    platform TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '[]',
+    created_by TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_subscriptions_repository ON subscriptions(repository);
+CREATE INDEX idx_subscriptions_channel ON subscriptions(platform, channel_id);
+
+CREATE TABLE channel_mutes (
+    platform TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    muted_until TEXT,
+    muted_by TEXT NOT NULL,
+    PRIMARY KEY (platform, channel_id)
+);
+
+CREATE TABLE event_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    repository TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    payload TEXT,
+    occurred_at TEXT NOT NULL,
+    claimed_until TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    delivered_at TEXT
+);
+
+CREATE INDEX idx_event_outbox_undelivered ON event_outbox(claimed_until) WHERE delivered_at IS NULL;
+
+CREATE TABLE webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '[]',
+    secret TEXT NOT NULL DEFAULT '',
+    headers TEXT NOT NULL DEFAULT '{}',
+    active INTEGER NOT NULL DEFAULT 1,
+    created_by TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL DEFAULT '',
+    event_type TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    delivered_at TEXT
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id);
+
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    repository TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    payload BLOB,
+    occurred_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_audit_events_occurred_at ON audit_events(occurred_at DESC);
+CREATE INDEX idx_audit_events_repository ON audit_events(repository, occurred_at DESC);
+
+CREATE TABLE api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '[]',
+    hash TEXT NOT NULL UNIQUE,
+    created_by TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    expires_at TEXT,
+    last_used_at TEXT,
+    revoked_at TEXT
+);
+
+CREATE TABLE roles (
+    name TEXT PRIMARY KEY,
+    permissions TEXT NOT NULL DEFAULT '[]',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE TABLE role_assignments (
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role)
+);
@@ -0,0 +1,2 @@
+-- SQLite version of the Postgres migration 000014
+ALTER TABLE repositories ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- SQLite version of the Postgres migration 000016
+ALTER TABLE workflows ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,836 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: chatops/v1/chatops.proto
+
+package chatopsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ProcessCommandRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Command type, such as verify_repository or deploy
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Command parameters, such as repository_name and pipeline_name
+	Parameters *structpb.Struct `protobuf:"bytes,2,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	// Optional channel notifications of the command are posted to
+	ChannelId     string `protobuf:"bytes,3,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessCommandRequest) Reset() {
+	*x = ProcessCommandRequest{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessCommandRequest) ProtoMessage() {}
+
+func (x *ProcessCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessCommandRequest.ProtoReflect.Descriptor instead.
+func (*ProcessCommandRequest) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProcessCommandRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ProcessCommandRequest) GetParameters() *structpb.Struct {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *ProcessCommandRequest) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+type ProcessCommandResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// success, error or a command specific status. Denied and invalid commands report
+	// error rather than failing the call.
+	Status  string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Command specific details
+	Details       *structpb.Value `protobuf:"bytes,3,opt,name=details,proto3" json:"details,omitempty"`
+	Error         string          `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessCommandResponse) Reset() {
+	*x = ProcessCommandResponse{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessCommandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessCommandResponse) ProtoMessage() {}
+
+func (x *ProcessCommandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessCommandResponse.ProtoReflect.Descriptor instead.
+func (*ProcessCommandResponse) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProcessCommandResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ProcessCommandResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ProcessCommandResponse) GetDetails() *structpb.Value {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *ProcessCommandResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListRepositoriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRepositoriesRequest) Reset() {
+	*x = ListRepositoriesRequest{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRepositoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRepositoriesRequest) ProtoMessage() {}
+
+func (x *ListRepositoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRepositoriesRequest.ProtoReflect.Descriptor instead.
+func (*ListRepositoriesRequest) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{2}
+}
+
+type ListRepositoriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Repositories  []*Repository          `protobuf:"bytes,1,rep,name=repositories,proto3" json:"repositories,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRepositoriesResponse) Reset() {
+	*x = ListRepositoriesResponse{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRepositoriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRepositoriesResponse) ProtoMessage() {}
+
+func (x *ListRepositoriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRepositoriesResponse.ProtoReflect.Descriptor instead.
+func (*ListRepositoriesResponse) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListRepositoriesResponse) GetRepositories() []*Repository {
+	if x != nil {
+		return x.Repositories
+	}
+	return nil
+}
+
+type Repository struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	DefaultBranch string                 `protobuf:"bytes,3,opt,name=default_branch,json=defaultBranch,proto3" json:"default_branch,omitempty"`
+	AddedBy       string                 `protobuf:"bytes,4,opt,name=added_by,json=addedBy,proto3" json:"added_by,omitempty"`
+	AddedAt       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=added_at,json=addedAt,proto3" json:"added_at,omitempty"`
+	Pipelines     []*Pipeline            `protobuf:"bytes,6,rep,name=pipelines,proto3" json:"pipelines,omitempty"`
+	Environments  []*Environment         `protobuf:"bytes,7,rep,name=environments,proto3" json:"environments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Repository) Reset() {
+	*x = Repository{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Repository) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Repository) ProtoMessage() {}
+
+func (x *Repository) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Repository.ProtoReflect.Descriptor instead.
+func (*Repository) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Repository) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Repository) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Repository) GetDefaultBranch() string {
+	if x != nil {
+		return x.DefaultBranch
+	}
+	return ""
+}
+
+func (x *Repository) GetAddedBy() string {
+	if x != nil {
+		return x.AddedBy
+	}
+	return ""
+}
+
+func (x *Repository) GetAddedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AddedAt
+	}
+	return nil
+}
+
+func (x *Repository) GetPipelines() []*Pipeline {
+	if x != nil {
+		return x.Pipelines
+	}
+	return nil
+}
+
+func (x *Repository) GetEnvironments() []*Environment {
+	if x != nil {
+		return x.Environments
+	}
+	return nil
+}
+
+type Pipeline struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Path of the workflow file
+	Path          string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	IsDefault     bool   `protobuf:"varint,3,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pipeline) Reset() {
+	*x = Pipeline{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pipeline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pipeline) ProtoMessage() {}
+
+func (x *Pipeline) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pipeline.ProtoReflect.Descriptor instead.
+func (*Pipeline) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Pipeline) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Pipeline) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Pipeline) GetIsDefault() bool {
+	if x != nil {
+		return x.IsDefault
+	}
+	return false
+}
+
+type Environment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Pipeline      string                 `protobuf:"bytes,2,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	Ref           string                 `protobuf:"bytes,3,opt,name=ref,proto3" json:"ref,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Environment) Reset() {
+	*x = Environment{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Environment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Environment) ProtoMessage() {}
+
+func (x *Environment) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Environment.ProtoReflect.Descriptor instead.
+func (*Environment) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Environment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Environment) GetPipeline() string {
+	if x != nil {
+		return x.Pipeline
+	}
+	return ""
+}
+
+func (x *Environment) GetRef() string {
+	if x != nil {
+		return x.Ref
+	}
+	return ""
+}
+
+type WatchRunsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional run to watch, every run when empty
+	RunId string `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	// Optional repository the watched runs belong to
+	Repository    string `protobuf:"bytes,2,opt,name=repository,proto3" json:"repository,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRunsRequest) Reset() {
+	*x = WatchRunsRequest{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRunsRequest) ProtoMessage() {}
+
+func (x *WatchRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRunsRequest.ProtoReflect.Descriptor instead.
+func (*WatchRunsRequest) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchRunsRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *WatchRunsRequest) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+type RunUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Run   *Run                   `protobuf:"bytes,1,opt,name=run,proto3" json:"run,omitempty"`
+	// Status before the change, empty for the first update of a run
+	PreviousStatus string `protobuf:"bytes,2,opt,name=previous_status,json=previousStatus,proto3" json:"previous_status,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RunUpdate) Reset() {
+	*x = RunUpdate{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunUpdate) ProtoMessage() {}
+
+func (x *RunUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunUpdate.ProtoReflect.Descriptor instead.
+func (*RunUpdate) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RunUpdate) GetRun() *Run {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+func (x *RunUpdate) GetPreviousStatus() string {
+	if x != nil {
+		return x.PreviousStatus
+	}
+	return ""
+}
+
+type Run struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Provider    string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	Repository  string                 `protobuf:"bytes,3,opt,name=repository,proto3" json:"repository,omitempty"`
+	Pipeline    string                 `protobuf:"bytes,4,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	Environment string                 `protobuf:"bytes,5,opt,name=environment,proto3" json:"environment,omitempty"`
+	Type        string                 `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"`
+	Status      string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	Url         string                 `protobuf:"bytes,8,opt,name=url,proto3" json:"url,omitempty"`
+	TriggeredBy string                 `protobuf:"bytes,9,opt,name=triggered_by,json=triggeredBy,proto3" json:"triggered_by,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Whether the run reached a final status
+	Finished      bool `protobuf:"varint,12,opt,name=finished,proto3" json:"finished,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Run) Reset() {
+	*x = Run{}
+	mi := &file_chatops_v1_chatops_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Run) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Run) ProtoMessage() {}
+
+func (x *Run) ProtoReflect() protoreflect.Message {
+	mi := &file_chatops_v1_chatops_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Run.ProtoReflect.Descriptor instead.
+func (*Run) Descriptor() ([]byte, []int) {
+	return file_chatops_v1_chatops_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Run) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Run) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Run) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *Run) GetPipeline() string {
+	if x != nil {
+		return x.Pipeline
+	}
+	return ""
+}
+
+func (x *Run) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *Run) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Run) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Run) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Run) GetTriggeredBy() string {
+	if x != nil {
+		return x.TriggeredBy
+	}
+	return ""
+}
+
+func (x *Run) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Run) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Run) GetFinished() bool {
+	if x != nil {
+		return x.Finished
+	}
+	return false
+}
+
+var File_chatops_v1_chatops_proto protoreflect.FileDescriptor
+
+const file_chatops_v1_chatops_proto_rawDesc = "" +
+	"\n" +
+	"\x18chatops/v1/chatops.proto\x12\n" +
+	"chatops.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x83\x01\n" +
+	"\x15ProcessCommandRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x127\n" +
+	"\n" +
+	"parameters\x18\x02 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"parameters\x12\x1d\n" +
+	"\n" +
+	"channel_id\x18\x03 \x01(\tR\tchannelId\"\x92\x01\n" +
+	"\x16ProcessCommandResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\adetails\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\adetails\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x19\n" +
+	"\x17ListRepositoriesRequest\"V\n" +
+	"\x18ListRepositoriesResponse\x12:\n" +
+	"\frepositories\x18\x01 \x03(\v2\x16.chatops.v1.RepositoryR\frepositories\"\x9c\x02\n" +
+	"\n" +
+	"Repository\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12%\n" +
+	"\x0edefault_branch\x18\x03 \x01(\tR\rdefaultBranch\x12\x19\n" +
+	"\badded_by\x18\x04 \x01(\tR\aaddedBy\x125\n" +
+	"\badded_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aaddedAt\x122\n" +
+	"\tpipelines\x18\x06 \x03(\v2\x14.chatops.v1.PipelineR\tpipelines\x12;\n" +
+	"\fenvironments\x18\a \x03(\v2\x17.chatops.v1.EnvironmentR\fenvironments\"Q\n" +
+	"\bPipeline\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"is_default\x18\x03 \x01(\bR\tisDefault\"O\n" +
+	"\vEnvironment\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bpipeline\x18\x02 \x01(\tR\bpipeline\x12\x10\n" +
+	"\x03ref\x18\x03 \x01(\tR\x03ref\"I\n" +
+	"\x10WatchRunsRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x02 \x01(\tR\n" +
+	"repository\"W\n" +
+	"\tRunUpdate\x12!\n" +
+	"\x03run\x18\x01 \x01(\v2\x0f.chatops.v1.RunR\x03run\x12'\n" +
+	"\x0fprevious_status\x18\x02 \x01(\tR\x0epreviousStatus\"\x82\x03\n" +
+	"\x03Run\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x03 \x01(\tR\n" +
+	"repository\x12\x1a\n" +
+	"\bpipeline\x18\x04 \x01(\tR\bpipeline\x12 \n" +
+	"\venvironment\x18\x05 \x01(\tR\venvironment\x12\x12\n" +
+	"\x04type\x18\x06 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x10\n" +
+	"\x03url\x18\b \x01(\tR\x03url\x12!\n" +
+	"\ftriggered_by\x18\t \x01(\tR\vtriggeredBy\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1a\n" +
+	"\bfinished\x18\f \x01(\bR\bfinished2\x8c\x02\n" +
+	"\x0eChatOpsService\x12W\n" +
+	"\x0eProcessCommand\x12!.chatops.v1.ProcessCommandRequest\x1a\".chatops.v1.ProcessCommandResponse\x12]\n" +
+	"\x10ListRepositories\x12#.chatops.v1.ListRepositoriesRequest\x1a$.chatops.v1.ListRepositoriesResponse\x12B\n" +
+	"\tWatchRuns\x12\x1c.chatops.v1.WatchRunsRequest\x1a\x15.chatops.v1.RunUpdate0\x01B2Z0github.com/Tovli/chatops/pkg/chatopsv1;chatopsv1b\x06proto3"
+
+var (
+	file_chatops_v1_chatops_proto_rawDescOnce sync.Once
+	file_chatops_v1_chatops_proto_rawDescData []byte
+)
+
+func file_chatops_v1_chatops_proto_rawDescGZIP() []byte {
+	file_chatops_v1_chatops_proto_rawDescOnce.Do(func() {
+		file_chatops_v1_chatops_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_chatops_v1_chatops_proto_rawDesc), len(file_chatops_v1_chatops_proto_rawDesc)))
+	})
+	return file_chatops_v1_chatops_proto_rawDescData
+}
+
+var file_chatops_v1_chatops_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_chatops_v1_chatops_proto_goTypes = []any{
+	(*ProcessCommandRequest)(nil),    // 0: chatops.v1.ProcessCommandRequest
+	(*ProcessCommandResponse)(nil),   // 1: chatops.v1.ProcessCommandResponse
+	(*ListRepositoriesRequest)(nil),  // 2: chatops.v1.ListRepositoriesRequest
+	(*ListRepositoriesResponse)(nil), // 3: chatops.v1.ListRepositoriesResponse
+	(*Repository)(nil),               // 4: chatops.v1.Repository
+	(*Pipeline)(nil),                 // 5: chatops.v1.Pipeline
+	(*Environment)(nil),              // 6: chatops.v1.Environment
+	(*WatchRunsRequest)(nil),         // 7: chatops.v1.WatchRunsRequest
+	(*RunUpdate)(nil),                // 8: chatops.v1.RunUpdate
+	(*Run)(nil),                      // 9: chatops.v1.Run
+	(*structpb.Struct)(nil),          // 10: google.protobuf.Struct
+	(*structpb.Value)(nil),           // 11: google.protobuf.Value
+	(*timestamppb.Timestamp)(nil),    // 12: google.protobuf.Timestamp
+}
+var file_chatops_v1_chatops_proto_depIdxs = []int32{
+	10, // 0: chatops.v1.ProcessCommandRequest.parameters:type_name -> google.protobuf.Struct
+	11, // 1: chatops.v1.ProcessCommandResponse.details:type_name -> google.protobuf.Value
+	4,  // 2: chatops.v1.ListRepositoriesResponse.repositories:type_name -> chatops.v1.Repository
+	12, // 3: chatops.v1.Repository.added_at:type_name -> google.protobuf.Timestamp
+	5,  // 4: chatops.v1.Repository.pipelines:type_name -> chatops.v1.Pipeline
+	6,  // 5: chatops.v1.Repository.environments:type_name -> chatops.v1.Environment
+	9,  // 6: chatops.v1.RunUpdate.run:type_name -> chatops.v1.Run
+	12, // 7: chatops.v1.Run.created_at:type_name -> google.protobuf.Timestamp
+	12, // 8: chatops.v1.Run.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 9: chatops.v1.ChatOpsService.ProcessCommand:input_type -> chatops.v1.ProcessCommandRequest
+	2,  // 10: chatops.v1.ChatOpsService.ListRepositories:input_type -> chatops.v1.ListRepositoriesRequest
+	7,  // 11: chatops.v1.ChatOpsService.WatchRuns:input_type -> chatops.v1.WatchRunsRequest
+	1,  // 12: chatops.v1.ChatOpsService.ProcessCommand:output_type -> chatops.v1.ProcessCommandResponse
+	3,  // 13: chatops.v1.ChatOpsService.ListRepositories:output_type -> chatops.v1.ListRepositoriesResponse
+	8,  // 14: chatops.v1.ChatOpsService.WatchRuns:output_type -> chatops.v1.RunUpdate
+	12, // [12:15] is the sub-list for method output_type
+	9,  // [9:12] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_chatops_v1_chatops_proto_init() }
+func file_chatops_v1_chatops_proto_init() {
+	if File_chatops_v1_chatops_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chatops_v1_chatops_proto_rawDesc), len(file_chatops_v1_chatops_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_chatops_v1_chatops_proto_goTypes,
+		DependencyIndexes: file_chatops_v1_chatops_proto_depIdxs,
+		MessageInfos:      file_chatops_v1_chatops_proto_msgTypes,
+	}.Build()
+	File_chatops_v1_chatops_proto = out.File
+	file_chatops_v1_chatops_proto_goTypes = nil
+	file_chatops_v1_chatops_proto_depIdxs = nil
+}
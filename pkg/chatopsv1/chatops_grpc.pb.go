@@ -0,0 +1,219 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: chatops/v1/chatops.proto
+
+package chatopsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ChatOpsService_ProcessCommand_FullMethodName   = "/chatops.v1.ChatOpsService/ProcessCommand"
+	ChatOpsService_ListRepositories_FullMethodName = "/chatops.v1.ChatOpsService/ListRepositories"
+	ChatOpsService_WatchRuns_FullMethodName        = "/chatops.v1.ChatOpsService/WatchRuns"
+)
+
+// ChatOpsServiceClient is the client API for ChatOpsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChatOpsService runs ChatOps commands and streams run status. Calls are authenticated
+// with the API tokens of the HTTP API, sent as "authorization: Bearer <token>" metadata,
+// and each method requires the same token scope as its HTTP counterpart.
+type ChatOpsServiceClient interface {
+	// ProcessCommand runs a command as the user of the token, subject to RBAC. Requires
+	// the commands:run scope.
+	ProcessCommand(ctx context.Context, in *ProcessCommandRequest, opts ...grpc.CallOption) (*ProcessCommandResponse, error)
+	// ListRepositories lists the managed repositories. Requires the repositories:read scope.
+	ListRepositories(ctx context.Context, in *ListRepositoriesRequest, opts ...grpc.CallOption) (*ListRepositoriesResponse, error)
+	// WatchRuns streams run status changes. Watching a single run sends its current state
+	// first and ends once it finished. Requires the runs:read scope.
+	WatchRuns(ctx context.Context, in *WatchRunsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RunUpdate], error)
+}
+
+type chatOpsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChatOpsServiceClient(cc grpc.ClientConnInterface) ChatOpsServiceClient {
+	return &chatOpsServiceClient{cc}
+}
+
+func (c *chatOpsServiceClient) ProcessCommand(ctx context.Context, in *ProcessCommandRequest, opts ...grpc.CallOption) (*ProcessCommandResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessCommandResponse)
+	err := c.cc.Invoke(ctx, ChatOpsService_ProcessCommand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatOpsServiceClient) ListRepositories(ctx context.Context, in *ListRepositoriesRequest, opts ...grpc.CallOption) (*ListRepositoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRepositoriesResponse)
+	err := c.cc.Invoke(ctx, ChatOpsService_ListRepositories_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatOpsServiceClient) WatchRuns(ctx context.Context, in *WatchRunsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RunUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChatOpsService_ServiceDesc.Streams[0], ChatOpsService_WatchRuns_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRunsRequest, RunUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatOpsService_WatchRunsClient = grpc.ServerStreamingClient[RunUpdate]
+
+// ChatOpsServiceServer is the server API for ChatOpsService service.
+// All implementations must embed UnimplementedChatOpsServiceServer
+// for forward compatibility.
+//
+// ChatOpsService runs ChatOps commands and streams run status. Calls are authenticated
+// with the API tokens of the HTTP API, sent as "authorization: Bearer <token>" metadata,
+// and each method requires the same token scope as its HTTP counterpart.
+type ChatOpsServiceServer interface {
+	// ProcessCommand runs a command as the user of the token, subject to RBAC. Requires
+	// the commands:run scope.
+	ProcessCommand(context.Context, *ProcessCommandRequest) (*ProcessCommandResponse, error)
+	// ListRepositories lists the managed repositories. Requires the repositories:read scope.
+	ListRepositories(context.Context, *ListRepositoriesRequest) (*ListRepositoriesResponse, error)
+	// WatchRuns streams run status changes. Watching a single run sends its current state
+	// first and ends once it finished. Requires the runs:read scope.
+	WatchRuns(*WatchRunsRequest, grpc.ServerStreamingServer[RunUpdate]) error
+	mustEmbedUnimplementedChatOpsServiceServer()
+}
+
+// UnimplementedChatOpsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChatOpsServiceServer struct{}
+
+func (UnimplementedChatOpsServiceServer) ProcessCommand(context.Context, *ProcessCommandRequest) (*ProcessCommandResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessCommand not implemented")
+}
+func (UnimplementedChatOpsServiceServer) ListRepositories(context.Context, *ListRepositoriesRequest) (*ListRepositoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRepositories not implemented")
+}
+func (UnimplementedChatOpsServiceServer) WatchRuns(*WatchRunsRequest, grpc.ServerStreamingServer[RunUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRuns not implemented")
+}
+func (UnimplementedChatOpsServiceServer) mustEmbedUnimplementedChatOpsServiceServer() {}
+func (UnimplementedChatOpsServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeChatOpsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChatOpsServiceServer will
+// result in compilation errors.
+type UnsafeChatOpsServiceServer interface {
+	mustEmbedUnimplementedChatOpsServiceServer()
+}
+
+func RegisterChatOpsServiceServer(s grpc.ServiceRegistrar, srv ChatOpsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedChatOpsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChatOpsService_ServiceDesc, srv)
+}
+
+func _ChatOpsService_ProcessCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatOpsServiceServer).ProcessCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatOpsService_ProcessCommand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatOpsServiceServer).ProcessCommand(ctx, req.(*ProcessCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatOpsService_ListRepositories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRepositoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatOpsServiceServer).ListRepositories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChatOpsService_ListRepositories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatOpsServiceServer).ListRepositories(ctx, req.(*ListRepositoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatOpsService_WatchRuns_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRunsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatOpsServiceServer).WatchRuns(m, &grpc.GenericServerStream[WatchRunsRequest, RunUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChatOpsService_WatchRunsServer = grpc.ServerStreamingServer[RunUpdate]
+
+// ChatOpsService_ServiceDesc is the grpc.ServiceDesc for ChatOpsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChatOpsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "chatops.v1.ChatOpsService",
+	HandlerType: (*ChatOpsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ProcessCommand",
+			Handler:    _ChatOpsService_ProcessCommand_Handler,
+		},
+		{
+			MethodName: "ListRepositories",
+			Handler:    _ChatOpsService_ListRepositories_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRuns",
+			Handler:       _ChatOpsService_WatchRuns_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "chatops/v1/chatops.proto",
+}
@@ -0,0 +1,273 @@
+// Package client is a typed Go client of the ChatOps HTTP API, following the OpenAPI
+// document served at /api/v1/openapi.json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is returned for responses with an error status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("chatops API: %d %s", e.StatusCode, e.Message)
+}
+
+// Client calls the ChatOps HTTP API
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+type Options struct {
+	BaseURL    string       // Server URL, e.g. https://chatops.example.com
+	Token      string       // API token or admin token
+	HTTPClient *http.Client // Optional: defaults to a client with a 30 second timeout
+}
+
+// New creates a new instance of Client
+func New(opts Options) (*Client, error) {
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("base URL is required")
+	}
+	if _, err := url.Parse(opts.BaseURL); err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	client := &Client{
+		baseURL:    strings.TrimSuffix(opts.BaseURL, "/"),
+		token:      opts.Token,
+		httpClient: opts.HTTPClient,
+	}
+	if client.httpClient == nil {
+		client.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return client, nil
+}
+
+// RunCommand runs a command as the user of the token
+func (c *Client) RunCommand(ctx context.Context, cmd *Command) (*CommandResult, error) {
+	var result CommandResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/commands", nil, cmd, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) ListRepositories(ctx context.Context) ([]Repository, error) {
+	var repos []Repository
+	err := c.do(ctx, http.MethodGet, "/api/v1/repositories", nil, nil, &repos)
+	return repos, err
+}
+
+func (c *Client) ListPipelines(ctx context.Context, repository string) ([]Pipeline, error) {
+	var pipelines []Pipeline
+	err := c.do(ctx, http.MethodGet, "/api/v1/repositories/"+url.PathEscape(repository)+"/pipelines", nil, nil, &pipelines)
+	return pipelines, err
+}
+
+// ListRuns lists tracked runs, newest first
+func (c *Client) ListRuns(ctx context.Context, filter RunFilter) ([]Run, error) {
+	query := url.Values{}
+	setQuery(query, "repository", filter.Repository)
+	setQuery(query, "pipeline", filter.Pipeline)
+	setQuery(query, "user", filter.User)
+	setLimit(query, filter.Limit)
+
+	var runs []Run
+	err := c.do(ctx, http.MethodGet, "/api/v1/runs", query, nil, &runs)
+	return runs, err
+}
+
+func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
+	var run Run
+	if err := c.do(ctx, http.MethodGet, "/api/v1/runs/"+url.PathEscape(id), nil, nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListAuditEvents lists recorded events, newest first
+func (c *Client) ListAuditEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	query := url.Values{}
+	setQuery(query, "type", filter.Type)
+	setQuery(query, "repository", filter.Repository)
+	setQuery(query, "actor", filter.Actor)
+	if !filter.Since.IsZero() {
+		query.Set("since", filter.Since.UTC().Format(time.RFC3339))
+	}
+	setLimit(query, filter.Limit)
+
+	var events []AuditEvent
+	err := c.do(ctx, http.MethodGet, "/api/v1/audit", query, nil, &events)
+	return events, err
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := c.do(ctx, http.MethodGet, "/api/v1/webhooks", nil, nil, &webhooks)
+	return webhooks, err
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, req *WebhookRequest) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, http.MethodPost, "/api/v1/webhooks", nil, req, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id string) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, http.MethodGet, "/api/v1/webhooks/"+url.PathEscape(id), nil, nil, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id string, req *WebhookRequest) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, http.MethodPut, "/api/v1/webhooks/"+url.PathEscape(id), nil, req, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/webhooks/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ListDeliveries shows the delivery log of a webhook, newest first. A zero limit uses the
+// server default.
+func (c *Client) ListDeliveries(ctx context.Context, id string, limit int) ([]WebhookDelivery, error) {
+	query := url.Values{}
+	setLimit(query, limit)
+
+	var deliveries []WebhookDelivery
+	err := c.do(ctx, http.MethodGet, "/api/v1/webhooks/"+url.PathEscape(id)+"/deliveries", query, nil, &deliveries)
+	return deliveries, err
+}
+
+func (c *Client) ListTokens(ctx context.Context) ([]Token, error) {
+	var tokens []Token
+	err := c.do(ctx, http.MethodGet, "/api/v1/tokens", nil, nil, &tokens)
+	return tokens, err
+}
+
+// CreateToken creates an API token. The returned Token field is its only copy.
+func (c *Client) CreateToken(ctx context.Context, req *TokenRequest) (*Token, error) {
+	var token Token
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tokens", nil, req, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (c *Client) RevokeToken(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/tokens/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// Health reports the health of the server. Unhealthy servers are reported through the
+// status rather than an error.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	err := c.do(ctx, http.MethodGet, "/health", nil, nil, &health)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable && health.Status != "" {
+		return &health, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// do sends a request, decoding the JSON response into out when it is not nil. Error
+// responses are returned as *APIError, with the body decoded into out as well.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var errorBody struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &errorBody) == nil && errorBody.Message != "" {
+			apiErr.Message = errorBody.Message
+		}
+		if out != nil {
+			json.Unmarshal(data, out)
+		}
+		return apiErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func setLimit(query url.Values, limit int) {
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+}
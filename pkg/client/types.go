@@ -0,0 +1,179 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// API token scopes
+const (
+	ScopeRunCommands      = "commands:run"
+	ScopeReadRepositories = "repositories:read"
+	ScopeReadRuns         = "runs:read"
+	ScopeReadAudit        = "audit:read"
+	ScopeManageWebhooks   = "webhooks:manage"
+	ScopeManageTokens     = "tokens:manage"
+)
+
+// Command is a command to run as the user of the token
+type Command struct {
+	Type       string                 `json:"type"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	ChannelID  string                 `json:"channel_id,omitempty"` // Optional: channel notifications of the command are posted to
+}
+
+// CommandResult is the outcome of a command. Denied and invalid commands report the
+// "error" status rather than failing the request.
+type CommandResult struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+type Repository struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	URL           string        `json:"url"`
+	DefaultBranch string        `json:"default_branch"`
+	AddedBy       string        `json:"added_by"`
+	AddedAt       time.Time     `json:"added_at"`
+	Pipelines     []Pipeline    `json:"pipelines"`
+	Environments  []Environment `json:"environments"`
+}
+
+type Pipeline struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	IsDefault bool   `json:"is_default"`
+}
+
+type Environment struct {
+	Name     string `json:"name"`
+	Pipeline string `json:"pipeline"`
+	Ref      string `json:"ref,omitempty"`
+}
+
+// Run statuses
+const (
+	RunStatusQueued     = "queued"
+	RunStatusInProgress = "in_progress"
+	RunStatusCancelling = "cancelling"
+	RunStatusSuccess    = "success"
+	RunStatusFailure    = "failure"
+	RunStatusCancelled  = "cancelled"
+	RunStatusUntracked  = "untracked" // The provider never identified the triggered run
+)
+
+type Run struct {
+	ID          string    `json:"id"`
+	Provider    string    `json:"provider"`
+	Repository  string    `json:"repository"`
+	Pipeline    string    `json:"pipeline"`
+	Environment string    `json:"environment,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Status      string    `json:"status"`
+	URL         string    `json:"url,omitempty"`
+	TriggeredBy string    `json:"triggered_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsFinished reports whether the run reached a final status
+func (r *Run) IsFinished() bool {
+	switch r.Status {
+	case RunStatusSuccess, RunStatusFailure, RunStatusCancelled, RunStatusUntracked:
+		return true
+	default:
+		return false
+	}
+}
+
+// RunFilter narrows ListRuns. Empty fields match every run.
+type RunFilter struct {
+	Repository string
+	Pipeline   string
+	User       string
+	Limit      int // Optional: defaults to 50 on the server
+}
+
+type AuditEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Repository string          `json:"repository,omitempty"`
+	Actor      string          `json:"actor,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// AuditFilter narrows ListAuditEvents. Empty fields match every event.
+type AuditFilter struct {
+	Type       string // Event filter, such as "deployment" or "lock.taken"
+	Repository string
+	Actor      string
+	Since      time.Time
+	Limit      int // Optional: defaults to 100 on the server
+}
+
+type Webhook struct {
+	ID        string            `json:"id"`
+	URL       string            `json:"url"`
+	Events    []string          `json:"events"`
+	HasSecret bool              `json:"has_secret"`
+	Headers   map[string]string `json:"headers"`
+	Active    bool              `json:"active"`
+	CreatedBy string            `json:"created_by"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// WebhookRequest creates or replaces a webhook. A nil secret keeps the current one on
+// update, and a nil active flag means active.
+type WebhookRequest struct {
+	URL     string            `json:"url"`
+	Events  []string          `json:"events"`
+	Secret  *string           `json:"secret,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Active  *bool             `json:"active,omitempty"`
+}
+
+type WebhookDelivery struct {
+	ID            string     `json:"id"`
+	EventID       string     `json:"event_id,omitempty"`
+	EventType     string     `json:"event_type"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// Token is an API token. Token holds its value only when returned by CreateToken.
+type Token struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     string     `json:"user_id"`
+	Scopes     []string   `json:"scopes"`
+	Token      string     `json:"token,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type TokenRequest struct {
+	Name      string     `json:"name"`
+	UserID    string     `json:"user_id,omitempty"` // Optional: defaults to the user of the requesting token
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type Health struct {
+	Status    string            `json:"status"`
+	Timestamp time.Time         `json:"timestamp"`
+	Services  map[string]string `json:"services"`
+}